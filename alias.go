@@ -0,0 +1,53 @@
+package uam
+
+import "fmt"
+
+// WithAliases renames species as they are read, so that files produced by
+// different mechanisms or preprocessors can be compared and merged
+// without converting them first. aliases maps species names as they
+// appear in a file to the names they should be given; for example,
+// {"FORM": "HCHO"} treats FORM and HCHO as the same species. Aliases
+// apply to Spnames, to the keys of the Data map filled by ReadHour, and
+// to the names given to SelectSpecies. It is an error for two species in
+// a file to be given the same name.
+func WithAliases(aliases map[string]string) Option {
+	return func(f *UAM) {
+		f.aliases = aliases
+	}
+}
+
+// applyAliases renames the species in f.Spnames according to f.aliases.
+func (f *UAM) applyAliases() error {
+	if len(f.aliases) == 0 {
+		return nil
+	}
+	seen := make(map[string]string, len(f.Spnames))
+	for l, spname := range f.Spnames {
+		name := spname
+		if alias, ok := f.aliases[spname]; ok {
+			name = alias
+		}
+		if prev, ok := seen[name]; ok {
+			return fmt.Errorf("species %v and %v are both named %v after "+
+				"applying aliases", prev, spname, name)
+		}
+		seen[name] = spname
+		if name != spname {
+			if f.fileNames == nil {
+				f.fileNames = make(map[string]string)
+			}
+			f.fileNames[name] = spname
+			f.Spnames[l] = name
+		}
+	}
+	return nil
+}
+
+// fileName returns the name that species spname has within the file,
+// before any aliases were applied.
+func (f *UAM) fileName(spname string) string {
+	if name, ok := f.fileNames[spname]; ok {
+		return name
+	}
+	return spname
+}
@@ -0,0 +1,165 @@
+package uam
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+)
+
+// AuditCheck is the result of one check made by AuditFile.
+type AuditCheck struct {
+	Name     string `json:"name"`
+	Passed   bool   `json:"passed"`
+	Critical bool   `json:"critical"` // whether failing the check fails the file
+	Message  string `json:"message,omitempty"`
+}
+
+// AuditReport summarizes the health of a file.
+type AuditReport struct {
+	File   string       `json:"file"`
+	Hours  int          `json:"hours"` // number of complete hours read
+	Checks []AuditCheck `json:"checks"`
+	Score  float64      `json:"score"`  // percentage of checks passed
+	Passed bool         `json:"passed"` // whether all critical checks passed
+}
+
+// AuditOptions holds options for AuditFile.
+type AuditOptions struct {
+	Mechanism     string // if set, check that the species belong to this mechanism
+	AllowNegative bool   // whether negative values are acceptable
+}
+
+// AuditFile reads a whole file and checks its health: that the header
+// and every record marker are valid, that the file is complete and its
+// hours are continuous, that the species are valid, and that the values
+// are finite, non-negative, and not all zero. It combines the results
+// into a score and an overall pass or fail, for auditing archives of
+// model inputs and outputs.
+func AuditFile(filename string, opts AuditOptions) *AuditReport {
+	r := &AuditReport{File: filename}
+	defer r.score()
+	check := func(name string, critical bool, err error) bool {
+		c := AuditCheck{Name: name, Passed: err == nil, Critical: critical}
+		if err != nil {
+			c.Message = err.Error()
+		}
+		r.Checks = append(r.Checks, c)
+		return c.Passed
+	}
+
+	f, err := Open(filename, WithStrict(), WithTolerant())
+	if !check("header", true, err) {
+		return r
+	}
+	defer f.Close()
+	if opts.Mechanism != "" {
+		check("species", false, f.ValidateSpecies(opts.Mechanism))
+	}
+
+	var continuity error
+	nonFinite := make(map[string]int)
+	negative := make(map[string]int)
+	nonZero := make(map[string]bool)
+	data := make(map[string][]float32)
+	for {
+		if _, _, _, _, _, _, err = f.ReadHour(data); err != nil {
+			break
+		}
+		date, time := AddHours(f.sdate, f.begtim, r.Hours)
+		if continuity == nil && (f.date != date || f.time != time) {
+			continuity = fmt.Errorf("hour %d starts at %d %g; it should "+
+				"start at %d %g", r.Hours, f.date, f.time, date, time)
+		}
+		r.Hours++
+		for spname, vals := range data {
+			for _, v := range vals {
+				switch {
+				case math.IsNaN(float64(v)) || math.IsInf(float64(v), 0):
+					nonFinite[spname]++
+				case v < 0:
+					negative[spname]++
+				case v > 0:
+					nonZero[spname] = true
+				}
+			}
+		}
+	}
+	if err == io.EOF {
+		err = nil
+	}
+	check("records", true, err)
+	if err == nil {
+		err = f.Truncated()
+	}
+	check("complete", true, err)
+	if want := hoursBetween(f.sdate, f.begtim, f.edate, f.endtim); continuity == nil && want >= 0 && r.Hours != want {
+		continuity = fmt.Errorf("file has %d hours but the header covers %d",
+			r.Hours, want)
+	}
+	check("continuity", true, continuity)
+	check("finite", true, speciesCounts(nonFinite, "non-finite values"))
+	if !opts.AllowNegative {
+		check("non-negative", false, speciesCounts(negative, "negative values"))
+	}
+	var zero []string
+	for _, spname := range f.Spnames {
+		if !nonZero[spname] && nonFinite[spname] == 0 && negative[spname] == 0 {
+			zero = append(zero, spname)
+		}
+	}
+	if len(zero) > 0 && r.Hours > 0 {
+		err = fmt.Errorf("all values are zero for %v", strings.Join(zero, ", "))
+	} else {
+		err = nil
+	}
+	check("non-zero", false, err)
+	return r
+}
+
+// score calculates the score and overall result from the checks.
+func (r *AuditReport) score() {
+	r.Passed = true
+	passed := 0
+	for _, c := range r.Checks {
+		if c.Passed {
+			passed++
+		} else if c.Critical {
+			r.Passed = false
+		}
+	}
+	r.Score = 100 * float64(passed) / float64(len(r.Checks))
+}
+
+// speciesCounts returns an error describing the number of problem values
+// for each species in counts, or nil if there are none.
+func speciesCounts(counts map[string]int, problem string) error {
+	if len(counts) == 0 {
+		return nil
+	}
+	var msgs []string
+	for spname, n := range counts {
+		msgs = append(msgs, fmt.Sprintf("%v (%d)", spname, n))
+	}
+	sort.Strings(msgs)
+	return fmt.Errorf("%v: %v", problem, strings.Join(msgs, ", "))
+}
+
+// hoursBetween returns the number of hours from the start date and time
+// to the end date and time, or -1 if the end is not a whole number of
+// hours (up to ten years) after the start.
+func hoursBetween(sdate int32, stime float32, edate int32, etime float32) int {
+	if etime == 24 {
+		// Times of 24 are used for the end of a day.
+		edate, etime = AddHours(edate, 0, 24)
+	}
+	date, time := sdate, stime
+	for n := 0; n <= 24*366*10; n++ {
+		if date == edate && time == etime {
+			return n
+		}
+		date, time = AddHours(date, time, 1)
+	}
+	return -1
+}
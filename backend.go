@@ -0,0 +1,100 @@
+package uam
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Backend is a place where files can be written, such as a local
+// directory, object storage, or memory.
+type Backend interface {
+	// Create returns a writer for a new file called name, replacing
+	// any existing file. The file is complete once the writer is closed.
+	Create(name string) (io.WriteCloser, error)
+}
+
+// CreateIn creates a file called name in backend b and writes the
+// header information in f to it, like Create. The returned Writer must
+// be closed to finish writing the file.
+func CreateIn(b Backend, name string, f *UAM) (*Writer, error) {
+	wc, err := b.Create(name)
+	if err != nil {
+		return nil, err
+	}
+	w, err := NewWriter(wc, f)
+	if err != nil {
+		wc.Close()
+		return nil, err
+	}
+	w.c = wc
+	return w, nil
+}
+
+// LocalBackend writes files to the local file system. Files are locked
+// while they are being written, as described for Create.
+type LocalBackend struct {
+	Dir string // directory that names are relative to; "" means the current directory
+}
+
+// Create implements Backend.
+func (l LocalBackend) Create(name string) (io.WriteCloser, error) {
+	filename := name
+	if l.Dir != "" {
+		filename = filepath.Join(l.Dir, name)
+	}
+	fid, err := os.OpenFile(filename, os.O_RDWR|os.O_CREATE, 0666)
+	if err != nil {
+		return nil, err
+	}
+	// Truncate only after locking, so a locked file is left intact.
+	if err = lockFile(fid); err == nil {
+		err = fid.Truncate(0)
+	}
+	if err != nil {
+		fid.Close()
+		return nil, fmt.Errorf("%v: %w", filename, err)
+	}
+	return fid, nil
+}
+
+// MemoryBackend holds files in memory, which is useful for testing and
+// for passing generated files to other code without touching disk. It
+// is safe for concurrent use.
+type MemoryBackend struct {
+	mu    sync.Mutex
+	files map[string][]byte
+}
+
+// Create implements Backend.
+func (m *MemoryBackend) Create(name string) (io.WriteCloser, error) {
+	return &memoryFile{m: m, name: name}, nil
+}
+
+// File returns the contents of the file called name, which is only
+// available once its writer has been closed.
+func (m *MemoryBackend) File(name string) ([]byte, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	b, ok := m.files[name]
+	return b, ok
+}
+
+type memoryFile struct {
+	bytes.Buffer
+	m    *MemoryBackend
+	name string
+}
+
+func (f *memoryFile) Close() error {
+	f.m.mu.Lock()
+	defer f.m.mu.Unlock()
+	if f.m.files == nil {
+		f.m.files = make(map[string][]byte)
+	}
+	f.m.files[f.name] = f.Bytes()
+	return nil
+}
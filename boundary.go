@@ -0,0 +1,184 @@
+package uam
+
+import (
+	"fmt"
+	"io"
+)
+
+// The edges of a grid, in the order in which they are stored in
+// BOUNDARY files.
+const (
+	westEdge = iota + 1
+	eastEdge
+	southEdge
+	northEdge
+)
+
+// BoundaryWriter writes BOUNDARY files, which hold the boundary
+// conditions of a CAMx domain: the values of each species in the cells
+// along the edges of the grid, for each layer and hour.
+type BoundaryWriter struct {
+	*Writer
+}
+
+// NewBoundaryWriter writes the header information in f, which must be a
+// gridded file called "BOUNDARY", to w, followed by the records that
+// define the boundary cells along each edge of the grid. Every cell
+// along each edge is a boundary cell, except that the corner cells are
+// not used.
+func NewBoundaryWriter(w io.Writer, f *UAM) (*BoundaryWriter, error) {
+	if f.Name != "BOUNDARY" {
+		return nil, fmt.Errorf("%v is not a BOUNDARY file", f.Name)
+	}
+	if f.Nx < 3 || f.Ny < 3 || f.Nz <= 0 {
+		return nil, fmt.Errorf("invalid grid dimensions %dx%dx%d", f.Nx, f.Ny, f.Nz)
+	}
+	wr, err := NewWriter(w, f)
+	if err != nil {
+		return nil, err
+	}
+	for edge := int32(westEdge); edge <= northEdge; edge++ {
+		ncell, loc := f.Ny, int32(2) // index of the first cell inside of the boundary
+		switch edge {
+		case eastEdge:
+			loc = f.Nx - 1
+		case southEdge:
+			ncell = f.Nx
+		case northEdge:
+			ncell, loc = f.Nx, f.Ny-1
+		}
+		wr.put(int32(1), edge, ncell)
+		for n := int32(0); n < ncell; n++ {
+			if n == 0 || n == ncell-1 {
+				wr.put(int32(0), int32(0), int32(0), int32(0))
+			} else {
+				wr.put(loc, int32(0), int32(0), int32(0))
+			}
+		}
+		if err = wr.flush(); err != nil {
+			return nil, err
+		}
+	}
+	return &BoundaryWriter{Writer: wr}, nil
+}
+
+// CreateBoundary creates a file called filename and writes the header
+// information in f to it, as with NewBoundaryWriter. The file is locked
+// while it is being written, as described for Create. The returned
+// BoundaryWriter should be closed when all hours have been written.
+func CreateBoundary(filename string, f *UAM) (*BoundaryWriter, error) {
+	wc, err := LocalBackend{}.Create(filename)
+	if err != nil {
+		return nil, err
+	}
+	w, err := NewBoundaryWriter(wc, f)
+	if err != nil {
+		wc.Close()
+		return nil, err
+	}
+	w.c = wc
+	return w, nil
+}
+
+// WriteHour writes the boundary conditions for the next hour, taken from
+// the cells along the edges of the grid in Data, which must hold an
+// array of Nx*Ny*Nz values (in GLIndex order) for every species in the
+// file. Values in the interior of the grid are ignored. The time of each
+// hour is calculated from the start time in the header.
+func (w *BoundaryWriter) WriteHour(Data map[string][]float32) error {
+	f := w.f
+	n := int(f.Nx * f.Ny * f.Nz)
+	for _, spname := range f.Spnames {
+		if len(Data[spname]) != n {
+			return fmt.Errorf("species %v has %d values; it should have %d",
+				spname, len(Data[spname]), n)
+		}
+	}
+	if err := w.putTime(); err != nil {
+		return err
+	}
+	for _, spname := range f.Spnames {
+		vals := Data[spname]
+		for edge := int32(westEdge); edge <= northEdge; edge++ {
+			w.put(int32(1))
+			w.putStr(spname, 40)
+			w.put(edge)
+			ncell := f.Ny
+			if edge == southEdge || edge == northEdge {
+				ncell = f.Nx
+			}
+			for c := int32(0); c < ncell; c++ {
+				var i, j int32
+				switch edge {
+				case westEdge:
+					i, j = 0, c
+				case eastEdge:
+					i, j = f.Nx-1, c
+				case southEdge:
+					i, j = c, 0
+				case northEdge:
+					i, j = c, f.Ny-1
+				}
+				for k := int32(0); k < f.Nz; k++ {
+					w.put(vals[(k*f.Ny+j)*f.Nx+i])
+				}
+			}
+			if err := w.flush(); err != nil {
+				return err
+			}
+		}
+	}
+	w.nhrs++
+	return nil
+}
+
+// ExtractBoundary writes a BOUNDARY file called outfile with boundary
+// conditions for grid nest, for one-way nesting, from every hour of the
+// AVERAGE (or INSTANT or AIRQUALITY) file called filename, such as the
+// output of a simulation on a coarser grid. The values in the boundary cells of nest are
+// interpolated from the surrounding cells of the coarse grid as with a
+// NestDown, so nest must be inside of the coarse grid and have the same
+// projection and number of layers. The rest of the header is copied from
+// the original file.
+func ExtractBoundary(filename, outfile string, nest GridDef) error {
+	f, err := Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if f.Name == "EMISSIONS" || f.Name == "PTSOURCE" {
+		return fmt.Errorf("%v: cannot extract boundary conditions from %v files", filename, f.Name)
+	}
+	out := NewLike(f, 0)
+	h := out.Header()
+	h.Name = "BOUNDARY"
+	nest.Apply(&h)
+	out.setHeader(h)
+	d, err := NewNestDown(f.Header(), h)
+	if err != nil {
+		return err
+	}
+	w, err := CreateBoundary(outfile, out)
+	if err != nil {
+		return err
+	}
+	data := make(map[string][]float32)
+	for {
+		if err = f.readHour(data); err == io.EOF {
+			break
+		} else if err != nil {
+			w.Close()
+			return err
+		}
+		res, err := d.Interpolate(data)
+		if err != nil {
+			w.Close()
+			return err
+		}
+		if err = w.WriteHour(res); err != nil {
+			w.Close()
+			return err
+		}
+	}
+	return w.Close()
+}
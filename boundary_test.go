@@ -0,0 +1,119 @@
+package uam_test
+
+import (
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ctessum/uam"
+	"github.com/ctessum/uam/uamtest"
+)
+
+// records splits b into the payloads of its Fortran records.
+func records(t *testing.T, b []byte) [][]byte {
+	t.Helper()
+	var recs [][]byte
+	for len(b) > 0 {
+		n := int(binary.BigEndian.Uint32(b))
+		if len(b) < n+8 || binary.BigEndian.Uint32(b[n+4:]) != uint32(n) {
+			t.Fatalf("invalid record of %d bytes after record %d", n, len(recs))
+		}
+		recs = append(recs, b[4:n+4])
+		b = b[n+8:]
+	}
+	return recs
+}
+
+// int32s and float32s decode the big-endian values in b.
+func int32s(b []byte) []int32 {
+	v := make([]int32, len(b)/4)
+	for n := range v {
+		v[n] = int32(binary.BigEndian.Uint32(b[4*n:]))
+	}
+	return v
+}
+
+func float32s(b []byte) []float32 {
+	v := make([]float32, len(b)/4)
+	for n := range v {
+		v[n] = math.Float32frombits(binary.BigEndian.Uint32(b[4*n:]))
+	}
+	return v
+}
+
+func TestExtractBoundary(t *testing.T) {
+	// The concentrations increase by 1 with each parent column.
+	a, err := uamtest.Average(uamtest.Options{Hours: 2, Pattern: uamtest.Linear(0, 0, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	nest, err := uamtest.DefaultGrid.NestedGrid(1, 3, 1, 2, 2) // 6x4 cells
+	if err != nil {
+		t.Fatal(err)
+	}
+	outfile := filepath.Join(t.TempDir(), "bc.uam")
+	if err = uam.ExtractBoundary(uamtest.TempFile(t, a), outfile, nest); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(outfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recs := records(t, b)
+	// Each hour has a time record and a record for each edge of each
+	// species, and they follow a record defining each edge.
+	const perHour = 1 + 3*4
+	hours := recs[len(recs)-2*perHour:]
+	defs := recs[len(recs)-2*perHour-4 : len(recs)-2*perHour]
+
+	for e, want := range [][2]int32{{4, 2}, {4, 5}, {6, 2}, {6, 3}} { // W, E, S, N
+		v := int32s(defs[e])
+		if len(v) != 3+4*int(want[0]) || v[1] != int32(e+1) || v[2] != want[0] {
+			t.Fatalf("edge %d: got definition %v", e+1, v)
+		}
+		if v[3] != 0 || v[3+4] != want[1] || v[len(v)-4] != 0 {
+			t.Errorf("edge %d: got cells %v, want corners of 0 and others at %d", e+1, v[3:], want[1])
+		}
+	}
+
+	if got := int32s(hours[perHour][:4])[0]; got != 15001 {
+		t.Errorf("hour 1: got date %d", got)
+	}
+	if got := float32s(hours[perHour][4:8])[0]; got != 1 {
+		t.Errorf("hour 1: got time %g", got)
+	}
+	// The O3 records of the second hour for the west, east, and south
+	// edges, and the value in each of their cells.
+	o3 := hours[perHour+1+2*4:]
+	for e, want := range []func(c int) float32{
+		func(int) float32 { return 0.25 },
+		func(int) float32 { return 0.25 + 0.5*5 },
+		func(c int) float32 { return 0.25 + 0.5*float32(c) },
+	} {
+		// Each character of the name takes four bytes.
+		var name []byte
+		for c := 0; c < 10; c++ {
+			name = append(name, o3[e][4+4*c])
+		}
+		if got := strings.TrimSpace(string(name)); got != "O3" {
+			t.Errorf("edge %d: got species %q", e+1, got)
+		}
+		vals := float32s(o3[e][48:])
+		for n, v := range vals {
+			if c := n / int(nest.Nz); v != want(c) {
+				t.Errorf("edge %d cell %d: got %g, want %g", e+1, c, v, want(c))
+			}
+		}
+	}
+
+	e, err := uamtest.Emissions(uamtest.Options{Hours: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = uam.ExtractBoundary(uamtest.TempFile(t, e), outfile, nest); err == nil {
+		t.Error("emissions file: got no error")
+	}
+}
@@ -0,0 +1,72 @@
+package uam
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+)
+
+// ReadHours reads hours from through to-1 (counting from 0) of f into
+// new Hours, decoding several hours at once with the number of goroutines
+// set with WithWorkers, or GOMAXPROCS if it is not set. The hours are read
+// with a Handle, so they have the species selection, window, and units
+// that apply to ReadHour, and the position of f is not changed. f must
+// be an uncompressed file with random access.
+func (f *UAM) ReadHours(from, to int) ([]*Hour, error) {
+	if from < 0 || to < from {
+		return nil, fmt.Errorf("invalid range of hours [%d, %d)", from, to)
+	}
+	h, err := f.Handle()
+	if err != nil {
+		return nil, err
+	}
+	workers := f.workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	hours := make([]*Hour, to-from)
+	errs := make([]error, to-from)
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(workers, len(hours)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := range jobs {
+				hours[n], errs[n] = h.ReadHour(from + n)
+				if errs[n] != nil {
+					// Drain remaining jobs.
+					for range jobs {
+					}
+					return
+				}
+			}
+		}()
+	}
+	for n := range hours {
+		jobs <- n
+	}
+	close(jobs)
+	wg.Wait()
+	for n, err := range errs {
+		if err == io.EOF {
+			return nil, fmt.Errorf("hour %d is not in the file", from+n)
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	return hours, nil
+}
+
+// ReadAll reads all of the complete hours of f, like ReadHours.
+func (f *UAM) ReadAll() ([]*Hour, error) {
+	n := f.CompleteHours()
+	if f.index != nil {
+		n = len(f.index.Hours)
+	}
+	if n < 0 {
+		return nil, fmt.Errorf("the number of hours in the file is not known")
+	}
+	return f.ReadHours(0, n)
+}
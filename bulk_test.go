@@ -0,0 +1,162 @@
+package uam_test
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ctessum/uam"
+	"github.com/ctessum/uam/uamtest"
+)
+
+func TestHandle(t *testing.T) {
+	f, err := uamtest.PointSource(uamtest.Options{Hours: 6})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Hours[4].Overrides = []uam.StackOverride{{I: 1}, {J: 2}, {K: -1, PlumeHeight: 300}}
+	r := open(t, f)
+	h, err := r.Handle()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make([]*uam.Hour, len(f.Hours))
+	errs := make([]error, len(f.Hours))
+	var wg sync.WaitGroup
+	for n := range got {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			got[n], errs[n] = h.ReadHour(n)
+		}(n)
+	}
+	wg.Wait()
+	for n, err := range errs {
+		if err != nil {
+			t.Fatalf("hour %d: %v", n, err)
+		}
+	}
+	checkHours(t, got, f.Hours)
+	if _, err = h.ReadHour(len(f.Hours)); err != io.EOF {
+		t.Errorf("hour past the end: got %v, want io.EOF", err)
+	}
+
+	// The handle keeps the settings the file had when it was made.
+	r.SelectSpecies([]string{"NO"})
+	hr, err := h.ReadHour(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hr.Data) != len(f.Spnames) {
+		t.Errorf("got species %v, want all", hr.Data)
+	}
+}
+
+func TestReadHours(t *testing.T) {
+	f, err := uamtest.Emissions(uamtest.Options{Hours: 30})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := open(t, f, uam.WithWorkers(4))
+	hours, err := r.ReadHours(3, 27)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkHours(t, hours, f.Hours[3:27])
+	if hours, err = r.ReadAll(); err != nil {
+		t.Fatal(err)
+	}
+	checkHours(t, hours, f.Hours)
+	// The position of the file does not change.
+	checkHours(t, readAll(t, r), f.Hours)
+
+	if _, err = r.ReadHours(25, 31); err == nil {
+		t.Error("hours past the end: got no error")
+	}
+	if _, err = r.ReadHours(5, 4); err == nil {
+		t.Error("invalid range: got no error")
+	}
+}
+
+// countingReaderAt counts the most calls to ReadAt that are in progress
+// at once.
+type countingReaderAt struct {
+	ra       io.ReaderAt
+	mu       sync.Mutex
+	cur, max int
+}
+
+func (c *countingReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	c.mu.Lock()
+	c.cur++
+	c.max = max(c.max, c.cur)
+	c.mu.Unlock()
+	time.Sleep(time.Millisecond)
+	defer func() {
+		c.mu.Lock()
+		c.cur--
+		c.mu.Unlock()
+	}()
+	return c.ra.ReadAt(p, off)
+}
+
+func TestReadHoursSequential(t *testing.T) {
+	f, err := uamtest.Emissions(uamtest.Options{Hours: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	if err = f.Write(&b); err != nil {
+		t.Fatal(err)
+	}
+	// One worker must be used even when more could run.
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+	ra := &countingReaderAt{ra: bytes.NewReader(b.Bytes())}
+	r, err := uam.NewReaderAt(ra, int64(b.Len()), uam.WithWorkers(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hours, err := r.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkHours(t, hours, f.Hours)
+	if ra.max != 1 {
+		t.Errorf("got up to %d reads at once with one worker, want 1", ra.max)
+	}
+}
+
+func TestHandleWindow(t *testing.T) {
+	f, err := uamtest.Emissions(uamtest.Options{Hours: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := open(t, f)
+	if err = r.SetWindow(1, 3, 1, 3); err != nil {
+		t.Fatal(err)
+	}
+	hours, err := r.ReadHours(0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for h, hr := range hours {
+		surface := hr.Surface("NO")
+		if len(surface) != 4 || surface[3] != uamtest.Index(0, h, 0, 2, 2) {
+			t.Errorf("hour %d: got surface %v, want the 2x2 window", h, surface)
+		}
+		p, err := hr.Profile("NO", 2, 1, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := []float32{uamtest.Index(0, h, 0, 1, 2), uamtest.Index(0, h, 1, 1, 2)}; !reflect.DeepEqual(p.Values, want) {
+			t.Errorf("hour %d: got profile %v, want %v", h, p.Values, want)
+		}
+		if _, err = hr.Profile("NO", 0, 0, nil); err == nil {
+			t.Errorf("hour %d: profile outside of the window: got no error", h)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package uam
+
+import "bytes"
+
+// NewBytesReader reads the header info from data, which holds a whole
+// file, and returns a UAM that reads hours of data from it. Data are
+// decoded directly from the slice, as with OpenMmap, with full support
+// for random access, and nothing is read from the file system, so it can
+// be used where there is none, such as in a browser with GOOS=js, to
+// read files that have been loaded into memory. Compressed data are
+// decompressed as they are read, as with NewReader, and can only be read
+// sequentially. data must not be modified while the UAM is in use.
+func NewBytesReader(data []byte, opts ...Option) (*UAM, error) {
+	if bytes.HasPrefix(data, gzipMagic) || bytes.HasPrefix(data, zstdMagic) {
+		return NewReader(bytes.NewReader(data), opts...)
+	}
+	return newMemReader(data, opts)
+}
+
+// newMemReader reads the header info from the uncompressed file held in
+// data.
+func newMemReader(data []byte, opts []Option) (*UAM, error) {
+	f := newUAM(opts)
+	f.r = newMemStream(data)
+	if err := f.readHeader(); err != nil {
+		return nil, f.parseError(err, -1, f.r.off)
+	}
+	return f, nil
+}
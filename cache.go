@@ -0,0 +1,182 @@
+package uam
+
+import (
+	"container/list"
+	"sync"
+)
+
+// Cache holds decoded hours of species data in memory, up to a limit on
+// the number of bytes of data, so that tools that read the same hours
+// over and over, such as interactive viewers, only decode them once.
+// When the cache is full, the least recently used entries are discarded.
+// Entries are keyed by file, hour, and species, and hold the values of
+// all layers over the whole grid as stored in the file, so one cache
+// can be shared by any number of files and readers regardless of their
+// windows and units. It is safe for concurrent use.
+type Cache struct {
+	mu       sync.Mutex
+	maxBytes int64
+	bytes    int64
+	entries  map[cacheKey]*list.Element
+	lru      list.List // of *cacheEntry, most recently used first
+	stats    CacheStats
+}
+
+// CacheStats counts the lookups in a Cache.
+type CacheStats struct {
+	Hits, Misses int64
+	Evictions    int64 // entries discarded to make room for others
+}
+
+type cacheKey struct {
+	file    string
+	hour    int
+	species string
+}
+
+type cacheEntry struct {
+	key  cacheKey
+	vals []float32
+}
+
+// NewCache returns an empty cache that holds up to maxBytes bytes of
+// data.
+func NewCache(maxBytes int64) *Cache {
+	return &Cache{maxBytes: maxBytes, entries: make(map[cacheKey]*list.Element)}
+}
+
+// WithCache makes ReadLayer, ReadSpecies, ReadHour, ReadNextHour, and
+// ReadHours look up the data they read in c before decoding them, and
+// add the data they decode to c. When reading hours in order, the
+// records of cached species are skipped, and the hours are decoded by
+// one goroutine regardless of WithWorkers. Files are identified by the
+// name they were opened with, so files read with NewReader or
+// NewReaderAt, which have no name, are not cached. Entries for a file
+// that has changed since it was cached must be removed with Remove.
+func WithCache(c *Cache) Option {
+	return func(f *UAM) {
+		f.cache = c
+	}
+}
+
+// readCachedRecords reads the records of species l in the current hour,
+// one for each layer with n values each, from the current position of f
+// into dst, keeping only the values within the window, if any. If the
+// species is in the cache, its records are skipped and the cached
+// values are used; otherwise they are decoded over the whole grid and
+// added to the cache.
+func (f *UAM) readCachedRecords(l int, n int64, dst []float32) error {
+	spname := f.Spnames[l]
+	key := cacheKey{file: f.name, hour: f.hour, species: f.fileName(spname)}
+	vals, ok := f.cache.get(key)
+	if ok {
+		if err := skip(f.r, int64(f.layers())*f.speciesRecordSize(n)); err != nil {
+			return err
+		}
+	} else {
+		vals = make([]float32, n*int64(f.layers()))
+		for k := int64(0); k < int64(f.layers()); k++ {
+			off := f.r.off
+			length, err := f.readSpeciesName(spname, n)
+			if err != nil {
+				return err
+			}
+			f.logRecord(spname, int32(k), off, length)
+			if err = f.r.readFloats(vals[k*n : (k+1)*n]); err != nil {
+				return err
+			}
+			if err = f.endRecord(f.speciesRecordSize(n) - 2*f.markerLen()); err != nil {
+				return err
+			}
+		}
+		f.cache.put(key, vals)
+	}
+	size := f.layerSize()
+	for k := int32(0); k < f.layers(); k++ {
+		f.copyLayer(dst[int(k)*size:int(k+1)*size], vals, k)
+	}
+	return nil
+}
+
+// get returns the cached values for key, which must not be modified.
+func (c *Cache) get(key cacheKey) ([]float32, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		c.stats.Misses++
+		return nil, false
+	}
+	c.stats.Hits++
+	c.lru.MoveToFront(e)
+	return e.Value.(*cacheEntry).vals, true
+}
+
+// put adds vals to the cache under key, discarding the least recently
+// used entries to make room. Values larger than the whole cache are not
+// added.
+func (c *Cache) put(key cacheKey, vals []float32) {
+	size := 4 * int64(len(vals))
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if size > c.maxBytes {
+		return
+	}
+	if e, ok := c.entries[key]; ok {
+		c.remove(e)
+	}
+	for c.bytes+size > c.maxBytes {
+		c.remove(c.lru.Back())
+		c.stats.Evictions++
+	}
+	c.entries[key] = c.lru.PushFront(&cacheEntry{key: key, vals: vals})
+	c.bytes += size
+}
+
+// remove removes entry e. c.mu must be held.
+func (c *Cache) remove(e *list.Element) {
+	ce := c.lru.Remove(e).(*cacheEntry)
+	delete(c.entries, ce.key)
+	c.bytes -= 4 * int64(len(ce.vals))
+}
+
+// Remove removes all entries for the named file.
+func (c *Cache) Remove(file string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, e := range c.entries {
+		if key.file == file {
+			c.remove(e)
+		}
+	}
+}
+
+// Clear removes all entries.
+func (c *Cache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[cacheKey]*list.Element)
+	c.lru.Init()
+	c.bytes = 0
+}
+
+// Len returns the number of entries in the cache.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// Bytes returns the number of bytes of data in the cache.
+func (c *Cache) Bytes() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.bytes
+}
+
+// Stats returns the numbers of lookups and evictions so far.
+func (c *Cache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
@@ -0,0 +1,128 @@
+package uam_test
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/ctessum/uam"
+	"github.com/ctessum/uam/uamtest"
+)
+
+func TestCache(t *testing.T) {
+	f, err := uamtest.Emissions(uamtest.Options{Hours: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	filename := uamtest.TempFile(t, f)
+	// Each entry holds the 24 values of a species in one hour.
+	c := uam.NewCache(2 * 24 * 4)
+	r, err := uam.Open(filename, uam.WithCache(c))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	vals, err := r.ReadSpecies(0, "NO")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(vals, f.Hours[0].Data["NO"]) {
+		t.Errorf("got %v, want %v", vals, f.Hours[0].Data["NO"])
+	}
+	// Reading a layer of the same species uses the cached values, and
+	// the window is applied to them.
+	if err = r.SetWindow(1, 3, 0, 1); err != nil {
+		t.Fatal(err)
+	}
+	dst := make([]float32, 2)
+	if err = r.ReadLayer(0, "NO", 1, dst); err != nil {
+		t.Fatal(err)
+	}
+	if want := f.Hours[0].Data["NO"][13:15]; !reflect.DeepEqual(dst, want) {
+		t.Errorf("got layer %v, want %v", dst, want)
+	}
+	if s := c.Stats(); s.Hits != 1 || s.Misses != 1 || c.Len() != 1 || c.Bytes() != 96 {
+		t.Errorf("got %+v with %d entries of %d bytes, want 1 hit and 1 miss in 1 entry of 96 bytes",
+			s, c.Len(), c.Bytes())
+	}
+
+	// The least recently used entry is evicted to make room.
+	for _, species := range []string{"NO2", "NO", "O3", "NO"} {
+		if _, err = r.ReadSpecies(0, species); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if s := c.Stats(); s.Hits != 3 || s.Misses != 3 || s.Evictions != 1 || c.Len() != 2 {
+		t.Errorf("got %+v with %d entries, want 3 hits, 3 misses, 1 eviction, and 2 entries",
+			s, c.Len())
+	}
+
+	// Files without names are not cached.
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := uam.NewBytesReader(data, uam.WithCache(c))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = m.ReadSpecies(1, "NO"); err != nil {
+		t.Fatal(err)
+	}
+	if c.Len() != 2 {
+		t.Errorf("got %d entries after reading an unnamed file, want 2", c.Len())
+	}
+
+	c.Remove(filename)
+	if c.Len() != 0 || c.Bytes() != 0 {
+		t.Errorf("got %d entries of %d bytes after Remove, want none", c.Len(), c.Bytes())
+	}
+}
+
+func TestCacheHours(t *testing.T) {
+	o := uamtest.Options{Hours: 3}
+	for _, newFile := range []func(uamtest.Options) (*uam.UAM, error){uamtest.Emissions, uamtest.PointSource} {
+		f, err := newFile(o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		filename := uamtest.TempFile(t, f)
+		c := uam.NewCache(1 << 20)
+		r, err := uam.Open(filename, uam.WithCache(c))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer r.Close()
+		checkHours(t, readAll(t, r), f.Hours)
+		n := int64(o.Hours * len(f.Spnames))
+		if s := c.Stats(); s.Hits != 0 || s.Misses != n || c.Len() != int(n) {
+			t.Errorf("%v: got %+v with %d entries, want %d misses and entries", f.Name, s, c.Len(), n)
+		}
+
+		// Reading the hours again, with a different window and species,
+		// decodes nothing.
+		r, err = uam.Open(filename, uam.WithCache(c), uam.WithWorkers(3))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer r.Close()
+		seq := open(t, f)
+		if f.Name != "PTSOURCE" {
+			r.SetWindow(1, 3, 1, 3)
+			seq.SetWindow(1, 3, 1, 3)
+		}
+		r.SelectSpecies([]string{"NO2"})
+		seq.SelectSpecies([]string{"NO2"})
+		want := readAll(t, seq)
+		checkHours(t, readAll(t, r), want)
+		hours, err := r.ReadHours(0, o.Hours)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkHours(t, hours, want)
+		if s := c.Stats(); s.Hits != 2*int64(o.Hours) || s.Misses != n {
+			t.Errorf("%v: got %+v, want %d hits and %d misses", f.Name, s, 2*o.Hours, n)
+		}
+	}
+}
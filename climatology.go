@@ -0,0 +1,137 @@
+package uam
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Climatology averages the hourly fields of a set of files that share
+// the same type, grid, and species, such as a month or season of daily
+// AVERAGE files, into a single mean day. weights gives the weight of each
+// file; if it is nil all files are weighted equally. Files that do not
+// exist (missing days) are left out of the average, as are the missing
+// hours of truncated files, and the weights of the remaining files are
+// renormalized separately for each hour. The returned header holds the
+// header information, species, and stacks of the first available file,
+// but no input: it can be passed to Create or NewWriter to write out the
+// climatology, but it cannot be read from.
+func Climatology(filenames []string, weights []float64) (hdr *UAM, hours []map[string][]float32, err error) {
+	if weights != nil && len(weights) != len(filenames) {
+		return nil, nil, fmt.Errorf("there are %d weights but %d files",
+			len(weights), len(filenames))
+	}
+	var sums []map[string][]float64
+	var wsums []float64
+	for fi, filename := range filenames {
+		w := 1.
+		if weights != nil {
+			w = weights[fi]
+		}
+		f, err := Open(filename)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return nil, nil, err
+		}
+		if hdr == nil {
+			h := *f
+			h.closer, h.r = nil, nil
+			h.Note = fmt.Sprintf("Climatology of %d files", len(filenames))
+			hdr = &h
+		} else if err = sameStructure(hdr, f); err != nil {
+			f.Close()
+			return nil, nil, fmt.Errorf("%v: %w", filename, err)
+		}
+		for ihr := 0; ihr < int(f.Nhrs); ihr++ {
+			data := make(map[string][]float32)
+			_, _, _, _, _, _, err = f.ReadHour(data)
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				break // Truncated file.
+			} else if err != nil {
+				f.Close()
+				return nil, nil, fmt.Errorf("%v: %w", filename, err)
+			}
+			if ihr == len(sums) {
+				sums = append(sums, make(map[string][]float64))
+				wsums = append(wsums, 0)
+			}
+			for spname, vals := range data {
+				sum := sums[ihr][spname]
+				if sum == nil {
+					sum = make([]float64, len(vals))
+					sums[ihr][spname] = sum
+				}
+				for i, v := range vals {
+					sum[i] += w * float64(v)
+				}
+			}
+			wsums[ihr] += w
+		}
+		f.Close()
+	}
+	if hdr == nil {
+		return nil, nil, fmt.Errorf("none of the %d climatology input "+
+			"files exist", len(filenames))
+	}
+	hours = make([]map[string][]float32, len(sums))
+	for ihr, sum := range sums {
+		if wsums[ihr] == 0 {
+			return nil, nil, fmt.Errorf("total weight for hour %d is zero", ihr)
+		}
+		hours[ihr] = make(map[string][]float32)
+		for spname, vals := range sum {
+			mean := make([]float32, len(vals))
+			for i, v := range vals {
+				mean[i] = float32(v / wsums[ihr])
+			}
+			hours[ihr][spname] = mean
+		}
+	}
+	return hdr, hours, nil
+}
+
+// WriteClimatology calculates the climatology of filenames (see
+// Climatology) and writes it to a file called outfile.
+func WriteClimatology(outfile string, filenames []string, weights []float64) error {
+	hdr, hours, err := Climatology(filenames, weights)
+	if err != nil {
+		return err
+	}
+	w, err := Create(outfile, hdr)
+	if err != nil {
+		return err
+	}
+	for _, data := range hours {
+		if err = w.WriteHour(data); err != nil {
+			w.Close()
+			return err
+		}
+	}
+	return w.Close()
+}
+
+// sameStructure returns an error if a and b do not have the same file
+// type, grid dimensions, and species.
+func sameStructure(a, b *UAM) error {
+	if a.Name != b.Name {
+		return fmt.Errorf("file type %v does not match %v", b.Name, a.Name)
+	}
+	if a.Nx != b.Nx || a.Ny != b.Ny || a.Nz != b.Nz || a.Npts != b.Npts {
+		return fmt.Errorf("dimensions %dx%dx%d (%d points) do not match "+
+			"%dx%dx%d (%d points)", b.Nx, b.Ny, b.Nz, b.Npts,
+			a.Nx, a.Ny, a.Nz, a.Npts)
+	}
+	if len(a.Spnames) != len(b.Spnames) {
+		return fmt.Errorf("%w: %d species does not match %d",
+			ErrSpeciesMismatch, len(b.Spnames), len(a.Spnames))
+	}
+	for i := range a.Spnames {
+		if a.Spnames[i] != b.Spnames[i] {
+			return fmt.Errorf("%w: species %v does not match %v",
+				ErrSpeciesMismatch, b.Spnames[i], a.Spnames[i])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package uam_test
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/ctessum/uam"
+	"github.com/ctessum/uam/uamtest"
+)
+
+func TestClimatology(t *testing.T) {
+	dir := t.TempDir()
+	var filenames []string
+	for n, o := range []uamtest.Options{
+		{Pattern: uamtest.Constant(1)},
+		{Pattern: uamtest.Constant(2)},
+		{Pattern: uamtest.Constant(4), Hours: 12}, // a truncated day
+	} {
+		f, err := uamtest.Average(o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		filename := filepath.Join(dir, fmt.Sprintf("day%d.uam", n))
+		if err = f.WriteFile(filename); err != nil {
+			t.Fatal(err)
+		}
+		filenames = append(filenames, filename)
+	}
+	filenames = append(filenames, filepath.Join(dir, "missing.uam"))
+	out := filepath.Join(dir, "climatology.uam")
+	if err := uam.WriteClimatology(out, filenames, []float64{1, 1, 2, 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := uam.Open(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if r.Note != "Climatology of 4 files" {
+		t.Errorf("got note %q", r.Note)
+	}
+	hours := readAll(t, r)
+	if len(hours) != 24 {
+		t.Fatalf("got %d hours, want 24", len(hours))
+	}
+	for h, hr := range hours {
+		// The missing file is left out, as are the missing hours of the
+		// truncated one.
+		want := float32(1+2+2*4) / 4
+		if h >= 12 {
+			want = float32(1+2) / 2
+		}
+		for spname, vals := range hr.Data {
+			for _, v := range vals {
+				if v != want {
+					t.Fatalf("hour %d %v: got %g, want %g", h, spname, v, want)
+				}
+			}
+		}
+	}
+
+	if _, _, err = uam.Climatology(filenames, []float64{1}); err == nil {
+		t.Error("wrong number of weights: got no error")
+	}
+	if _, _, err = uam.Climatology(filenames[3:], nil); err == nil {
+		t.Error("no files: got no error")
+	}
+	e, err := uamtest.Emissions(uamtest.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = uam.Climatology([]string{filenames[0], uamtest.TempFile(t, e)}, nil); err == nil {
+		t.Error("different file types: got no error")
+	}
+}
@@ -0,0 +1,56 @@
+package uam
+
+import (
+	"fmt"
+	"io"
+)
+
+// Clone returns an independent reader over the same file, positioned at
+// the same hour, with the same options, species selection, and window.
+// Reads from the clone do not affect the position of f or of other
+// clones, so several goroutines can each use their own clone to process
+// different hours (see SeekHour) or species of one file at the same time.
+// Clones of files opened with Open or OpenFS have their own file
+// descriptor and must be closed separately. Clones of files opened with OpenMmap share
+// the mapped memory, so f must not be closed until its clones are no
+// longer in use.
+func (f *UAM) Clone() (*UAM, error) {
+	c := *f
+	c.ctx = nil
+	c.skipped = append([]SkippedSpan(nil), f.skipped...)
+	c.Hours = append([]*Hour(nil), f.Hours...)
+	switch {
+	case f.r != nil && f.r.mem:
+		c.unmap = nil
+		c.r = newMemStream(f.r.data)
+	case f.reopen != nil && !f.compressed():
+		src, closer, err := f.reopen()
+		if err != nil {
+			return nil, err
+		}
+		c.closer = closer
+		c.r = newStream(src, f.bufSize)
+	default:
+		return nil, fmt.Errorf("cannot clone a reader that is not " +
+			"reading from an uncompressed file")
+	}
+	if _, err := c.r.Seek(f.r.off, io.SeekStart); err != nil {
+		c.Close()
+		return nil, err
+	}
+	return &c, nil
+}
+
+// SeekHour moves the reader to the start of the given hour (counting from
+// 0), so that the next call to ReadHour reads that hour. Seeking backward
+// requires a file opened with Open or OpenMmap.
+func (f *UAM) SeekHour(hour int) error {
+	if hour < 0 {
+		return fmt.Errorf("invalid hour %d", hour)
+	}
+	if _, err := f.r.Seek(f.hourOffset(hour), io.SeekStart); err != nil {
+		return err
+	}
+	f.hour = hour
+	return nil
+}
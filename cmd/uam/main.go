@@ -0,0 +1,53 @@
+// Command uam provides tools for working with UAM files, such as CAMx
+// emissions and average concentration files, as subcommands that share
+// the same flags for selecting species, hours, and layers.
+//
+// Usage:
+//
+//	uam <command> [flags] [arguments]
+//
+// Run "uam help" for a list of commands and "uam help <command>" for
+// the flags of a command.
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/ctessum/uam/internal/cli"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	name, args := os.Args[1], os.Args[2:]
+	if name == "help" || name == "-h" || name == "-help" || name == "--help" {
+		if len(args) == 0 {
+			usage()
+			return
+		}
+		name, args = args[0], []string{"-h"}
+	}
+	for _, c := range cli.Commands {
+		if c.Name == name {
+			os.Exit(c.Run("uam "+name, args))
+		}
+	}
+	fmt.Fprintf(os.Stderr, "uam: unknown command %q\n", name)
+	usage()
+	os.Exit(2)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: uam <command> [flags] [arguments]")
+	fmt.Fprintln(os.Stderr, "\ncommands:")
+	tw := tabwriter.NewWriter(os.Stderr, 0, 8, 2, ' ', 0)
+	for _, c := range cli.Commands {
+		fmt.Fprintf(tw, "\t%v\t%v\n", c.Name, c.Summary)
+	}
+	tw.Flush()
+	fmt.Fprintln(os.Stderr, "\nRun \"uam help <command>\" for the flags of a command.")
+}
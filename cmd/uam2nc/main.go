@@ -0,0 +1,18 @@
+// Command uam2nc converts UAM files to NetCDF. Each input file is
+// written to a file with the same name and the extension .nc, either
+// next to the input or in the directory given by -o.
+//
+// Usage:
+//
+//	uam2nc [-o dir] [-species NO,NO2] file...
+package main
+
+import (
+	"os"
+
+	"github.com/ctessum/uam/internal/cli"
+)
+
+func main() {
+	os.Exit(cli.Convert.Run("uam2nc", os.Args[1:]))
+}
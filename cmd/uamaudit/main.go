@@ -0,0 +1,19 @@
+// Command uamaudit checks the health of UAM files, such as an archive
+// of model inputs and outputs, and reports a score and a pass or fail
+// result for each file. Directories are searched recursively for files
+// matching a pattern. The exit status is 1 if any file fails.
+//
+// Usage:
+//
+//	uamaudit [-pattern glob] [-mechanism name] [-json] path...
+package main
+
+import (
+	"os"
+
+	"github.com/ctessum/uam/internal/cli"
+)
+
+func main() {
+	os.Exit(cli.Validate.Run("uamaudit", os.Args[1:]))
+}
@@ -0,0 +1,21 @@
+// Command uamdiff compares two UAM files hour by hour, such as the
+// outputs of two versions of an emissions processing pipeline. For each
+// species and hour that differ, it reports the largest absolute and
+// relative differences, the cell where the largest difference occurs,
+// and the number of values that differ by more than the tolerances. The
+// exit status is 1 if the files differ.
+//
+// Usage:
+//
+//	uamdiff [-abs tol] [-rel tol] [-all] [-species NO,NO2] [-hours 0-5] a b
+package main
+
+import (
+	"os"
+
+	"github.com/ctessum/uam/internal/cli"
+)
+
+func main() {
+	os.Exit(cli.Diff.Run("uamdiff", os.Args[1:]))
+}
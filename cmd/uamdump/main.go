@@ -0,0 +1,18 @@
+// Command uamdump writes the data in a UAM file to standard output in
+// long-format CSV, with one row per value, for inspection in
+// spreadsheets and data analysis tools.
+//
+// Usage:
+//
+//	uamdump [-species NO,NO2] [-hours 0-5,12] [-layers 0] [-skip-zero] file
+package main
+
+import (
+	"os"
+
+	"github.com/ctessum/uam/internal/cli"
+)
+
+func main() {
+	os.Exit(cli.Dump.Run("uamdump", os.Args[1:]))
+}
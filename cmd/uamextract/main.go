@@ -0,0 +1,21 @@
+// Command uamextract writes point data from a gridded UAM file, such as
+// a CAMx AVERAGE file, to standard output as CSV: a time series or
+// vertical profile at a grid cell or longitude and latitude, or a single
+// layer as a grid.
+//
+// Usage:
+//
+//	uamextract -at i,j [-layers 0] [-species O3] [-hours 0-23] file
+//	uamextract -lonlat lon,lat [-layers 0] [-species O3] file
+//	uamextract -layer k [-species O3] [-hours 12] file
+package main
+
+import (
+	"os"
+
+	"github.com/ctessum/uam/internal/cli"
+)
+
+func main() {
+	os.Exit(cli.Extract.Run("uamextract", os.Args[1:]))
+}
@@ -0,0 +1,18 @@
+// Command uaminfo prints a summary of UAM files: the file type, grid
+// definition, projection, species, time span, and, optionally, the
+// total of each species over the whole file.
+//
+// Usage:
+//
+//	uaminfo [-totals=false] file...
+package main
+
+import (
+	"os"
+
+	"github.com/ctessum/uam/internal/cli"
+)
+
+func main() {
+	os.Exit(cli.Info.Run("uaminfo", os.Args[1:]))
+}
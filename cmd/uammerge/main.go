@@ -0,0 +1,18 @@
+// Command uammerge sums gridded EMISSIONS files, such as area, mobile,
+// and biogenic emissions, cell by cell into a single file. The files
+// must have the same grid, species, and time span.
+//
+// Usage:
+//
+//	uammerge -o merged.bin file...
+package main
+
+import (
+	"os"
+
+	"github.com/ctessum/uam/internal/cli"
+)
+
+func main() {
+	os.Exit(cli.Merge.Run("uammerge", os.Args[1:]))
+}
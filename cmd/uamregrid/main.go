@@ -0,0 +1,35 @@
+// Command uamregrid resamples a gridded UAM file onto another grid with
+// the same number of layers, which may use a different projection.
+// Emissions are conserved and other values, such as concentrations, are
+// area-weighted averages.
+//
+// The destination grid and its projection are described in a YAML file
+// (see uam.GridConfig), for example:
+//
+//	nx: 100
+//	ny: 80
+//	nz: 1
+//	dx: 12000
+//	dy: 12000
+//	xorig: -2412000
+//	yorig: -1620000
+//	iproj: 2 # Lambert conformal conic
+//	orgx: -97
+//	orgy: 40
+//	tlat1: 33
+//	tlat2: 45
+//
+// Usage:
+//
+//	uamregrid -grid grid.yaml -o output file
+package main
+
+import (
+	"os"
+
+	"github.com/ctessum/uam/internal/cli"
+)
+
+func main() {
+	os.Exit(cli.Regrid.Run("uamregrid", os.Args[1:]))
+}
@@ -0,0 +1,19 @@
+// Command uamscale multiplies species in a UAM file by constant factors
+// or by a per-cell factor grid and writes the result to a new file, for
+// building emission sensitivity scenarios.
+//
+// Usage:
+//
+//	uamscale -o output [-species NO,NO2] [-factor 0.7] [-factors SO2=0.5]
+//		[-grid factors.bin -grid-species FACTOR] file
+package main
+
+import (
+	"os"
+
+	"github.com/ctessum/uam/internal/cli"
+)
+
+func main() {
+	os.Exit(cli.Scale.Run("uamscale", os.Args[1:]))
+}
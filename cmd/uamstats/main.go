@@ -0,0 +1,19 @@
+// Command uamstats prints summary statistics of each species in each
+// hour of a UAM file: the minimum, maximum, mean, and sum of the finite
+// values and the numbers of NaN and negative values. Statistics can be
+// written as a table, CSV, or JSON lines for use in QA scripts.
+//
+// Usage:
+//
+//	uamstats [-csv | -json] [-species NO,NO2] [-hours 0-5] [-layers 0] file
+package main
+
+import (
+	"os"
+
+	"github.com/ctessum/uam/internal/cli"
+)
+
+func main() {
+	os.Exit(cli.Stats.Run("uamstats", os.Args[1:]))
+}
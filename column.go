@@ -0,0 +1,51 @@
+package uam
+
+import "fmt"
+
+// Column integrates vals, which holds the values of one species in one
+// hour of gridded file f in the format returned by ReadHour, over the
+// layers of the grid, returning one value per grid cell (of the window
+// set by SetWindow, if any) in the same order as the layers. If layers is
+// nil, the values are summed, as for emissions; otherwise each value is
+// multiplied by the thickness (m) of its layer, so that, for example,
+// concentrations per m³ become column amounts per m². The layer
+// structure of an hour can be taken from a height/pressure file with
+// ZP.Layers.
+func (f *UAM) Column(vals []float32, layers Layers) ([]float32, error) {
+	if f.Name == "PTSOURCE" {
+		return nil, fmt.Errorf("columns can only be integrated for gridded files")
+	}
+	_, _, nx, ny := f.WindowGrid()
+	n := nx * ny
+	if len(vals) != int(n*f.Nz) {
+		return nil, fmt.Errorf("field has %d values; it should have %d",
+			len(vals), n*f.Nz)
+	}
+	var i1, j1 int32
+	if f.window != nil {
+		i1, j1 = f.window.i1, f.window.j1
+	}
+	col := make([]float32, n)
+	for j := int32(0); j < ny; j++ {
+		for i := int32(0); i < nx; i++ {
+			var tops []float32
+			if layers != nil {
+				if tops = layers.LayerTops(i+i1, j+j1); len(tops) < int(f.Nz) {
+					return nil, fmt.Errorf("there are %d layer tops but %d layers",
+						len(tops), f.Nz)
+				}
+			}
+			var sum, bottom float64
+			for k := int32(0); k < f.Nz; k++ {
+				v := float64(vals[k*n+j*nx+i])
+				if tops != nil {
+					v *= float64(tops[k]) - bottom
+					bottom = float64(tops[k])
+				}
+				sum += v
+			}
+			col[j*nx+i] = float32(sum)
+		}
+	}
+	return col, nil
+}
@@ -0,0 +1,225 @@
+package uam
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"slices"
+)
+
+// CompareOptions controls how files are compared by Compare.
+type CompareOptions struct {
+	// Values a and b differ if |a-b| > Abs + Rel*|b|. NaN values are
+	// equal to each other and differ from all other values.
+	Abs, Rel float64
+	// Filter restricts the species, layers, and hours that are
+	// compared. SkipZero is ignored.
+	Filter TidyFilter
+}
+
+// differs returns whether a and b differ by more than the tolerances.
+func (o CompareOptions) differs(a, b float64) bool {
+	if math.IsNaN(a) || math.IsNaN(b) {
+		return math.IsNaN(a) != math.IsNaN(b)
+	}
+	return math.Abs(a-b) > o.Abs+o.Rel*math.Abs(b)
+}
+
+// HeaderDiff is a field of the Header whose value differs between two
+// files.
+type HeaderDiff struct {
+	Field string
+	A, B  interface{}
+}
+
+// Location is the location of a value in a file.
+type Location struct {
+	Hour int // index of the hour, counting from 0
+	// K, J, and I are the layer, row, and column of a value in a
+	// gridded file. For PTSOURCE files, I is the index of the stack.
+	K, J, I int32
+}
+
+// SpeciesDiff summarizes the differences between the values of one
+// species in two files.
+type SpeciesDiff struct {
+	Species string
+	// Hour is the hour that is summarized, counting from 0, or -1 if
+	// the summary covers all hours compared.
+	Hour           int
+	MaxAbs, MaxRel float64  // largest absolute and relative differences
+	At             Location // location of the largest absolute difference
+	First          Location // first value that differs, if Differing > 0
+	Differing      int      // number of values that differ
+	Values         int      // number of values compared
+}
+
+// add compares value a with value b at loc.
+func (d *SpeciesDiff) add(loc Location, a, b float64, opts CompareOptions) {
+	d.Values++
+	if !opts.differs(a, b) {
+		if a == b || math.IsNaN(a) {
+			return
+		}
+	} else {
+		if d.Differing == 0 {
+			d.First = loc
+		}
+		d.Differing++
+	}
+	abs := math.Abs(a - b)
+	if math.IsNaN(abs) {
+		abs = math.Inf(1)
+	}
+	if abs > d.MaxAbs || d.MaxAbs == 0 {
+		d.MaxAbs, d.At = abs, loc
+	}
+	if b != 0 {
+		d.MaxRel = math.Max(d.MaxRel, abs/math.Abs(b))
+	} else {
+		d.MaxRel = math.Inf(1)
+	}
+}
+
+// merge adds the summary of another hour, o, to d.
+func (d *SpeciesDiff) merge(o SpeciesDiff) {
+	if o.Differing > 0 && d.Differing == 0 {
+		d.First = o.First
+	}
+	if o.MaxAbs > d.MaxAbs || (d.MaxAbs == 0 && o.MaxAbs != 0) {
+		d.MaxAbs, d.At = o.MaxAbs, o.At
+	}
+	d.MaxRel = math.Max(d.MaxRel, o.MaxRel)
+	d.Differing += o.Differing
+	d.Values += o.Values
+}
+
+// Comparison is the result of comparing two files with Compare.
+type Comparison struct {
+	Header []HeaderDiff // header fields that differ
+	OnlyA  []string     // species that are only in the first file
+	OnlyB  []string     // species that are only in the second file
+	Hours  int          // number of hours compared
+	// Species summarizes the differences in each species that is in
+	// both files over all hours compared, in the order of the first
+	// file, and Hourly summarizes them for each hour and species.
+	Species []SpeciesDiff
+	Hourly  []SpeciesDiff
+}
+
+// Equal returns whether the files compared are the same to within the
+// tolerances.
+func (c *Comparison) Equal() bool {
+	if len(c.Header) > 0 || len(c.OnlyA) > 0 || len(c.OnlyB) > 0 {
+		return false
+	}
+	for _, d := range c.Species {
+		if d.Differing > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Compare compares the remaining hours of files a and b, which must have
+// the same type and dimensions, value by value, for use in regression
+// tests of the programs that produce them. Hours are matched in order,
+// and it is an error for the files to have different numbers of hours
+// (unless opts.Filter.Hours is set). Neither file may have a window, and
+// species selections are set on both as they are read.
+func Compare(a, b *UAM, opts CompareOptions) (*Comparison, error) {
+	if a.Name != b.Name || a.Nx != b.Nx || a.Ny != b.Ny || a.Nz != b.Nz || a.Npts != b.Npts {
+		return nil, fmt.Errorf("cannot compare %v file with %dx%dx%d cells "+
+			"(%d points) to %v file with %dx%dx%d cells (%d points)",
+			a.Name, a.Nx, a.Ny, a.Nz, a.Npts, b.Name, b.Nx, b.Ny, b.Nz, b.Npts)
+	}
+	if a.window != nil || b.window != nil {
+		return nil, fmt.Errorf("cannot compare files with windows")
+	}
+	c := new(Comparison)
+	ha, hb := reflect.ValueOf(a.Header()), reflect.ValueOf(b.Header())
+	for i := 0; i < ha.NumField(); i++ {
+		if va, vb := ha.Field(i).Interface(), hb.Field(i).Interface(); va != vb {
+			c.Header = append(c.Header, HeaderDiff{Field: ha.Type().Field(i).Name, A: va, B: vb})
+		}
+	}
+	filter := opts.Filter
+	selected := func(spname string) bool {
+		return filter.Species == nil || slices.Contains(filter.Species, spname)
+	}
+	var species []string
+	for _, spname := range a.Spnames {
+		if !selected(spname) {
+			continue
+		}
+		if slices.Contains(b.Spnames, spname) {
+			species = append(species, spname)
+		} else {
+			c.OnlyA = append(c.OnlyA, spname)
+		}
+	}
+	for _, spname := range b.Spnames {
+		if selected(spname) && !slices.Contains(a.Spnames, spname) {
+			c.OnlyB = append(c.OnlyB, spname)
+		}
+	}
+	if len(species) == 0 {
+		return c, nil
+	}
+	for _, f := range []*UAM{a, b} {
+		if err := f.SelectSpecies(species); err != nil {
+			return nil, err
+		}
+	}
+	c.Species = make([]SpeciesDiff, len(species))
+	for l, spname := range species {
+		c.Species[l] = SpeciesDiff{Species: spname, Hour: -1}
+	}
+
+	last := -1
+	for _, h := range filter.Hours {
+		last = max(last, h)
+	}
+	nx, nxy := int(a.Nx), int(a.Nx*a.Ny)
+	da, db := make(map[string][]float32), make(map[string][]float32)
+	for hour := 0; filter.Hours == nil || hour <= last; hour++ {
+		if err := a.readHour(da); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if err := b.readHour(db); err == io.EOF {
+			return nil, fmt.Errorf("the second file has fewer hours than the first")
+		} else if err != nil {
+			return nil, err
+		}
+		if filter.Hours != nil && !slices.Contains(filter.Hours, hour) {
+			continue
+		}
+		c.Hours++
+		for l, spname := range species {
+			d := SpeciesDiff{Species: spname, Hour: hour}
+			for i, v := range da[spname] {
+				loc := Location{Hour: hour, I: int32(i)}
+				if a.Name != "PTSOURCE" {
+					loc.K, loc.J, loc.I = int32(i/nxy), int32(i%nxy/nx), int32(i%nx)
+					if filter.Layers != nil && !slices.Contains(filter.Layers, loc.K) {
+						continue
+					}
+				}
+				d.add(loc, float64(v), float64(db[spname][i]), opts)
+			}
+			c.Species[l].merge(d)
+			c.Hourly = append(c.Hourly, d)
+		}
+	}
+	if filter.Hours == nil {
+		if err := b.readHour(db); err == nil {
+			return nil, fmt.Errorf("the second file has more hours than the first")
+		} else if err != io.EOF {
+			return nil, err
+		}
+	}
+	return c, nil
+}
@@ -0,0 +1,88 @@
+package uam_test
+
+import (
+	"testing"
+
+	"github.com/ctessum/uam"
+	"github.com/ctessum/uam/uamtest"
+)
+
+func TestCompare(t *testing.T) {
+	a, err := uamtest.Emissions(uamtest.Options{Hours: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := uamtest.Emissions(uamtest.Options{Hours: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := uam.Compare(open(t, a), open(t, b), uam.CompareOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.Equal() || c.Hours != 2 {
+		t.Errorf("identical files: got %+v, want equal with 2 hours", c)
+	}
+
+	// Change one value by 1%, in hour 1, layer 1, row 2, column 3.
+	n := (1*b.Ny+2)*b.Nx + 3
+	old := b.Hours[1].Data["NO2"][n]
+	b.Hours[1].Data["NO2"][n] *= 1.01
+	c, err = uam.Compare(open(t, a), open(t, b), uam.CompareOptions{Rel: 0.02})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.Equal() {
+		t.Errorf("difference within tolerance: got %+v, want equal", c.Species)
+	}
+	c, err = uam.Compare(open(t, a), open(t, b), uam.CompareOptions{Rel: 0.001})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Equal() {
+		t.Fatal("difference over tolerance: got equal")
+	}
+	d := c.Species[1]
+	want := uam.Location{Hour: 1, K: 1, J: 2, I: 3}
+	if d.Species != "NO2" || d.Differing != 1 || d.At != want || d.First != want {
+		t.Errorf("got %+v, want one difference in NO2 at %+v", d, want)
+	}
+	if got := d.MaxAbs; got < 0.009*float64(old) || got > 0.011*float64(old) {
+		t.Errorf("got largest difference %g, want about %g", got, 0.01*old)
+	}
+	for _, d := range []uam.SpeciesDiff{c.Species[0], c.Species[2]} {
+		if d.Differing != 0 {
+			t.Errorf("%v: got %d differences, want 0", d.Species, d.Differing)
+		}
+	}
+
+	// Only compare hour 0, and other species.
+	c, err = uam.Compare(open(t, a), open(t, b), uam.CompareOptions{Filter: uam.TidyFilter{Hours: []int{0}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.Equal() || c.Hours != 1 {
+		t.Errorf("hour 0: got %+v, want equal with 1 hour", c)
+	}
+	e, err := uamtest.Emissions(uamtest.Options{Hours: 2, Species: []string{"NO", "CO"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err = uam.Compare(open(t, a), open(t, e), uam.CompareOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Equal() || len(c.OnlyA) != 2 || len(c.OnlyB) != 1 || len(c.Species) != 1 {
+		t.Errorf("different species: got only %v and %v, and %d in both",
+			c.OnlyA, c.OnlyB, len(c.Species))
+	}
+
+	// Files with different numbers of hours.
+	f, err := uamtest.Emissions(uamtest.Options{Hours: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = uam.Compare(open(t, a), open(t, f), uam.CompareOptions{}); err == nil {
+		t.Error("different numbers of hours: got no error")
+	}
+}
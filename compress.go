@@ -0,0 +1,65 @@
+package uam
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// decompress returns a reader that decompresses src if it starts with
+// the magic bytes of gzip or zstd data, along with the decompressor,
+// which should be closed when reading is finished. Otherwise, it returns
+// src itself and a nil decompressor.
+func decompress(src io.Reader) (io.Reader, io.Closer, error) {
+	var magic []byte
+	if s, ok := src.(io.ReadSeeker); ok {
+		// Read the magic bytes and then return to the start, so
+		// that uncompressed input remains seekable.
+		pos, err := s.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, nil, err
+		}
+		magic = make([]byte, len(zstdMagic))
+		n, err := io.ReadFull(s, magic)
+		if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+			return nil, nil, err
+		}
+		magic = magic[:n]
+		if _, err = s.Seek(pos, io.SeekStart); err != nil {
+			return nil, nil, err
+		}
+	} else {
+		br := bufio.NewReader(src)
+		magic, _ = br.Peek(len(zstdMagic))
+		src = br
+	}
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		gz, err := gzip.NewReader(src)
+		if err != nil {
+			return nil, nil, err
+		}
+		return gz, gz, nil
+	case bytes.HasPrefix(magic, zstdMagic):
+		d, err := zstd.NewReader(src)
+		if err != nil {
+			return nil, nil, err
+		}
+		rc := d.IOReadCloser()
+		return rc, rc, nil
+	}
+	return src, nil, nil
+}
+
+// compressed returns whether the input is being decompressed.
+func (f *UAM) compressed() bool {
+	return f.dec != nil
+}
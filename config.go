@@ -0,0 +1,225 @@
+package uam
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// GridConfig describes a modeling domain.
+type GridConfig struct {
+	Nx    int32   `yaml:"nx"`    // number of columns
+	Ny    int32   `yaml:"ny"`    // number of rows
+	Nz    int32   `yaml:"nz"`    // number of layers
+	Dx    float32 `yaml:"dx"`    // cell width
+	Dy    float32 `yaml:"dy"`    // cell height
+	Xorig float32 `yaml:"xorig"` // x coordinate of the SW corner
+	Yorig float32 `yaml:"yorig"` // y coordinate of the SW corner
+	Iutm  int32   `yaml:"iutm"`  // UTM zone, if applicable
+
+	// Iproj is the projection type (see ProjectionType), which must be
+	// given. Orgx and Orgy are the center longitude and latitude of
+	// Lambert conformal and polar stereographic projections, and Tlat1
+	// and Tlat2 are their true latitudes.
+	Iproj *int32  `yaml:"iproj"`
+	Orgx  float32 `yaml:"orgx"`
+	Orgy  float32 `yaml:"orgy"`
+	Tlat1 float32 `yaml:"tlat1"`
+	Tlat2 float32 `yaml:"tlat2"`
+
+	// LayerTops is the height (m) of the top of each layer, if known.
+	LayerTops UniformLayers `yaml:"layer_tops"`
+}
+
+// Validate checks that the grid description is complete and consistent.
+func (g GridConfig) Validate() error {
+	if g.Nx <= 0 || g.Ny <= 0 || g.Nz <= 0 {
+		return fmt.Errorf("invalid grid dimensions %dx%dx%d", g.Nx, g.Ny, g.Nz)
+	}
+	if g.Dx <= 0 || g.Dy <= 0 {
+		return fmt.Errorf("invalid grid cell size %gx%g", g.Dx, g.Dy)
+	}
+	if g.Iproj == nil {
+		return fmt.Errorf("no projection type (iproj)")
+	}
+	switch p := ProjectionType(*g.Iproj); p {
+	case LatLon:
+	case UTM:
+		if g.Iutm == 0 {
+			return fmt.Errorf("no UTM zone (iutm)")
+		}
+	case LambertConformal:
+		if g.Tlat1 == 0 || g.Tlat2 == 0 {
+			return fmt.Errorf("the %v projection needs true latitudes "+
+				"(tlat1 and tlat2)", p)
+		}
+	case PolarStereographic:
+		if g.Tlat1 == 0 {
+			return fmt.Errorf("the %v projection needs a true latitude (tlat1)", p)
+		}
+	default:
+		return fmt.Errorf("unsupported projection type %d", *g.Iproj)
+	}
+	if g.LayerTops != nil {
+		if len(g.LayerTops) != int(g.Nz) {
+			return fmt.Errorf("there are %d layer tops but %d layers",
+				len(g.LayerTops), g.Nz)
+		}
+		for k := 1; k < len(g.LayerTops); k++ {
+			if g.LayerTops[k] <= g.LayerTops[k-1] {
+				return fmt.Errorf("layer tops are not increasing at "+
+					"layer %d", k)
+			}
+		}
+	}
+	return nil
+}
+
+// Check returns an error if the grid of gridded file f, including its
+// projection, does not match g.
+func (g GridConfig) Check(f *UAM) error {
+	d, err := g.GridDef()
+	if err != nil {
+		return err
+	}
+	return d.Check(f.GridDef())
+}
+
+// LoadGridConfig reads and validates a grid description from a YAML
+// file.
+func LoadGridConfig(filename string) (GridConfig, error) {
+	var g GridConfig
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return g, err
+	}
+	if err = yaml.Unmarshal(b, &g); err != nil {
+		return g, fmt.Errorf("%v: %w", filename, err)
+	}
+	if err = g.Validate(); err != nil {
+		return g, fmt.Errorf("%v: %w", filename, err)
+	}
+	return g, nil
+}
+
+// GridDef returns the grid definition in g, or an error if g is not
+// valid.
+func (g GridConfig) GridDef() (GridDef, error) {
+	if err := g.Validate(); err != nil {
+		return GridDef{}, err
+	}
+	return GridDef{
+		Xorig: g.Xorig, Yorig: g.Yorig, Dx: g.Dx, Dy: g.Dy,
+		Nx: g.Nx, Ny: g.Ny, Nz: g.Nz,
+		Iproj: *g.Iproj, Iutm: g.Iutm, Orgx: g.Orgx, Orgy: g.Orgy,
+		Tlat1: g.Tlat1, Tlat2: g.Tlat2,
+	}, nil
+}
+
+// Header returns the header of a file of type name on grid g.
+func (g GridConfig) Header(name string) (Header, error) {
+	d, err := g.GridDef()
+	if err != nil {
+		return Header{}, err
+	}
+	return d.Header(name), nil
+}
+
+// RunConfig describes a model run: its domain, chemical mechanism,
+// species, and time period.
+type RunConfig struct {
+	Grid      GridConfig `yaml:"grid"`
+	Mechanism string     `yaml:"mechanism"` // a key of Mechanisms, if set
+	Species   []string   `yaml:"species"`
+	StartDate int32      `yaml:"start_date"` // YYJJJ or YYYYJJJ
+	StartTime float32    `yaml:"start_time"` // hour
+	Hours     int        `yaml:"hours"`      // number of hours
+}
+
+// LoadRunConfig reads and validates a run configuration from a YAML
+// file.
+func LoadRunConfig(filename string) (*RunConfig, error) {
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	c := new(RunConfig)
+	if err = yaml.Unmarshal(b, c); err != nil {
+		return nil, fmt.Errorf("%v: %w", filename, err)
+	}
+	if err = c.Validate(); err != nil {
+		return nil, fmt.Errorf("%v: %w", filename, err)
+	}
+	return c, nil
+}
+
+// Validate checks that the configuration is complete and consistent,
+// including that the species belong to the mechanism, if one is given.
+func (c *RunConfig) Validate() error {
+	if err := c.Grid.Validate(); err != nil {
+		return err
+	}
+	if len(c.Species) == 0 {
+		return fmt.Errorf("no species")
+	}
+	if c.Mechanism != "" {
+		if err := ValidateSpecies(c.Mechanism, c.Species); err != nil {
+			return err
+		}
+	}
+	if c.StartDate <= 0 || c.StartDate%1000 < 1 || c.StartDate%1000 > 366 {
+		return fmt.Errorf("invalid start date %d", c.StartDate)
+	}
+	if c.StartTime < 0 || c.StartTime >= 24 {
+		return fmt.Errorf("invalid start time %g", c.StartTime)
+	}
+	if c.Hours <= 0 {
+		return fmt.Errorf("invalid number of hours %d", c.Hours)
+	}
+	return nil
+}
+
+// Check returns an error if the gridded file f does not match the grid,
+// species, and time period in c.
+func (c *RunConfig) Check(f *UAM) error {
+	if err := c.Grid.Check(f); err != nil {
+		return err
+	}
+	have := make(map[string]bool, len(f.Spnames))
+	for _, spname := range f.Spnames {
+		have[spname] = true
+	}
+	for _, spname := range c.Species {
+		if !have[spname] {
+			return fmt.Errorf("%w: species %v is missing",
+				ErrSpeciesMismatch, spname)
+		}
+	}
+	if f.sdate != c.StartDate || f.begtim != c.StartTime {
+		return fmt.Errorf("start %d %g does not match %d %g",
+			f.sdate, f.begtim, c.StartDate, c.StartTime)
+	}
+	return nil
+}
+
+// Header returns the header of a file of type name covering the run.
+func (c *RunConfig) Header(name string) (Header, error) {
+	h, err := c.Grid.Header(name)
+	if err != nil {
+		return h, err
+	}
+	h.Sdate, h.Begtim = c.StartDate, c.StartTime
+	h.Edate, h.Endtim = AddHours(c.StartDate, c.StartTime, c.Hours)
+	return h, nil
+}
+
+// NewGridded creates an empty gridded file of type name in memory that
+// covers the run, with the species in the configuration. See NewGridded.
+func (c *RunConfig) NewGridded(name string) (*UAM, error) {
+	h, err := c.Header(name)
+	if err != nil {
+		return nil, err
+	}
+	return NewGridded(h, c.Species)
+}
@@ -0,0 +1,82 @@
+package uam_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ctessum/uam"
+)
+
+const runConfig = `grid:
+  nx: 3
+  ny: 2
+  nz: 2
+  dx: 4000
+  dy: 4000
+  xorig: -100
+  yorig: 200
+  iproj: 2
+  orgx: -97
+  orgy: 40
+  tlat1: 33
+  tlat2: 45
+  layer_tops: [20, 50]
+species: [NO, NO2]
+start_date: 2016001
+start_time: 0
+hours: 25
+`
+
+func writeConfig(t *testing.T, config string) string {
+	t.Helper()
+	filename := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(filename, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+func TestRunConfig(t *testing.T) {
+	c, err := uam.LoadRunConfig(writeConfig(t, runConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := c.NewGridded("EMISSIONS")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := uam.GridDef{
+		Xorig: -100, Yorig: 200, Dx: 4000, Dy: 4000, Nx: 3, Ny: 2, Nz: 2,
+		Iproj: 2, Orgx: -97, Orgy: 40, Tlat1: 33, Tlat2: 45,
+	}
+	if got := f.GridDef(); got != want {
+		t.Errorf("got grid %+v, want %+v", got, want)
+	}
+	if h := f.Header(); h.Edate != 2016002 || h.Endtim != 1 {
+		t.Errorf("got end %d %g, want 2016002 1", h.Edate, h.Endtim)
+	}
+	if err = c.Check(f); err != nil {
+		t.Error(err)
+	}
+	c.Grid.Tlat2 = 60
+	if err = c.Check(f); err == nil || !strings.Contains(err.Error(), "projection") {
+		t.Errorf("changed projection: got %v, want a projection mismatch", err)
+	}
+}
+
+func TestGridConfigProjection(t *testing.T) {
+	for _, test := range []struct{ from, to, want string }{
+		{"  iproj: 2\n", "", "no projection type"},
+		{"  iproj: 2\n", "  iproj: 1\n", "no UTM zone"},
+		{"  tlat2: 45\n", "", "true latitudes"},
+		{"  iproj: 2\n", "  iproj: 7\n", "unsupported projection"},
+	} {
+		config := strings.Replace(runConfig, test.from, test.to, 1)
+		if _, err := uam.LoadRunConfig(writeConfig(t, config)); err == nil ||
+			!strings.Contains(err.Error(), test.want) {
+			t.Errorf("%q -> %q: got %v, want %v", test.from, test.to, err, test.want)
+		}
+	}
+}
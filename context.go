@@ -0,0 +1,37 @@
+package uam
+
+import "context"
+
+// OpenContext is like Open, but stops reading the header and returns
+// ctx.Err() if ctx is canceled.
+func OpenContext(ctx context.Context, filename string, opts ...Option) (*UAM, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	f, err := Open(filename, append(opts, func(f *UAM) { f.ctx = ctx })...)
+	if err != nil {
+		return nil, err
+	}
+	f.ctx = nil
+	return f, nil
+}
+
+// ReadHourContext is like ReadHour, but checks ctx between records and
+// returns an error wrapping ctx.Err() if ctx is canceled. In that case
+// the file is left partway through the hour and cannot be read further.
+func (f *UAM) ReadHourContext(ctx context.Context, Data map[string][]float32) (
+	[]float32, []float32, []float32, []float32,
+	[]float32, []float32, error) {
+	prev := f.ctx
+	f.ctx = ctx
+	defer func() { f.ctx = prev }()
+	return f.ReadHour(Data)
+}
+
+// checkContext returns the error of the current context, if any.
+func (f *UAM) checkContext() error {
+	if f.ctx == nil {
+		return nil
+	}
+	return f.ctx.Err()
+}
@@ -0,0 +1,145 @@
+package uam
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+)
+
+// contourSegments lists, for each marching squares case, the pairs of
+// cell edges (0 bottom, 1 right, 2 top, 3 left) that contour segments
+// join. Bit 0 of the case is set if the SW corner is at or above the
+// level, bit 1 the SE corner, bit 2 the NE corner, and bit 3 the NW
+// corner. The saddles, 5 and 10, are listed for a center below the
+// level; they are resolved separately when the center is above it.
+var contourSegments = [16][][2]int{
+	1: {{3, 0}}, 2: {{0, 1}}, 3: {{3, 1}}, 4: {{1, 2}},
+	5: {{3, 0}, {1, 2}}, 6: {{0, 2}}, 7: {{2, 3}}, 8: {{2, 3}},
+	9: {{0, 2}}, 10: {{0, 1}, {2, 3}}, 11: {{1, 2}}, 12: {{3, 1}},
+	13: {{0, 1}}, 14: {{3, 0}},
+}
+
+// contourLines returns the lines along which field, which holds nx*ny
+// values at the points of a lattice, is equal to level, as fractional
+// lattice coordinates (i, j). Squares with a NaN corner are skipped.
+func contourLines(field []float32, nx, ny int, level float64) [][][2]float64 {
+	v := func(i, j int) float64 { return float64(field[j*nx+i]) }
+	// Each point where a contour crosses an edge of the lattice is
+	// identified by the edge: 2*(j*nx+i) for the edge from (i, j) east
+	// and 2*(j*nx+i)+1 for the edge from (i, j) north.
+	point := func(key int) [2]float64 {
+		n := key / 2
+		i, j := n%nx, n/nx
+		i2, j2 := i+1, j
+		if key%2 == 1 {
+			i2, j2 = i, j+1
+		}
+		t := (level - v(i, j)) / (v(i2, j2) - v(i, j))
+		return [2]float64{float64(i) + t*float64(i2-i), float64(j) + t*float64(j2-j)}
+	}
+	links := make(map[int][]int)
+	for j := 0; j < ny-1; j++ {
+		for i := 0; i < nx-1; i++ {
+			c := [4]float64{v(i, j), v(i+1, j), v(i+1, j+1), v(i, j+1)}
+			idx := 0
+			skip := false
+			for b, cv := range c {
+				skip = skip || math.IsNaN(cv)
+				if cv >= level {
+					idx |= 1 << b
+				}
+			}
+			if skip {
+				continue
+			}
+			segs := contourSegments[idx]
+			if (idx == 5 || idx == 10) && (c[0]+c[1]+c[2]+c[3])/4 >= level {
+				segs = contourSegments[15-idx]
+			}
+			edges := [4]int{2 * (j*nx + i), 2*(j*nx+i+1) + 1, 2 * ((j+1)*nx + i), 2*(j*nx+i) + 1}
+			for _, s := range segs {
+				a, b := edges[s[0]], edges[s[1]]
+				links[a] = append(links[a], b)
+				links[b] = append(links[b], a)
+			}
+		}
+	}
+
+	// Join the segments into lines, starting with the ends of open
+	// lines and then following closed loops.
+	keys := make([]int, 0, len(links))
+	for k := range links {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	var lines [][][2]float64
+	follow := func(start int) {
+		line := [][2]float64{point(start)}
+		for k := start; len(links[k]) > 0; {
+			next := links[k][0]
+			links[k] = links[k][1:]
+			for n, back := range links[next] {
+				if back == k {
+					links[next] = append(links[next][:n], links[next][n+1:]...)
+					break
+				}
+			}
+			line = append(line, point(next))
+			k = next
+		}
+		lines = append(lines, line)
+	}
+	for _, k := range keys {
+		if len(links[k]) == 1 {
+			follow(k)
+		}
+	}
+	for _, k := range keys {
+		if len(links[k]) > 0 {
+			follow(k)
+		}
+	}
+	return lines
+}
+
+// WriteContours writes contour lines of a 2D field on grid g at each of
+// levels to w as a GeoJSON FeatureCollection in longitude and latitude,
+// with one MultiLineString feature per level that has the level as its
+// property level. field holds Nx*Ny values in the same order as the
+// layers of a gridded file, which are taken to be at the centers of the
+// cells. Cells with NaN values are left out of the contours.
+func (g GridDef) WriteContours(w io.Writer, field []float32, levels []float64) error {
+	if len(field) != int(g.Nx*g.Ny) {
+		return fmt.Errorf("field has %d values; it should have %d",
+			len(field), g.Nx*g.Ny)
+	}
+	p := g.Projection()
+	gw := newGeoJSONWriter(w)
+	for _, level := range levels {
+		lines := contourLines(field, int(g.Nx), int(g.Ny), level)
+		for _, line := range lines {
+			for n, pt := range line {
+				x := float64(g.Xorig) + (pt[0]+0.5)*float64(g.Dx)
+				y := float64(g.Yorig) + (pt[1]+0.5)*float64(g.Dy)
+				lon, lat, err := p.Inverse(x, y)
+				if err != nil {
+					return err
+				}
+				line[n] = [2]float64{lon, lat}
+			}
+		}
+		if lines == nil {
+			lines = [][][2]float64{}
+		}
+		err := gw.write(geoJSONFeature{
+			Type:       "Feature",
+			Geometry:   geoJSONGeometry{Type: "MultiLineString", Coordinates: lines},
+			Properties: map[string]any{"level": level},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return gw.close()
+}
@@ -0,0 +1,90 @@
+package uam_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/ctessum/uam/uamtest"
+)
+
+// contours decodes the GeoJSON written by WriteContours.
+type contours struct {
+	Features []struct {
+		Geometry struct {
+			Type        string
+			Coordinates [][][2]float64
+		}
+		Properties struct{ Level float64 }
+	}
+}
+
+func TestWriteContours(t *testing.T) {
+	g := uamtest.DefaultGrid
+	// The values increase by 1 with each column.
+	field := make([]float32, g.Nx*g.Ny)
+	for n := range field {
+		field[n] = float32(int32(n) % g.Nx)
+	}
+	var b bytes.Buffer
+	if err := g.WriteContours(&b, field, []float64{1.5, 10}); err != nil {
+		t.Fatal(err)
+	}
+	var c contours
+	if err := json.Unmarshal(b.Bytes(), &c); err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Features) != 2 || c.Features[0].Properties.Level != 1.5 ||
+		c.Features[1].Properties.Level != 10 {
+		t.Fatalf("got %+v", c)
+	}
+	if lines := c.Features[1].Geometry.Coordinates; len(lines) != 0 {
+		t.Errorf("level above the data: got lines %v", lines)
+	}
+
+	// Level 1.5 is a straight line halfway between columns 1 and 2.
+	lines := c.Features[0].Geometry.Coordinates
+	if c.Features[0].Geometry.Type != "MultiLineString" || len(lines) != 1 || len(lines[0]) != int(g.Ny) {
+		t.Fatalf("got lines %v, want one line with %d points", lines, g.Ny)
+	}
+	p := g.Projection()
+	for _, pt := range lines[0] {
+		// Find the row from the latitude, which rises with each row.
+		best, bestDist := int32(0), math.Inf(1)
+		for j := int32(0); j < g.Ny; j++ {
+			lon, lat, err := p.Inverse(float64(g.Xorig)+2*float64(g.Dx),
+				float64(g.Yorig)+(float64(j)+0.5)*float64(g.Dy))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d := math.Hypot(lon-pt[0], lat-pt[1]); d < bestDist {
+				best, bestDist = j, d
+			}
+		}
+		if bestDist > 1e-9 {
+			t.Errorf("point %v is not on the line (closest to row %d by %g°)", pt, best, bestDist)
+		}
+	}
+
+	// A peak makes a closed loop.
+	g.Nx, g.Ny = 5, 5
+	field = make([]float32, 25)
+	field[2*5+2] = 1
+	b.Reset()
+	if err := g.WriteContours(&b, field, []float64{0.5}); err != nil {
+		t.Fatal(err)
+	}
+	c = contours{}
+	if err := json.Unmarshal(b.Bytes(), &c); err != nil {
+		t.Fatal(err)
+	}
+	lines = c.Features[0].Geometry.Coordinates
+	if len(lines) != 1 || len(lines[0]) != 5 || lines[0][0] != lines[0][4] {
+		t.Errorf("got lines %v, want one closed loop around the peak", lines)
+	}
+
+	if err := g.WriteContours(&b, field[1:], []float64{0.5}); err == nil {
+		t.Error("wrong length: got no error")
+	}
+}
@@ -0,0 +1,54 @@
+package uam
+
+// CellCenters returns the projected coordinates of the center of each
+// cell of grid g, with Nx*Ny values in the same order as the layers of a
+// gridded file: the center of cell (i, j) is (x[j*Nx+i], y[j*Nx+i]).
+func (g GridDef) CellCenters() (x, y []float64) {
+	return g.points(0.5, g.Nx, g.Ny)
+}
+
+// CellCorners returns the projected coordinates of the corners of the
+// cells of grid g, with (Nx+1)*(Ny+1) values: the SW corner of cell
+// (i, j) is (x[j*(Nx+1)+i], y[j*(Nx+1)+i]).
+func (g GridDef) CellCorners() (x, y []float64) {
+	return g.points(0, g.Nx+1, g.Ny+1)
+}
+
+// points returns the coordinates of an nx×ny lattice of points that
+// starts offset cells from the SW corner of g.
+func (g GridDef) points(offset float64, nx, ny int32) (x, y []float64) {
+	x = make([]float64, nx*ny)
+	y = make([]float64, nx*ny)
+	for j := int32(0); j < ny; j++ {
+		yj := float64(g.Yorig) + (float64(j)+offset)*float64(g.Dy)
+		for i := int32(0); i < nx; i++ {
+			x[j*nx+i] = float64(g.Xorig) + (float64(i)+offset)*float64(g.Dx)
+			y[j*nx+i] = yj
+		}
+	}
+	return x, y
+}
+
+// CellCenterLonLats returns the longitude and latitude (degrees) of the
+// center of each cell of grid g, in the same order as CellCenters.
+func (g GridDef) CellCenterLonLats() (lon, lat []float64, err error) {
+	return g.lonLats(g.CellCenters())
+}
+
+// CellCornerLonLats returns the longitude and latitude (degrees) of the
+// corners of the cells of grid g, in the same order as CellCorners.
+func (g GridDef) CellCornerLonLats() (lon, lat []float64, err error) {
+	return g.lonLats(g.CellCorners())
+}
+
+// lonLats converts projected coordinates in place to longitude and
+// latitude.
+func (g GridDef) lonLats(x, y []float64) (lon, lat []float64, err error) {
+	p := g.Projection()
+	for n := range x {
+		if x[n], y[n], err = p.Inverse(x[n], y[n]); err != nil {
+			return nil, nil, err
+		}
+	}
+	return x, y, nil
+}
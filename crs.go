@@ -0,0 +1,115 @@
+package uam
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// The geographic coordinate systems that the projections are based on,
+// in OGC WKT.
+const (
+	wktWGS84 = `GEOGCS["WGS 84",DATUM["WGS_1984",SPHEROID["WGS 84",6378137,298.257223563]],` +
+		`PRIMEM["Greenwich",0],UNIT["degree",0.0174532925199433]]`
+	wktSphere = `GEOGCS["Sphere",DATUM["Sphere_6370000",SPHEROID["Sphere",6370000,0]],` +
+		`PRIMEM["Greenwich",0],UNIT["degree",0.0174532925199433]]`
+)
+
+// ProjString returns p as a PROJ string, for use with PROJ, GDAL, and
+// other GIS tools.
+func (p Projection) ProjString() (string, error) {
+	switch p.Type {
+	case LatLon:
+		return "+proj=longlat +datum=WGS84 +no_defs", nil
+	case UTM:
+		s := "+proj=utm +zone=" + strconv.Itoa(int(abs32(p.Zone)))
+		if p.Zone < 0 {
+			s += " +south"
+		}
+		return s + " +datum=WGS84 +units=m +no_defs", nil
+	case LambertConformal:
+		return "+proj=lcc +lat_1=" + ftoa(p.TrueLat1) +
+			" +lat_2=" + ftoa(p.TrueLat2) +
+			" +lat_0=" + ftoa(p.CenterLat) +
+			" +lon_0=" + ftoa(p.CenterLon) +
+			" +x_0=0 +y_0=0 +R=6370000 +units=m +no_defs", nil
+	case PolarStereographic:
+		y0 := p.psFalseNorthing()
+		return "+proj=stere +lat_0=" + ftoa(p.poleLat()) +
+			" +lat_ts=" + ftoa(p.TrueLat1) +
+			" +lon_0=" + ftoa(p.CenterLon) +
+			" +x_0=0 +y_0=" + ftoa(y0) +
+			" +R=6370000 +units=m +no_defs", nil
+	}
+	return "", fmt.Errorf("unsupported projection %v", p.Type)
+}
+
+// WKT returns p as an OGC well-known text (WKT 1) coordinate reference
+// system.
+func (p Projection) WKT() (string, error) {
+	param := func(name string, v float64) string {
+		return `PARAMETER["` + name + `",` + ftoa(v) + `],`
+	}
+	switch p.Type {
+	case LatLon:
+		return wktWGS84, nil
+	case UTM:
+		zone, hemi, northing := abs32(p.Zone), "N", 0.
+		if p.Zone < 0 {
+			hemi, northing = "S", 10000000
+		}
+		return fmt.Sprintf(`PROJCS["WGS 84 / UTM zone %d%s",`, zone, hemi) + wktWGS84 + "," +
+			`PROJECTION["Transverse_Mercator"],` +
+			param("latitude_of_origin", 0) +
+			param("central_meridian", float64(zone-1)*6-180+3) +
+			param("scale_factor", 0.9996) +
+			param("false_easting", 500000) +
+			param("false_northing", northing) +
+			`UNIT["metre",1]]`, nil
+	case LambertConformal:
+		return `PROJCS["Lambert Conformal Conic",` + wktSphere + "," +
+			`PROJECTION["Lambert_Conformal_Conic_2SP"],` +
+			param("standard_parallel_1", p.TrueLat1) +
+			param("standard_parallel_2", p.TrueLat2) +
+			param("latitude_of_origin", p.CenterLat) +
+			param("central_meridian", p.CenterLon) +
+			param("false_easting", 0) +
+			param("false_northing", 0) +
+			`UNIT["metre",1]]`, nil
+	case PolarStereographic:
+		return `PROJCS["Polar Stereographic",` + wktSphere + "," +
+			`PROJECTION["Polar_Stereographic"],` +
+			param("latitude_of_origin", p.TrueLat1) +
+			param("central_meridian", p.CenterLon) +
+			param("scale_factor", 1) +
+			param("false_easting", 0) +
+			param("false_northing", p.psFalseNorthing()) +
+			`UNIT["metre",1]]`, nil
+	}
+	return "", fmt.Errorf("unsupported projection %v", p.Type)
+}
+
+// poleLat returns the latitude of the pole of a polar stereographic
+// projection.
+func (p Projection) poleLat() float64 {
+	return math.Copysign(90, p.TrueLat1)
+}
+
+// psFalseNorthing returns the offset that moves the origin of a polar
+// stereographic projection from the pole to (CenterLon, CenterLat).
+func (p Projection) psFalseNorthing() float64 {
+	_, y0 := lonLatToPS(p.CenterLon, p.CenterLat, p.CenterLon, p.TrueLat1)
+	return -y0
+}
+
+// ftoa formats v without an exponent, as PROJ and WKT parsers expect.
+func ftoa(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func abs32(v int32) int32 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,182 @@
+package uam
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Dataset is a sequence of files with the same type, grid, and species
+// that together cover a continuous period of time, such as a month of
+// daily AVERAGE files. The hours of the files are presented as a single
+// time axis, and the files are read on demand, one at a time.
+type Dataset struct {
+	Files []string // names of the files, in time order
+	hdr   *UAM     // header of the first file
+	spans []fileSpan
+	hours int // total number of hours
+
+	cur     *UAM // file being read by ReadHour, if any
+	curFile int  // index of cur in Files
+}
+
+// OpenDataset reads the headers of filenames, which must be in time
+// order, and checks that the files have the same type, grid, and
+// species and follow one another without gaps. Where consecutive files
+// overlap, such as daily files that end with hour 24 of the day, the
+// hours of the later file are used; see StitchFiles.
+func OpenDataset(filenames []string) (*Dataset, error) {
+	if len(filenames) == 0 {
+		return nil, fmt.Errorf("no files in dataset")
+	}
+	d := &Dataset{Files: filenames}
+	files := make([]*UAM, len(filenames))
+	for n, filename := range filenames {
+		f, err := Open(filename)
+		if err != nil {
+			return nil, err
+		}
+		f.Close()
+		files[n] = f
+	}
+	var err error
+	if d.spans, d.hours, err = planStitch(files, filenames); err != nil {
+		return nil, err
+	}
+	d.hdr = files[0]
+	return d, nil
+}
+
+// NumHours returns the number of hours in d.
+func (d *Dataset) NumHours() int {
+	return d.hours
+}
+
+// HourTime returns the start date (YYJJJ) and hour of hour n of d,
+// counting from 0.
+func (d *Dataset) HourTime(n int) (date int32, time float32) {
+	return AddHours(d.hdr.sdate, d.hdr.begtim, n)
+}
+
+// HourIndex returns the index of the hour of d that starts at the given
+// date (YYJJJ) and hour.
+func (d *Dataset) HourIndex(date int32, time float32) (int, error) {
+	n := hoursBetween(d.hdr.sdate, d.hdr.begtim, date, time)
+	if n < 0 || n >= d.hours {
+		return 0, fmt.Errorf("%d %g is not the start of an hour in the dataset", date, time)
+	}
+	return n, nil
+}
+
+// ReadHour reads hour n of d, counting from 0, into Data, which is used
+// as in UAM.ReadHour. The file that holds the hour is kept open, so
+// reading hours in order is efficient; Close closes it.
+func (d *Dataset) ReadHour(n int, Data map[string][]float32) error {
+	if n < 0 || n >= d.hours {
+		return fmt.Errorf("hour %d is outside of the range 0 to %d", n, d.hours-1)
+	}
+	file := len(d.spans) - 1
+	for file > 0 && d.spans[file].offset > n {
+		file--
+	}
+	if d.cur == nil || d.curFile != file {
+		d.Close()
+		f, err := Open(d.Files[file])
+		if err != nil {
+			return err
+		}
+		d.cur, d.curFile = f, file
+	}
+	if hour := n - d.spans[file].offset; hour != d.cur.hour {
+		if err := d.cur.SeekHour(hour); err != nil {
+			return fmt.Errorf("%v: %w", d.Files[file], err)
+		}
+	}
+	if err := d.cur.readHour(Data); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return fmt.Errorf("%v: %w", d.Files[file], err)
+	}
+	return nil
+}
+
+// Close closes the file that was last read by ReadHour, if any.
+func (d *Dataset) Close() {
+	if d.cur != nil {
+		d.cur.Close()
+		d.cur = nil
+	}
+}
+
+// Header returns the header of the first file in d.
+func (d *Dataset) Header() Header {
+	return d.hdr.Header()
+}
+
+// Species returns the species in the files of d.
+func (d *Dataset) Species() []string {
+	return d.hdr.Spnames
+}
+
+// TimeSeries is a series of hourly values.
+type TimeSeries struct {
+	Date   []int32   // start date (YYJJJ) of each hour
+	Time   []float32 // start hour of each hour
+	Values []float32
+}
+
+// TimeSeries returns the values of the named species in layer k of grid
+// cell (i, j) in every hour of d. Only that cell is decoded, so the grids
+// are not read into memory.
+func (d *Dataset) TimeSeries(species string, i, j, k int32) (TimeSeries, error) {
+	var ts TimeSeries
+	if d.hdr.Name == "PTSOURCE" {
+		return ts, fmt.Errorf("time series can only be extracted from gridded files")
+	}
+	if k < 0 || k >= d.hdr.Nz {
+		return ts, fmt.Errorf("layer %d is out of range", k)
+	}
+	data := make(map[string][]float32)
+	for n, filename := range d.Files {
+		err := func() error {
+			f, err := Open(filename)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			if err = f.SelectSpecies([]string{species}); err != nil {
+				return err
+			}
+			if err = f.SetWindow(i, i+1, j, j+1); err != nil {
+				return err
+			}
+			for hour := 0; hour < d.spans[n].hours; hour++ {
+				if err = f.readHour(data); errors.Is(err, io.EOF) {
+					return io.ErrUnexpectedEOF
+				} else if err != nil {
+					return err
+				}
+				ts.Date = append(ts.Date, f.date)
+				ts.Time = append(ts.Time, f.time)
+				ts.Values = append(ts.Values, data[species][k])
+			}
+			return nil
+		}()
+		if err != nil {
+			return TimeSeries{}, fmt.Errorf("%v: %w", filename, err)
+		}
+	}
+	return ts, nil
+}
+
+// TimeSeriesAt returns the values of the named species in layer k of
+// the grid cell that contains the given longitude and latitude in every
+// hour of d. See TimeSeries.
+func (d *Dataset) TimeSeriesAt(species string, lon, lat float64, k int32) (TimeSeries, error) {
+	i, j, err := d.hdr.CellAt(lon, lat)
+	if err != nil {
+		return TimeSeries{}, err
+	}
+	return d.TimeSeries(species, i, j, k)
+}
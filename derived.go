@@ -0,0 +1,86 @@
+package uam
+
+import (
+	"fmt"
+	"sort"
+)
+
+// DerivedSpecies is a species that is calculated from the species in a
+// file as a weighted sum, such as NOx = NO + NO2 or a total VOC with
+// carbon-number weights.
+type DerivedSpecies struct {
+	Name       string
+	Components map[string]float32 // weight of each species in the sum
+}
+
+// NOx is the sum of NO and NO2.
+var NOx = DerivedSpecies{Name: "NOX", Components: map[string]float32{"NO": 1, "NO2": 1}}
+
+// WithDerived adds derived species to the Data map filled by ReadHour,
+// after the species in the file have been read. Derived species are not
+// added to Spnames, but they can be given to SelectSpecies, which then
+// also selects their components to be decoded. It is an error for a
+// derived species to have the name of a species in the file or to have
+// a component that is not in the file.
+func WithDerived(derived ...DerivedSpecies) Option {
+	return func(f *UAM) {
+		f.derived = append(f.derived, derived...)
+	}
+}
+
+// checkDerived checks the derived species of f against its species.
+func (f *UAM) checkDerived() error {
+	have := make(map[string]bool, len(f.Spnames))
+	for _, spname := range f.Spnames {
+		have[spname] = true
+	}
+	for _, d := range f.derived {
+		if have[d.Name] {
+			return fmt.Errorf("derived species %v is already in the file", d.Name)
+		}
+		for c := range d.Components {
+			if !have[c] {
+				return fmt.Errorf("component %v of derived species %v is not in the file",
+					c, d.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// derivedSpecies returns the derived species named name, if any.
+func (f *UAM) derivedSpecies(name string) (DerivedSpecies, bool) {
+	for _, d := range f.derived {
+		if d.Name == name {
+			return d, true
+		}
+	}
+	return DerivedSpecies{}, false
+}
+
+// computeDerived adds the selected derived species to Data.
+func (f *UAM) computeDerived(Data map[string][]float32) {
+	for _, d := range f.derived {
+		if !f.isSelected(d.Name) {
+			continue
+		}
+		// Sum in a fixed order so that the results are reproducible.
+		names := make([]string, 0, len(d.Components))
+		for c := range d.Components {
+			names = append(names, c)
+		}
+		sort.Strings(names)
+		var sum []float32
+		for _, c := range names {
+			w, vals := d.Components[c], Data[c]
+			if sum == nil {
+				sum = reuse(Data[d.Name], len(vals))
+				clear(sum)
+			}
+			for i, v := range vals {
+				sum[i] += w * v
+			}
+		}
+		Data[d.Name] = sum
+	}
+}
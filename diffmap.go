@@ -0,0 +1,163 @@
+package uam
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+)
+
+// DiffMetric specifies which field is compared when making difference
+// maps.
+type DiffMetric int
+
+const (
+	// DiffHour compares a single hour.
+	DiffHour DiffMetric = iota
+	// DiffDailyMean compares the mean over all hours.
+	DiffDailyMean
+	// DiffDailyMax compares the maximum over all hours.
+	DiffDailyMax
+)
+
+// DiffMapOptions holds options for WriteDiffMaps.
+type DiffMapOptions struct {
+	Metric  DiffMetric
+	Hour    int      // index of the hour to compare when Metric is DiffHour
+	Layer   int32    // layer to compare
+	Species []string // species to map; nil means all species
+	Scale   int      // pixels per grid cell; 0 means 1
+}
+
+// WriteDiffMaps creates a PNG image in directory dir for each species,
+// named after the species, showing the difference between gridded files
+// scenario and base (scenario minus base). The color scale of each image
+// is symmetric around zero and spans the largest absolute difference for
+// that species. It returns the names of the files that were written.
+func WriteDiffMaps(dir, base, scenario string, opts DiffMapOptions) ([]string, error) {
+	b, err := Open(base)
+	if err != nil {
+		return nil, err
+	}
+	defer b.Close()
+	s, err := Open(scenario)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Close()
+	if b.Name == "PTSOURCE" {
+		return nil, fmt.Errorf("difference maps can only be made for " +
+			"gridded files")
+	}
+	if err = sameStructure(b, s); err != nil {
+		return nil, err
+	}
+	if opts.Layer < 0 || opts.Layer >= b.Nz {
+		return nil, fmt.Errorf("layer %d is out of range", opts.Layer)
+	}
+	species := opts.Species
+	if species == nil {
+		species = b.Spnames
+	}
+	if err = b.SelectSpecies(species); err != nil {
+		return nil, err
+	}
+	if err = s.SelectSpecies(species); err != nil {
+		return nil, err
+	}
+
+	bm, err := diffMetric(b, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", base, err)
+	}
+	sm, err := diffMetric(s, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", scenario, err)
+	}
+
+	var files []string
+	for _, spname := range species {
+		diff := make([]float32, len(bm[spname]))
+		var maxAbs float32
+		for i := range diff {
+			diff[i] = sm[spname][i] - bm[spname][i]
+			maxAbs = float32(math.Max(float64(maxAbs), math.Abs(float64(diff[i]))))
+		}
+		filename := filepath.Join(dir, spname+".png")
+		w, err := os.Create(filename)
+		if err != nil {
+			return files, err
+		}
+		err = WritePNG(w, diff, int(b.Nx), int(b.Ny), -maxAbs, maxAbs,
+			Diverging, opts.Scale)
+		if err != nil {
+			w.Close()
+			return files, err
+		}
+		if err = w.Close(); err != nil {
+			return files, err
+		}
+		files = append(files, filename)
+	}
+	return files, nil
+}
+
+// diffMetric reads f and calculates the field to be compared for each
+// selected species in the requested layer.
+func diffMetric(f *UAM, opts DiffMapOptions) (map[string][]float32, error) {
+	n := f.Nx * f.Ny
+	out := make(map[string][]float32)
+	nhrs := 0
+	for ; ; nhrs++ {
+		data := make(map[string][]float32)
+		_, _, _, _, _, _, err := f.ReadHour(data)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		for spname, vals := range data {
+			layer := vals[opts.Layer*n : (opts.Layer+1)*n]
+			acc, ok := out[spname]
+			if !ok {
+				acc = make([]float32, n)
+				copy(acc, layer)
+				out[spname] = acc
+				continue
+			}
+			switch opts.Metric {
+			case DiffHour:
+				copy(acc, layer)
+			case DiffDailyMean:
+				for i, v := range layer {
+					acc[i] += v
+				}
+			case DiffDailyMax:
+				for i, v := range layer {
+					acc[i] = float32(math.Max(float64(acc[i]), float64(v)))
+				}
+			default:
+				return nil, fmt.Errorf("invalid difference metric %d", opts.Metric)
+			}
+		}
+		if opts.Metric == DiffHour && nhrs == opts.Hour {
+			return out, nil
+		}
+	}
+	if opts.Metric == DiffHour {
+		return nil, fmt.Errorf("hour %d is out of range; file has %d hours",
+			opts.Hour, nhrs)
+	}
+	if nhrs == 0 {
+		return nil, fmt.Errorf("file has no hours")
+	}
+	if opts.Metric == DiffDailyMean {
+		for _, acc := range out {
+			for i := range acc {
+				acc[i] /= float32(nhrs)
+			}
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,103 @@
+package uam_test
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ctessum/uam"
+	"github.com/ctessum/uam/uamtest"
+)
+
+// readPNG decodes the PNG file with the given name.
+func readPNG(t *testing.T, filename string) image.Image {
+	t.Helper()
+	r, err := os.Open(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	img, err := png.Decode(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
+
+// rgba returns c as color.RGBA.
+func rgba(c color.Color) color.RGBA {
+	return color.RGBAModel.Convert(c).(color.RGBA)
+}
+
+func TestWriteDiffMaps(t *testing.T) {
+	dir := t.TempDir()
+	base, err := uamtest.Average(uamtest.Options{Hours: 4, Pattern: uamtest.Constant(1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Cell (0, 0) increases with each hour and cell (3, 2) is lower than
+	// in the base case in hour 0.
+	scenario, err := uamtest.Average(uamtest.Options{Hours: 4,
+		Pattern: func(l, h int, k, j, i int32) float32 {
+			switch {
+			case i == 0 && j == 0:
+				return 1 + float32(h+1)
+			case i == 3 && j == 2 && h == 0:
+				return 0
+			}
+			return 1
+		}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, s := uamtest.TempFile(t, base), uamtest.TempFile(t, scenario)
+
+	red := color.RGBA{255, 0, 0, 255}
+	white := color.RGBA{255, 255, 255, 255}
+	for _, test := range []struct {
+		metric uam.DiffMetric
+		cell32 color.RGBA // color of cell (3, 2)
+	}{
+		{uam.DiffHour, color.RGBA{0, 0, 255, 255}},          // -1 of ±1
+		{uam.DiffDailyMean, color.RGBA{229, 229, 255, 255}}, // -0.25 of ±2.5
+		{uam.DiffDailyMax, white},
+	} {
+		files, err := uam.WriteDiffMaps(dir, b, s, uam.DiffMapOptions{
+			Metric: test.metric, Layer: 1, Species: []string{"NO2"}, Scale: 2})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(files) != 1 || files[0] != filepath.Join(dir, "NO2.png") {
+			t.Fatalf("metric %d: got files %v", test.metric, files)
+		}
+		img := readPNG(t, files[0])
+		if got := img.Bounds().Size(); got != image.Pt(8, 6) {
+			t.Fatalf("metric %d: got size %v, want 8x6", test.metric, got)
+		}
+		// Row j = 0 is at the bottom.
+		if got := rgba(img.At(0, 5)); got != red {
+			t.Errorf("metric %d: cell (0, 0): got %v, want %v", test.metric, got, red)
+		}
+		if got := rgba(img.At(2, 5)); got != white {
+			t.Errorf("metric %d: cell (1, 0): got %v, want %v", test.metric, got, white)
+		}
+		if got := rgba(img.At(6, 0)); got != test.cell32 {
+			t.Errorf("metric %d: cell (3, 2): got %v, want %v", test.metric, got, test.cell32)
+		}
+	}
+
+	_, err = uam.WriteDiffMaps(dir, b, s, uam.DiffMapOptions{Hour: 4})
+	if err == nil {
+		t.Error("hour out of range: got no error")
+	}
+	e, err := uamtest.Emissions(uamtest.Options{Hours: 4, Species: []string{"NO"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = uam.WriteDiffMaps(dir, b, uamtest.TempFile(t, e), uam.DiffMapOptions{}); err == nil {
+		t.Error("different files: got no error")
+	}
+}
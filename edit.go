@@ -0,0 +1,108 @@
+package uam
+
+import (
+	"fmt"
+	"slices"
+)
+
+// PolygonMask returns a mask on grid g with weights of 1 in the cells
+// whose centers are inside of polygon, which holds the (longitude,
+// latitude) vertices of a ring in degrees, and 0 elsewhere. The ring
+// does not need to be closed.
+func (g GridDef) PolygonMask(polygon [][2]float64) (*Mask, error) {
+	if len(polygon) < 3 {
+		return nil, fmt.Errorf("polygon has %d vertices; it needs at least 3", len(polygon))
+	}
+	lon, lat, err := g.CellCenterLonLats()
+	if err != nil {
+		return nil, err
+	}
+	values := make([]float32, g.Nx*g.Ny)
+	for c := range values {
+		if inPolygon(lon[c], lat[c], polygon) {
+			values[c] = 1
+		}
+	}
+	return &Mask{Nx: g.Nx, Ny: g.Ny, Values: values}, nil
+}
+
+// inPolygon reports whether point (x, y) is inside of polygon, using the
+// even-odd rule.
+func inPolygon(x, y float64, polygon [][2]float64) bool {
+	in := false
+	for i, j := 0, len(polygon)-1; i < len(polygon); j, i = i, i+1 {
+		a, b := polygon[i], polygon[j]
+		if (a[1] > y) != (b[1] > y) &&
+			x < (b[0]-a[0])*(y-a[1])/(b[1]-a[1])+a[0] {
+			in = !in
+		}
+	}
+	return in
+}
+
+// ZeroOut sets the named species to zero in the cells covered by m, in
+// the hours held in memory with the given indices. See Replace.
+func (f *UAM) ZeroOut(m *Mask, species []string, hours []int) error {
+	return f.Replace(m, species, hours, 0)
+}
+
+// Replace sets the named species (or all species, if species is nil) to
+// value in the cells covered by m, in the hours held in memory with the
+// given indices (or all hours, if hours is nil), for example to remove
+// the emissions of a region for a brute-force source apportionment run.
+// Every layer of gridded files is changed; the stacks of PTSOURCE files
+// are changed according to the cell that contains them. Cells with
+// weights between 0 and 1 are moved the corresponding fraction of the
+// way to value. m must be on the grid of f.
+func (f *UAM) Replace(m *Mask, species []string, hours []int, value float32) error {
+	if m.Nx != f.Nx || m.Ny != f.Ny {
+		return fmt.Errorf("mask is %dx%d but the grid is %dx%d", m.Nx, m.Ny, f.Nx, f.Ny)
+	}
+	if species == nil {
+		species = f.Spnames
+	}
+	for _, spname := range species {
+		if !slices.Contains(f.Spnames, spname) {
+			return fmt.Errorf("species %v is not in the file", spname)
+		}
+	}
+	if hours == nil {
+		hours = make([]int, len(f.Hours))
+		for h := range hours {
+			hours[h] = h
+		}
+	}
+	for _, h := range hours {
+		if h < 0 || h >= len(f.Hours) {
+			return fmt.Errorf("hour %d is outside of the range 0 to %d", h, len(f.Hours)-1)
+		}
+	}
+	weights := f.cellWeights(m)
+	for _, h := range hours {
+		for _, spname := range species {
+			vals := f.Hours[h].Data[spname]
+			for i := range vals {
+				if w := weights[i%len(weights)]; w != 0 {
+					vals[i] += w * (value - vals[i])
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// cellWeights returns the weights of m for each value in a layer of a
+// gridded file or for each stack of a PTSOURCE file. Stacks outside of
+// the grid have weights of 0.
+func (f *UAM) cellWeights(m *Mask) []float32 {
+	if f.Name != "PTSOURCE" {
+		return m.Values
+	}
+	weights := make([]float32, f.Npts)
+	for p := range weights {
+		if i, j, ok := f.stackCell(p); ok {
+			weights[p] = m.Values[j*f.Nx+i]
+		}
+	}
+	return weights
+}
@@ -0,0 +1,101 @@
+package uam
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// These errors describe the ways in which reading a file can fail. They
+// are wrapped, along with the hour and byte offset at which the failure
+// occurred, in a *ParseError, so callers should check for them with
+// errors.Is.
+var (
+	// ErrTruncatedFile means that the file ended partway through a
+	// record. Errors that wrap it also wrap io.ErrUnexpectedEOF.
+	ErrTruncatedFile = errors.New("truncated file")
+	// ErrBadRecordMarker means that a Fortran record length marker
+	// does not match the expected length of the record.
+	ErrBadRecordMarker = errors.New("bad record marker")
+	// ErrUnknownFileType means that the file type in the header is not
+	// one of EMISSIONS, AVERAGE, AIRQUALITY, INSTANT, or PTSOURCE.
+	ErrUnknownFileType = errors.New("unknown file type")
+	// ErrSpeciesMismatch means that a record holds a different species
+	// from the one expected by the header, or that files that should
+	// have the same species do not.
+	ErrSpeciesMismatch = errors.New("species mismatch")
+	// ErrInvalidHeader means that a size in the header is out of range,
+	// is more than the Limits of the reader, or describes records that
+	// do not fit in the file.
+	ErrInvalidHeader = errors.New("invalid header")
+)
+
+// ParseError describes where in a file a read failed.
+type ParseError struct {
+	Hour    int    // index of the hour being read, or -1 for the header
+	Species string // species being read, if any
+	Layer   int    // layer being read, or -1 if not applicable
+	Record  int    // index of the record being read
+	Offset  int64  // byte offset at which the failure occurred
+	Err     error
+
+	records []RecordInfo // records of the hour or header being read
+}
+
+func (e *ParseError) Error() string {
+	s := "header"
+	if e.Hour >= 0 {
+		s = fmt.Sprintf("hour %d", e.Hour)
+	}
+	if e.Species != "" {
+		s += ", species " + e.Species
+	}
+	if e.Layer >= 0 {
+		s += fmt.Sprintf(", layer %d", e.Layer)
+	}
+	return fmt.Sprintf("%v (record %d, byte %d): %v", s, e.Record, e.Offset, e.Err)
+}
+
+func (e *ParseError) Unwrap() error { return e.Err }
+
+// DumpRecords writes the expected layout of the records in the hour (or
+// header) in which the error occurred to w, marking the record where
+// the failure happened.
+func (e *ParseError) DumpRecords(w io.Writer) error {
+	for _, r := range e.records {
+		mark := "  "
+		if r.Index == e.Record {
+			mark = "=>"
+		}
+		if _, err := fmt.Fprintf(w, "%v %v\n", mark, r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseError wraps err with the position of the record at byte offset
+// off within hour (or the header, if hour is -1).
+func (f *UAM) parseError(err error, hour int, off int64) error {
+	var pe *ParseError
+	if err == nil || errors.As(err, &pe) {
+		return err
+	}
+	if (err == io.EOF || err == io.ErrUnexpectedEOF) && !errors.Is(err, ErrTruncatedFile) {
+		err = fmt.Errorf("%w: %w", ErrTruncatedFile, io.ErrUnexpectedEOF)
+	}
+	pe = &ParseError{Hour: hour, Layer: -1, Record: -1, Offset: off, Err: err}
+	pe.records = f.RecordMap(hour)
+	for _, r := range pe.records {
+		if off >= r.Offset && off < r.Offset+r.Size {
+			pe.Record, pe.Species, pe.Layer = r.Index, r.Species, r.Layer
+			break
+		}
+	}
+	if pe.Record < 0 && len(pe.records) > 0 {
+		// Past the end of the expected records.
+		r := pe.records[len(pe.records)-1]
+		pe.Record, pe.Species, pe.Layer = r.Index, r.Species, r.Layer
+	}
+	return pe
+}
@@ -0,0 +1,112 @@
+package uam
+
+import (
+	"fmt"
+	"io"
+	"math"
+)
+
+// Extremum is the location and value of a maximum or minimum.
+type Extremum struct {
+	Species  string
+	Hour     int   // index of the hour in the file
+	Layer    int32 // layer, or -1 for PTSOURCE files
+	J, I     int32 // grid cell, or, for PTSOURCE files, -1 and the stack index
+	Value    float32
+	Lon, Lat float64 // location, or NaN if the projection is not supported
+}
+
+// ArgMax returns the largest value of species spname in each layer of
+// the hour of data that was last read into Data by ReadHour, along with
+// its location. For PTSOURCE files it returns the largest value over all
+// stacks. NaN values are ignored.
+func (f *UAM) ArgMax(Data map[string][]float32, spname string) ([]Extremum, error) {
+	return f.argExtreme(Data, spname, func(a, b float32) bool { return a > b })
+}
+
+// ArgMin is like ArgMax but returns the smallest values.
+func (f *UAM) ArgMin(Data map[string][]float32, spname string) ([]Extremum, error) {
+	return f.argExtreme(Data, spname, func(a, b float32) bool { return a < b })
+}
+
+// argExtreme finds the values in each layer for which better(value,
+// best) is never true for any other value.
+func (f *UAM) argExtreme(Data map[string][]float32, spname string, better func(a, b float32) bool) ([]Extremum, error) {
+	vals, ok := Data[spname]
+	if !ok {
+		return nil, fmt.Errorf("species %v is not in the data", spname)
+	}
+	if f.Name == "PTSOURCE" {
+		if len(vals) == 0 {
+			return nil, nil
+		}
+		best := argBest(vals, better)
+		e := Extremum{Species: spname, Hour: f.hour - 1, Layer: -1, J: -1,
+			I: int32(best), Value: vals[best]}
+		e.Lon, e.Lat, ok = f.lonLat(float64(f.Xcoord[best]), float64(f.Ycoord[best]))
+		return []Extremum{e}, nil
+	}
+	_, _, nx, ny := f.WindowGrid()
+	n := int(nx * ny)
+	if len(vals) != n*int(f.Nz) {
+		return nil, fmt.Errorf("species %v has %d values; it should have %d",
+			spname, len(vals), n*int(f.Nz))
+	}
+	ext := make([]Extremum, f.Nz)
+	for k := range ext {
+		layer := vals[k*n : (k+1)*n]
+		best := argBest(layer, better)
+		j, i := int32(best)/nx, int32(best)%nx
+		if f.window != nil {
+			j, i = j+f.window.j1, i+f.window.i1
+		}
+		ext[k] = Extremum{Species: spname, Hour: f.hour - 1, Layer: int32(k),
+			J: j, I: i, Value: layer[best]}
+		ext[k].Lon, ext[k].Lat = f.cellLonLat(i, j)
+	}
+	return ext, nil
+}
+
+// argBest returns the index of the value in vals for which better(value,
+// best) is never true for any other value, ignoring NaNs, or 0 if all of
+// the values are NaN.
+func argBest(vals []float32, better func(a, b float32) bool) int {
+	best := -1
+	for i, v := range vals {
+		if math.IsNaN(float64(v)) {
+			continue
+		}
+		if best < 0 || better(v, vals[best]) {
+			best = i
+		}
+	}
+	return max(best, 0)
+}
+
+// Extrema reads the remaining hours of f and returns the maximum and
+// minimum of each species in each hour and layer.
+func (f *UAM) Extrema() (max, min []Extremum, err error) {
+	data := make(map[string][]float32)
+	for {
+		_, _, _, _, _, _, err = f.ReadHour(data)
+		if err == io.EOF {
+			return max, min, nil
+		} else if err != nil {
+			return nil, nil, err
+		}
+		for _, spname := range f.Spnames {
+			if !f.isSelected(spname) {
+				continue
+			}
+			hi, err := f.ArgMax(data, spname)
+			if err != nil {
+				return nil, nil, err
+			}
+			lo, err := f.ArgMin(data, spname)
+			if err != nil {
+				return nil, nil, err
+			}
+			max, min = append(max, hi...), append(min, lo...)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package uam_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/ctessum/uam"
+	"github.com/ctessum/uam/uamtest"
+)
+
+func TestArgExtreme(t *testing.T) {
+	f, err := uamtest.Emissions(uamtest.Options{Hours: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := open(t, f)
+	data := make(map[string][]float32)
+	if _, _, _, _, _, _, err = r.ReadHour(data); err != nil {
+		t.Fatal(err)
+	}
+	// NaNs are ignored, even in the first cell.
+	data["NO2"][0] = float32(math.NaN())
+	hi, err := r.ArgMax(data, "NO2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lo, err := r.ArgMin(data, "NO2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hi) != 2 || len(lo) != 2 {
+		t.Fatalf("got %d maxima and %d minima, want one for each layer", len(hi), len(lo))
+	}
+	for _, test := range []struct {
+		got  uam.Extremum
+		i, j int32
+	}{{hi[0], 3, 2}, {hi[1], 3, 2}, {lo[0], 1, 0}, {lo[1], 0, 0}} {
+		e := test.got
+		if e.I != test.i || e.J != test.j || e.Value != uamtest.Index(1, 0, e.Layer, e.J, e.I) {
+			t.Errorf("got %+v, want cell (%d, %d)", e, test.i, test.j)
+		}
+		if e.Hour != 0 || math.IsNaN(e.Lon) || math.IsNaN(e.Lat) {
+			t.Errorf("got %+v, want hour 0 and a location", e)
+		}
+	}
+	if _, err = r.ArgMax(data, "CO"); err == nil {
+		t.Error("missing species: got no error")
+	}
+}
+
+func TestExtrema(t *testing.T) {
+	f, err := uamtest.Emissions(uamtest.Options{Hours: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := open(t, f)
+	if err = r.SetWindow(1, 3, 0, 2); err != nil {
+		t.Fatal(err)
+	}
+	r.SelectSpecies([]string{"O3"})
+	hi, lo, err := r.Extrema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hi) != 4 || len(lo) != 4 {
+		t.Fatalf("got %d maxima and %d minima, want 4", len(hi), len(lo))
+	}
+	// The locations are in the full grid.
+	for n, e := range hi {
+		if h := n / 2; e.Hour != h || e.Species != "O3" || e.I != 2 || e.J != 1 ||
+			e.Value != uamtest.Index(2, h, e.Layer, 1, 2) {
+			t.Errorf("maximum %d: got %+v", n, e)
+		}
+	}
+	for n, e := range lo {
+		if e.I != 1 || e.J != 0 {
+			t.Errorf("minimum %d: got %+v, want cell (1, 0)", n, e)
+		}
+	}
+
+	p, err := uamtest.PointSource(uamtest.Options{Hours: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hi, lo, err = open(t, p).Extrema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hi) != 3 || hi[0].I != 2 || hi[0].J != -1 || hi[0].Layer != -1 || lo[0].I != 0 {
+		t.Errorf("PTSOURCE: got maxima %+v and minima %+v", hi, lo)
+	}
+}
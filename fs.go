@@ -0,0 +1,56 @@
+package uam
+
+import (
+	"io"
+	"io/fs"
+)
+
+// OpenFS opens the named file in fsys for reading and reads the header
+// info, like Open. This allows files to be read from embedded file
+// systems, archives, and other storage that implements fs.FS. Hours can
+// only be read out of order, and parallel decoding and resynchronization
+// are only available, if the files returned by fsys implement
+// io.ReaderAt and io.Seeker.
+func OpenFS(fsys fs.FS, name string, opts ...Option) (*UAM, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	size := func() (int64, error) {
+		info, err := fs.Stat(fsys, name)
+		if err != nil {
+			return 0, err
+		}
+		return info.Size(), nil
+	}
+	f, err := newReader(file, size, opts)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	f.closer, f.name = file, name
+	f.reopen = func() (io.Reader, io.Closer, error) {
+		file, err := fsys.Open(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		return file, file, nil
+	}
+	return f, nil
+}
+
+// NewReaderAt reads the header info from the first size bytes of ra and
+// returns a UAM that reads hours of data from it, with full support for
+// random access. This is useful for in-memory files and for adapters to
+// remote storage.
+func NewReaderAt(ra io.ReaderAt, size int64, opts ...Option) (*UAM, error) {
+	f, err := newReader(io.NewSectionReader(ra, 0, size),
+		func() (int64, error) { return size, nil }, opts)
+	if err != nil {
+		return nil, err
+	}
+	f.reopen = func() (io.Reader, io.Closer, error) {
+		return io.NewSectionReader(ra, 0, size), nil, nil
+	}
+	return f, nil
+}
@@ -0,0 +1,104 @@
+package uam
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+)
+
+// geoJSONFeature is a GeoJSON feature. Coordinates are longitude and
+// latitude.
+type geoJSONFeature struct {
+	Type       string          `json:"type"`
+	Geometry   geoJSONGeometry `json:"geometry"`
+	Properties map[string]any  `json:"properties"`
+}
+
+type geoJSONGeometry struct {
+	Type        string `json:"type"`
+	Coordinates any    `json:"coordinates"`
+}
+
+// geoJSONWriter writes a GeoJSON FeatureCollection one feature at a
+// time, so that large grids need not be held in memory.
+type geoJSONWriter struct {
+	w *bufio.Writer
+	n int
+}
+
+func newGeoJSONWriter(w io.Writer) *geoJSONWriter {
+	gw := &geoJSONWriter{w: bufio.NewWriter(w)}
+	gw.w.WriteString(`{"type":"FeatureCollection","features":[`)
+	return gw
+}
+
+func (gw *geoJSONWriter) write(f geoJSONFeature) error {
+	b, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+	if gw.n > 0 {
+		gw.w.WriteByte(',')
+	}
+	gw.w.WriteByte('\n')
+	gw.n++
+	_, err = gw.w.Write(b)
+	return err
+}
+
+func (gw *geoJSONWriter) close() error {
+	gw.w.WriteString("\n]}\n")
+	return gw.w.Flush()
+}
+
+// jsonValue returns v, or nil if v cannot be represented in JSON.
+func jsonValue(v float32) any {
+	if math.IsNaN(float64(v)) || math.IsInf(float64(v), 0) {
+		return nil
+	}
+	return v
+}
+
+// WriteGeoJSON writes the cells of grid g to w as a GeoJSON
+// FeatureCollection of polygons in longitude and latitude, with the
+// column and row of each cell as the properties i and j. Each entry of
+// values, which may be nil, holds a 2D field of Nx*Ny values in the same
+// order as the layers of a gridded file (such as one layer of a species
+// in one hour) and is added as a property with the name of its key.
+func (g GridDef) WriteGeoJSON(w io.Writer, values map[string][]float32) error {
+	for name, vals := range values {
+		if len(vals) != int(g.Nx*g.Ny) {
+			return fmt.Errorf("%v has %d values; it should have %d",
+				name, len(vals), g.Nx*g.Ny)
+		}
+	}
+	lon, lat, err := g.CellCornerLonLats()
+	if err != nil {
+		return err
+	}
+	gw := newGeoJSONWriter(w)
+	nx := g.Nx + 1
+	for j := int32(0); j < g.Ny; j++ {
+		for i := int32(0); i < g.Nx; i++ {
+			ring := make([][2]float64, 5)
+			for c, n := range []int32{j*nx + i, j*nx + i + 1, (j+1)*nx + i + 1, (j+1)*nx + i, j*nx + i} {
+				ring[c] = [2]float64{lon[n], lat[n]}
+			}
+			props := map[string]any{"i": i, "j": j}
+			for name, vals := range values {
+				props[name] = jsonValue(vals[j*g.Nx+i])
+			}
+			err = gw.write(geoJSONFeature{
+				Type:       "Feature",
+				Geometry:   geoJSONGeometry{Type: "Polygon", Coordinates: [][][2]float64{ring}},
+				Properties: props,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return gw.close()
+}
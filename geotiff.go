@@ -0,0 +1,252 @@
+package uam
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+)
+
+// TIFF field types.
+const (
+	tiffASCII  = 2
+	tiffShort  = 3
+	tiffLong   = 4
+	tiffDouble = 12
+)
+
+// tiffField is an entry of a TIFF image file directory, with its
+// value encoded in little-endian byte order.
+type tiffField struct {
+	tag   uint16
+	typ   uint16
+	count uint32
+	value []byte
+}
+
+func tiffShorts(tag uint16, v ...uint16) tiffField {
+	b := make([]byte, 0, 2*len(v))
+	for _, s := range v {
+		b = binary.LittleEndian.AppendUint16(b, s)
+	}
+	return tiffField{tag, tiffShort, uint32(len(v)), b}
+}
+
+func tiffDoubles(tag uint16, v ...float64) tiffField {
+	b := make([]byte, 0, 8*len(v))
+	for _, d := range v {
+		b = binary.LittleEndian.AppendUint64(b, math.Float64bits(d))
+	}
+	return tiffField{tag, tiffDouble, uint32(len(v)), b}
+}
+
+func tiffString(tag uint16, s string) tiffField {
+	return tiffField{tag, tiffASCII, uint32(len(s) + 1), append([]byte(s), 0)}
+}
+
+// geoKeys builds the GeoTIFF GeoKeyDirectory, GeoDoubleParams, and
+// GeoAsciiParams fields.
+type geoKeys struct {
+	keys    map[uint16][4]uint16
+	doubles []float64
+	ascii   string
+}
+
+// GeoTIFF key locations.
+const (
+	geoDoubleParamsTag = 34736
+	geoAsciiParamsTag  = 34737
+)
+
+func (k *geoKeys) setShort(key, v uint16) {
+	k.keys[key] = [4]uint16{key, 0, 1, v}
+}
+
+func (k *geoKeys) setDouble(key uint16, v float64) {
+	k.keys[key] = [4]uint16{key, geoDoubleParamsTag, 1, uint16(len(k.doubles))}
+	k.doubles = append(k.doubles, v)
+}
+
+func (k *geoKeys) setString(key uint16, s string) {
+	k.keys[key] = [4]uint16{key, geoAsciiParamsTag, uint16(len(s) + 1), uint16(len(k.ascii))}
+	k.ascii += s + "|"
+}
+
+func (k *geoKeys) fields() []tiffField {
+	ids := make([]int, 0, len(k.keys))
+	for id := range k.keys {
+		ids = append(ids, int(id))
+	}
+	sort.Ints(ids)
+	dir := []uint16{1, 1, 0, uint16(len(ids))}
+	for _, id := range ids {
+		e := k.keys[uint16(id)]
+		dir = append(dir, e[:]...)
+	}
+	fields := []tiffField{tiffShorts(34735, dir...)}
+	if len(k.doubles) > 0 {
+		fields = append(fields, tiffDoubles(geoDoubleParamsTag, k.doubles...))
+	}
+	if k.ascii != "" {
+		fields = append(fields, tiffString(geoAsciiParamsTag, k.ascii))
+	}
+	return fields
+}
+
+// geoKeys returns the GeoTIFF keys that describe projection p.
+func (p Projection) geoKeys() (*geoKeys, error) {
+	const (
+		modelType       = 1024
+		rasterType      = 1025
+		citation        = 1026
+		geographicType  = 2048
+		geogCitation    = 2049
+		geodeticDatum   = 2050
+		ellipsoid       = 2056
+		semiMajorAxis   = 2057
+		semiMinorAxis   = 2058
+		projectedCSType = 3072
+		projection      = 3074
+		coordTrans      = 3075
+		linearUnits     = 3076
+		stdParallel1    = 3078
+		stdParallel2    = 3079
+		natOriginLat    = 3081
+		falseEasting    = 3082
+		falseNorthing   = 3083
+		falseOriginLong = 3084
+		falseOriginLat  = 3085
+		falseOriginE    = 3086
+		falseOriginN    = 3087
+		scaleAtNatOrig  = 3092
+		vertPoleLong    = 3095
+
+		userDefined = 32767
+	)
+	k := &geoKeys{keys: make(map[uint16][4]uint16)}
+	k.setShort(rasterType, 1) // pixels are areas
+	switch p.Type {
+	case LatLon:
+		k.setShort(modelType, 2)
+		k.setShort(geographicType, 4326) // WGS 84
+		return k, nil
+	case UTM:
+		k.setShort(modelType, 1)
+		code := 32600 + abs32(p.Zone) // WGS 84 / UTM zone N
+		if p.Zone < 0 {
+			code += 100 // WGS 84 / UTM zone S
+		}
+		k.setShort(projectedCSType, uint16(code))
+		return k, nil
+	case LambertConformal, PolarStereographic:
+	default:
+		return nil, fmt.Errorf("unsupported projection %v", p.Type)
+	}
+	k.setShort(modelType, 1)
+	k.setString(citation, p.String())
+	k.setShort(geographicType, userDefined)
+	k.setString(geogCitation, "Sphere")
+	k.setShort(geodeticDatum, userDefined)
+	k.setShort(ellipsoid, userDefined)
+	k.setDouble(semiMajorAxis, earthRadius)
+	k.setDouble(semiMinorAxis, earthRadius)
+	k.setShort(projectedCSType, userDefined)
+	k.setShort(projection, userDefined)
+	k.setShort(linearUnits, 9001) // meters
+	if p.Type == LambertConformal {
+		k.setShort(coordTrans, 8)
+		k.setDouble(stdParallel1, p.TrueLat1)
+		k.setDouble(stdParallel2, p.TrueLat2)
+		k.setDouble(falseOriginLong, p.CenterLon)
+		k.setDouble(falseOriginLat, p.CenterLat)
+		k.setDouble(falseOriginE, 0)
+		k.setDouble(falseOriginN, 0)
+	} else {
+		k.setShort(coordTrans, 15)
+		k.setDouble(natOriginLat, p.TrueLat1)
+		k.setDouble(vertPoleLong, p.CenterLon)
+		k.setDouble(scaleAtNatOrig, 1)
+		k.setDouble(falseEasting, 0)
+		k.setDouble(falseNorthing, p.psFalseNorthing())
+	}
+	return k, nil
+}
+
+// WriteGeoTIFF writes a 2D field on grid g to w as a single-band,
+// 32-bit floating point GeoTIFF that is georeferenced in the projection
+// of g. field holds Nx*Ny values in the same order as the layers of a
+// gridded file, such as one layer of a species in one hour or its
+// average over several hours. NaN values are marked as missing.
+func (g GridDef) WriteGeoTIFF(w io.Writer, field []float32) error {
+	if len(field) != int(g.Nx*g.Ny) {
+		return fmt.Errorf("field has %d values; it should have %d",
+			len(field), g.Nx*g.Ny)
+	}
+	keys, err := g.Projection().geoKeys()
+	if err != nil {
+		return err
+	}
+	const header = 8
+	size := 4 * uint32(len(field))
+	long := func(tag uint16, v uint32) tiffField {
+		return tiffField{tag, tiffLong, 1, binary.LittleEndian.AppendUint32(nil, v)}
+	}
+	top := float64(g.Yorig) + float64(g.Ny)*float64(g.Dy)
+	fields := []tiffField{
+		long(256, uint32(g.Nx)), // ImageWidth
+		long(257, uint32(g.Ny)), // ImageLength
+		tiffShorts(258, 32),     // BitsPerSample
+		tiffShorts(259, 1),      // Compression: none
+		tiffShorts(262, 1),      // PhotometricInterpretation: BlackIsZero
+		long(273, header),       // StripOffsets
+		tiffShorts(277, 1),      // SamplesPerPixel
+		long(278, uint32(g.Ny)), // RowsPerStrip
+		long(279, size),         // StripByteCounts
+		tiffShorts(284, 1),      // PlanarConfiguration: chunky
+		tiffShorts(339, 3),      // SampleFormat: IEEE floating point
+		tiffDoubles(33550, float64(g.Dx), float64(g.Dy), 0),   // ModelPixelScale
+		tiffDoubles(33922, 0, 0, 0, float64(g.Xorig), top, 0), // ModelTiepoint
+		tiffString(42113, "nan"),                              // GDAL_NODATA
+	}
+	fields = append(fields, keys.fields()...)
+	sort.Slice(fields, func(i, j int) bool { return fields[i].tag < fields[j].tag })
+
+	bw := bufio.NewWriter(w)
+	ifd := header + size
+	b := []byte("II*\x00")
+	b = binary.LittleEndian.AppendUint32(b, ifd)
+	bw.Write(b)
+	// The rows are stored from north to south.
+	b = make([]byte, 4*g.Nx)
+	for j := g.Ny - 1; j >= 0; j-- {
+		for i, v := range field[j*g.Nx : (j+1)*g.Nx] {
+			binary.LittleEndian.PutUint32(b[4*i:], math.Float32bits(v))
+		}
+		bw.Write(b)
+	}
+	// Values that do not fit in the directory follow it.
+	extra := ifd + 2 + 12*uint32(len(fields)) + 4
+	b = binary.LittleEndian.AppendUint16(b[:0], uint16(len(fields)))
+	var data []byte
+	for _, f := range fields {
+		b = binary.LittleEndian.AppendUint16(b, f.tag)
+		b = binary.LittleEndian.AppendUint16(b, f.typ)
+		b = binary.LittleEndian.AppendUint32(b, f.count)
+		if len(f.value) <= 4 {
+			b = append(b, f.value...)
+			b = append(b, make([]byte, 4-len(f.value))...)
+			continue
+		}
+		b = binary.LittleEndian.AppendUint32(b, extra+uint32(len(data)))
+		data = append(data, f.value...)
+		if len(data)%2 != 0 {
+			data = append(data, 0)
+		}
+	}
+	b = binary.LittleEndian.AppendUint32(b, 0) // no more directories
+	bw.Write(b)
+	bw.Write(data)
+	return bw.Flush()
+}
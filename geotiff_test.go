@@ -0,0 +1,88 @@
+package uam_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/ctessum/uam/uamtest"
+)
+
+// tiffFields returns the value or offset of each field in the first
+// image file directory of little-endian TIFF file b.
+func tiffFields(t *testing.T, b []byte) map[uint16]uint32 {
+	t.Helper()
+	if string(b[:4]) != "II*\x00" {
+		t.Fatalf("got header %q", b[:4])
+	}
+	ifd := binary.LittleEndian.Uint32(b[4:])
+	n := int(binary.LittleEndian.Uint16(b[ifd:]))
+	fields := make(map[uint16]uint32, n)
+	for e := 0; e < n; e++ {
+		entry := b[int(ifd)+2+12*e:]
+		tag, typ := binary.LittleEndian.Uint16(entry), binary.LittleEndian.Uint16(entry[2:])
+		if typ == 3 { // SHORT
+			fields[tag] = uint32(binary.LittleEndian.Uint16(entry[8:]))
+		} else {
+			fields[tag] = binary.LittleEndian.Uint32(entry[8:])
+		}
+	}
+	return fields
+}
+
+func TestWriteGeoTIFF(t *testing.T) {
+	g := uamtest.DefaultGrid
+	field := make([]float32, g.Nx*g.Ny)
+	for j := int32(0); j < g.Ny; j++ {
+		for i := int32(0); i < g.Nx; i++ {
+			field[j*g.Nx+i] = float32(10*j + i)
+		}
+	}
+	field[0] = float32(math.NaN())
+	var buf bytes.Buffer
+	if err := g.WriteGeoTIFF(&buf, field); err != nil {
+		t.Fatal(err)
+	}
+	b := buf.Bytes()
+	fields := tiffFields(t, b)
+	if fields[256] != uint32(g.Nx) || fields[257] != uint32(g.Ny) {
+		t.Errorf("got size %dx%d, want %dx%d", fields[256], fields[257], g.Nx, g.Ny)
+	}
+	if fields[339] != 3 || fields[258] != 32 {
+		t.Errorf("got sample format %d with %d bits, want 32-bit floats", fields[339], fields[258])
+	}
+
+	// The rows are stored from north to south.
+	data := b[fields[273]:]
+	for row := int32(0); row < g.Ny; row++ {
+		for i := int32(0); i < g.Nx; i++ {
+			got := math.Float32frombits(binary.LittleEndian.Uint32(data[4*(row*g.Nx+i):]))
+			want := field[(g.Ny-1-row)*g.Nx+i]
+			if got != want && !(math.IsNaN(float64(got)) && math.IsNaN(float64(want))) {
+				t.Errorf("row %d column %d: got %g, want %g", row, i, got, want)
+			}
+		}
+	}
+
+	// The tie point puts the top left corner of the image at the
+	// northwest corner of the grid.
+	var tie [6]float64
+	for n := range tie {
+		tie[n] = math.Float64frombits(binary.LittleEndian.Uint64(b[fields[33922]+uint32(8*n):]))
+	}
+	if want := [6]float64{0, 0, 0, float64(g.Xorig), float64(g.Yorig + float32(g.Ny)*g.Dy), 0}; tie != want {
+		t.Errorf("got tie point %v, want %v", tie, want)
+	}
+	if _, ok := fields[34735]; !ok {
+		t.Error("no GeoKeyDirectory")
+	}
+
+	if err := g.WriteGeoTIFF(&buf, field[1:]); err == nil {
+		t.Error("wrong length: got no error")
+	}
+	g.Iproj = 99
+	if err := g.WriteGeoTIFF(&buf, field); err == nil {
+		t.Error("unsupported projection: got no error")
+	}
+}
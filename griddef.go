@@ -0,0 +1,83 @@
+package uam
+
+import "fmt"
+
+// GridDef describes the geometry of a grid: its location, cell size,
+// dimensions, and projection. The projection parameters are stored in
+// the header fields that CAMx uses for them.
+type GridDef struct {
+	Xorig, Yorig float32 // SW corner of the grid
+	Dx, Dy       float32 // cell size
+	Nx, Ny, Nz   int32   // number of columns, rows, and layers
+
+	Iproj        int32   // projection type (Nzlo in the header)
+	Iutm         int32   // UTM zone
+	Orgx, Orgy   float32 // center longitude and latitude of the projection
+	Tlat1, Tlat2 float32 // true latitudes (Hts and Htl in the header)
+}
+
+// GridDef returns the grid definition in h.
+func (h Header) GridDef() GridDef {
+	return GridDef{
+		Xorig: h.Utmx, Yorig: h.Utmy, Dx: h.Dx, Dy: h.Dy,
+		Nx: h.Nx, Ny: h.Ny, Nz: h.Nz,
+		Iproj: h.Nzlo, Iutm: h.Iutm, Orgx: h.Orgx, Orgy: h.Orgy,
+		Tlat1: h.Hts, Tlat2: h.Htl,
+	}
+}
+
+// GridDef returns the definition of the grid of f.
+func (f *UAM) GridDef() GridDef {
+	return f.Header().GridDef()
+}
+
+// Apply sets the grid fields of h to g.
+func (g GridDef) Apply(h *Header) {
+	h.Utmx, h.Utmy, h.Dx, h.Dy = g.Xorig, g.Yorig, g.Dx, g.Dy
+	h.Nx, h.Ny, h.Nz = g.Nx, g.Ny, g.Nz
+	h.Nzlo, h.Iutm, h.Orgx, h.Orgy = g.Iproj, g.Iutm, g.Orgx, g.Orgy
+	h.Hts, h.Htl = g.Tlat1, g.Tlat2
+}
+
+// Header returns the header of a file of type name on grid g.
+func (g GridDef) Header(name string) Header {
+	h := Header{Name: name}
+	g.Apply(&h)
+	return h
+}
+
+// Equal returns whether g and o describe the same grid.
+func (g GridDef) Equal(o GridDef) bool {
+	return g == o
+}
+
+// SameProjection returns whether g and o use the same projection.
+func (g GridDef) SameProjection(o GridDef) bool {
+	return g.Iproj == o.Iproj && g.Iutm == o.Iutm && g.Orgx == o.Orgx &&
+		g.Orgy == o.Orgy && g.Tlat1 == o.Tlat1 && g.Tlat2 == o.Tlat2
+}
+
+// Check returns an error describing how o differs from g, or nil if
+// they describe the same grid.
+func (g GridDef) Check(o GridDef) error {
+	if o.Nx != g.Nx || o.Ny != g.Ny || o.Nz != g.Nz {
+		return fmt.Errorf("dimensions %dx%dx%d do not match %dx%dx%d",
+			o.Nx, o.Ny, o.Nz, g.Nx, g.Ny, g.Nz)
+	}
+	if o.Dx != g.Dx || o.Dy != g.Dy {
+		return fmt.Errorf("cell size %gx%g does not match %gx%g",
+			o.Dx, o.Dy, g.Dx, g.Dy)
+	}
+	if o.Xorig != g.Xorig || o.Yorig != g.Yorig {
+		return fmt.Errorf("SW corner (%g, %g) does not match (%g, %g)",
+			o.Xorig, o.Yorig, g.Xorig, g.Yorig)
+	}
+	if !g.SameProjection(o) {
+		return fmt.Errorf("projection (type %d, zone %d, center (%g, %g), true "+
+			"latitudes %g and %g) does not match (type %d, zone %d, center "+
+			"(%g, %g), true latitudes %g and %g)",
+			o.Iproj, o.Iutm, o.Orgx, o.Orgy, o.Tlat1, o.Tlat2,
+			g.Iproj, g.Iutm, g.Orgx, g.Orgy, g.Tlat1, g.Tlat2)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package uam
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"slices"
+)
+
+// Handle reads hours of a file by their position rather than from a
+// cursor, so that it can be used by any number of goroutines at once,
+// for example to read different hours of one file at the same time. Each
+// read finds its records from the layout of the file (or its index; see
+// UseIndex) and reads them with io.ReaderAt, keeping all of its state
+// in the call. A Handle reads with the options, species selection, and
+// window that its file had when the Handle was made; later changes to
+// the file do not affect it. It uses the file's input, so the file must
+// not be closed while the Handle is in use.
+type Handle struct {
+	f    *UAM // copy of the settings of the file
+	ra   io.ReaderAt
+	size int64 // size of the file, or -1 if it is not known
+}
+
+// Handle returns a Handle for reading f concurrently. f must be an
+// uncompressed file with random access, such as one opened with Open,
+// OpenMmap, or NewReaderAt.
+func (f *UAM) Handle() (*Handle, error) {
+	ra := f.r.readerAt()
+	if ra == nil || f.compressed() {
+		return nil, errNoRandomAccess
+	}
+	c := *f
+	c.ctx, c.Hours, c.Data, c.overrides = nil, nil, nil, nil
+	c.Spnames = slices.Clone(f.Spnames)
+	c.selected = maps.Clone(f.selected)
+	return &Handle{f: &c, ra: ra, size: f.fileSize()}, nil
+}
+
+// ReadHour reads the given hour (counting from 0) into a new Hour, like
+// ReadNextHour on the file. It returns io.EOF if the file ends before
+// the hour.
+func (h *Handle) ReadHour(hour int) (*Hour, error) {
+	f := h.f
+	if hour < 0 {
+		return nil, fmt.Errorf("invalid hour %d", hour)
+	}
+	off := f.hourOffset(hour)
+	if f.index != nil && hour >= len(f.index.Hours) || h.size >= 0 && off >= h.size {
+		return nil, io.EOF
+	}
+	buf := make([]byte, f.timeRecordSize())
+	if err := h.readRecord(buf, 16, off); err != nil {
+		return nil, f.parseError(err, hour, off)
+	}
+	ml := f.markerLen()
+	out := &Hour{
+		Date: int32(ByteOrder.Uint32(buf[ml:])),
+		Time: math.Float32frombits(ByteOrder.Uint32(buf[ml+4:])),
+		Data: make(map[string][]float32),
+	}
+	if f.Name == "PTSOURCE" {
+		off += f.timeRecordSize() + f.recordSize(8)
+		buf = make([]byte, f.recordSize(20*int64(f.Npts)))
+		if err := h.readRecord(buf, 20*int64(f.Npts), off); err != nil {
+			return nil, f.parseError(err, hour, off)
+		}
+		out.Overrides = make([]StackOverride, f.Npts)
+		decodeOverrides(buf[ml:], out.Overrides)
+	} else {
+		f.setGrid(out)
+	}
+	for _, spname := range f.Spnames {
+		if !f.isSelected(spname) {
+			continue
+		}
+		vals, err := f.readSpecies(h.ra, hour, spname)
+		if err != nil {
+			return nil, err
+		}
+		out.Data[spname] = vals
+	}
+	f.computeDerived(out.Data)
+	return out, nil
+}
+
+// readRecord reads the record at off, which holds n bytes of data, into
+// buf and checks its leading marker.
+func (h *Handle) readRecord(buf []byte, n, off int64) error {
+	if _, err := h.ra.ReadAt(buf, off); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	return h.f.markerError(h.f.decodeMarker(buf), n, off)
+}
+
+// ReadLayer reads one layer of a species into dst, like UAM.ReadLayer.
+func (h *Handle) ReadLayer(hour int, species string, k int32, dst []float32) error {
+	return h.f.readLayer(h.ra, hour, species, k, dst)
+}
+
+// ReadSpecies reads all layers of a species, like UAM.ReadSpecies.
+func (h *Handle) ReadSpecies(hour int, species string) ([]float32, error) {
+	return h.f.readSpecies(h.ra, hour, species)
+}
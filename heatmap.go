@@ -0,0 +1,82 @@
+package uam
+
+import (
+	"fmt"
+	"io"
+	"math"
+)
+
+// HeatmapOptions holds options for WriteHeatmap.
+type HeatmapOptions struct {
+	Species string
+	Hour    int   // index of the hour
+	Layer   int32 // layer to draw
+
+	// Min and Max are the values at the ends of the color scale. If both
+	// are zero, the scale spans the range of the values that are drawn.
+	Min, Max float32
+	// Log spreads the color scale logarithmically. Values that are not
+	// positive are then transparent.
+	Log      bool
+	Colormap Colormap // nil means Sequential
+	Scale    int      // pixels per grid cell; 0 means 1
+}
+
+// WriteHeatmap reads gridded file f up to the requested hour and writes
+// an image of one layer of a species in that hour to w in PNG format,
+// with row j = 0 at the bottom. It selects only opts.Species to be
+// decoded from f.
+func (f *UAM) WriteHeatmap(w io.Writer, opts HeatmapOptions) error {
+	if f.Name == "PTSOURCE" {
+		return fmt.Errorf("heatmaps can only be made for gridded files")
+	}
+	if opts.Layer < 0 || opts.Layer >= f.Nz {
+		return fmt.Errorf("layer %d is out of range", opts.Layer)
+	}
+	auto := opts.Min == 0 && opts.Max == 0
+	if opts.Log && !auto && (opts.Min <= 0 || opts.Max <= 0) {
+		return fmt.Errorf("a logarithmic color scale needs a positive minimum and maximum")
+	}
+	if err := f.SelectSpecies([]string{opts.Species}); err != nil {
+		return err
+	}
+	data := make(map[string][]float32)
+	for h := 0; h <= opts.Hour; h++ {
+		if err := f.readHour(data); err == io.EOF {
+			return fmt.Errorf("hour %d is out of range; file has %d hours",
+				opts.Hour, h)
+		} else if err != nil {
+			return err
+		}
+	}
+	_, _, nx, ny := f.WindowGrid()
+	n := nx * ny
+	field := append([]float32(nil), data[opts.Species][opts.Layer*n:(opts.Layer+1)*n]...)
+	vmin, vmax := opts.Min, opts.Max
+	if opts.Log {
+		for i, v := range field {
+			if v > 0 {
+				field[i] = float32(math.Log10(float64(v)))
+			} else {
+				field[i] = float32(math.NaN())
+			}
+		}
+		if !auto {
+			vmin = float32(math.Log10(float64(vmin)))
+			vmax = float32(math.Log10(float64(vmax)))
+		}
+	}
+	if auto {
+		vmin, vmax = float32(math.Inf(1)), float32(math.Inf(-1))
+		for _, v := range field {
+			if !math.IsNaN(float64(v)) {
+				vmin, vmax = min(vmin, v), max(vmax, v)
+			}
+		}
+	}
+	cmap := opts.Colormap
+	if cmap == nil {
+		cmap = Sequential
+	}
+	return WritePNG(w, field, int(nx), int(ny), vmin, vmax, cmap, opts.Scale)
+}
@@ -0,0 +1,70 @@
+package uam_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/ctessum/uam"
+	"github.com/ctessum/uam/uamtest"
+)
+
+func TestWriteHeatmap(t *testing.T) {
+	e, err := uamtest.Emissions(uamtest.Options{Hours: 2, Pattern: uamtest.Linear(0, 1, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	heatmap := func(opts uam.HeatmapOptions) image.Image {
+		t.Helper()
+		var b bytes.Buffer
+		if err := open(t, e).WriteHeatmap(&b, opts); err != nil {
+			t.Fatal(err)
+		}
+		img, err := png.Decode(&b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return img
+	}
+	dark := color.RGBA{0x44, 0x01, 0x54, 255}
+	yellow := color.RGBA{0xfd, 0xe7, 0x25, 255}
+
+	// In hour 1, the values go from 1 in column 0 to 4 in column 3.
+	img := heatmap(uam.HeatmapOptions{Species: "NO", Hour: 1, Scale: 3})
+	if got := img.Bounds().Size(); got != image.Pt(12, 9) {
+		t.Fatalf("got size %v, want 12x9", got)
+	}
+	if got := rgba(img.At(0, 8)); got != dark {
+		t.Errorf("column 0: got %v, want %v", got, dark)
+	}
+	if got := rgba(img.At(11, 0)); got != yellow {
+		t.Errorf("column 3: got %v, want %v", got, yellow)
+	}
+
+	// Values outside of a fixed range are clamped, and values that are
+	// not positive are transparent on a logarithmic scale.
+	img = heatmap(uam.HeatmapOptions{Species: "NO", Min: 1, Max: 2, Colormap: uam.Diverging})
+	if got := rgba(img.At(3, 0)); got != (color.RGBA{255, 0, 0, 255}) {
+		t.Errorf("clamped: got %v", got)
+	}
+	img = heatmap(uam.HeatmapOptions{Species: "NO", Log: true})
+	if got := rgba(img.At(0, 0)); got.A != 0 {
+		t.Errorf("log of 0: got %v, want transparent", got)
+	}
+	if got := rgba(img.At(1, 0)); got != dark {
+		t.Errorf("log of 1: got %v, want %v", got, dark)
+	}
+
+	for _, opts := range []uam.HeatmapOptions{
+		{Species: "NO", Hour: 2},
+		{Species: "NO", Layer: 2},
+		{Species: "CO"},
+		{Species: "NO", Log: true, Min: 0, Max: 1},
+	} {
+		if err = open(t, e).WriteHeatmap(&bytes.Buffer{}, opts); err == nil {
+			t.Errorf("%+v: got no error", opts)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package uam
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// HistogramOptions holds options for Histograms.
+type HistogramOptions struct {
+	Bins    int      // number of bins; 0 means 20
+	Min     float64  // lower edge of the first bin
+	Max     float64  // upper edge of the last bin; if Max <= Min, the range of the data is used
+	Log     bool     // whether to space the bins logarithmically
+	PerHour bool     // whether to make a histogram for each hour instead of one for all hours
+	Species []string // species to include; nil means all
+}
+
+// Histogram is the distribution of the values of one species.
+type Histogram struct {
+	Species string    `json:"species"`
+	Hour    int       `json:"hour"`  // index of the hour, or -1 for all hours
+	Edges   []float64 `json:"edges"` // bin edges; bin i spans [Edges[i], Edges[i+1]), and the last bin includes its end
+	Counts  []int     `json:"counts"`
+	Below   int       `json:"below"` // number of values below the first bin
+	Above   int       `json:"above"` // number of values above the end of the last bin
+}
+
+// add adds v to the histogram.
+func (h *Histogram) add(v float64, log bool) {
+	edges := h.Edges
+	switch {
+	case v < edges[0] || math.IsNaN(v):
+		h.Below++
+		return
+	case v > edges[len(edges)-1]:
+		h.Above++
+		return
+	case v == edges[len(edges)-1]:
+		// Include the upper edge in the last bin.
+		h.Counts[len(h.Counts)-1]++
+		return
+	}
+	var b int
+	if log {
+		b = int(math.Log(v/edges[0]) / math.Log(edges[len(edges)-1]/edges[0]) * float64(len(h.Counts)))
+	} else {
+		b = int((v - edges[0]) / (edges[len(edges)-1] - edges[0]) * float64(len(h.Counts)))
+	}
+	h.Counts[min(b, len(h.Counts)-1)]++
+}
+
+// histogramEdges returns the edges of bins bins from lo to hi.
+func histogramEdges(lo, hi float64, bins int, log bool) []float64 {
+	edges := make([]float64, bins+1)
+	for i := range edges {
+		frac := float64(i) / float64(bins)
+		if log {
+			edges[i] = lo * math.Pow(hi/lo, frac)
+		} else {
+			edges[i] = lo + (hi-lo)*frac
+		}
+	}
+	return edges
+}
+
+// Histograms reads the remaining hours of f and returns a histogram of
+// the values of each species, either for each hour or for all hours
+// together. If no range is given in opts, the range of each species is
+// found by reading the data twice, which requires that the file can be
+// read out of order (see SeekHour). Logarithmic bins only include
+// positive values. The species to include are selected on f with
+// SelectSpecies.
+func (f *UAM) Histograms(opts HistogramOptions) ([]Histogram, error) {
+	if opts.Bins <= 0 {
+		opts.Bins = 20
+	}
+	if opts.Log && opts.Max > opts.Min && opts.Min <= 0 {
+		return nil, fmt.Errorf("logarithmic bins need a positive minimum")
+	}
+	species := opts.Species
+	if species == nil {
+		species = f.Spnames
+	}
+	if err := f.SelectSpecies(species); err != nil {
+		return nil, err
+	}
+	start := f.hour
+	data := make(map[string][]float32)
+
+	ranges := make(map[string][2]float64)
+	if opts.Max <= opts.Min {
+		for _, spname := range species {
+			ranges[spname] = [2]float64{math.Inf(1), math.Inf(-1)}
+		}
+		for {
+			_, _, _, _, _, _, err := f.ReadHour(data)
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				return nil, err
+			}
+			for _, spname := range species {
+				r := ranges[spname]
+				for _, v := range data[spname] {
+					if opts.Log && v <= 0 {
+						continue
+					}
+					r[0], r[1] = min(r[0], float64(v)), max(r[1], float64(v))
+				}
+				ranges[spname] = r
+			}
+		}
+		if err := f.SeekHour(start); err != nil {
+			return nil, fmt.Errorf("finding the range of the data: %w", err)
+		}
+		for spname, r := range ranges {
+			if math.IsInf(r[0], 1) {
+				r = [2]float64{1, 10} // No data; any range will do.
+			} else if r[0] == r[1] {
+				r[1] = r[0] + max(math.Abs(r[0]), 1)
+			}
+			ranges[spname] = r
+		}
+	} else {
+		for _, spname := range species {
+			ranges[spname] = [2]float64{opts.Min, opts.Max}
+		}
+	}
+
+	newHist := func(spname string, hour int) *Histogram {
+		r := ranges[spname]
+		return &Histogram{Species: spname, Hour: hour,
+			Edges:  histogramEdges(r[0], r[1], opts.Bins, opts.Log),
+			Counts: make([]int, opts.Bins),
+		}
+	}
+	var hists []Histogram
+	total := make(map[string]*Histogram)
+	for {
+		_, _, _, _, _, _, err := f.ReadHour(data)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		for _, spname := range species {
+			h := total[spname]
+			if opts.PerHour || h == nil {
+				h = newHist(spname, -1)
+				if opts.PerHour {
+					h.Hour = f.hour - 1
+				} else {
+					total[spname] = h
+				}
+			}
+			for _, v := range data[spname] {
+				h.add(float64(v), opts.Log)
+			}
+			if opts.PerHour {
+				hists = append(hists, *h)
+			}
+		}
+	}
+	if !opts.PerHour {
+		for _, spname := range species {
+			if h, ok := total[spname]; ok {
+				hists = append(hists, *h)
+			}
+		}
+	}
+	sort.SliceStable(hists, func(i, j int) bool {
+		return hists[i].Hour < hists[j].Hour
+	})
+	return hists, nil
+}
+
+// WriteHistogramsCSV writes histograms to w in CSV format, with one
+// row per bin.
+func WriteHistogramsCSV(w io.Writer, hists []Histogram) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"species", "hour", "bin_low", "bin_high", "count"})
+	for _, h := range hists {
+		for i, c := range h.Counts {
+			cw.Write([]string{h.Species, strconv.Itoa(h.Hour),
+				strconv.FormatFloat(h.Edges[i], 'g', -1, 64),
+				strconv.FormatFloat(h.Edges[i+1], 'g', -1, 64),
+				strconv.Itoa(c)})
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteHistogramsJSON writes histograms to w in JSON format.
+func WriteHistogramsJSON(w io.Writer, hists []Histogram) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(hists)
+}
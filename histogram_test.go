@@ -0,0 +1,83 @@
+package uam_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ctessum/uam"
+	"github.com/ctessum/uam/uamtest"
+)
+
+func TestHistograms(t *testing.T) {
+	// Each of the values 0, 1, 2, and 3 (the column) is in 6 cells.
+	e, err := uamtest.Emissions(uamtest.Options{Hours: 2, Pattern: uamtest.Linear(0, 0, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		opts uam.HistogramOptions
+		want []uam.Histogram
+	}{
+		{
+			opts: uam.HistogramOptions{Bins: 3, Species: []string{"NO"}},
+			want: []uam.Histogram{{Species: "NO", Hour: -1,
+				Edges: []float64{0, 1, 2, 3}, Counts: []int{12, 12, 24}}},
+		},
+		{
+			opts: uam.HistogramOptions{Bins: 1, Min: 1, Max: 2, Species: []string{"O3"}, PerHour: true},
+			want: []uam.Histogram{
+				{Species: "O3", Hour: 0, Edges: []float64{1, 2}, Counts: []int{12}, Below: 6, Above: 6},
+				{Species: "O3", Hour: 1, Edges: []float64{1, 2}, Counts: []int{12}, Below: 6, Above: 6},
+			},
+		},
+		{
+			// Zeros are below the first logarithmic bin.
+			opts: uam.HistogramOptions{Bins: 2, Log: true, Species: []string{"NO2"}},
+			want: []uam.Histogram{{Species: "NO2", Hour: -1,
+				Edges: []float64{1, 1.7320508075688772, 3}, Counts: []int{12, 24}, Below: 12}},
+		},
+	} {
+		got, err := open(t, e).Histograms(test.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%+v: got %+v, want %+v", test.opts, got, test.want)
+		}
+	}
+
+	hists, err := open(t, e).Histograms(uam.HistogramOptions{Bins: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hists) != 3 {
+		t.Fatalf("got %d histograms, want one for each species", len(hists))
+	}
+	var b bytes.Buffer
+	if err = uam.WriteHistogramsCSV(&b, hists[:1]); err != nil {
+		t.Fatal(err)
+	}
+	want := "species,hour,bin_low,bin_high,count\nNO,-1,0,1.5,24\nNO,-1,1.5,3,24\n"
+	if b.String() != want {
+		t.Errorf("CSV: got %q, want %q", b.String(), want)
+	}
+	b.Reset()
+	if err = uam.WriteHistogramsJSON(&b, hists); err != nil {
+		t.Fatal(err)
+	}
+	var decoded []uam.Histogram
+	if err = json.Unmarshal(b.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, hists) {
+		t.Errorf("JSON: got %+v, want %+v", decoded, hists)
+	}
+
+	if _, err = open(t, e).Histograms(uam.HistogramOptions{Log: true, Max: 1}); err == nil ||
+		!strings.Contains(err.Error(), "positive") {
+		t.Errorf("logarithmic bins from 0: got error %v", err)
+	}
+}
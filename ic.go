@@ -0,0 +1,53 @@
+package uam
+
+import (
+	"fmt"
+	"io"
+)
+
+// InitialConditions writes an AIRQUALITY file called outfile that holds
+// the last hour of the AVERAGE or INSTANT file called filename, to be
+// used as the initial conditions of a simulation that starts when the
+// original one ends, such as the next day of a series of daily runs. The
+// initial conditions are for the time at the end of the last hour, and
+// the rest of the header is copied from the original file.
+func InitialConditions(filename, outfile string) error {
+	f, err := Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if f.Name != "AVERAGE" && f.Name != "INSTANT" {
+		return fmt.Errorf("%v: cannot make initial conditions from %v files", filename, f.Name)
+	}
+	// Read alternately into two maps so that the last complete hour is
+	// kept when the end of the file is reached.
+	last, next := make(map[string][]float32), make(map[string][]float32)
+	var date int32
+	var time float32
+	for hours := 0; ; hours++ {
+		if err = f.readHour(next); err == io.EOF {
+			if hours == 0 {
+				return fmt.Errorf("%v has no hours", filename)
+			}
+			break
+		} else if err != nil {
+			return err
+		}
+		last, next = next, last
+		date, time = f.date, f.time
+	}
+	ic := NewLike(f, 0)
+	ic.Name = "AIRQUALITY"
+	ic.sdate, ic.begtim = AddHours(date, time, 1)
+	ic.edate, ic.endtim = AddHours(date, time, 2)
+	w, err := Create(outfile, ic)
+	if err != nil {
+		return err
+	}
+	if err = w.WriteHour(last); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
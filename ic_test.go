@@ -0,0 +1,50 @@
+package uam_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ctessum/uam"
+	"github.com/ctessum/uam/uamtest"
+)
+
+func TestInitialConditions(t *testing.T) {
+	dir := t.TempDir()
+	a, err := uamtest.Average(uamtest.Options{Hours: 24})
+	if err != nil {
+		t.Fatal(err)
+	}
+	outfile := filepath.Join(dir, "ic.uam")
+	if err = uam.InitialConditions(uamtest.TempFile(t, a), outfile); err != nil {
+		t.Fatal(err)
+	}
+	r, err := uam.Open(outfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	// The initial conditions are for the start of the next day.
+	if h := r.Header(); h.Name != "AIRQUALITY" || h.Sdate != 15002 || h.Begtim != 0 ||
+		h.Edate != 15002 || h.Endtim != 1 {
+		t.Errorf("got header %+v", h)
+	}
+	hours := readAll(t, r)
+	if len(hours) != 1 {
+		t.Fatalf("got %d hours, want 1", len(hours))
+	}
+	for spname, vals := range hours[0].Data {
+		for n, v := range vals {
+			if want := a.Hours[23].Data[spname][n]; v != want {
+				t.Fatalf("%v %d: got %g, want %g from the last hour", spname, n, v, want)
+			}
+		}
+	}
+
+	e, err := uamtest.Emissions(uamtest.Options{Hours: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = uam.InitialConditions(uamtest.TempFile(t, e), outfile); err == nil {
+		t.Error("emissions file: got no error")
+	}
+}
@@ -0,0 +1,513 @@
+package uam
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"os"
+	"slices"
+)
+
+// indexMagic starts every index file.
+const indexMagic = "UAMINDEX"
+
+// Index holds the byte offset of every record in the hours of a file and
+// the time of every hour, as found by reading the record markers, so that
+// a single hour, species, or layer can be read directly and hours can be
+// found by time without scanning the file. An index is built by
+// BuildIndex and can be saved next to the file with SaveIndex, for use
+// by later readers with UseIndex or OpenIndexed.
+type Index struct {
+	Size      int64 // size of the indexed file in bytes
+	DataStart int64 // offset of the first hour
+	Hours     []IndexedHour
+}
+
+// IndexedHour is the entry of an Index for one hour.
+type IndexedHour struct {
+	Date   int32   // start date (YYJJJ)
+	Time   float32 // start hour
+	Offset int64   // offset of the time record
+	// Records holds the offset of the data record of each species and
+	// layer: layer k of species l of a gridded file is at index l*Nz+k,
+	// and species l of a PTSOURCE file is at index l.
+	Records []int64
+}
+
+// recordsPerHour returns the number of data records in each hour of f.
+func (f *UAM) recordsPerHour() int {
+	if f.Name == "PTSOURCE" {
+		return int(f.Nspec)
+	}
+	return int(f.Nspec) * int(f.Nz)
+}
+
+// BuildIndex reads the record markers and species names of every hour
+// of f, without decoding the data, and returns an index of the
+// records. Only complete hours are indexed. f must be an uncompressed
+// file with random access, such as one opened with Open or OpenMmap; its
+// position is not changed.
+func (f *UAM) BuildIndex() (*Index, error) {
+	ra := f.r.readerAt()
+	size := f.fileSize()
+	if ra == nil || size < 0 {
+		return nil, fmt.Errorf("an index can only be built for uncompressed files with random access")
+	}
+	x := &Index{Size: size, DataStart: f.dataStart}
+	ml := f.markerLen()
+	// record reads the first n bytes of the contents of the record at
+	// off and returns them with the offset of the next record.
+	record := func(off int64, n int) ([]byte, int64, error) {
+		b := make([]byte, ml+int64(n))
+		if _, err := ra.ReadAt(b, off); err != nil {
+			return nil, 0, err
+		}
+		length := f.decodeMarker(b)
+		if length < int64(n) {
+			return nil, 0, fmt.Errorf("%w: marker at byte %d is %d; it should be at least %d",
+				ErrBadRecordMarker, off, length, n)
+		}
+		return b[ml:], off + length + 2*ml, nil
+	}
+	nz := f.Nz
+	if f.Name == "PTSOURCE" {
+		nz = 1
+	}
+	for off := f.dataStart; off < size; {
+		h := IndexedHour{Offset: off, Records: make([]int64, 0, f.recordsPerHour())}
+		b, next, err := record(off, 16)
+		if err != nil {
+			return x, f.indexError(err, len(x.Hours), off)
+		}
+		h.Date = int32(ByteOrder.Uint32(b))
+		h.Time = math.Float32frombits(ByteOrder.Uint32(b[4:]))
+		off = next
+		if f.Name == "PTSOURCE" {
+			for i := 0; i < 2; i++ { // number of points and override records
+				if _, next, err = record(off, 0); err != nil {
+					return x, f.indexError(err, len(x.Hours), off)
+				}
+				off = next
+			}
+		}
+		for _, spname := range f.Spnames {
+			for k := int32(0); k < nz; k++ {
+				b, next, err := record(off, 44)
+				if err != nil {
+					return x, f.indexError(err, len(x.Hours), off)
+				}
+				if s := recordName(b); s != f.fileName(spname) {
+					return x, f.parseError(fmt.Errorf("%w: found record for species %v where %v was expected",
+						ErrSpeciesMismatch, s, f.fileName(spname)), len(x.Hours), off)
+				}
+				h.Records = append(h.Records, off)
+				off = next
+			}
+		}
+		if off > size {
+			break // The last hour is incomplete.
+		}
+		x.Hours = append(x.Hours, h)
+	}
+	return x, nil
+}
+
+// indexError returns nil if err is the end of the file, which means that
+// the hour being indexed is incomplete, and otherwise wraps err with its
+// position.
+func (f *UAM) indexError(err error, hour int, off int64) error {
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	return f.parseError(err, hour, off)
+}
+
+// Write writes x to w in a compact binary format that can be read with
+// ReadIndex.
+func (x *Index) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	perHour := 0
+	if len(x.Hours) > 0 {
+		perHour = len(x.Hours[0].Records)
+	}
+	bw.WriteString(indexMagic)
+	binary.Write(bw, ByteOrder, []int64{x.Size, x.DataStart})
+	binary.Write(bw, ByteOrder, []int32{int32(len(x.Hours)), int32(perHour)})
+	for _, h := range x.Hours {
+		if len(h.Records) != perHour {
+			return fmt.Errorf("hours of the index have different numbers of records")
+		}
+		binary.Write(bw, ByteOrder, h.Date)
+		binary.Write(bw, ByteOrder, h.Time)
+		binary.Write(bw, ByteOrder, h.Offset)
+		binary.Write(bw, ByteOrder, h.Records)
+	}
+	return bw.Flush()
+}
+
+// ReadIndex reads an index written by Index.Write.
+func ReadIndex(r io.Reader) (*Index, error) {
+	br := bufio.NewReader(r)
+	magic := make([]byte, len(indexMagic))
+	if _, err := io.ReadFull(br, magic); err != nil || string(magic) != indexMagic {
+		return nil, fmt.Errorf("not an index file")
+	}
+	x := new(Index)
+	var counts [2]int32
+	if err := binary.Read(br, ByteOrder, &x.Size); err != nil {
+		return nil, err
+	}
+	if err := binary.Read(br, ByteOrder, &x.DataStart); err != nil {
+		return nil, err
+	}
+	if err := binary.Read(br, ByteOrder, &counts); err != nil {
+		return nil, err
+	}
+	nhours, perHour := counts[0], counts[1]
+	if nhours < 0 || perHour < 0 || int64(perHour)*int64(nhours)*8 > x.Size {
+		return nil, fmt.Errorf("invalid index of %d hours of %d records", nhours, perHour)
+	}
+	x.Hours = make([]IndexedHour, nhours)
+	for n := range x.Hours {
+		h := &x.Hours[n]
+		h.Records = make([]int64, perHour)
+		for _, v := range []interface{}{&h.Date, &h.Time, &h.Offset, h.Records} {
+			if err := binary.Read(br, ByteOrder, v); err != nil {
+				if err == io.EOF {
+					err = io.ErrUnexpectedEOF
+				}
+				return nil, err
+			}
+		}
+	}
+	return x, nil
+}
+
+// IndexFilename returns the name of the sidecar index file for the file
+// called filename.
+func IndexFilename(filename string) string {
+	return filename + ".idx"
+}
+
+// SaveIndex builds an index of the file called filename and writes it to
+// the file named by IndexFilename.
+func SaveIndex(filename string) (*Index, error) {
+	f, err := Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	x, err := f.BuildIndex()
+	if err != nil {
+		return nil, err
+	}
+	return x, writeIndexFile(filename, x)
+}
+
+// writeIndexFile writes x to the sidecar index file of filename.
+func writeIndexFile(filename string, x *Index) error {
+	w, err := os.Create(IndexFilename(filename))
+	if err != nil {
+		return err
+	}
+	if err = x.Write(w); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// LoadIndex reads the sidecar index of the file called filename, as
+// written by SaveIndex.
+func LoadIndex(filename string) (*Index, error) {
+	r, err := os.Open(IndexFilename(filename))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	x, err := ReadIndex(r)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", IndexFilename(filename), err)
+	}
+	return x, nil
+}
+
+// UseIndex makes f use x to find the start of each hour in SeekHour and
+// the records read by ReadLayer. It returns an error if x does not match
+// the size and layout of f, such as when the file has changed since the
+// index was built.
+func (f *UAM) UseIndex(x *Index) error {
+	if size := f.fileSize(); size >= 0 && size != x.Size {
+		return fmt.Errorf("index is for a file of %d bytes; the file has %d bytes", x.Size, size)
+	}
+	if x.DataStart != f.dataStart {
+		return fmt.Errorf("index is for a file whose data start at byte %d; they start at byte %d",
+			x.DataStart, f.dataStart)
+	}
+	for n, h := range x.Hours {
+		if len(h.Records) != f.recordsPerHour() {
+			return fmt.Errorf("index has %d records for hour %d; the file has %d per hour",
+				len(h.Records), n, f.recordsPerHour())
+		}
+	}
+	f.index = x
+	return nil
+}
+
+// OpenIndexed opens a file like Open and uses its sidecar index, if it
+// has one that matches the file. Otherwise, the index is built and saved
+// so that later readers can use it.
+func OpenIndexed(filename string, opts ...Option) (*UAM, error) {
+	f, err := Open(filename, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x, err := LoadIndex(filename)
+	if err == nil && f.UseIndex(x) == nil {
+		return f, nil
+	} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		f.Close()
+		return nil, err
+	}
+	if x, err = f.BuildIndex(); err == nil {
+		err = writeIndexFile(filename, x)
+	}
+	if err == nil {
+		err = f.UseIndex(x)
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// ReadLayer reads layer k of the named species in the given hour
+// (counting from 0) into dst, without reading any other records. For
+// PTSOURCE files, k must be 0 and dst must have Npts values; for gridded
+// files, dst must have the number of values in one layer of the window
+// set with SetWindow, or of the whole grid. The record is found with the
+// index set by UseIndex, if any, and otherwise from the layout given by
+// the header. If the file has a cache (see WithCache), all layers of the
+// species are read and cached, so that the other layers can be read
+// without decoding. f must support random access; its position is not
+// changed. To read from several goroutines at once, use a Handle.
+func (f *UAM) ReadLayer(hour int, species string, k int32, dst []float32) error {
+	ra := f.r.readerAt()
+	if ra == nil {
+		return errNoRandomAccess
+	}
+	return f.readLayer(ra, hour, species, k, dst)
+}
+
+// readLayer implements ReadLayer, reading from ra.
+func (f *UAM) readLayer(ra io.ReaderAt, hour int, species string, k int32, dst []float32) error {
+	l := slices.Index(f.Spnames, species)
+	if l < 0 {
+		return fmt.Errorf("species %v is not in the file", species)
+	}
+	if k < 0 || k >= f.layers() {
+		return fmt.Errorf("layer %d is out of range", k)
+	}
+	if n := f.layerSize(); len(dst) != n {
+		return fmt.Errorf("dst has %d values; it should have %d", len(dst), n)
+	}
+	if f.cached() {
+		vals, err := f.cachedSpecies(ra, hour, l)
+		if err != nil {
+			return err
+		}
+		f.copyLayer(dst, vals, k)
+	} else if err := f.decodeLayer(ra, hour, l, k, f.window, dst); err != nil {
+		return err
+	}
+	if f.massUnits != nil {
+		return toMass(map[string][]float32{species: dst}, []string{species}, f.massUnits)
+	}
+	return nil
+}
+
+// ReadSpecies reads all layers of the named species in the given hour
+// (counting from 0), without reading any other species, and returns them
+// in the format used by ReadHour. As with ReadLayer, the records are
+// found with the index, if any, and the file's cache is used if it has
+// one.
+func (f *UAM) ReadSpecies(hour int, species string) ([]float32, error) {
+	ra := f.r.readerAt()
+	if ra == nil {
+		return nil, errNoRandomAccess
+	}
+	return f.readSpecies(ra, hour, species)
+}
+
+// readSpecies implements ReadSpecies, reading from ra.
+func (f *UAM) readSpecies(ra io.ReaderAt, hour int, species string) ([]float32, error) {
+	l := slices.Index(f.Spnames, species)
+	if l < 0 {
+		return nil, fmt.Errorf("species %v is not in the file", species)
+	}
+	n := f.layerSize()
+	out := make([]float32, n*int(f.layers()))
+	var vals []float32
+	if f.cached() {
+		var err error
+		if vals, err = f.cachedSpecies(ra, hour, l); err != nil {
+			return nil, err
+		}
+	}
+	for k := int32(0); k < f.layers(); k++ {
+		dst := out[int(k)*n : int(k+1)*n]
+		if vals != nil {
+			f.copyLayer(dst, vals, k)
+		} else if err := f.decodeLayer(ra, hour, l, k, f.window, dst); err != nil {
+			return nil, err
+		}
+	}
+	if f.massUnits != nil {
+		if err := toMass(map[string][]float32{species: out}, []string{species}, f.massUnits); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// layers returns the number of layers of data: Nz for gridded files and
+// 1 for PTSOURCE files.
+func (f *UAM) layers() int32 {
+	if f.Name == "PTSOURCE" {
+		return 1
+	}
+	return f.Nz
+}
+
+// layerSize returns the number of values in one layer of data read from
+// f: Npts for PTSOURCE files, or the number of cells in the window.
+func (f *UAM) layerSize() int {
+	if f.Name == "PTSOURCE" {
+		return int(f.Npts)
+	}
+	_, _, nx, ny := f.WindowGrid()
+	return int(nx * ny)
+}
+
+// cached returns whether reads of f use its cache.
+func (f *UAM) cached() bool {
+	return f.cache != nil && f.name != ""
+}
+
+// cachedSpecies returns the values of all layers of species l in the
+// given hour over the whole grid, from the cache if they are there, and
+// otherwise decodes them and adds them to the cache. The returned values
+// must not be modified.
+func (f *UAM) cachedSpecies(ra io.ReaderAt, hour, l int) ([]float32, error) {
+	key := cacheKey{file: f.name, hour: hour, species: f.fileName(f.Spnames[l])}
+	if vals, ok := f.cache.get(key); ok {
+		return vals, nil
+	}
+	n := int(f.Npts)
+	if f.Name != "PTSOURCE" {
+		n = int(f.Nx * f.Ny)
+	}
+	vals := make([]float32, n*int(f.layers()))
+	for k := int32(0); k < f.layers(); k++ {
+		if err := f.decodeLayer(ra, hour, l, k, nil, vals[int(k)*n:int(k+1)*n]); err != nil {
+			return nil, err
+		}
+	}
+	f.cache.put(key, vals)
+	return vals, nil
+}
+
+// copyLayer copies the values of layer k from all, which holds all
+// layers of a species over the whole grid, into dst, keeping only the
+// values within the window, if any.
+func (f *UAM) copyLayer(dst, all []float32, k int32) {
+	if f.Name == "PTSOURCE" {
+		copy(dst, all)
+		return
+	}
+	src := all[int(k*f.Nx*f.Ny):int((k+1)*f.Nx*f.Ny)]
+	w := f.window
+	if w == nil {
+		copy(dst, src)
+		return
+	}
+	nx := w.i2 - w.i1
+	for j := w.j1; j < w.j2; j++ {
+		copy(dst[(j-w.j1)*nx:(j-w.j1+1)*nx], src[j*f.Nx+w.i1:j*f.Nx+w.i2])
+	}
+}
+
+// errNoRandomAccess is returned by methods that require random access
+// when the file does not support it.
+var errNoRandomAccess = errors.New("the file does not support random access")
+
+// decodeLayer reads the record of layer k of species l in the given hour
+// from ra into dst, keeping only the values within window w, if any, of a
+// gridded file.
+func (f *UAM) decodeLayer(ra io.ReaderAt, hour, l int, k int32, w *window, dst []float32) error {
+	var off int64
+	switch {
+	case hour < 0 || f.index != nil && hour >= len(f.index.Hours):
+		return fmt.Errorf("hour %d is not in the file", hour)
+	case f.index != nil:
+		off = f.index.Hours[hour].Records[l*int(f.layers())+int(k)]
+	case f.Name == "PTSOURCE":
+		off = f.hourOffset(hour) + f.timeRecordSize() +
+			f.recordSize(8) + f.recordSize(20*int64(f.Npts)) +
+			int64(l)*f.speciesRecordSize(int64(f.Npts))
+	default:
+		off = f.hourOffset(hour) + f.timeRecordSize() +
+			(int64(l)*int64(f.Nz)+int64(k))*f.gridRecordSize()
+	}
+	spname := f.Spnames[l]
+	var err error
+	if f.Name == "PTSOURCE" {
+		err = f.decodePointRecord(ra, off, spname, dst)
+	} else {
+		buf := make([]byte, f.gridRecordSize())
+		err = f.decodeGridRecord(ra, off, buf, spname, 0, w, map[string][]float32{spname: dst})
+	}
+	if err != nil {
+		return f.parseError(err, hour, off)
+	}
+	return nil
+}
+
+// hourOffset returns the offset of the time record of the given hour,
+// from the index if it has the hour.
+func (f *UAM) hourOffset(hour int) int64 {
+	if f.index != nil && hour < len(f.index.Hours) {
+		return f.index.Hours[hour].Offset
+	}
+	return f.dataStart + int64(hour)*f.hourSize()
+}
+
+// decodePointRecord reads the record of species spname at offset off of
+// a PTSOURCE file into dst.
+func (f *UAM) decodePointRecord(ra io.ReaderAt, off int64, spname string, dst []float32) error {
+	buf := make([]byte, f.speciesRecordSize(int64(f.Npts)))
+	if _, err := ra.ReadAt(buf, off); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	ml := int(f.markerLen())
+	if err := f.markerError(f.decodeMarker(buf), int64(len(buf)-2*ml), off); err != nil {
+		return err
+	}
+	if s := recordName(buf[ml:]); s != f.fileName(spname) {
+		return fmt.Errorf("%w: found record for species %v where %v "+
+			"was expected", ErrSpeciesMismatch, s, f.fileName(spname))
+	}
+	vals := buf[ml+44:]
+	for i := range dst {
+		dst[i] = math.Float32frombits(ByteOrder.Uint32(vals[4*i:]))
+	}
+	return nil
+}
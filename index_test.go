@@ -0,0 +1,117 @@
+package uam_test
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/ctessum/uam"
+	"github.com/ctessum/uam/uamtest"
+)
+
+func TestIndex(t *testing.T) {
+	for _, newFile := range []func(uamtest.Options) (*uam.UAM, error){uamtest.Emissions, uamtest.PointSource} {
+		f, err := newFile(uamtest.Options{Hours: 3})
+		if err != nil {
+			t.Fatal(err)
+		}
+		filename := uamtest.TempFile(t, f)
+		x, err := uam.SaveIndex(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(x.Hours) != 3 {
+			t.Fatalf("%v: indexed %d hours, want 3", f.Name, len(x.Hours))
+		}
+		// The offsets match the layout of the file.
+		for h, hr := range x.Hours {
+			recs := f.RecordMap(h)
+			if hr.Offset != recs[0].Offset || hr.Date != f.Hours[h].Date || hr.Time != f.Hours[h].Time {
+				t.Errorf("%v hour %d: got %+v, want offset %d", f.Name, h, hr, recs[0].Offset)
+			}
+			species := recs[len(recs)-len(hr.Records):]
+			for n, off := range hr.Records {
+				if off != species[n].Offset {
+					t.Errorf("%v hour %d record %d: got offset %d, want %d", f.Name, h, n, off, species[n].Offset)
+				}
+			}
+		}
+
+		var b bytes.Buffer
+		if err = x.Write(&b); err != nil {
+			t.Fatal(err)
+		}
+		y, err := uam.ReadIndex(&b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(x, y) {
+			t.Errorf("%v: index round trips to %+v, want %+v", f.Name, y, x)
+		}
+
+		r, err := uam.OpenIndexed(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer r.Close()
+		if err = r.SeekHour(2); err != nil {
+			t.Fatal(err)
+		}
+		checkHours(t, readAll(t, r), f.Hours[2:])
+		dst := make([]float32, len(f.Hours[1].Data["O3"])/int(f.Nz))
+		if f.Name == "PTSOURCE" {
+			dst = make([]float32, f.Npts)
+		}
+		if err = r.ReadLayer(1, "O3", 0, dst); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(dst, f.Hours[1].Data["O3"][:len(dst)]) {
+			t.Errorf("%v: got layer %v, want %v", f.Name, dst, f.Hours[1].Data["O3"][:len(dst)])
+		}
+	}
+}
+
+func TestIndexMismatch(t *testing.T) {
+	f, err := uamtest.Emissions(uamtest.Options{Hours: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	filename := uamtest.TempFile(t, f)
+	if _, err = uam.SaveIndex(filename); err != nil {
+		t.Fatal(err)
+	}
+	// Add an hour, so that the index no longer matches the file.
+	f, err = uamtest.Emissions(uamtest.Options{Hours: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = f.WriteFile(filename); err != nil {
+		t.Fatal(err)
+	}
+	x, err := uam.LoadIndex(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := uam.Open(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if err = r.UseIndex(x); err == nil {
+		t.Error("stale index: got no error")
+	}
+
+	// OpenIndexed replaces the stale index.
+	r, err = uam.OpenIndexed(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if x, err = uam.LoadIndex(filename); err != nil || len(x.Hours) != 3 {
+		t.Errorf("got %v hours in the new index (%v), want 3", len(x.Hours), err)
+	}
+	if _, err = os.Stat(uam.IndexFilename(filename)); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,90 @@
+package uam
+
+import (
+	"fmt"
+	"math"
+)
+
+// InjectionIssue describes a point source whose emissions are injected
+// outside of the vertical extent of the model.
+type InjectionIssue struct {
+	Stack  int     // index of the stack
+	Height float32 // injection height (m)
+	Top    float32 // model top at the stack location (m)
+	// K is the kcell of the override record of the stack, which is
+	// negative if its plume height is overridden, or 0 if it has none.
+	K      int32
+	Reason string
+}
+
+func (i InjectionIssue) String() string {
+	if i.K > 0 {
+		return fmt.Sprintf("stack %d: kcell %d: %v", i.Stack, i.K, i.Reason)
+	}
+	return fmt.Sprintf("stack %d: injection height %g m: %v",
+		i.Stack, i.Height, i.Reason)
+}
+
+// AuditInjectionHeights compares the injection height of each stack in
+// a PTSOURCE file against the model layer structure, and reports stacks
+// that would be injected above the model top, at or below the ground
+// (layer 0), or outside of the horizontal domain. overrides, if not nil,
+// holds the override records of an hour (see Overrides): the plume
+// heights of stacks with height overrides are checked instead of their
+// stack heights, and kcell overrides are checked against the number of
+// layers, reporting layers above the model top and cell overrides with
+// a kcell of 0, which would inject into the ground. If overrides is nil,
+// the stack heights are checked.
+func (f *UAM) AuditInjectionHeights(layers Layers, overrides []StackOverride) ([]InjectionIssue, error) {
+	if f.Name != "PTSOURCE" {
+		return nil, fmt.Errorf("injection heights can only be audited for " +
+			"PTSOURCE files")
+	}
+	if overrides != nil && len(overrides) != int(f.Npts) {
+		return nil, fmt.Errorf("there are %d override records but %d stacks",
+			len(overrides), f.Npts)
+	}
+	var issues []InjectionIssue
+	for ip := 0; ip < int(f.Npts); ip++ {
+		var o StackOverride
+		if overrides != nil {
+			o = overrides[ip]
+		}
+		height := f.StackHeight[ip]
+		if o.HeightOverride() {
+			height = float32(math.Abs(float64(o.PlumeHeight)))
+		}
+		issue := InjectionIssue{Stack: ip, Height: height, K: o.K}
+		i := int32((f.Xcoord[ip] - f.Utmx) / f.Dx)
+		j := int32((f.Ycoord[ip] - f.Utmy) / f.Dy)
+		if f.Xcoord[ip] < f.Utmx || f.Ycoord[ip] < f.Utmy || i >= f.Nx || j >= f.Ny {
+			issue.Reason = "stack is outside of the model domain"
+			issues = append(issues, issue)
+			continue
+		}
+		tops := layers.LayerTops(i, j)
+		if len(tops) == 0 {
+			return nil, fmt.Errorf("no layers for cell (%d, %d)", i, j)
+		}
+		issue.Top = tops[len(tops)-1]
+		switch {
+		case o.K > int32(len(tops)):
+			issue.Reason = fmt.Sprintf("injected above the model top (layer %d of %d)", o.K, len(tops))
+			issues = append(issues, issue)
+			continue
+		case o.K == 0 && (o.I != 0 || o.J != 0):
+			issue.Reason = fmt.Sprintf("cell (%d, %d) is overridden with a kcell of 0, "+
+				"which injects into the ground (layer 0)", o.I, o.J)
+			issues = append(issues, issue)
+			continue
+		}
+		if height <= 0 {
+			issue.Reason = "injected at or below the ground (layer 0)"
+			issues = append(issues, issue)
+		} else if height > issue.Top {
+			issue.Reason = fmt.Sprintf("injected above the model top (%g m)", issue.Top)
+			issues = append(issues, issue)
+		}
+	}
+	return issues, nil
+}
@@ -0,0 +1,55 @@
+package uam_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ctessum/uam"
+	"github.com/ctessum/uam/uamtest"
+)
+
+func TestAuditInjectionHeights(t *testing.T) {
+	f, err := uamtest.PointSource(uamtest.Options{Hours: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	layers := uam.UniformLayers{50, 100, 200}
+
+	issues, err := f.AuditInjectionHeights(layers, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 0 {
+		t.Errorf("stack heights: got %v, want no issues", issues)
+	}
+
+	overrides := []uam.StackOverride{
+		{K: 5},                     // above the top of 3 layers
+		{I: 1, J: 1, K: 0},         // cell override into the ground
+		{K: -1, PlumeHeight: -500}, // plume height above the model top
+	}
+	issues, err = f.AuditInjectionHeights(layers, overrides)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 3 {
+		t.Fatalf("overrides: got %d issues, want 3: %v", len(issues), issues)
+	}
+	for n, want := range []string{"layer 5 of 3", "kcell of 0", "above the model top (200 m)"} {
+		if issues[n].Stack != n || !strings.Contains(issues[n].Reason, want) {
+			t.Errorf("issue %d: got %v, want stack %d: %v", n, issues[n], n, want)
+		}
+	}
+	for n, want := range []int32{5, 0, -1} {
+		if issues[n].K != want {
+			t.Errorf("issue %d: got K %d, want %d", n, issues[n].K, want)
+		}
+	}
+	if issues[2].Height != 500 || strings.Contains(issues[2].String(), "kcell") {
+		t.Errorf("got %v, want an injection height of 500 m", issues[2])
+	}
+
+	if _, err = f.AuditInjectionHeights(layers, overrides[:1]); err == nil {
+		t.Error("no error for the wrong number of override records")
+	}
+}
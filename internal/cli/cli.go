@@ -0,0 +1,182 @@
+// Package cli implements the command-line tools for UAM files. Each
+// command can be run as a subcommand of the uam program or as a
+// standalone program, such as uaminfo, and all commands share the same
+// flags for selecting species, hours, and layers.
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ctessum/uam"
+)
+
+// Command is a command-line tool.
+type Command struct {
+	Name    string // name of the command as a subcommand of uam
+	Args    string // synopsis of the arguments
+	Summary string // one-line description
+
+	// setup defines the flags of the command on fs and returns a
+	// function that runs the command with the remaining arguments.
+	setup func(fs *flag.FlagSet) func(args []string) error
+}
+
+// Commands lists all commands, in the order that they are listed in help.
+var Commands = []*Command{Info, Dump, Diff, Stats, Extract, Convert, Regrid, Merge, Scale, Validate}
+
+// Run runs the command as the program prog with the given arguments,
+// not including the program name, and returns the exit status.
+func (c *Command) Run(prog string, args []string) int {
+	fs := flag.NewFlagSet(prog, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: %v %v\n", prog, c.Args)
+		fs.PrintDefaults()
+	}
+	run := c.setup(fs)
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+	err := run(fs.Args())
+	var status exitStatus
+	var usage usageError
+	switch {
+	case err == nil:
+		return 0
+	case errors.As(err, &status):
+		return int(status)
+	case errors.As(err, &usage):
+		fmt.Fprintln(os.Stderr, prog+":", err)
+		fs.Usage()
+		return 2
+	}
+	fmt.Fprintln(os.Stderr, prog+":", err)
+	return 1
+}
+
+// usageError is an error in the command-line arguments.
+type usageError string
+
+func (e usageError) Error() string { return string(e) }
+
+// exitStatus is returned by commands that have already reported a
+// failure and only need to exit with the given status.
+type exitStatus int
+
+func (e exitStatus) Error() string { return fmt.Sprintf("exit status %d", int(e)) }
+
+// selection holds the flags that select which data a command uses.
+type selection struct {
+	species, hours, layers string
+}
+
+// register defines the -species flag on fs and, if all is set, the
+// -hours and -layers flags.
+func (s *selection) register(fs *flag.FlagSet, all bool) {
+	fs.StringVar(&s.species, "species", "", "comma-separated species to use; default all")
+	if all {
+		fs.StringVar(&s.hours, "hours", "", "hours to use, such as 0-5,12; default all")
+		fs.StringVar(&s.layers, "layers", "", "layers to use, such as 0,1; default all")
+	}
+}
+
+// filter returns the selection as a filter, with nil fields for flags
+// that were not set.
+func (s *selection) filter() (uam.TidyFilter, error) {
+	var filter uam.TidyFilter
+	if s.species != "" {
+		filter.Species = strings.Split(s.species, ",")
+	}
+	var err error
+	if filter.Hours, err = parseList(s.hours); err != nil {
+		return filter, usageError("-hours: " + err.Error())
+	}
+	layers, err := parseList(s.layers)
+	if err != nil {
+		return filter, usageError("-layers: " + err.Error())
+	}
+	for _, k := range layers {
+		filter.Layers = append(filter.Layers, int32(k))
+	}
+	return filter, nil
+}
+
+// parseList parses a comma-separated list of integers and ranges, such
+// as "0-5,12". An empty string gives a nil list.
+func parseList(s string) ([]int, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var list []int
+	for _, item := range strings.Split(s, ",") {
+		lo, hi, isRange := strings.Cut(item, "-")
+		a, err := strconv.Atoi(lo)
+		if err != nil {
+			return nil, err
+		}
+		b := a
+		if isRange {
+			if b, err = strconv.Atoi(hi); err != nil {
+				return nil, err
+			}
+		}
+		if b < a {
+			return nil, fmt.Errorf("invalid range %v", item)
+		}
+		for i := a; i <= b; i++ {
+			list = append(list, i)
+		}
+	}
+	return list, nil
+}
+
+// eachHour reads the remaining hours of f that are selected by filter,
+// calling fn with the index and data of each. Only the species in
+// filter are decoded.
+func eachHour(f *uam.UAM, filter uam.TidyFilter, fn func(hour int, data map[string][]float32) error) error {
+	if filter.Species != nil {
+		if err := f.SelectSpecies(filter.Species); err != nil {
+			return err
+		}
+	}
+	last := -1
+	for _, h := range filter.Hours {
+		last = max(last, h)
+	}
+	data := make(map[string][]float32)
+	for hour := 0; filter.Hours == nil || hour <= last; hour++ {
+		_, _, _, _, _, _, err := f.ReadHour(data)
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if filter.Hours != nil && !slices.Contains(filter.Hours, hour) {
+			continue
+		}
+		if err = fn(hour, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// selectedSpecies returns the species of f that are in filter.
+func selectedSpecies(f *uam.UAM, filter uam.TidyFilter) []string {
+	var species []string
+	for _, spname := range f.Spnames {
+		if filter.Species == nil || slices.Contains(filter.Species, spname) {
+			species = append(species, spname)
+		}
+	}
+	return species
+}
@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ctessum/uam"
+)
+
+// Convert converts UAM files to NetCDF. Each input file is written to a
+// file with the same name and the extension .nc, either next to the
+// input or in the directory given by -o.
+var Convert = &Command{
+	Name:    "convert",
+	Args:    "[flags] file...",
+	Summary: "convert files to NetCDF",
+	setup: func(fs *flag.FlagSet) func(args []string) error {
+		outDir := fs.String("o", "", "directory to write output files to; default next to each input")
+		var sel selection
+		sel.register(fs, false)
+		return func(args []string) error {
+			if len(args) == 0 {
+				return usageError("no files")
+			}
+			filter, err := sel.filter()
+			if err != nil {
+				return err
+			}
+			failed := false
+			for _, filename := range args {
+				out := strings.TrimSuffix(filename, filepath.Ext(filename)) + ".nc"
+				if *outDir != "" {
+					out = filepath.Join(*outDir, filepath.Base(out))
+				}
+				if err := convert(filename, out, filter.Species); err != nil {
+					fmt.Fprintln(os.Stderr, fs.Name()+":", err)
+					failed = true
+					continue
+				}
+				fmt.Println(filename, "->", out)
+			}
+			if failed {
+				return exitStatus(1)
+			}
+			return nil
+		}
+	},
+}
+
+// convert writes the named species (or all species, if names is nil)
+// in the UAM file filename to the NetCDF file out. If the conversion
+// fails, out is removed.
+func convert(filename, out string, names []string) (err error) {
+	f, err := uam.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if names != nil {
+		if err = f.SelectSpecies(names); err != nil {
+			return fmt.Errorf("%v: %w", filename, err)
+		}
+	}
+	w, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := w.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			os.Remove(out)
+		}
+	}()
+	if err = f.WriteNetCDF(w); err != nil {
+		return fmt.Errorf("%v: %w", filename, err)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/ctessum/uam"
+)
+
+// Diff compares two UAM files hour by hour and reports, for each
+// species and hour, the largest absolute and relative differences and
+// the number of values that differ by more than the tolerances, along
+// with the cell or stack with the largest absolute difference. Values
+// a and b differ if |a-b| > abs + rel*|b|. The exit status is 1 if the
+// files differ.
+var Diff = &Command{
+	Name:    "diff",
+	Args:    "[flags] a b",
+	Summary: "compare two files",
+	setup: func(fs *flag.FlagSet) func(args []string) error {
+		abs := fs.Float64("abs", 0, "absolute tolerance")
+		rel := fs.Float64("rel", 0, "relative tolerance")
+		all := fs.Bool("all", false, "report all species and hours, not only those that differ")
+		var sel selection
+		sel.register(fs, true)
+		return func(args []string) error {
+			if len(args) != 2 {
+				return usageError("need exactly two files")
+			}
+			filter, err := sel.filter()
+			if err != nil {
+				return err
+			}
+			opts := uam.CompareOptions{Abs: *abs, Rel: *rel, Filter: filter}
+			same, err := diff(os.Stdout, args[0], args[1], opts, *all)
+			if err != nil {
+				return err
+			}
+			if !same {
+				return exitStatus(1)
+			}
+			return nil
+		}
+	},
+}
+
+// location describes the location of a value in f, or "-" if there is
+// no difference.
+func location(f *uam.UAM, loc uam.Location, diff float64) string {
+	switch {
+	case diff == 0:
+		return "-"
+	case f.Name == "PTSOURCE":
+		return fmt.Sprintf("stack %d", loc.I)
+	}
+	return fmt.Sprintf("k=%d j=%d i=%d", loc.K, loc.J, loc.I)
+}
+
+// diff writes a report of the differences between files a and b to w
+// and returns whether they are the same.
+func diff(w io.Writer, a, b string, opts uam.CompareOptions, all bool) (bool, error) {
+	fa, err := uam.Open(a)
+	if err != nil {
+		return false, err
+	}
+	defer fa.Close()
+	fb, err := uam.Open(b)
+	if err != nil {
+		return false, err
+	}
+	defer fb.Close()
+	c, err := uam.Compare(fa, fb, opts)
+	if err != nil {
+		return false, fmt.Errorf("comparing %v with %v: %w", a, b, err)
+	}
+	for _, h := range c.Header {
+		fmt.Fprintf(w, "header %v: %v != %v\n", h.Field, h.A, h.B)
+	}
+	for _, spname := range c.OnlyA {
+		fmt.Fprintf(w, "species %v: only in %v\n", spname, a)
+	}
+	for _, spname := range c.OnlyB {
+		fmt.Fprintf(w, "species %v: only in %v\n", spname, b)
+	}
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	printed := false
+	for _, d := range c.Hourly {
+		if d.Differing == 0 && !all {
+			continue
+		}
+		if !printed {
+			fmt.Fprintln(tw, "SPECIES\tHOUR\tMAX ABS\tAT\tMAX REL\tDIFFERING\tVALUES")
+			printed = true
+		}
+		fmt.Fprintf(tw, "%v\t%d\t%v\t%v\t%v\t%d\t%d\n", d.Species, d.Hour,
+			format(d.MaxAbs), location(fa, d.At, d.MaxAbs), format(d.MaxRel),
+			d.Differing, d.Values)
+	}
+	return c.Equal(), tw.Flush()
+}
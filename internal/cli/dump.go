@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"bufio"
+	"encoding/csv"
+	"flag"
+	"os"
+	"strconv"
+
+	"github.com/ctessum/uam"
+)
+
+// Dump writes the data in a UAM file to standard output in long-format
+// CSV, with one row per value, for inspection in spreadsheets and data
+// analysis tools.
+var Dump = &Command{
+	Name:    "dump",
+	Args:    "[flags] file",
+	Summary: "write values as long-format CSV",
+	setup: func(fs *flag.FlagSet) func(args []string) error {
+		var sel selection
+		sel.register(fs, true)
+		skipZero := fs.Bool("skip-zero", false, "leave out values that are zero")
+		return func(args []string) error {
+			if len(args) != 1 {
+				return usageError("need exactly one file")
+			}
+			filter, err := sel.filter()
+			if err != nil {
+				return err
+			}
+			filter.SkipZero = *skipZero
+			return dump(args[0], filter)
+		}
+	},
+}
+
+// dump writes the values in the named file that match filter to
+// standard output.
+func dump(filename string, filter uam.TidyFilter) error {
+	f, err := uam.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	out := bufio.NewWriter(os.Stdout)
+	w := csv.NewWriter(out)
+	w.Write([]string{"hour", "layer", "j", "i", "species", "value"})
+	it := f.Tidy(filter)
+	for it.Next() {
+		r := it.Record()
+		w.Write([]string{
+			strconv.Itoa(r.Hour),
+			strconv.Itoa(int(r.Layer)),
+			strconv.Itoa(int(r.J)),
+			strconv.Itoa(int(r.I)),
+			r.Species,
+			strconv.FormatFloat(float64(r.Value), 'g', -1, 32),
+		})
+	}
+	if err = it.Err(); err != nil {
+		return err
+	}
+	w.Flush()
+	if err = w.Error(); err != nil {
+		return err
+	}
+	return out.Flush()
+}
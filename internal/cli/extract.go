@@ -0,0 +1,186 @@
+package cli
+
+import (
+	"bufio"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ctessum/uam"
+)
+
+// Extract writes point data from a gridded UAM file to standard output
+// as CSV. With -at or -lonlat, it writes a time series at one grid cell
+// in the layers selected with -layers, which is all layers (a vertical
+// profile in each hour) by default; only that cell is decoded. With
+// -layer, it writes one layer of each selected species and hour as a
+// grid, with one row per grid row from south to north.
+var Extract = &Command{
+	Name:    "extract",
+	Args:    "(-at i,j | -lonlat lon,lat | -layer k) [flags] file",
+	Summary: "extract a time series, profile, or layer as CSV",
+	setup: func(fs *flag.FlagSet) func(args []string) error {
+		at := fs.String("at", "", "column and row of the cell, counting from 0 at the SW corner")
+		lonlat := fs.String("lonlat", "", "longitude and latitude of the cell")
+		layer := fs.Int("layer", -1, "layer to write as a grid")
+		var sel selection
+		sel.register(fs, true)
+		return func(args []string) error {
+			if len(args) != 1 {
+				return usageError("need exactly one file")
+			}
+			n := 0
+			for _, set := range []bool{*at != "", *lonlat != "", *layer >= 0} {
+				if set {
+					n++
+				}
+			}
+			if n != 1 {
+				return usageError("need exactly one of -at, -lonlat, and -layer")
+			}
+			filter, err := sel.filter()
+			if err != nil {
+				return err
+			}
+			if *layer >= 0 {
+				return extractLayer(args[0], int32(*layer), filter)
+			}
+			var i, j int32
+			var lon, lat float64
+			if *at != "" {
+				i, j, err = parseCell(*at)
+				if err != nil {
+					return usageError("-at: " + err.Error())
+				}
+			} else if lon, lat, err = parseLonLat(*lonlat); err != nil {
+				return usageError("-lonlat: " + err.Error())
+			}
+			return extract(args[0], i, j, *lonlat != "", lon, lat, filter)
+		}
+	},
+}
+
+// parseCell parses a cell location of the form "i,j".
+func parseCell(s string) (i, j int32, err error) {
+	si, sj, ok := strings.Cut(s, ",")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid cell %q; want i,j", s)
+	}
+	vi, err := strconv.ParseInt(si, 10, 32)
+	if err != nil {
+		return 0, 0, err
+	}
+	vj, err := strconv.ParseInt(sj, 10, 32)
+	if err != nil {
+		return 0, 0, err
+	}
+	return int32(vi), int32(vj), nil
+}
+
+// parseLonLat parses a location of the form "lon,lat".
+func parseLonLat(s string) (lon, lat float64, err error) {
+	slon, slat, ok := strings.Cut(s, ",")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid location %q; want lon,lat", s)
+	}
+	if lon, err = strconv.ParseFloat(slon, 64); err != nil {
+		return 0, 0, err
+	}
+	lat, err = strconv.ParseFloat(slat, 64)
+	return lon, lat, err
+}
+
+// extract writes the values in cell (i, j), or the cell containing
+// (lon, lat) if useLonLat is set, of the named file that match filter to
+// standard output.
+func extract(filename string, i, j int32, useLonLat bool, lon, lat float64, filter uam.TidyFilter) error {
+	f, err := uam.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if f.Name == "PTSOURCE" {
+		return fmt.Errorf("%v: cannot extract a cell from a PTSOURCE file", filename)
+	}
+	if useLonLat {
+		if i, j, err = f.CellAt(lon, lat); err != nil {
+			return err
+		}
+	}
+	if err = f.SetWindow(i, i+1, j, j+1); err != nil {
+		return err
+	}
+	out := bufio.NewWriter(os.Stdout)
+	w := csv.NewWriter(out)
+	w.Write([]string{"hour", "date", "time", "species", "layer", "value"})
+	it := f.Tidy(filter)
+	for it.Next() {
+		r := it.Record()
+		w.Write([]string{
+			strconv.Itoa(r.Hour),
+			strconv.Itoa(int(r.Date)),
+			strconv.FormatFloat(float64(r.Time), 'g', -1, 32),
+			r.Species,
+			strconv.Itoa(int(r.Layer)),
+			strconv.FormatFloat(float64(r.Value), 'g', -1, 32),
+		})
+	}
+	if err = it.Err(); err != nil {
+		return err
+	}
+	w.Flush()
+	if err = w.Error(); err != nil {
+		return err
+	}
+	return out.Flush()
+}
+
+// extractLayer writes layer k of the species and hours of the named file
+// that match filter to standard output as grids.
+func extractLayer(filename string, k int32, filter uam.TidyFilter) error {
+	f, err := uam.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if f.Name == "PTSOURCE" {
+		return fmt.Errorf("%v: cannot extract a layer from a PTSOURCE file", filename)
+	}
+	if k >= f.Nz {
+		return fmt.Errorf("%v: layer %d is out of range; the file has %d layers",
+			filename, k, f.Nz)
+	}
+	species := selectedSpecies(f, filter)
+	out := bufio.NewWriter(os.Stdout)
+	w := csv.NewWriter(out)
+	row := []string{"hour", "species", "j"}
+	for i := 0; i < int(f.Nx); i++ {
+		row = append(row, strconv.Itoa(i))
+	}
+	w.Write(row)
+	nxy := f.Nx * f.Ny
+	err = eachHour(f, filter, func(hour int, data map[string][]float32) error {
+		for _, spname := range species {
+			vals := data[spname][k*nxy : (k+1)*nxy]
+			for j := int32(0); j < f.Ny; j++ {
+				row = append(row[:0], strconv.Itoa(hour), spname, strconv.Itoa(int(j)))
+				for _, v := range vals[j*f.Nx : (j+1)*f.Nx] {
+					row = append(row, strconv.FormatFloat(float64(v), 'g', -1, 32))
+				}
+				w.Write(row)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	w.Flush()
+	if err = w.Error(); err != nil {
+		return err
+	}
+	return out.Flush()
+}
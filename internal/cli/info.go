@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/ctessum/uam"
+)
+
+// Info prints a summary of UAM files: the file type, grid definition,
+// projection, species, time span, and, optionally, the total of each
+// species over the whole file.
+var Info = &Command{
+	Name:    "info",
+	Args:    "[flags] file...",
+	Summary: "summarize the contents of files",
+	setup: func(fs *flag.FlagSet) func(args []string) error {
+		totals := fs.Bool("totals", true, "calculate the total of each species (reads the whole file)")
+		return func(args []string) error {
+			if len(args) == 0 {
+				return usageError("no files")
+			}
+			for i, filename := range args {
+				if i > 0 {
+					fmt.Println()
+				}
+				if err := info(os.Stdout, filename, *totals); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	},
+}
+
+// info prints a summary of the named file to w.
+func info(w io.Writer, filename string, totals bool) error {
+	f, err := uam.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	h := f.Header()
+	fmt.Fprintf(w, "file %v {\n", filename)
+	fmt.Fprintf(w, "type: %v\n", h.Name)
+	fmt.Fprintf(w, "note: %v\n", h.Note)
+	fmt.Fprintf(w, "start: %d %05.2f\n", h.Sdate, h.Begtim)
+	fmt.Fprintf(w, "end: %d %05.2f\n", h.Edate, h.Endtim)
+	if n := f.CompleteHours(); n >= 0 {
+		fmt.Fprintf(w, "hours: %d\n", n)
+	}
+	fmt.Fprintf(w, "grid: %d x %d x %d cells of %g x %g, SW corner (%g, %g)\n",
+		h.Nx, h.Ny, h.Nz, h.Dx, h.Dy, h.Utmx, h.Utmy)
+	fmt.Fprintf(w, "projection: %v\n", f.Projection())
+	if h.Name == "PTSOURCE" {
+		fmt.Fprintf(w, "stacks: %d\n", f.Npts)
+	}
+	fmt.Fprintf(w, "species (%d):\n", len(f.Spnames))
+	var sums map[string]float64
+	if totals {
+		if sums, err = speciesTotals(f); err != nil {
+			return err
+		}
+	}
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	for _, spname := range f.Spnames {
+		if totals {
+			fmt.Fprintf(tw, "\t%v\t%g\n", spname, sums[spname])
+		} else {
+			fmt.Fprintf(tw, "\t%v\n", spname)
+		}
+	}
+	tw.Flush()
+	fmt.Fprintln(w, "}")
+	return nil
+}
+
+// speciesTotals returns the sum of each species over all hours and
+// cells of f.
+func speciesTotals(f *uam.UAM) (map[string]float64, error) {
+	sums := make(map[string]float64)
+	err := eachHour(f, uam.TidyFilter{}, func(_ int, data map[string][]float32) error {
+		for spname, vals := range data {
+			for _, v := range vals {
+				sums[spname] += float64(v)
+			}
+		}
+		return nil
+	})
+	return sums, err
+}
@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"flag"
+
+	"github.com/ctessum/uam"
+)
+
+// Merge sums gridded EMISSIONS files, such as the emissions from
+// different sectors, cell by cell into a single file. The files must
+// have the same grid, species, and time span. PTSOURCE files are
+// combined into a single file holding the stacks of every file instead.
+// With -union, EMISSIONS files may have different species.
+var Merge = &Command{
+	Name:    "merge",
+	Args:    "[-union] -o output file...",
+	Summary: "sum emissions files or combine point source files",
+	setup: func(fs *flag.FlagSet) func(args []string) error {
+		out := fs.String("o", "", "output file")
+		union := fs.Bool("union", false, "allow emissions files with different species")
+		return func(args []string) error {
+			if *out == "" {
+				return usageError("no output file")
+			}
+			if len(args) == 0 {
+				return usageError("no files")
+			}
+			f, err := uam.Open(args[0])
+			if err != nil {
+				return err
+			}
+			f.Close()
+			if f.Name == "PTSOURCE" {
+				return uam.MergePointSources(*out, args)
+			}
+			if *union {
+				sum, err := uam.SumEmissions(args, true)
+				if err != nil {
+					return err
+				}
+				return sum.WriteFile(*out)
+			}
+			return uam.MergeEmissions(*out, args)
+		}
+	},
+}
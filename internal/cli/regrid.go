@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/ctessum/uam"
+)
+
+// Regrid resamples a gridded UAM file onto the grid described in a YAML
+// grid definition file (see uam.GridConfig), which must have the same
+// number of layers and may use a different projection. Emissions are
+// conserved and other values are area-weighted averages.
+var Regrid = &Command{
+	Name:    "regrid",
+	Args:    "-grid grid.yaml -o output file",
+	Summary: "resample a file onto another grid",
+	setup: func(fs *flag.FlagSet) func(args []string) error {
+		grid := fs.String("grid", "", "YAML file describing the destination grid")
+		out := fs.String("o", "", "output file")
+		return func(args []string) error {
+			if len(args) != 1 {
+				return usageError("need exactly one file")
+			}
+			if *grid == "" || *out == "" {
+				return usageError("-grid and -o are required")
+			}
+			g, err := uam.LoadGridConfig(*grid)
+			if err != nil {
+				return err
+			}
+			return regrid(args[0], *out, g)
+		}
+	},
+}
+
+// regrid writes the data in the named file, resampled onto grid g, to
+// out.
+func regrid(filename, out string, g uam.GridConfig) error {
+	f, err := uam.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if f.Name == "PTSOURCE" {
+		return fmt.Errorf("%v: cannot regrid a PTSOURCE file", filename)
+	}
+	d, err := g.GridDef()
+	if err != nil {
+		return err
+	}
+	h := f.Header()
+	d.Apply(&h)
+	r, err := uam.NewRegridder(f.Header(), h)
+	if err != nil {
+		return err
+	}
+	dst, err := uam.NewGridded(h, f.Spnames)
+	if err != nil {
+		return err
+	}
+	w, err := uam.Create(out, dst)
+	if err != nil {
+		return err
+	}
+	data := make(map[string][]float32)
+	for {
+		_, _, _, _, _, _, err = f.ReadHour(data)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			w.Close()
+			return err
+		}
+		res, err := r.Regrid(data)
+		if err != nil {
+			w.Close()
+			return err
+		}
+		if err = w.WriteHour(res); err != nil {
+			w.Close()
+			return err
+		}
+	}
+	return w.Close()
+}
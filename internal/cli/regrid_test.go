@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+
+	"github.com/ctessum/uam"
+	"github.com/ctessum/uam/uamtest"
+)
+
+func TestRegridProjection(t *testing.T) {
+	src := uam.GridDef{
+		Xorig: 400000, Yorig: 4400000, Dx: 4000, Dy: 4000, Nx: 4, Ny: 3, Nz: 2,
+		Iproj: int32(uam.UTM), Iutm: 15,
+	}
+	f, err := uamtest.Emissions(uamtest.Options{Grid: src, Hours: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := uamtest.TempFile(t, f)
+
+	// Cover the source grid with 2 km Lambert conformal cells, with a
+	// margin of a cell on each side.
+	iproj := int32(uam.LambertConformal)
+	g := uam.GridConfig{Nz: src.Nz, Dx: 2000, Dy: 2000, Iproj: &iproj,
+		Orgx: -97, Orgy: 40, Tlat1: 33, Tlat2: 45}
+	lcc := uam.GridDef{Iproj: iproj, Orgx: g.Orgx, Orgy: g.Orgy, Tlat1: g.Tlat1, Tlat2: g.Tlat2}
+	x0, y0, x1, y1 := math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)
+	for _, c := range [][2]float32{{0, 0}, {1, 0}, {0, 1}, {1, 1}} {
+		lon, lat, err := src.Projection().Inverse(float64(src.Xorig+c[0]*float32(src.Nx)*src.Dx),
+			float64(src.Yorig+c[1]*float32(src.Ny)*src.Dy))
+		if err != nil {
+			t.Fatal(err)
+		}
+		x, y, err := lcc.Projection().Forward(lon, lat)
+		if err != nil {
+			t.Fatal(err)
+		}
+		x0, y0, x1, y1 = min(x0, x), min(y0, y), max(x1, x), max(y1, y)
+	}
+	g.Xorig = float32(math.Floor(x0/2000)*2000 - 2000)
+	g.Yorig = float32(math.Floor(y0/2000)*2000 - 2000)
+	g.Nx = int32(math.Ceil((x1-float64(g.Xorig))/2000)) + 1
+	g.Ny = int32(math.Ceil((y1-float64(g.Yorig))/2000)) + 1
+	d, err := g.GridDef()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := filepath.Join(t.TempDir(), "out.uam")
+	if err = regrid(in, out, g); err != nil {
+		t.Fatal(err)
+	}
+	r, err := uam.Open(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if got := r.GridDef(); got != d {
+		t.Errorf("got grid %+v, want %+v", got, d)
+	}
+	for h := 0; ; h++ {
+		hr, err := r.ReadNextHour()
+		if err != nil {
+			if h != 2 {
+				t.Errorf("got %d hours (%v), want 2", h, err)
+			}
+			break
+		}
+		for _, spname := range f.Spnames {
+			var want, got float64
+			for _, v := range f.Hours[h].Data[spname] {
+				want += float64(v)
+			}
+			for _, v := range hr.Data[spname] {
+				got += float64(v)
+			}
+			if math.Abs(got-want) > 1e-4*want {
+				t.Errorf("hour %d %v: total is %g, want %g", h, spname, got, want)
+			}
+		}
+	}
+}
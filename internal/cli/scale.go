@@ -0,0 +1,145 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ctessum/uam"
+)
+
+// Scale multiplies species in a UAM file by constant factors and,
+// optionally, by a per-cell factor grid, and writes the result to a new
+// file. This is the usual way to build emission sensitivity scenarios.
+// -factor applies to the species selected with -species (all species
+// by default), -factors sets the factors of individual species instead,
+// and -grid multiplies the selected species in each cell by the first layer
+// of a species in a gridded UAM file on the same grid.
+var Scale = &Command{
+	Name:    "scale",
+	Args:    "-o output [flags] file",
+	Summary: "multiply species by scaling factors",
+	setup: func(fs *flag.FlagSet) func(args []string) error {
+		out := fs.String("o", "", "output file")
+		factor := fs.Float64("factor", 1, "factor for the selected species")
+		factors := fs.String("factors", "", "factors for individual species, such as NO=0.7,NO2=0.7")
+		grid := fs.String("grid", "", "gridded UAM file holding per-cell factors")
+		gridSpecies := fs.String("grid-species", "", "species in the -grid file holding the factors")
+		var sel selection
+		sel.register(fs, false)
+		return func(args []string) error {
+			if len(args) != 1 {
+				return usageError("need exactly one file")
+			}
+			if *out == "" {
+				return usageError("no output file")
+			}
+			if (*grid == "") != (*gridSpecies == "") {
+				return usageError("-grid and -grid-species must be used together")
+			}
+			filter, err := sel.filter()
+			if err != nil {
+				return err
+			}
+			perSpecies, err := parseFactors(*factors)
+			if err != nil {
+				return usageError("-factors: " + err.Error())
+			}
+			var mask *uam.Mask
+			if *grid != "" {
+				if mask, err = uam.ReadMask(*grid, *gridSpecies); err != nil {
+					return err
+				}
+			}
+			return scale(args[0], *out, filter.Species, float32(*factor), perSpecies, mask)
+		}
+	},
+}
+
+// parseFactors parses a comma-separated list of species and factors,
+// such as "NO=0.7,NO2=0.7".
+func parseFactors(s string) (map[string]float32, error) {
+	factors := make(map[string]float32)
+	if s == "" {
+		return factors, nil
+	}
+	for _, item := range strings.Split(s, ",") {
+		spname, v, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid factor %q; want species=factor", item)
+		}
+		x, err := strconv.ParseFloat(v, 32)
+		if err != nil {
+			return nil, err
+		}
+		factors[spname] = float32(x)
+	}
+	return factors, nil
+}
+
+// scale writes the named file to out with the species in species (or
+// all species, if it is nil) multiplied by factor and mask, except that
+// the species in perSpecies are multiplied by their own factors instead
+// of factor.
+func scale(filename, out string, species []string, factor float32, perSpecies map[string]float32, mask *uam.Mask) error {
+	f, err := uam.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if species == nil {
+		species = f.Spnames
+	}
+	factors := make(map[string]float32)
+	for _, spname := range species {
+		factors[spname] = factor
+	}
+	for spname, x := range perSpecies {
+		factors[spname] = x
+	}
+	for spname := range factors {
+		if !slices.Contains(f.Spnames, spname) {
+			return fmt.Errorf("%v: species %v is not in the file", filename, spname)
+		}
+	}
+	if mask != nil && (f.Name == "PTSOURCE" || mask.Nx != f.Nx || mask.Ny != f.Ny) {
+		return fmt.Errorf("factor grid is %dx%d but %v is not a gridded file with "+
+			"the same grid", mask.Nx, mask.Ny, filename)
+	}
+	w, err := uam.Create(out, uam.NewLike(f, 0))
+	if err != nil {
+		return err
+	}
+	data := make(map[string][]float32)
+	for {
+		_, _, _, _, _, _, err = f.ReadHour(data)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			w.Close()
+			return err
+		}
+		for spname, x := range factors {
+			if x == 1 {
+				continue
+			}
+			for i := range data[spname] {
+				data[spname][i] *= x
+			}
+		}
+		if mask != nil {
+			if err = mask.Apply(data, species); err != nil {
+				w.Close()
+				return err
+			}
+		}
+		if err = w.WriteHour(data); err != nil {
+			w.Close()
+			return err
+		}
+	}
+	return w.Close()
+}
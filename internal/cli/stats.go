@@ -0,0 +1,147 @@
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ctessum/uam"
+)
+
+// Stats prints summary statistics of each species in each hour of a
+// UAM file: the minimum, maximum, mean, and sum of the finite values and
+// the numbers of NaN and negative values. With -json, the statistics
+// are written as one JSON object per line, with null in place of
+// values that are not finite.
+var Stats = &Command{
+	Name:    "stats",
+	Args:    "[flags] file",
+	Summary: "print statistics for each species and hour",
+	setup: func(fs *flag.FlagSet) func(args []string) error {
+		asCSV := fs.Bool("csv", false, "write statistics as CSV")
+		asJSON := fs.Bool("json", false, "write statistics as JSON lines")
+		var sel selection
+		sel.register(fs, true)
+		return func(args []string) error {
+			if len(args) != 1 {
+				return usageError("need exactly one file")
+			}
+			filter, err := sel.filter()
+			if err != nil {
+				return err
+			}
+			output := "table"
+			switch {
+			case *asCSV && *asJSON:
+				return usageError("-csv and -json cannot be used together")
+			case *asCSV:
+				output = "csv"
+			case *asJSON:
+				output = "json"
+			}
+			return stats(os.Stdout, args[0], filter, output)
+		}
+	},
+}
+
+// stats writes statistics of the named file to w in the given output
+// format: "table", "csv", or "json".
+func stats(w io.Writer, filename string, filter uam.TidyFilter, output string) error {
+	f, err := uam.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	species := selectedSpecies(f, filter)
+	header := []string{"hour", "species", "min", "max", "mean", "sum", "nan", "negative"}
+	var write func(hour int, spname string, s uam.Summary)
+	var flush func() error
+	switch output {
+	case "json":
+		enc := json.NewEncoder(w)
+		write = func(hour int, spname string, s uam.Summary) {
+			enc.Encode(statsJSON{hour, spname, finite(s.Min), finite(s.Max),
+				finite(s.Mean), finite(s.Sum), s.NaN, s.Negative})
+		}
+		flush = func() error { return nil }
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Write(header)
+		write = func(hour int, spname string, s uam.Summary) { cw.Write(statsRow(hour, spname, s)) }
+		flush = func() error {
+			cw.Flush()
+			return cw.Error()
+		}
+	default:
+		tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+		fmt.Fprintln(tw, strings.Join(header, "\t")+"\t")
+		write = func(hour int, spname string, s uam.Summary) {
+			fmt.Fprintln(tw, strings.Join(statsRow(hour, spname, s), "\t")+"\t")
+		}
+		flush = tw.Flush
+	}
+	nxy := f.Nx * f.Ny
+	var vals []float32
+	err = eachHour(f, filter, func(hour int, data map[string][]float32) error {
+		for _, spname := range species {
+			vals = data[spname]
+			if f.Name != "PTSOURCE" && filter.Layers != nil {
+				vals = nil
+				for k := int32(0); k < f.Nz; k++ {
+					if slices.Contains(filter.Layers, k) {
+						vals = append(vals, data[spname][k*nxy:(k+1)*nxy]...)
+					}
+				}
+			}
+			write(hour, spname, uam.Summarize(vals))
+		}
+		return nil
+	})
+	if ferr := flush(); err == nil {
+		err = ferr
+	}
+	return err
+}
+
+// statsRow returns the statistics of a species in one hour as a row of
+// text.
+func statsRow(hour int, spname string, s uam.Summary) []string {
+	return []string{strconv.Itoa(hour), spname, format(s.Min), format(s.Max),
+		format(s.Mean), format(s.Sum), strconv.Itoa(s.NaN), strconv.Itoa(s.Negative)}
+}
+
+// statsJSON is the JSON form of the statistics of a species in one
+// hour.
+type statsJSON struct {
+	Hour     int         `json:"hour"`
+	Species  string      `json:"species"`
+	Min      interface{} `json:"min"`
+	Max      interface{} `json:"max"`
+	Mean     interface{} `json:"mean"`
+	Sum      interface{} `json:"sum"`
+	NaN      int         `json:"nan"`
+	Negative int         `json:"negative"`
+}
+
+// finite returns v as a float32, or nil if it is not finite, so that it
+// can be encoded as JSON.
+func finite(v float64) interface{} {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return nil
+	}
+	return float32(v)
+}
+
+// format formats v with the shortest representation that round-trips
+// as a float32.
+func format(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 32)
+}
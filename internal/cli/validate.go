@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ctessum/uam"
+)
+
+// Validate checks the health of UAM files, such as an archive of model
+// inputs and outputs, and reports a score and a pass or fail result for
+// each file. Directories are searched recursively for files matching a
+// pattern. The exit status is 1 if any file fails.
+var Validate = &Command{
+	Name:    "validate",
+	Args:    "[flags] path...",
+	Summary: "check the health of files",
+	setup: func(flags *flag.FlagSet) func(args []string) error {
+		pattern := flags.String("pattern", "*", "names of files to check in directories")
+		mechanism := flags.String("mechanism", "", "check that species belong to this mechanism")
+		allowNegative := flags.Bool("allow-negative", false, "do not check for negative values")
+		asJSON := flags.Bool("json", false, "write reports as JSON")
+		return func(args []string) error {
+			if len(args) == 0 {
+				return usageError("no paths")
+			}
+			if _, err := filepath.Match(*pattern, ""); err != nil {
+				return usageError("-pattern: " + err.Error())
+			}
+			files, err := findFiles(args, *pattern)
+			if err != nil {
+				return err
+			}
+			opts := uam.AuditOptions{Mechanism: *mechanism, AllowNegative: *allowNegative}
+			reports := make([]*uam.AuditReport, len(files))
+			failed := false
+			for i, filename := range files {
+				reports[i] = uam.AuditFile(filename, opts)
+				failed = failed || !reports[i].Passed
+			}
+			if *asJSON {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				err = enc.Encode(reports)
+			} else {
+				err = printReports(reports)
+			}
+			if err != nil {
+				return err
+			}
+			if failed {
+				return exitStatus(1)
+			}
+			return nil
+		}
+	},
+}
+
+// findFiles returns the files among paths and, for directories, the
+// files within them whose names match pattern.
+func findFiles(paths []string, pattern string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+			if p != path {
+				if ok, _ := filepath.Match(pattern, d.Name()); !ok {
+					return nil
+				}
+			}
+			files = append(files, p)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// printReports writes a table of results to standard output.
+func printReports(reports []*uam.AuditReport) error {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "FILE\tRESULT\tSCORE\tHOURS\tPROBLEMS")
+	for _, r := range reports {
+		result := "PASS"
+		if !r.Passed {
+			result = "FAIL"
+		}
+		var problems []string
+		for _, c := range r.Checks {
+			if !c.Passed {
+				problems = append(problems, c.Name+": "+c.Message)
+			}
+		}
+		fmt.Fprintf(tw, "%v\t%v\t%.0f\t%d\t%v\n", r.File, result, r.Score,
+			r.Hours, strings.Join(problems, "; "))
+	}
+	return tw.Flush()
+}
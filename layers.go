@@ -0,0 +1,138 @@
+package uam
+
+import (
+	"fmt"
+	"io"
+)
+
+// RemapLayers remaps vals, which holds the values of one species in one
+// hour of gridded file f in the format returned by ReadHour, from the
+// layer structure src, which must match the layers of f, to the layer
+// structure dst, which must have the same number of layers in every
+// column. It returns the values on the new layers in the same order.
+// Each new layer is weighted by the heights that it shares with each of
+// the original layers. Emissions are amounts per cell, so they are
+// divided among the new layers in proportion to the thickness of each
+// original layer that falls in each, conserving the column total;
+// amounts above the top of the new layers are added to the top layer.
+// Other values, such as concentrations, are averaged over the part of
+// each new layer that overlaps the original layers, and are the values
+// of the top original layer where there is no overlap. For example,
+// emissions prepared for 26 layers can be collapsed onto 14 layers whose
+// tops are a subset of the original ones. The layer structure of an
+// hour can be taken from a height/pressure file with ZP.Layers.
+func (f *UAM) RemapLayers(vals []float32, src, dst Layers) ([]float32, error) {
+	if f.Name == "PTSOURCE" {
+		return nil, fmt.Errorf("layers can only be remapped for gridded files")
+	}
+	_, _, nx, ny := f.WindowGrid()
+	n := nx * ny
+	if len(vals) != int(n*f.Nz) {
+		return nil, fmt.Errorf("field has %d values; it should have %d",
+			len(vals), n*f.Nz)
+	}
+	var i1, j1 int32
+	if f.window != nil {
+		i1, j1 = f.window.i1, f.window.j1
+	}
+	extensive := f.Name == "EMISSIONS"
+	var out []float32
+	var nz int32
+	for j := int32(0); j < ny; j++ {
+		for i := int32(0); i < nx; i++ {
+			stops, dtops := src.LayerTops(i+i1, j+j1), dst.LayerTops(i+i1, j+j1)
+			if len(stops) != int(f.Nz) {
+				return nil, fmt.Errorf("there are %d original layer tops but %d layers",
+					len(stops), f.Nz)
+			}
+			if out == nil {
+				if nz = int32(len(dtops)); nz == 0 {
+					return nil, fmt.Errorf("there are no new layers")
+				}
+				out = make([]float32, n*nz)
+			} else if len(dtops) != int(nz) {
+				return nil, fmt.Errorf("there are %d new layers in cell (%d, %d) but %d in others",
+					len(dtops), i+i1, j+j1, nz)
+			}
+			c := j*nx + i
+			for l := int32(0); l < nz; l++ {
+				dlo, dhi := 0.0, float64(dtops[l])
+				if l > 0 {
+					dlo = float64(dtops[l-1])
+				}
+				if extensive && l == nz-1 {
+					dhi = max(dhi, float64(stops[len(stops)-1]))
+				}
+				var sum, weight float64
+				for k := int32(0); k < f.Nz; k++ {
+					slo, shi := 0.0, float64(stops[k])
+					if k > 0 {
+						slo = float64(stops[k-1])
+					}
+					overlap := min(dhi, shi) - max(dlo, slo)
+					if overlap <= 0 {
+						continue
+					}
+					v := float64(vals[k*n+c])
+					if extensive {
+						sum += v * overlap / (shi - slo)
+					} else {
+						sum += v * overlap
+						weight += overlap
+					}
+				}
+				switch {
+				case extensive:
+				case weight > 0:
+					sum /= weight
+				default:
+					sum = float64(vals[(f.Nz-1)*n+c])
+				}
+				out[l*n+c] = float32(sum)
+			}
+		}
+	}
+	return out, nil
+}
+
+// RemapLayersFile remaps every species and hour of the gridded file
+// called filename from the layer structure src to dst with RemapLayers,
+// and writes the result to a file called outfile with the rest of its
+// header copied from the original.
+func RemapLayersFile(filename, outfile string, src, dst Layers) error {
+	f, err := Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if f.Name == "PTSOURCE" {
+		return fmt.Errorf("%v: layers can only be remapped for gridded files", filename)
+	}
+	out := NewLike(f, 0)
+	out.Nz = int32(len(dst.LayerTops(0, 0)))
+	w, err := Create(outfile, out)
+	if err != nil {
+		return err
+	}
+	data := make(map[string][]float32)
+	res := make(map[string][]float32)
+	for {
+		if err = f.readHour(data); err == io.EOF {
+			break
+		} else if err != nil {
+			w.Close()
+			return err
+		}
+		for spname, vals := range data {
+			if res[spname], err = f.RemapLayers(vals, src, dst); err != nil {
+				w.Close()
+				return err
+			}
+		}
+		if err = w.WriteHour(res); err != nil {
+			w.Close()
+			return err
+		}
+	}
+	return w.Close()
+}
@@ -0,0 +1,75 @@
+package uam_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ctessum/uam"
+	"github.com/ctessum/uam/uamtest"
+)
+
+func TestRemapLayers(t *testing.T) {
+	// The values are 2 in the layer up to 100 m and 4 in the layer from
+	// 100 to 300 m.
+	o := uamtest.Options{Hours: 2, Pattern: uamtest.Linear(2, 0, 2, 0, 0)}
+	src := uam.UniformLayers{100, 300}
+
+	// Emissions are divided among the new layers, and those above the
+	// new top are added to the top layer.
+	e, err := uamtest.Emissions(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	outfile := filepath.Join(t.TempDir(), "emis.uam")
+	err = uam.RemapLayersFile(uamtest.TempFile(t, e), outfile, src, uam.UniformLayers{50, 100, 250})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := uam.Open(outfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if r.Nz != 3 {
+		t.Fatalf("got %d layers, want 3", r.Nz)
+	}
+	hours := readAll(t, r)
+	if len(hours) != 2 {
+		t.Fatalf("got %d hours, want 2", len(hours))
+	}
+	checkLayers(t, hours[1].Data["NO"], []float32{1, 1, 4})
+
+	// Concentrations are averaged over the original layers, and are the
+	// values of the top original layer above it.
+	a, err := uamtest.Average(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := a.RemapLayers(a.Hours[0].Data["O3"], src, uam.UniformLayers{50, 300, 500})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkLayers(t, got, []float32{2, 3.6, 4})
+
+	if _, err = a.RemapLayers(a.Hours[0].Data["O3"][1:], src, src); err == nil {
+		t.Error("wrong length: got no error")
+	}
+	if _, err = a.RemapLayers(a.Hours[0].Data["O3"], uam.UniformLayers{100}, src); err == nil {
+		t.Error("wrong number of original layers: got no error")
+	}
+}
+
+// checkLayers checks that each layer of the 4x3 field vals has the value
+// given in want.
+func checkLayers(t *testing.T, vals, want []float32) {
+	t.Helper()
+	const n = 4 * 3
+	if len(vals) != n*len(want) {
+		t.Fatalf("got %d values, want %d", len(vals), n*len(want))
+	}
+	for i, v := range vals {
+		if k := i / n; v != want[k] {
+			t.Errorf("layer %d: got %g, want %g", k, v, want[k])
+		}
+	}
+}
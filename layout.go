@@ -0,0 +1,135 @@
+package uam
+
+import "fmt"
+
+// Each hour of a gridded file is written by CAMx as a time record
+// followed by one record for each layer of each species, with the layers
+// of a species together:
+//
+//	do l = 1, nspec
+//	  do k = 1, nz
+//	    write(iunit) ione, (spname(n,l), n=1,10), ((c(i,j,k,l), i=1,nx), j=1,ny)
+//
+// Each hour of a PTSOURCE file is a time record, a record holding ione
+// and the number of stacks, a record of the overrides of each stack, and
+// one record for each species. The number of hours is not stored in the
+// header, so files are read until they end.
+//
+// The sizes of records are fixed by the header, so the byte offset of
+// any record in a file can be calculated without reading the records
+// before it. All sizes include the leading and trailing record markers.
+
+// recordSize returns the size of a record holding n bytes.
+func (f *UAM) recordSize(n int64) int64 {
+	return f.markerLen() + n + f.markerLen()
+}
+
+// timeRecordSize returns the size of the time record at the start of
+// each hour.
+func (f *UAM) timeRecordSize() int64 {
+	return f.recordSize(16)
+}
+
+// speciesRecordSize returns the size of a data record holding n values,
+// which starts with the segment number and the species name.
+func (f *UAM) speciesRecordSize(n int64) int64 {
+	return f.recordSize(4 + 40 + 4*n)
+}
+
+// gridRecordSize returns the size of a record holding one layer of a
+// gridded species.
+func (f *UAM) gridRecordSize() int64 {
+	return f.speciesRecordSize(int64(f.Nx) * int64(f.Ny))
+}
+
+// hourSize returns the size of one hour of data.
+func (f *UAM) hourSize() int64 {
+	if f.Name == "PTSOURCE" {
+		return f.timeRecordSize() + f.recordSize(8) +
+			f.recordSize(20*int64(f.Npts)) +
+			int64(f.Nspec)*f.speciesRecordSize(int64(f.Npts))
+	}
+	return f.timeRecordSize() + int64(f.Nspec)*int64(f.Nz)*f.gridRecordSize()
+}
+
+// RecordInfo describes the location and contents of a record in a file.
+type RecordInfo struct {
+	Index   int    // index of the record in the file
+	Offset  int64  // byte offset of the start of the record
+	Size    int64  // size of the record in bytes, including markers
+	Hour    int    // index of the hour, or -1 for header records
+	Kind    string // "header", "time", "points", "overrides", or "species"
+	Species string // species name, for species records
+	Layer   int    // layer index, for gridded species records; otherwise -1
+}
+
+func (r RecordInfo) String() string {
+	s := fmt.Sprintf("record %d at byte %d (%d bytes): ", r.Index, r.Offset, r.Size)
+	if r.Hour >= 0 {
+		s += fmt.Sprintf("hour %d ", r.Hour)
+	}
+	s += r.Kind
+	if r.Species != "" {
+		s += " " + r.Species
+	}
+	if r.Layer >= 0 {
+		s += fmt.Sprintf(" layer %d", r.Layer)
+	}
+	return s
+}
+
+// RecordMap returns the location and contents of each record in the
+// header (if hour is -1) or in the hour with the given index, counting
+// from the first hour in the file. The map is calculated from the header
+// information, so it describes where records should be even if the
+// file is truncated or corrupt.
+func (f *UAM) RecordMap(hour int) []RecordInfo {
+	var recs []RecordInfo
+	add := func(kind, species string, layer int, size int64) {
+		r := RecordInfo{Hour: hour, Kind: kind, Species: species, Layer: layer, Size: size}
+		if n := len(recs); n > 0 {
+			r.Index = recs[n-1].Index + 1
+			r.Offset = recs[n-1].Offset + recs[n-1].Size
+		}
+		recs = append(recs, r)
+	}
+	if hour < 0 {
+		add("header", "", -1, f.recordSize(304))
+		add("header", "", -1, f.recordSize(60))
+		add("header", "", -1, f.recordSize(16))
+		add("header", "", -1, f.recordSize(40*int64(f.Nspec)))
+		if f.Name == "PTSOURCE" {
+			add("header", "", -1, f.recordSize(8))
+			add("header", "", -1, f.recordSize(24*int64(f.Npts)))
+		}
+		return recs
+	}
+	header := f.RecordMap(-1)
+	last := header[len(header)-1]
+	perHour := 1 + len(f.Spnames)*int(f.Nz)
+	if f.Name == "PTSOURCE" {
+		perHour = 3 + len(f.Spnames)
+	}
+	recs = append(recs, RecordInfo{
+		Index:  last.Index + 1 + hour*perHour,
+		Offset: last.Offset + last.Size + int64(hour)*f.hourSize(),
+		Size:   f.timeRecordSize(),
+		Hour:   hour,
+		Kind:   "time",
+		Layer:  -1,
+	})
+	if f.Name == "PTSOURCE" {
+		add("points", "", -1, f.recordSize(8))
+		add("overrides", "", -1, f.recordSize(20*int64(f.Npts)))
+		for _, spname := range f.Spnames {
+			add("species", spname, -1, f.speciesRecordSize(int64(f.Npts)))
+		}
+		return recs
+	}
+	for _, spname := range f.Spnames {
+		for k := 0; k < int(f.Nz); k++ {
+			add("species", spname, k, f.gridRecordSize())
+		}
+	}
+	return recs
+}
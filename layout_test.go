@@ -0,0 +1,188 @@
+package uam_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/ctessum/uam"
+)
+
+// camxFile builds a file record by record in the order that CAMx writes
+// it, independently of uam.Writer.
+type camxFile struct{ bytes.Buffer }
+
+// record writes one Fortran record holding the given values, each of
+// which is an int32, a float32, or a string, which is written as a Fortran CHARACTER*4
+// array with each character padded with spaces.
+func (b *camxFile) record(vals ...any) {
+	var data bytes.Buffer
+	for _, v := range vals {
+		switch v := v.(type) {
+		case string:
+			for _, c := range []byte(v) {
+				data.Write([]byte{c, ' ', ' ', ' '})
+			}
+		default:
+			binary.Write(&data, binary.BigEndian, v)
+		}
+	}
+	binary.Write(b, binary.BigEndian, int32(data.Len()))
+	b.Write(data.Bytes())
+	binary.Write(b, binary.BigEndian, int32(data.Len()))
+}
+
+// padName pads s to n characters.
+func padName(s string, n int) string { return fmt.Sprintf("%-*s", n, s) }
+
+// header writes the header records shared by all file types.
+func (b *camxFile) header(ftype string, spnames []string, nx, ny, nz int32) {
+	b.record(padName(ftype, 10), padName("layout test", 60), int32(1), int32(len(spnames)),
+		int32(15001), float32(0), int32(15001), float32(24))
+	b.record(float32(0), float32(0), int32(15), float32(0), float32(0),
+		float32(1000), float32(1000), nx, ny, nz, int32(0), int32(0),
+		float32(0), float32(0), float32(0))
+	b.record(int32(1), int32(1), nx, ny)
+	var names []any
+	for _, s := range spnames {
+		names = append(names, padName(s, 10))
+	}
+	b.record(names...)
+}
+
+func TestGriddedLayout(t *testing.T) {
+	const nx, ny, nz, nhrs = 3, 2, 2, 5
+	spnames := []string{"NO", "NO2", "O3"}
+	// value returns a value that shows where it was written.
+	value := func(h, l int, k, j, i int32) float32 {
+		return float32(10000*h + 1000*l + 100*int(k) + 10*int(j) + int(i))
+	}
+	var b camxFile
+	b.header("EMISSIONS", spnames, nx, ny, nz)
+	for h := 0; h < nhrs; h++ {
+		b.record(int32(15001), float32(h), int32(15001), float32(h+1))
+		for l, s := range spnames {
+			for k := int32(0); k < nz; k++ {
+				rec := []any{int32(1), padName(s, 10)}
+				for j := int32(0); j < ny; j++ {
+					for i := int32(0); i < nx; i++ {
+						rec = append(rec, value(h, l, k, j, i))
+					}
+				}
+				b.record(rec...)
+			}
+		}
+	}
+	data := b.Bytes()
+
+	f, err := uam.NewBytesReader(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The record map must give the species of each record in the order
+	// they were written, and its offsets must land on their markers.
+	recs := f.RecordMap(1)
+	if len(recs) != 1+len(spnames)*nz {
+		t.Fatalf("got %d records in the hour, want %d", len(recs), 1+len(spnames)*nz)
+	}
+	for n, r := range recs[1:] {
+		l, k := n/nz, n%nz
+		if r.Species != spnames[l] || r.Layer != k {
+			t.Errorf("record %d: got %v layer %d, want %v layer %d", n, r.Species, r.Layer, spnames[l], k)
+		}
+		if got := binary.BigEndian.Uint32(data[r.Offset:]); int64(got) != r.Size-8 {
+			t.Errorf("record %d: marker at %d is %d, want %d", n, r.Offset, got, r.Size-8)
+		}
+		if got := binary.BigEndian.Uint32(data[r.Offset+r.Size-4:]); int64(got) != r.Size-8 {
+			t.Errorf("record %d: trailing marker is %d, want %d", n, got, r.Size-8)
+		}
+	}
+	last := f.RecordMap(nhrs - 1)
+	if end := last[len(last)-1]; end.Offset+end.Size != int64(len(data)) {
+		t.Errorf("the record map ends at byte %d, but the file has %d bytes", end.Offset+end.Size, len(data))
+	}
+
+	// The hours are read until the file ends, not for 24 hours.
+	for h := 0; ; h++ {
+		hr, err := f.ReadNextHour()
+		if err == io.EOF {
+			if h != nhrs {
+				t.Errorf("got %d hours, want %d", h, nhrs)
+			}
+			break
+		} else if err != nil {
+			t.Fatalf("hour %d: %v", h, err)
+		}
+		for l, s := range spnames {
+			for k := int32(0); k < nz; k++ {
+				for j := int32(0); j < ny; j++ {
+					for i := int32(0); i < nx; i++ {
+						got, want := hr.Data[s][(k*ny+j)*nx+i], value(h, l, k, j, i)
+						if got != want {
+							t.Fatalf("hour %d %v (%d, %d, %d): got %g, want %g", h, s, i, j, k, got, want)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestPointLayout(t *testing.T) {
+	const npts, nhrs = 3, 2
+	spnames := []string{"NO", "SO2"}
+	var b camxFile
+	b.header("PTSOURCE", spnames, 4, 4, 1)
+	b.record(int32(1), int32(npts))
+	var stacks []any
+	for p := 0; p < npts; p++ {
+		stacks = append(stacks, float32(100*p), float32(200*p), float32(10*p+10),
+			float32(p+1), float32(400), float32(3600))
+	}
+	b.record(stacks...)
+	for h := 0; h < nhrs; h++ {
+		b.record(int32(15001), float32(h), int32(15001), float32(h+1))
+		b.record(int32(1), int32(npts))
+		var overrides []any
+		for p := 0; p < npts; p++ {
+			overrides = append(overrides, int32(p), int32(h), int32(-1), float32(0), float32(100*h+p))
+		}
+		b.record(overrides...)
+		for l, s := range spnames {
+			rec := []any{int32(1), padName(s, 10)}
+			for p := 0; p < npts; p++ {
+				rec = append(rec, float32(100*h+10*l+p))
+			}
+			b.record(rec...)
+		}
+	}
+
+	f, err := uam.NewBytesReader(b.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for h := 0; ; h++ {
+		hr, err := f.ReadNextHour()
+		if err == io.EOF {
+			if h != nhrs {
+				t.Errorf("got %d hours, want %d", h, nhrs)
+			}
+			break
+		} else if err != nil {
+			t.Fatalf("hour %d: %v", h, err)
+		}
+		for p := 0; p < npts; p++ {
+			want := uam.StackOverride{I: int32(p), J: int32(h), K: -1, PlumeHeight: float32(100*h + p)}
+			if hr.Overrides[p] != want {
+				t.Errorf("hour %d stack %d: got override %+v, want %+v", h, p, hr.Overrides[p], want)
+			}
+			for l, s := range spnames {
+				if got, want := hr.Data[s][p], float32(100*h+10*l+p); got != want {
+					t.Errorf("hour %d %v stack %d: got %g, want %g", h, s, p, got, want)
+				}
+			}
+		}
+	}
+}
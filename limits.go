@@ -0,0 +1,104 @@
+package uam
+
+import "fmt"
+
+// Limits bounds the sizes given in the header of a file that is being
+// opened, so that a corrupt or malicious header, such as one claiming
+// that there are two billion species, cannot make the reader allocate
+// enormous amounts of memory. A limit of zero means no limit.
+type Limits struct {
+	MaxSpecies int // number of species
+	MaxStacks  int // number of stacks in a PTSOURCE file
+	// MaxValues is the number of values in one hour of all species:
+	// Nspec*Nx*Ny*Nz for gridded files or Nspec*Npts for PTSOURCE files.
+	MaxValues int64
+}
+
+// DefaultLimits are the limits for files opened without WithLimits.
+// They are far larger than the domains of real simulations.
+var DefaultLimits = Limits{MaxSpecies: 10000, MaxStacks: 1 << 24, MaxValues: 1 << 31}
+
+// WithLimits sets the limits on the sizes given in the header of the
+// file, for reading files from untrusted sources. Whatever the limits,
+// the header is also checked against the size of the file, where it is
+// known, before the species names and stack parameters are allocated.
+// Errors wrap ErrInvalidHeader.
+func WithLimits(l Limits) Option {
+	return func(f *UAM) {
+		f.limits = &l
+	}
+}
+
+// checkCount returns an error if the number n of things described by
+// what is negative or more than max, unless max is zero.
+func checkCount(what string, n, max int64) error {
+	if n < 0 {
+		return fmt.Errorf("%w: there are %d %v", ErrInvalidHeader, n, what)
+	}
+	if max > 0 && n > max {
+		return fmt.Errorf("%w: there are %d %v, which is more than the limit of %d",
+			ErrInvalidHeader, n, what, max)
+	}
+	return nil
+}
+
+// checkDims checks the numbers of species, grid cells, and (for
+// PTSOURCE files) stacks in the header against the limits.
+func (f *UAM) checkDims() error {
+	l := DefaultLimits
+	if f.limits != nil {
+		l = *f.limits
+	}
+	if err := checkCount("species", int64(f.Nspec), int64(l.MaxSpecies)); err != nil {
+		return err
+	}
+	for _, n := range []int32{f.Nx, f.Ny, f.Nz} {
+		if n < 0 {
+			return fmt.Errorf("%w: invalid grid dimensions %dx%dx%d",
+				ErrInvalidHeader, f.Nx, f.Ny, f.Nz)
+		}
+	}
+	values := float64(f.Nspec) * float64(f.Nx) * float64(f.Ny) * float64(f.Nz)
+	if f.Name == "PTSOURCE" {
+		if err := checkCount("stacks", int64(f.Npts), int64(l.MaxStacks)); err != nil {
+			return err
+		}
+		values = float64(f.Nspec) * float64(f.Npts)
+	}
+	if l.MaxValues > 0 && values > float64(l.MaxValues) {
+		return fmt.Errorf("%w: each hour has %.0f values, which is more than the limit of %d",
+			ErrInvalidHeader, values, l.MaxValues)
+	}
+	return nil
+}
+
+// checkFits returns an error if the rest of a record that holds n
+// bytes of data about what, whose leading marker has been read, does
+// not fit in the file.
+func (f *UAM) checkFits(what string, n int64) error {
+	size := f.fileSize()
+	if size < 0 {
+		return nil
+	}
+	if need := n + f.markerLen(); f.r.off+need > size {
+		return fmt.Errorf("%w: the %v need %d bytes but the file has %d left",
+			ErrInvalidHeader, what, need, max(size-f.r.off, 0))
+	}
+	return nil
+}
+
+// fileSize returns the size of the (uncompressed) file, or -1 if it is
+// not known.
+func (f *UAM) fileSize() int64 {
+	switch {
+	case f.r != nil && f.r.mem:
+		return int64(len(f.r.data))
+	case f.size != nil && !f.compressed():
+		size, err := f.size()
+		if err != nil {
+			return -1
+		}
+		return size
+	}
+	return -1
+}
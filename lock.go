@@ -0,0 +1,10 @@
+package uam
+
+import "errors"
+
+// ErrLocked is returned by Create when the output file is locked by
+// another Writer, which may be in a different process. Files created by
+// Create are locked with an advisory lock until the Writer is closed, so
+// that concurrent jobs writing to the same output fail immediately
+// instead of interleaving their records.
+var ErrLocked = errors.New("file is locked by another writer")
@@ -0,0 +1,8 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly || windows)
+
+package uam
+
+import "os"
+
+// lockFile does nothing on systems that do not support file locking.
+func lockFile(fid *os.File) error { return nil }
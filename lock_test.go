@@ -0,0 +1,51 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly || windows
+
+package uam_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ctessum/uam"
+	"github.com/ctessum/uam/uamtest"
+)
+
+func TestCreateLocked(t *testing.T) {
+	f, err := uamtest.Emissions(uamtest.Options{Hours: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	filename := filepath.Join(t.TempDir(), "emis.uam")
+	w, err := uam.Create(filename, f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = w.WriteHour(f.Hours[0].Data); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = uam.Create(filename, f); !errors.Is(err, uam.ErrLocked) {
+		t.Fatalf("got error %v, want ErrLocked", err)
+	}
+	// The locked file is left intact.
+	if info2, err := os.Stat(filename); err != nil {
+		t.Fatal(err)
+	} else if info2.Size() != info.Size() {
+		t.Errorf("locked file changed size from %d to %d", info.Size(), info2.Size())
+	}
+
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	w, err = uam.Create(filename, f)
+	if err != nil {
+		t.Fatalf("after closing: %v", err)
+	}
+	w.Close()
+}
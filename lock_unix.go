@@ -0,0 +1,19 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package uam
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockFile places an exclusive advisory lock on fid, returning
+// ErrLocked if it is already locked. The lock is released when fid is
+// closed.
+func lockFile(fid *os.File) error {
+	err := syscall.Flock(int(fid.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if err == syscall.EWOULDBLOCK {
+		return ErrLocked
+	}
+	return err
+}
@@ -0,0 +1,34 @@
+package uam
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+var procLockFileEx = syscall.NewLazyDLL("kernel32.dll").NewProc("LockFileEx")
+
+const (
+	lockfileFailImmediately = 0x1
+	lockfileExclusiveLock   = 0x2
+
+	errorLockViolation syscall.Errno = 33
+)
+
+// lockFile places an exclusive lock on the whole of fid, returning
+// ErrLocked if it is already locked. The lock is released when fid is
+// closed.
+func lockFile(fid *os.File) error {
+	var ol syscall.Overlapped
+	r, _, err := procLockFileEx.Call(fid.Fd(),
+		lockfileExclusiveLock|lockfileFailImmediately, 0,
+		0xffffffff, 0xffffffff, // lock all bytes
+		uintptr(unsafe.Pointer(&ol)))
+	if r != 0 {
+		return nil
+	}
+	if err == errorLockViolation {
+		return ErrLocked
+	}
+	return err
+}
@@ -0,0 +1,14 @@
+package uam
+
+import "log/slog"
+
+// WithLogger sets a logger that receives debug-level events as the file
+// is parsed: the header contents, the date and time of each hour, and the
+// offset and length of each data record. Recovered errors (see
+// WithResync) are logged as warnings. This is intended to help
+// troubleshoot files that do not parse correctly.
+func WithLogger(l *slog.Logger) Option {
+	return func(f *UAM) {
+		f.log = l
+	}
+}
@@ -0,0 +1,193 @@
+package uam
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// earthRadius is the radius (m) of the spherical earth assumed by CAMx
+// and its meteorological preprocessors.
+const earthRadius = 6370000.
+
+// lonLat converts projected coordinates x and y to longitude and
+// latitude (degrees) using the projection of f. ok is false if the
+// projection is not supported.
+func (f *UAM) lonLat(x, y float64) (lon, lat float64, ok bool) {
+	lon, lat, err := f.Projection().Inverse(x, y)
+	return lon, lat, err == nil
+}
+
+// cellLonLat returns the longitude and latitude of the center of grid
+// cell (i, j).
+func (f *UAM) cellLonLat(i, j int32) (lon, lat float64) {
+	x := float64(f.Utmx) + (float64(i)+0.5)*float64(f.Dx)
+	y := float64(f.Utmy) + (float64(j)+0.5)*float64(f.Dy)
+	lon, lat, _ = f.lonLat(x, y)
+	return
+}
+
+// utmToLonLat converts UTM coordinates (m) in the given zone to
+// longitude and latitude on the WGS84 ellipsoid. Negative zones are in
+// the southern hemisphere.
+func utmToLonLat(x, y float64, zone int) (lon, lat float64) {
+	const (
+		a  = 6378137.
+		f  = 1 / 298.257223563
+		k0 = 0.9996
+	)
+	if zone < 0 {
+		zone = -zone
+		y -= 10000000
+	}
+	e2 := f * (2 - f)
+	ep2 := e2 / (1 - e2)
+	x -= 500000
+	m := y / k0
+	mu := m / (a * (1 - e2/4 - 3*e2*e2/64 - 5*e2*e2*e2/256))
+	e1 := (1 - math.Sqrt(1-e2)) / (1 + math.Sqrt(1-e2))
+	phi1 := mu + (3*e1/2-27*math.Pow(e1, 3)/32)*math.Sin(2*mu) +
+		(21*e1*e1/16-55*math.Pow(e1, 4)/32)*math.Sin(4*mu) +
+		(151*math.Pow(e1, 3)/96)*math.Sin(6*mu) +
+		(1097*math.Pow(e1, 4)/512)*math.Sin(8*mu)
+	sin1, cos1, tan1 := math.Sin(phi1), math.Cos(phi1), math.Tan(phi1)
+	n1 := a / math.Sqrt(1-e2*sin1*sin1)
+	t1 := tan1 * tan1
+	c1 := ep2 * cos1 * cos1
+	r1 := a * (1 - e2) / math.Pow(1-e2*sin1*sin1, 1.5)
+	d := x / (n1 * k0)
+	lat = phi1 - (n1*tan1/r1)*(d*d/2-
+		(5+3*t1+10*c1-4*c1*c1-9*ep2)*math.Pow(d, 4)/24+
+		(61+90*t1+298*c1+45*t1*t1-252*ep2-3*c1*c1)*math.Pow(d, 6)/720)
+	lon = (d - (1+2*t1+c1)*math.Pow(d, 3)/6 +
+		(5-2*c1+28*t1-3*c1*c1+8*ep2+24*t1*t1)*math.Pow(d, 5)/120) / cos1
+	lon0 := float64(zone-1)*6 - 180 + 3
+	return lon0 + lon*180/math.Pi, lat * 180 / math.Pi
+}
+
+// lccToLonLat converts Lambert conformal conic coordinates (m) on a
+// sphere, with the given central longitude and latitude and true
+// latitudes (degrees), to longitude and latitude.
+func lccToLonLat(x, y, lon0, lat0, lat1, lat2 float64) (lon, lat float64) {
+	const rad = math.Pi / 180
+	phi0, phi1, phi2 := lat0*rad, lat1*rad, lat2*rad
+	t := func(phi float64) float64 { return math.Tan(math.Pi/4 + phi/2) }
+	n := math.Sin(phi1)
+	if math.Abs(phi1-phi2) > 1e-10 {
+		n = math.Log(math.Cos(phi1)/math.Cos(phi2)) / math.Log(t(phi2)/t(phi1))
+	}
+	F := math.Cos(phi1) * math.Pow(t(phi1), n) / n
+	rho0 := earthRadius * F / math.Pow(t(phi0), n)
+	rho := math.Copysign(math.Hypot(x, rho0-y), n)
+	theta := math.Atan2(x, rho0-y)
+	if n < 0 {
+		theta = math.Atan2(-x, y-rho0)
+	}
+	lat = 2*math.Atan(math.Pow(earthRadius*F/rho, 1/n)) - math.Pi/2
+	return lon0 + theta/n/rad, lat / rad
+}
+
+// ErrOutsideDomain means that a location is not within the grid.
+var ErrOutsideDomain = errors.New("location is outside of the domain")
+
+// CellAt returns the column and row of the grid cell that contains the
+// given longitude and latitude (degrees). The error wraps
+// ErrOutsideDomain if the location is outside of the grid.
+func (g GridDef) CellAt(lon, lat float64) (i, j int32, err error) {
+	x, y, err := g.Projection().Forward(lon, lat)
+	if err != nil {
+		return 0, 0, err
+	}
+	fi := math.Floor((x - float64(g.Xorig)) / float64(g.Dx))
+	fj := math.Floor((y - float64(g.Yorig)) / float64(g.Dy))
+	if !(fi >= 0 && fi < float64(g.Nx) && fj >= 0 && fj < float64(g.Ny)) {
+		return 0, 0, fmt.Errorf("%w: (%g, %g)", ErrOutsideDomain, lon, lat)
+	}
+	return int32(fi), int32(fj), nil
+}
+
+// CellAt returns the column and row of the cell of the grid of f that
+// contains the given longitude and latitude. See GridDef.CellAt.
+func (f *UAM) CellAt(lon, lat float64) (i, j int32, err error) {
+	return f.GridDef().CellAt(lon, lat)
+}
+
+// lonLatToUTM converts longitude and latitude on the WGS84 ellipsoid to
+// UTM coordinates (m) in the given zone. Negative zones are in the
+// southern hemisphere.
+func lonLatToUTM(lon, lat float64, zone int) (x, y float64) {
+	const (
+		a  = 6378137.
+		f  = 1 / 298.257223563
+		k0 = 0.9996
+	)
+	south := zone < 0
+	if south {
+		zone = -zone
+	}
+	e2 := f * (2 - f)
+	ep2 := e2 / (1 - e2)
+	lon0 := float64(zone-1)*6 - 180 + 3
+	phi := lat * math.Pi / 180
+	sin, cos, tan := math.Sin(phi), math.Cos(phi), math.Tan(phi)
+	n := a / math.Sqrt(1-e2*sin*sin)
+	t := tan * tan
+	c := ep2 * cos * cos
+	A := cos * (lon - lon0) * math.Pi / 180
+	m := a * ((1-e2/4-3*e2*e2/64-5*e2*e2*e2/256)*phi -
+		(3*e2/8+3*e2*e2/32+45*e2*e2*e2/1024)*math.Sin(2*phi) +
+		(15*e2*e2/256+45*e2*e2*e2/1024)*math.Sin(4*phi) -
+		(35*e2*e2*e2/3072)*math.Sin(6*phi))
+	x = k0*n*(A+(1-t+c)*math.Pow(A, 3)/6+
+		(5-18*t+t*t+72*c-58*ep2)*math.Pow(A, 5)/120) + 500000
+	y = k0 * (m + n*tan*(A*A/2+(5-t+9*c+4*c*c)*math.Pow(A, 4)/24+
+		(61-58*t+t*t+600*c-330*ep2)*math.Pow(A, 6)/720))
+	if south {
+		y += 10000000
+	}
+	return x, y
+}
+
+// lonLatToLCC converts longitude and latitude to Lambert conformal conic
+// coordinates (m) on a sphere. It is the inverse of lccToLonLat.
+func lonLatToLCC(lon, lat, lon0, lat0, lat1, lat2 float64) (x, y float64) {
+	const rad = math.Pi / 180
+	phi0, phi1, phi2 := lat0*rad, lat1*rad, lat2*rad
+	t := func(phi float64) float64 { return math.Tan(math.Pi/4 + phi/2) }
+	n := math.Sin(phi1)
+	if math.Abs(phi1-phi2) > 1e-10 {
+		n = math.Log(math.Cos(phi1)/math.Cos(phi2)) / math.Log(t(phi2)/t(phi1))
+	}
+	F := math.Cos(phi1) * math.Pow(t(phi1), n) / n
+	rho0 := earthRadius * F / math.Pow(t(phi0), n)
+	rho := earthRadius * F / math.Pow(t(lat*rad), n)
+	theta := n * (lon - lon0) * rad
+	return rho * math.Sin(theta), rho0 - rho*math.Cos(theta)
+}
+
+// lonLatToPS converts longitude and latitude to polar stereographic
+// coordinates (m) on a sphere, with the origin at the pole, the given
+// central longitude, and true latitude, whose sign gives the hemisphere.
+func lonLatToPS(lon, lat, lon0, latTrue float64) (x, y float64) {
+	const rad = math.Pi / 180
+	dlon := (lon - lon0) * rad
+	if latTrue >= 0 {
+		rho := earthRadius * (1 + math.Sin(latTrue*rad)) * math.Tan(math.Pi/4-lat*rad/2)
+		return rho * math.Sin(dlon), -rho * math.Cos(dlon)
+	}
+	rho := earthRadius * (1 - math.Sin(latTrue*rad)) * math.Tan(math.Pi/4+lat*rad/2)
+	return rho * math.Sin(dlon), rho * math.Cos(dlon)
+}
+
+// psToLonLat converts polar stereographic coordinates (m) to longitude
+// and latitude. It is the inverse of lonLatToPS.
+func psToLonLat(x, y, lon0, latTrue float64) (lon, lat float64) {
+	const rad = math.Pi / 180
+	rho := math.Hypot(x, y)
+	if latTrue >= 0 {
+		lat = math.Pi/2 - 2*math.Atan(rho/(earthRadius*(1+math.Sin(latTrue*rad))))
+		return lon0 + math.Atan2(x, -y)/rad, lat / rad
+	}
+	lat = 2*math.Atan(rho/(earthRadius*(1-math.Sin(latTrue*rad)))) - math.Pi/2
+	return lon0 + math.Atan2(x, y)/rad, lat / rad
+}
@@ -0,0 +1,69 @@
+package uam
+
+import (
+	"fmt"
+	"io"
+)
+
+// WithMarkerSize sets the size in bytes of the Fortran record length
+// markers in the file, which is 4 for most compilers but 8 for some
+// older compilers and compiler settings. By default, or if n is 0, the
+// size is detected from the first record of the file.
+func WithMarkerSize(n int) Option {
+	return func(f *UAM) {
+		f.markerSize = n
+	}
+}
+
+// markerLen returns the size in bytes of the record markers.
+func (f *UAM) markerLen() int64 {
+	if f.markerSize == 0 {
+		return 4
+	}
+	return int64(f.markerSize)
+}
+
+// detectMarkerSize sets the marker size from the leading marker of the
+// first record, which holds n bytes, unless it has already been set.
+func (f *UAM) detectMarkerSize(n int64) error {
+	switch f.markerSize {
+	case 0:
+	case 4, 8:
+		return nil
+	default:
+		return fmt.Errorf("invalid record marker size %d", f.markerSize)
+	}
+	b, err := f.r.peek(8)
+	if err != nil && err != io.EOF {
+		return err
+	}
+	f.markerSize = 4
+	if len(b) == 8 && int64(ByteOrder.Uint32(b)) != n && int64(ByteOrder.Uint64(b)) == n {
+		f.markerSize = 8
+	}
+	return nil
+}
+
+// readMarker reads a record marker.
+func (f *UAM) readMarker() (int64, error) {
+	if f.markerLen() == 8 {
+		var b [8]byte
+		if _, err := io.ReadFull(f.r, b[:]); err != nil {
+			if err == io.ErrUnexpectedEOF {
+				err = io.EOF
+			}
+			return 0, err
+		}
+		return int64(ByteOrder.Uint64(b[:])), nil
+	}
+	m, err := readInt(f.r)
+	return int64(m), err
+}
+
+// decodeMarker decodes the record marker at the start of b.
+func (f *UAM) decodeMarker(b []byte) int64 {
+	if f.markerLen() == 8 {
+		return int64(ByteOrder.Uint64(b))
+	}
+	return int64(int32(ByteOrder.Uint32(b)))
+}
@@ -0,0 +1,149 @@
+package uam
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+)
+
+// MarineSpecies are the CAMx species that are emitted over the ocean,
+// such as sea salt and marine halogens, and that should normally be
+// zero over land.
+var MarineSpecies = []string{"NA", "PCL", "I2", "HOI", "DMS"}
+
+// Mask is a horizontal field of weights on a grid that can be applied to
+// gridded data, where a weight of 1 keeps a value unchanged and a weight
+// of 0 sets it to zero. Weights between 0 and 1, such as the fraction of
+// a cell that is covered by ocean, scale values proportionally.
+type Mask struct {
+	Nx, Ny int32
+	Values []float32 // weight of each cell, indexed by j*Nx+i
+}
+
+// NewMask creates a mask for an nx by ny grid from a user-supplied
+// raster of weights, indexed by j*nx+i.
+func NewMask(nx, ny int32, values []float32) (*Mask, error) {
+	if len(values) != int(nx*ny) {
+		return nil, fmt.Errorf("mask has %d values; it should have %d",
+			len(values), nx*ny)
+	}
+	return &Mask{Nx: nx, Ny: ny, Values: values}, nil
+}
+
+// ReadLanduse reads the fractional coverage of each of ncat landuse
+// categories from a CAMx landuse (surface) file for an nx by ny grid.
+// The result is indexed by [category][j*nx+i], with the first category
+// at index 0.
+func ReadLanduse(filename string, nx, ny, ncat int32) ([][]float32, error) {
+	fid, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer fid.Close()
+	r := bufio.NewReader(fid)
+	if err = readDummy(r, 1); err != nil {
+		return nil, fmt.Errorf("reading landuse file %v: %v", filename, err)
+	}
+	fsurf := make([][]float32, ncat)
+	for m := range fsurf {
+		fsurf[m] = make([]float32, nx*ny)
+		for i := range fsurf[m] {
+			if fsurf[m][i], err = readFloat(r); err != nil {
+				return nil, fmt.Errorf("reading landuse file %v: %v",
+					filename, err)
+			}
+		}
+	}
+	return fsurf, nil
+}
+
+// LanduseMask creates a mask from landuse fractions as returned by
+// ReadLanduse, where the weight of each cell is the sum of the fractions
+// of the given categories (numbered starting from 1, as in CAMx). For
+// example, category 7 is water in the CAMx 11-category scheme, so
+// LanduseMask(fsurf, nx, ny, 7) returns the fraction of each cell that
+// is covered by water.
+func LanduseMask(fsurf [][]float32, nx, ny int32, categories ...int) (*Mask, error) {
+	values := make([]float32, nx*ny)
+	for _, c := range categories {
+		if c < 1 || c > len(fsurf) {
+			return nil, fmt.Errorf("landuse category %d is outside of the "+
+				"range 1 to %d", c, len(fsurf))
+		}
+		if len(fsurf[c-1]) != len(values) {
+			return nil, fmt.Errorf("landuse category %d has %d values; it "+
+				"should have %d", c, len(fsurf[c-1]), len(values))
+		}
+		for i, v := range fsurf[c-1] {
+			values[i] += v
+		}
+	}
+	for i, v := range values {
+		values[i] = min(v, 1)
+	}
+	return &Mask{Nx: nx, Ny: ny, Values: values}, nil
+}
+
+// ReadMask creates a mask from the first layer of species spname in the
+// first hour of a gridded UAM file, such as a CAMx ocean file holding the
+// fraction of each cell that is covered by ocean.
+func ReadMask(filename, spname string) (*Mask, error) {
+	f, err := Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if err = f.SelectSpecies([]string{spname}); err != nil {
+		return nil, fmt.Errorf("%v: %w", filename, err)
+	}
+	data := make(map[string][]float32)
+	if _, _, _, _, _, _, err = f.ReadHour(data); err != nil {
+		return nil, err
+	}
+	return &Mask{Nx: f.Nx, Ny: f.Ny, Values: data[spname][:f.Nx*f.Ny]}, nil
+}
+
+// Invert returns a mask with the weights reversed, for example to turn
+// an ocean mask into a land mask.
+func (m *Mask) Invert() *Mask {
+	values := make([]float32, len(m.Values))
+	for i, v := range m.Values {
+		values[i] = 1 - v
+	}
+	return &Mask{Nx: m.Nx, Ny: m.Ny, Values: values}
+}
+
+// Threshold returns a mask with weights of 1 where m is at least t and 0
+// elsewhere, for example to treat cells that are mostly ocean as ocean.
+func (m *Mask) Threshold(t float32) *Mask {
+	values := make([]float32, len(m.Values))
+	for i, v := range m.Values {
+		if v >= t {
+			values[i] = 1
+		}
+	}
+	return &Mask{Nx: m.Nx, Ny: m.Ny, Values: values}
+}
+
+// Apply multiplies every layer of the named species in Data, which holds
+// one hour of gridded data as read by ReadHour, by the mask weights.
+// Species that are not in Data are ignored. For example,
+// oceanMask.Apply(Data, MarineSpecies) zeroes sea salt and halogen
+// emissions over land.
+func (m *Mask) Apply(Data map[string][]float32, species []string) error {
+	n := len(m.Values)
+	for _, spname := range species {
+		vals, ok := Data[spname]
+		if !ok {
+			continue
+		}
+		if len(vals)%n != 0 {
+			return fmt.Errorf("species %v has %d values, which is not a "+
+				"multiple of the %dx%d mask", spname, len(vals), m.Nx, m.Ny)
+		}
+		for i := range vals {
+			vals[i] *= m.Values[i%n]
+		}
+	}
+	return nil
+}
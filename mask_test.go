@@ -0,0 +1,98 @@
+package uam_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ctessum/uam"
+	"github.com/ctessum/uam/uamtest"
+)
+
+func TestMask(t *testing.T) {
+	// The ocean fraction increases by a quarter with each column.
+	ocean, err := uamtest.Average(uamtest.Options{Hours: 1, Species: []string{"OCEAN"},
+		Pattern: uamtest.Linear(0, 0, 0, 0, 0.25)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := uam.ReadMask(uamtest.TempFile(t, ocean), "OCEAN")
+	if err != nil {
+		t.Fatal(err)
+	}
+	row := []float32{0, 0.25, 0.5, 0.75}
+	want := append(append(append([]float32{}, row...), row...), row...)
+	if m.Nx != 4 || m.Ny != 3 || !reflect.DeepEqual(m.Values, want) {
+		t.Fatalf("got %+v, want values %v", m, want)
+	}
+	if got := m.Invert().Values[:4]; !reflect.DeepEqual(got, []float32{1, 0.75, 0.5, 0.25}) {
+		t.Errorf("inverted: got %v", got)
+	}
+	if got := m.Threshold(0.5).Values[:4]; !reflect.DeepEqual(got, []float32{0, 0, 1, 1}) {
+		t.Errorf("threshold: got %v", got)
+	}
+
+	// Apply scales every layer of the given species.
+	data := map[string][]float32{"NA": make([]float32, 24), "NO": make([]float32, 24)}
+	for _, vals := range data {
+		for i := range vals {
+			vals[i] = 2
+		}
+	}
+	if err = m.Apply(data, uam.MarineSpecies); err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range data["NA"] {
+		if want := 2 * row[i%4]; v != want {
+			t.Errorf("NA %d: got %g, want %g", i, v, want)
+		}
+	}
+	if data["NO"][0] != 2 {
+		t.Errorf("NO was masked: got %g", data["NO"][0])
+	}
+	if err = m.Apply(map[string][]float32{"NA": make([]float32, 5)}, []string{"NA"}); err == nil {
+		t.Error("wrong length: got no error")
+	}
+
+	if _, err = uam.NewMask(4, 3, make([]float32, 11)); err == nil {
+		t.Error("NewMask with wrong length: got no error")
+	}
+}
+
+func TestLanduseMask(t *testing.T) {
+	// A 2x1 grid with 3 categories.
+	fsurf := [][]float32{{0.5, 0}, {0.25, 0.5}, {0.25, 0.75}}
+	var b bytes.Buffer
+	binary.Write(&b, uam.ByteOrder, int32(0)) // record marker
+	for _, cat := range fsurf {
+		binary.Write(&b, uam.ByteOrder, cat)
+	}
+	filename := filepath.Join(t.TempDir(), "landuse.bin")
+	if err := os.WriteFile(filename, b.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := uam.ReadLanduse(filename, 2, 1, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, fsurf) {
+		t.Fatalf("got %v, want %v", got, fsurf)
+	}
+	if _, err = uam.ReadLanduse(filename, 2, 1, 4); err == nil {
+		t.Error("too many categories: got no error")
+	}
+
+	m, err := uam.LanduseMask(fsurf, 2, 1, 2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(m.Values, []float32{0.5, 1}) {
+		t.Errorf("got %v, want [0.5 1]", m.Values)
+	}
+	if _, err = uam.LanduseMask(fsurf, 2, 1, 4); err == nil {
+		t.Error("category out of range: got no error")
+	}
+}
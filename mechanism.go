@@ -0,0 +1,162 @@
+package uam
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// aerosolSpecies are the CAMx CF aerosol species, which are shared by
+// all of the gas-phase mechanisms below.
+var aerosolSpecies = []string{
+	"PSO4", "PNO3", "PNH4", "POA", "PEC", "FPRM", "FCRS", "CPRM", "CCRS",
+	"NA", "PCL", "PH2O", "SOA1", "SOA2", "SOA3", "SOA4", "SOA5", "SOA6",
+	"SOA7", "SOPA", "SOPB",
+}
+
+// IsAerosol returns whether spname is a CAMx aerosol species. Aerosol
+// emissions are in g/hr, whereas gas-phase emissions are in mol/hr.
+func IsAerosol(spname string) bool {
+	for _, a := range aerosolSpecies {
+		if spname == a {
+			return true
+		}
+	}
+	return false
+}
+
+// Mechanisms holds the species names that are valid for each supported
+// chemical mechanism, keyed by mechanism name. Users may add their own
+// mechanisms or extend the built-in lists.
+var Mechanisms = map[string][]string{
+	"CB05": append([]string{
+		"NO", "NO2", "O3", "HONO", "CO", "SO2", "SULF", "NH3", "PAR", "OLE",
+		"TOL", "XYL", "FORM", "ALD2", "ALDX", "ETH", "ETHA", "ETOH", "MEOH",
+		"IOLE", "ISOP", "TERP", "CL2", "HCL", "NO3", "N2O5", "HNO3", "PNA",
+		"PAN", "PANX", "H2O2", "NTR", "ROOH", "MEPX", "FACD", "AACD", "PACD",
+		"CRES", "OPEN", "MGLY", "ISPD", "CH4",
+	}, aerosolSpecies...),
+	"CB6r5": append([]string{
+		"NO", "NO2", "O3", "HONO", "CO", "SO2", "SULF", "NH3", "PAR", "OLE",
+		"TOL", "XYL", "FORM", "ALD2", "ALDX", "ETH", "ETHA", "ETOH", "MEOH",
+		"IOLE", "ISOP", "TERP", "BENZ", "ETHY", "PRPA", "KET", "ACET", "CH4",
+		"NVOL", "UNR", "CL2", "HCL", "I2", "HOI", "DMS", "NO3", "N2O5",
+		"HNO3", "PNA", "PAN", "PANX", "OPAN", "H2O2", "NTR1", "NTR2", "INTR",
+		"ROOH", "MEPX", "FACD", "AACD", "PACD", "CRES", "CRON", "CAT1",
+		"OPEN", "XOPN", "GLY", "GLYD", "MGLY", "ISPD", "ISPX", "EPOX", "HPLD",
+	}, aerosolSpecies...),
+	"SAPRC07TC": append([]string{
+		"NO", "NO2", "O3", "HONO", "CO", "SO2", "SULF", "NH3", "HCHO", "CCHO",
+		"RCHO", "ACET", "MEK", "ACYE", "ETHE", "PRPE", "BD13", "BENZ", "TOLU",
+		"MXYL", "OXYL", "PXYL", "B124", "ISOP", "TERP", "SESQ", "ALK1",
+		"ALK2", "ALK3", "ALK4", "ALK5", "ARO1", "ARO2", "OLE1", "OLE2",
+		"MEOH", "ETOH", "CH4", "GLY", "MGLY", "BACL", "CRES", "ACRO", "MACR",
+		"MVK", "IPRD", "PRD2", "HCOOH", "CCOOH", "RCOOH", "NO3", "N2O5",
+		"HNO3", "HNO4", "PAN", "PAN2", "PBZN", "MAPAN", "H2O2", "RNO3",
+	}, aerosolSpecies...),
+}
+
+// UnknownSpecies is a species name that is not part of a mechanism.
+type UnknownSpecies struct {
+	Name       string
+	Suggestion string // closest valid name, or "" if none is close
+}
+
+// SpeciesError is returned by ValidateSpecies when one or more species
+// names are not part of the requested mechanism.
+type SpeciesError struct {
+	Mechanism string
+	Unknown   []UnknownSpecies
+}
+
+func (e *SpeciesError) Error() string {
+	msgs := make([]string, len(e.Unknown))
+	for i, u := range e.Unknown {
+		if u.Suggestion != "" {
+			msgs[i] = fmt.Sprintf("%v (did you mean %v?)", u.Name, u.Suggestion)
+		} else {
+			msgs[i] = u.Name
+		}
+	}
+	return fmt.Sprintf("species not in mechanism %v: %v",
+		e.Mechanism, strings.Join(msgs, ", "))
+}
+
+// ValidateSpecies checks names against the species of the named
+// mechanism (a key of Mechanisms). If any names are not recognized, the
+// returned error is a *SpeciesError that suggests the closest valid name
+// for near-misses such as "N02" for "NO2".
+func ValidateSpecies(mechanism string, names []string) error {
+	valid, ok := Mechanisms[mechanism]
+	if !ok {
+		known := make([]string, 0, len(Mechanisms))
+		for m := range Mechanisms {
+			known = append(known, m)
+		}
+		sort.Strings(known)
+		return fmt.Errorf("unknown mechanism %v; valid options are %v",
+			mechanism, strings.Join(known, ", "))
+	}
+	validSet := make(map[string]bool, len(valid))
+	for _, v := range valid {
+		validSet[v] = true
+	}
+	var unknown []UnknownSpecies
+	for _, name := range names {
+		if !validSet[name] {
+			unknown = append(unknown, UnknownSpecies{
+				Name:       name,
+				Suggestion: suggestSpecies(name, valid),
+			})
+		}
+	}
+	if len(unknown) > 0 {
+		return &SpeciesError{Mechanism: mechanism, Unknown: unknown}
+	}
+	return nil
+}
+
+// ValidateSpecies checks the species in the file against the named
+// mechanism. See the ValidateSpecies function for details.
+func (f *UAM) ValidateSpecies(mechanism string) error {
+	return ValidateSpecies(mechanism, f.Spnames)
+}
+
+// speciesConfusions maps characters that are commonly typed in place of
+// each other in species names.
+var speciesConfusions = strings.NewReplacer("0", "O", "1", "I", "5", "S")
+
+// suggestSpecies returns the valid name closest to name, or "" if no
+// valid name is within two edits.
+func suggestSpecies(name string, valid []string) string {
+	norm := speciesConfusions.Replace(strings.ToUpper(name))
+	best, bestDist := "", 3
+	for _, v := range valid {
+		d := editDistance(norm, speciesConfusions.Replace(v))
+		if d < bestDist {
+			best, bestDist = v, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,220 @@
+package uam
+
+import (
+	"fmt"
+	"io"
+)
+
+// Header holds the file-level information that describes a UAM file.
+type Header struct {
+	Name   string  // file type, e.g. "EMISSIONS" or "AVERAGE"
+	Note   string  // file description
+	Sdate  int32   // start date (YYJJJ)
+	Begtim float32 // start hour
+	Edate  int32   // end date (YYJJJ)
+	Endtim float32 // end hour
+	Orgx   float32 // Center
+	Orgy   float32 // Center
+	Iutm   int32   // UTM zone
+	Utmx   float32 // SW corner
+	Utmy   float32 // SW corner
+	Dx     float32 // grid size
+	Dy     float32 // grid size
+	Nx     int32   // number of cells
+	Ny     int32   // number of cells
+	Nz     int32   // number of layers
+	Nzlo   int32
+	Nzup   int32
+	Hts    float32
+	Htl    float32
+	Htu    float32
+}
+
+// Header returns the file-level information of f.
+func (f *UAM) Header() Header {
+	return Header{
+		Name: f.Name, Note: f.Note,
+		Sdate: f.sdate, Begtim: f.begtim, Edate: f.edate, Endtim: f.endtim,
+		Orgx: f.orgx, Orgy: f.orgy, Iutm: f.iutm, Utmx: f.Utmx, Utmy: f.Utmy,
+		Dx: f.Dx, Dy: f.Dy, Nx: f.Nx, Ny: f.Ny, Nz: f.Nz,
+		Nzlo: f.Nzlo, Nzup: f.Nzup, Hts: f.hts, Htl: f.htl, Htu: f.htu,
+	}
+}
+
+// setHeader copies the information in h to f.
+func (f *UAM) setHeader(h Header) {
+	f.Name, f.Note = h.Name, h.Note
+	f.nseg = 1
+	f.sdate, f.begtim, f.edate, f.endtim = h.Sdate, h.Begtim, h.Edate, h.Endtim
+	f.orgx, f.orgy, f.iutm = h.Orgx, h.Orgy, h.Iutm
+	f.Utmx, f.Utmy, f.Dx, f.Dy = h.Utmx, h.Utmy, h.Dx, h.Dy
+	f.Nx, f.Ny, f.Nz = h.Nx, h.Ny, h.Nz
+	f.Nzlo, f.Nzup, f.hts, f.htl, f.htu = h.Nzlo, h.Nzup, h.Hts, h.Htl, h.Htu
+}
+
+// Hour holds one hour of data.
+type Hour struct {
+	Date int32   // start date (YYJJJ)
+	Time float32 // start hour
+	// Data holds the values for each species, in the same
+	// format as the Data argument to ReadHour.
+	Data map[string][]float32
+	// Overrides holds the override record of each stack of a PTSOURCE
+	// file, or nil to write zeros. See StackOverride.
+	Overrides []StackOverride
+
+	// Size and SW cell of the grid of a gridded hour, which is the
+	// window set with SetWindow for hours that were read with one.
+	nx, ny, i1, j1 int32
+}
+
+// Surface returns the ground-level (k = 0) values of the named species
+// in h, with Nx*Ny values in which cell (i, j) is at index j*Nx+i, where
+// the grid is the window if h was read with one. The returned slice
+// shares its values with h.Data. It returns nil if the species is not in
+// h or h is not from a gridded file.
+func (h *Hour) Surface(species string) []float32 {
+	vals := h.Data[species]
+	n := int(h.nx * h.ny)
+	if n == 0 || len(vals) < n {
+		return nil
+	}
+	return vals[:n:n]
+}
+
+// NewGridded creates an empty gridded (e.g., EMISSIONS or AVERAGE)
+// file in memory with the given header information and species. Hours
+// of data can then be added with AddHour, and the file can be written
+// with Write or WriteFile.
+func NewGridded(h Header, species []string) (*UAM, error) {
+	switch h.Name {
+	case "EMISSIONS", "AVERAGE", "AIRQUALITY", "INSTANT":
+	default:
+		return nil, fmt.Errorf("%v is not a gridded file type", h.Name)
+	}
+	if h.Nx <= 0 || h.Ny <= 0 || h.Nz <= 0 {
+		return nil, fmt.Errorf("invalid grid dimensions %dx%dx%d",
+			h.Nx, h.Ny, h.Nz)
+	}
+	if len(species) == 0 {
+		return nil, fmt.Errorf("no species")
+	}
+	f := new(UAM)
+	f.setHeader(h)
+	f.Nspec = int32(len(species))
+	f.Spnames = append([]string(nil), species...)
+	return f, nil
+}
+
+// NewPointSource creates an empty PTSOURCE file in memory with the
+// given header information, species, and stacks. Hours of data can then
+// be added with AddHour, and the file can be written with Write or
+// WriteFile.
+func NewPointSource(h Header, species []string, stacks []Stack) (*UAM, error) {
+	if h.Name != "PTSOURCE" {
+		return nil, fmt.Errorf("%v is not a PTSOURCE file", h.Name)
+	}
+	if len(species) == 0 {
+		return nil, fmt.Errorf("no species")
+	}
+	if len(stacks) == 0 {
+		return nil, fmt.Errorf("no stacks")
+	}
+	f := new(UAM)
+	f.setHeader(h)
+	f.Nspec = int32(len(species))
+	f.Spnames = append([]string(nil), species...)
+	if err := f.SetStacks(stacks); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// AddHour appends an hour of data to a file that is held in memory.
+// Data must hold an array for every species in the file, with
+// Nx*Ny*Nz values for gridded files or Npts values for PTSOURCE files.
+// The time of the hour is calculated from the start time in the header.
+func (f *UAM) AddHour(Data map[string][]float32) error {
+	n := int(f.Nx * f.Ny * f.Nz)
+	if f.Name == "PTSOURCE" {
+		n = int(f.Npts)
+	}
+	for _, spname := range f.Spnames {
+		if len(Data[spname]) != n {
+			return fmt.Errorf("species %v has %d values; it should have %d",
+				spname, len(Data[spname]), n)
+		}
+	}
+	date, time := AddHours(f.sdate, f.begtim, len(f.Hours))
+	h := &Hour{Date: date, Time: time, Data: Data}
+	if f.Name != "PTSOURCE" {
+		h.nx, h.ny = f.Nx, f.Ny
+	}
+	f.Hours = append(f.Hours, h)
+	f.Nhrs = int32(len(f.Hours))
+	return nil
+}
+
+// Write writes the header and the hours held in memory to w.
+func (f *UAM) Write(w io.Writer) error {
+	wr, err := NewWriter(w, f)
+	if err != nil {
+		return err
+	}
+	for _, h := range f.Hours {
+		if err = wr.WriteHourOverrides(h.Data, h.Overrides); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteFile writes the header and the hours held in memory to a file
+// called filename.
+func (f *UAM) WriteFile(filename string) error {
+	w, err := Create(filename, f)
+	if err != nil {
+		return err
+	}
+	for _, h := range f.Hours {
+		if err = w.WriteHourOverrides(h.Data, h.Overrides); err != nil {
+			w.Close()
+			return err
+		}
+	}
+	return w.Close()
+}
+
+// NewLike creates a file in memory with the same header information,
+// species, and (for PTSOURCE files) stack parameters as existing, so
+// that derived files inherit all of its metadata. No hours are copied;
+// instead, if nhours is greater than zero, that many hours of
+// zero-filled data are added so that values can be filled in directly.
+func NewLike(existing *UAM, nhours int) *UAM {
+	f := new(UAM)
+	f.setHeader(existing.Header())
+	f.Nspec = existing.Nspec
+	f.Spnames = append([]string(nil), existing.Spnames...)
+	f.Npts = existing.Npts
+	if existing.Name == "PTSOURCE" {
+		f.Xcoord = append([]float32(nil), existing.Xcoord...)
+		f.Ycoord = append([]float32(nil), existing.Ycoord...)
+		f.StackHeight = append([]float32(nil), existing.StackHeight...)
+		f.StackDiam = append([]float32(nil), existing.StackDiam...)
+		f.StackTemp = append([]float32(nil), existing.StackTemp...)
+		f.StackVel = append([]float32(nil), existing.StackVel...)
+	}
+	n := int(f.Nx * f.Ny * f.Nz)
+	if f.Name == "PTSOURCE" {
+		n = int(f.Npts)
+	}
+	for h := 0; h < nhours; h++ {
+		data := make(map[string][]float32, len(f.Spnames))
+		for _, spname := range f.Spnames {
+			data[spname] = make([]float32, n)
+		}
+		// The sizes are correct by construction.
+		f.AddHour(data)
+	}
+	return f
+}
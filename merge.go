@@ -0,0 +1,264 @@
+package uam
+
+import (
+	"fmt"
+	"io"
+)
+
+// MergeEmissions sums gridded EMISSIONS files, such as the emissions
+// from different sectors, cell by cell and writes the result to a file
+// called outfile. The files must have the same grid, species, and time
+// span; the header of the merged file is copied from the first file.
+// See SumEmissions to combine files with different species.
+func MergeEmissions(outfile string, filenames []string) error {
+	files, err := openEmissions(filenames, false)
+	defer closeAll(files)
+	if err != nil {
+		return err
+	}
+	w, err := Create(outfile, NewLike(files[0], 0))
+	if err != nil {
+		return err
+	}
+	if err = sumHours(files, filenames, w.f.Spnames, w.WriteHour); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// SumEmissions sums gridded EMISSIONS files cell by cell like
+// MergeEmissions, but returns the result as a file held in memory. If
+// union is true, the files may have different species: the sum has the
+// species of the first file followed by any other species in the order
+// they appear in the other files, and files without a species contribute
+// nothing to it. Otherwise the files must have the same species.
+func SumEmissions(filenames []string, union bool) (*UAM, error) {
+	files, err := openEmissions(filenames, union)
+	defer closeAll(files)
+	if err != nil {
+		return nil, err
+	}
+	sum := NewLike(files[0], 0)
+	sum.Spnames = unionSpecies(files)
+	sum.Nspec = int32(len(sum.Spnames))
+	if err = sumHours(files, filenames, sum.Spnames, sum.AddHour); err != nil {
+		return nil, err
+	}
+	return sum, nil
+}
+
+// openEmissions opens the EMISSIONS files called filenames and checks
+// that they have the same grid and time span and, unless union is true,
+// species. The returned files must be closed even if there is an error.
+func openEmissions(filenames []string, union bool) ([]*UAM, error) {
+	if len(filenames) == 0 {
+		return nil, fmt.Errorf("no files to merge")
+	}
+	var files []*UAM
+	for i, filename := range filenames {
+		f, err := Open(filename)
+		if err != nil {
+			return files, err
+		}
+		files = append(files, f)
+		if f.Name != "EMISSIONS" {
+			return files, fmt.Errorf("%v: cannot merge %v files", filename, f.Name)
+		}
+		if i == 0 {
+			continue
+		}
+		if union {
+			err = files[0].GridDef().Check(f.GridDef())
+			if err == nil {
+				err = sameTime(files[0], f)
+			}
+		} else {
+			err = sameGridAndTime(files[0], f)
+		}
+		if err != nil {
+			return files, fmt.Errorf("%v does not match %v: %w", filename, filenames[0], err)
+		}
+	}
+	return files, nil
+}
+
+// closeAll closes each of files.
+func closeAll(files []*UAM) {
+	for _, f := range files {
+		f.Close()
+	}
+}
+
+// unionSpecies returns the species of the first of files followed by
+// any other species in the order they appear in the rest.
+func unionSpecies(files []*UAM) []string {
+	var species []string
+	have := make(map[string]bool)
+	for _, f := range files {
+		for _, spname := range f.Spnames {
+			if !have[spname] {
+				species = append(species, spname)
+				have[spname] = true
+			}
+		}
+	}
+	return species
+}
+
+// sumHours passes the sum of the named species in each hour of files to
+// emit, in a new map for each hour.
+func sumHours(files []*UAM, filenames []string, species []string, emit func(map[string][]float32) error) error {
+	n := int(files[0].Nx * files[0].Ny * files[0].Nz)
+	data := make(map[string][]float32)
+	for {
+		sum := make(map[string][]float32, len(species))
+		for _, spname := range species {
+			sum[spname] = make([]float32, n)
+		}
+		for i, f := range files {
+			err := f.readHour(data)
+			if err == io.EOF {
+				if i == 0 {
+					return checkEOF(files[1:], filenames[1:], filenames[0])
+				}
+				return fmt.Errorf("%v has fewer hours than %v", filenames[i], filenames[0])
+			} else if err != nil {
+				return err
+			}
+			for _, spname := range f.Spnames {
+				s := sum[spname]
+				for j, v := range data[spname] {
+					s[j] += v
+				}
+			}
+		}
+		if err := emit(sum); err != nil {
+			return err
+		}
+	}
+}
+
+// sameGridAndTime returns an error if gridded files a and b do not have
+// the same grid, species, and time span.
+func sameGridAndTime(a, b *UAM) error {
+	if err := sameStructure(a, b); err != nil {
+		return err
+	}
+	if err := a.GridDef().Check(b.GridDef()); err != nil {
+		return err
+	}
+	return sameTime(a, b)
+}
+
+// sameTime returns an error if files a and b do not have the same time
+// span.
+func sameTime(a, b *UAM) error {
+	if a.sdate != b.sdate || a.begtim != b.begtim || a.edate != b.edate ||
+		a.endtim != b.endtim {
+		return fmt.Errorf("time span %d %g to %d %g does not match %d %g to %d %g",
+			b.sdate, b.begtim, b.edate, b.endtim, a.sdate, a.begtim, a.edate, a.endtim)
+	}
+	return nil
+}
+
+// MergePointSources combines PTSOURCE files, such as the point sources
+// of different sectors, into a file called outfile that holds the stacks
+// of each file in turn, along with their emissions and override records
+// for each hour. The files must have the same grid and time span. The
+// merged file has the species of the first file followed by any other
+// species in the order they appear in the other files; stacks from files
+// without a species have zero emissions of it. The rest of the header is
+// copied from the first file.
+func MergePointSources(outfile string, filenames []string) error {
+	if len(filenames) == 0 {
+		return fmt.Errorf("no files to merge")
+	}
+	files := make([]*UAM, len(filenames))
+	for i, filename := range filenames {
+		f, err := Open(filename)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if f.Name != "PTSOURCE" {
+			return fmt.Errorf("%v: %v is not a PTSOURCE file", filename, f.Name)
+		}
+		if i > 0 {
+			err = files[0].GridDef().Check(f.GridDef())
+			if err == nil {
+				err = sameTime(files[0], f)
+			}
+			if err != nil {
+				return fmt.Errorf("%v does not match %v: %w", filename, filenames[0], err)
+			}
+		}
+		files[i] = f
+	}
+	merged := NewLike(files[0], 0)
+	merged.Spnames = unionSpecies(files)
+	var stacks []Stack
+	for _, f := range files {
+		stacks = append(stacks, f.Stacks()...)
+	}
+	merged.Nspec = int32(len(merged.Spnames))
+	if err := merged.SetStacks(stacks); err != nil {
+		return err
+	}
+	w, err := Create(outfile, merged)
+	if err != nil {
+		return err
+	}
+	if err = mergePointHours(w, files, filenames); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// mergePointHours writes each hour of PTSOURCE files, with their stacks
+// one after another, to w.
+func mergePointHours(w *Writer, files []*UAM, filenames []string) error {
+	for {
+		data := make(map[string][]float32, len(w.f.Spnames))
+		for _, spname := range w.f.Spnames {
+			data[spname] = make([]float32, 0, w.f.Npts)
+		}
+		var overrides []StackOverride
+		for i, f := range files {
+			h, err := f.ReadNextHour()
+			if err == io.EOF {
+				if i == 0 {
+					return checkEOF(files[1:], filenames[1:], filenames[0])
+				}
+				return fmt.Errorf("%v has fewer hours than %v", filenames[i], filenames[0])
+			} else if err != nil {
+				return err
+			}
+			for spname := range data {
+				vals := h.Data[spname]
+				if vals == nil {
+					vals = make([]float32, f.Npts)
+				}
+				data[spname] = append(data[spname], vals...)
+			}
+			overrides = append(overrides, h.Overrides...)
+		}
+		if err := w.WriteHourOverrides(data, overrides); err != nil {
+			return err
+		}
+	}
+}
+
+// checkEOF returns an error if any of files has hours left to read after
+// the last hour of the file called first.
+func checkEOF(files []*UAM, filenames []string, first string) error {
+	for i, f := range files {
+		if _, err := f.ReadNextHour(); err == nil {
+			return fmt.Errorf("%v has more hours than %v", filenames[i], first)
+		} else if err != io.EOF {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package uam_test
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ctessum/uam"
+	"github.com/ctessum/uam/uamtest"
+)
+
+func TestMergeEmissions(t *testing.T) {
+	a, err := uamtest.Emissions(uamtest.Options{Hours: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := uamtest.Emissions(uamtest.Options{Hours: 2, Pattern: uamtest.Constant(0.5)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(t.TempDir(), "merged.uam")
+	if err = uam.MergeEmissions(out, []string{uamtest.TempFile(t, a), uamtest.TempFile(t, b)}); err != nil {
+		t.Fatal(err)
+	}
+	r, err := uam.Open(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if r.Header() != a.Header() {
+		t.Errorf("got header %+v, want %+v", r.Header(), a.Header())
+	}
+	hours := readAll(t, r)
+	for h, hr := range hours {
+		for spname, vals := range hr.Data {
+			for i, v := range vals {
+				if want := a.Hours[h].Data[spname][i] + 0.5; v != want {
+					t.Fatalf("hour %d %v[%d]: got %g, want %g", h, spname, i, v, want)
+				}
+			}
+		}
+	}
+
+	c, err := uamtest.Emissions(uamtest.Options{Hours: 2, Species: []string{"NO", "CO"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = uam.MergeEmissions(out, []string{uamtest.TempFile(t, a), uamtest.TempFile(t, c)})
+	if !errors.Is(err, uam.ErrSpeciesMismatch) {
+		t.Errorf("different species: got %v, want ErrSpeciesMismatch", err)
+	}
+	d, err := uamtest.Emissions(uamtest.Options{Hours: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = uam.MergeEmissions(out, []string{uamtest.TempFile(t, a), uamtest.TempFile(t, d)}); err == nil {
+		t.Error("different time spans: got no error")
+	}
+}
+
+func TestMergePointSources(t *testing.T) {
+	a, err := uamtest.PointSource(uamtest.Options{Hours: 2, Species: []string{"NO", "SO2"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stacks := uamtest.DefaultStacks(uamtest.DefaultGrid)[:1]
+	b, err := uamtest.PointSource(uamtest.Options{Hours: 2, Species: []string{"CO"}, Stacks: stacks})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.Hours[1].Overrides = []uam.StackOverride{{I: 1, J: 2, K: 1}}
+	out := filepath.Join(t.TempDir(), "merged.uam")
+	if err = uam.MergePointSources(out, []string{uamtest.TempFile(t, a), uamtest.TempFile(t, b)}); err != nil {
+		t.Fatal(err)
+	}
+	r, err := uam.Open(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if want := []string{"NO", "SO2", "CO"}; !reflect.DeepEqual(r.Spnames, want) {
+		t.Fatalf("got species %v, want %v", r.Spnames, want)
+	}
+	if want := append(a.Stacks(), b.Stacks()...); !reflect.DeepEqual(r.Stacks(), want) {
+		t.Errorf("got stacks %v, want %v", r.Stacks(), want)
+	}
+	hours := readAll(t, r)
+	for h, hr := range hours {
+		want := map[string][]float32{
+			"NO":  append(a.Hours[h].Data["NO"], 0),
+			"SO2": append(a.Hours[h].Data["SO2"], 0),
+			"CO":  append([]float32{0, 0, 0}, b.Hours[h].Data["CO"]...),
+		}
+		if !reflect.DeepEqual(hr.Data, want) {
+			t.Errorf("hour %d: got %v, want %v", h, hr.Data, want)
+		}
+	}
+	if got := hours[1].Overrides[3]; got != b.Hours[1].Overrides[0] {
+		t.Errorf("got override %+v for the last stack, want %+v", got, b.Hours[1].Overrides[0])
+	}
+}
+
+func TestSumEmissions(t *testing.T) {
+	a, err := uamtest.Emissions(uamtest.Options{Hours: 1, Species: []string{"NO", "NO2"},
+		Pattern: uamtest.Constant(1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := uamtest.Emissions(uamtest.Options{Hours: 1, Species: []string{"CO", "NO"},
+		Pattern: uamtest.Constant(2)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := []string{uamtest.TempFile(t, a), uamtest.TempFile(t, b)}
+	if _, err = uam.SumEmissions(files, false); err == nil {
+		t.Error("different species without union: got no error")
+	}
+	sum, err := uam.SumEmissions(files, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"NO", "NO2", "CO"}; !reflect.DeepEqual(sum.Spnames, want) {
+		t.Fatalf("got species %v, want %v", sum.Spnames, want)
+	}
+	for spname, want := range map[string]float32{"NO": 3, "NO2": 1, "CO": 2} {
+		for _, v := range sum.Hours[0].Data[spname] {
+			if v != want {
+				t.Fatalf("%v: got %g, want %g", spname, v, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package uam
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+// DailyMax holds the daily maxima of rolling averages of a species in
+// the ground-level layer, as returned by Dataset.RollingMax.
+type DailyMax struct {
+	Nx, Ny int32
+	Date   []int32     // date (YYJJJ) of each day
+	Values [][]float32 // Values[day][j*Nx+i]
+	// Windows is the number of averaging windows that start in each day
+	// and end within the dataset, which is less than 24 for days at the
+	// ends of the dataset. It can be used to apply completeness
+	// criteria, such as requiring 18 of 24 windows.
+	Windows []int
+}
+
+// Max returns the largest value of each cell over all days.
+func (m *DailyMax) Max() []float32 {
+	out := make([]float32, m.Nx*m.Ny)
+	for i := range out {
+		out[i] = float32(math.Inf(-1))
+	}
+	for _, day := range m.Values {
+		for i, v := range day {
+			out[i] = max(out[i], v)
+		}
+	}
+	return out
+}
+
+// RollingMax returns, for each day covered by d, which must be made up
+// of AVERAGE files, and for each grid cell, the largest of the averages
+// of the named species in the ground-level layer over each period of
+// the given number of consecutive hours that starts in that day. Only
+// periods that end within d are used. Days are those of the times in
+// the files, so the files should be in local time for metrics defined
+// on local days.
+func (d *Dataset) RollingMax(species string, hours int) (*DailyMax, error) {
+	if d.hdr.Name != "AVERAGE" {
+		return nil, fmt.Errorf("rolling averages can only be calculated for AVERAGE files")
+	}
+	if !slices.Contains(d.hdr.Spnames, species) {
+		return nil, fmt.Errorf("species %v is not in the dataset", species)
+	}
+	if hours < 1 || hours > d.hours {
+		return nil, fmt.Errorf("cannot average over %d hours in a dataset of %d hours",
+			hours, d.hours)
+	}
+	defer d.Close()
+	m := &DailyMax{Nx: d.hdr.Nx, Ny: d.hdr.Ny}
+	n := int(m.Nx * m.Ny)
+	window := make([][]float32, hours) // surface values of the last hours, by h % hours
+	sum := make([]float64, n)
+	data := make(map[string][]float32)
+	for h := 0; h < d.hours; h++ {
+		if err := d.ReadHour(h, data); err != nil {
+			return nil, err
+		}
+		surf := data[species][:n]
+		old := window[h%hours]
+		for i, v := range surf {
+			sum[i] += float64(v)
+			if old != nil {
+				sum[i] -= float64(old[i])
+			}
+		}
+		window[h%hours] = append(old[:0], surf...)
+		if h < hours-1 {
+			continue
+		}
+		date, _ := d.HourTime(h - hours + 1)
+		day := len(m.Date) - 1
+		if day < 0 || m.Date[day] != date {
+			m.Date = append(m.Date, date)
+			m.Windows = append(m.Windows, 0)
+			vals := make([]float32, n)
+			for i := range vals {
+				vals[i] = float32(math.Inf(-1))
+			}
+			m.Values = append(m.Values, vals)
+			day++
+		}
+		m.Windows[day]++
+		for i, s := range sum {
+			m.Values[day][i] = max(m.Values[day][i], float32(s/float64(hours)))
+		}
+	}
+	return m, nil
+}
+
+// MDA8 returns the maximum daily 8-hour average of the named species,
+// usually O3, in each ground-level grid cell on each day covered by d.
+// See RollingMax.
+func (d *Dataset) MDA8(species string) (*DailyMax, error) {
+	return d.RollingMax(species, 8)
+}
@@ -0,0 +1,66 @@
+package uam_test
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ctessum/uam"
+	"github.com/ctessum/uam/uamtest"
+)
+
+func TestMDA8(t *testing.T) {
+	dir := t.TempDir()
+	// The ground-level values are the number of hours from the start.
+	a, err := uamtest.Average(uamtest.Options{Hours: 48, Pattern: uamtest.Linear(0, 1, 100, 0, 0)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	filename := uamtest.TempFile(t, a)
+	days := []string{filepath.Join(dir, "day1.uam"), filepath.Join(dir, "day2.uam")}
+	for n, day := range days {
+		if err = uam.SplitHours(filename, day, 24*n, 24*(n+1)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	d, err := uam.OpenDataset(days)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := d.MDA8("O3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The last window of the first day starts at hour 23, and that of
+	// the second day at hour 40, so that it ends with the dataset.
+	if !reflect.DeepEqual(m.Date, []int32{15001, 15002}) || !reflect.DeepEqual(m.Windows, []int{24, 17}) {
+		t.Fatalf("got dates %v with %v windows", m.Date, m.Windows)
+	}
+	if m.Nx != 4 || m.Ny != 3 {
+		t.Errorf("got grid %dx%d", m.Nx, m.Ny)
+	}
+	for day, want := range []float32{23 + 3.5, 40 + 3.5} {
+		for i, v := range m.Values[day] {
+			if v != want {
+				t.Fatalf("day %d cell %d: got %g, want %g", day, i, v, want)
+			}
+		}
+	}
+	if got := m.Max(); got[0] != 43.5 || len(got) != 12 {
+		t.Errorf("got maximum %v, want 43.5 in each of 12 cells", got)
+	}
+
+	for _, test := range []struct {
+		species string
+		hours   int
+	}{{"CO", 8}, {"O3", 0}, {"O3", 49}} {
+		d, err := uam.OpenDataset(days)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = d.RollingMax(test.species, test.hours); err == nil {
+			t.Errorf("%v over %d hours: got no error", test.species, test.hours)
+		}
+		d.Close()
+	}
+}
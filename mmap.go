@@ -0,0 +1,39 @@
+package uam
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+)
+
+// OpenMmap opens a file for reading and reads the header info, like
+// Open, but maps the whole file into memory instead of reading it
+// through a buffer. This is faster when hours and species are accessed
+// repeatedly or out of order, because data are decoded directly from the
+// mapped memory without system calls or copying. On systems where memory
+// mapping is not supported, the file is read into memory instead.
+// Compressed files cannot be mapped; use Open to read them instead.
+func OpenMmap(filename string, opts ...Option) (*UAM, error) {
+	fid, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	// The mapping remains valid after the file is closed.
+	data, unmap, err := mmapFile(fid)
+	fid.Close()
+	if err != nil {
+		return nil, err
+	}
+	if bytes.HasPrefix(data, gzipMagic) || bytes.HasPrefix(data, zstdMagic) {
+		unmap()
+		return nil, fmt.Errorf("%v is compressed and cannot be memory "+
+			"mapped", filename)
+	}
+	f, err := newMemReader(data, opts)
+	if err != nil {
+		unmap()
+		return nil, err
+	}
+	f.unmap, f.name = unmap, filename
+	return f, nil
+}
@@ -0,0 +1,18 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package uam
+
+import (
+	"io"
+	"os"
+)
+
+// mmapFile reads the contents of fid into memory on systems that do
+// not support memory mapping.
+func mmapFile(fid *os.File) ([]byte, func() error, error) {
+	data, err := io.ReadAll(fid)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, func() error { return nil }, nil
+}
@@ -0,0 +1,26 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package uam
+
+import (
+	"os"
+	"syscall"
+)
+
+// mmapFile maps the contents of fid into memory and returns them
+// along with a function that unmaps them.
+func mmapFile(fid *os.File) ([]byte, func() error, error) {
+	info, err := fid.Stat()
+	if err != nil {
+		return nil, nil, err
+	}
+	if info.Size() == 0 {
+		return []byte{}, func() error { return nil }, nil
+	}
+	data, err := syscall.Mmap(int(fid.Fd()), 0, int(info.Size()),
+		syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, func() error { return syscall.Munmap(data) }, nil
+}
@@ -0,0 +1,230 @@
+package uam
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+)
+
+// Distribution is a probability distribution of emission scaling
+// factors.
+type Distribution int
+
+const (
+	// Normal factors have a mean of 1 and are truncated at 0 so
+	// that emissions do not become negative.
+	Normal Distribution = iota
+	// Lognormal factors have a mean of 1 and are always positive.
+	Lognormal
+)
+
+// Perturbation describes the uncertainty of the emissions of one
+// species.
+type Perturbation struct {
+	Distribution Distribution
+	// Sigma is the standard deviation of the scaling factors, relative
+	// to the emissions (for example, 0.3 for 30%).
+	Sigma float64
+	// CorrelationLength is the distance, in the same units as the
+	// grid cell size, over which the factors are correlated. If it is
+	// 0 the factor for each grid cell (or stack) is independent, and
+	// if it is +Inf the same factor is applied everywhere. Finite,
+	// nonzero lengths are only supported for gridded files.
+	CorrelationLength float64
+}
+
+// MonteCarlo writes n realizations of the base file for ensemble
+// sensitivity runs. In each realization the emissions of each species
+// in perturbations are multiplied by random factors drawn from its
+// distribution, which are the same for every hour and layer; other
+// species are left unchanged. Sector contributions stored as tagged
+// species (see TagName) can be perturbed separately by using the tagged
+// species names. Realization i, counting from 0, is written to the file
+// named fmt.Sprintf(pattern, i). The same seed gives the same
+// realizations. It returns the names of the files that were written.
+func MonteCarlo(base, pattern string, n int, perturbations map[string]Perturbation, seed uint64) ([]string, error) {
+	b, err := Open(base)
+	if err != nil {
+		return nil, err
+	}
+	defer b.Close()
+	for spname, p := range perturbations {
+		found := false
+		for _, s := range b.Spnames {
+			found = found || s == spname
+		}
+		if !found {
+			return nil, fmt.Errorf("species %v is not in %v", spname, base)
+		}
+		if p.Sigma < 0 {
+			return nil, fmt.Errorf("species %v has negative sigma %g",
+				spname, p.Sigma)
+		}
+		if b.Name == "PTSOURCE" && p.CorrelationLength != 0 &&
+			!math.IsInf(p.CorrelationLength, 1) {
+			return nil, fmt.Errorf("species %v: spatial correlation is "+
+				"not supported for PTSOURCE files", spname)
+		}
+	}
+
+	// Draw the factors for all realizations before reading the data,
+	// in a fixed order so that results are reproducible.
+	rng := rand.New(rand.NewPCG(seed, 0))
+	factors := make([]map[string][]float32, n)
+	filenames := make([]string, n)
+	writers := make([]*Writer, n)
+	defer func() {
+		for _, w := range writers {
+			if w != nil {
+				w.Close()
+			}
+		}
+	}()
+	for i := range factors {
+		factors[i] = make(map[string][]float32)
+		for _, spname := range b.Spnames {
+			if p, ok := perturbations[spname]; ok {
+				factors[i][spname] = b.randomFactors(rng, p)
+			}
+		}
+		filenames[i] = fmt.Sprintf(pattern, i)
+		if writers[i], err = Create(filenames[i], b); err != nil {
+			return nil, err
+		}
+	}
+
+	data := make(map[string][]float32)
+	out := make(map[string][]float32)
+	for {
+		_, _, _, _, _, _, err = b.ReadHour(data)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		for i, w := range writers {
+			for spname, vals := range data {
+				f, ok := factors[i][spname]
+				if !ok {
+					out[spname] = vals
+					continue
+				}
+				scaled := reuse(out[spname], len(vals))
+				for j, v := range vals {
+					scaled[j] = v * f[j%len(f)]
+				}
+				out[spname] = scaled
+			}
+			if err = w.WriteHour(out); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for i, w := range writers {
+		writers[i] = nil
+		if err = w.Close(); err != nil {
+			return nil, err
+		}
+	}
+	return filenames, nil
+}
+
+// randomFactors returns a field of scaling factors drawn from p, with
+// one value per stack for PTSOURCE files or per horizontal grid cell for
+// gridded files.
+func (f *UAM) randomFactors(rng *rand.Rand, p Perturbation) []float32 {
+	n := int(f.Nx * f.Ny)
+	if f.Name == "PTSOURCE" {
+		n = int(f.Npts)
+	}
+	var z []float64
+	switch {
+	case math.IsInf(p.CorrelationLength, 1):
+		z = []float64{rng.NormFloat64()}
+	case p.CorrelationLength == 0:
+		z = make([]float64, n)
+		for i := range z {
+			z[i] = rng.NormFloat64()
+		}
+	default:
+		z = correlatedNoise(rng, f.Nx, f.Ny,
+			p.CorrelationLength/float64(f.Dx), p.CorrelationLength/float64(f.Dy))
+	}
+	factors := make([]float32, len(z))
+	for i, v := range z {
+		switch p.Distribution {
+		case Lognormal:
+			s := math.Sqrt(math.Log1p(p.Sigma * p.Sigma))
+			factors[i] = float32(math.Exp(s*v - s*s/2))
+		default:
+			factors[i] = float32(max(1+p.Sigma*v, 0))
+		}
+	}
+	return factors
+}
+
+// correlatedNoise returns an nx by ny field of standard normal values
+// that are spatially correlated, made by smoothing white noise with a
+// Gaussian kernel with standard deviations of lx and ly cells.
+func correlatedNoise(rng *rand.Rand, nx, ny int32, lx, ly float64) []float64 {
+	noise := make([]float64, nx*ny)
+	for i := range noise {
+		noise[i] = rng.NormFloat64()
+	}
+	kx, ky := gaussianKernel(lx), gaussianKernel(ly)
+	// The variance of the smoothed noise is the sum of the squared
+	// kernel weights within the grid, which is less near the edges.
+	vx, vy := kernelVariance(kx, nx), kernelVariance(ky, ny)
+	tmp := make([]float64, len(noise))
+	for j := int32(0); j < ny; j++ {
+		for i := int32(0); i < nx; i++ {
+			var sum float64
+			for d, w := range kx {
+				if ii := i + int32(d-len(kx)/2); ii >= 0 && ii < nx {
+					sum += w * noise[j*nx+ii]
+				}
+			}
+			tmp[j*nx+i] = sum
+		}
+	}
+	for j := int32(0); j < ny; j++ {
+		for i := int32(0); i < nx; i++ {
+			var sum float64
+			for d, w := range ky {
+				if jj := j + int32(d-len(ky)/2); jj >= 0 && jj < ny {
+					sum += w * tmp[jj*nx+i]
+				}
+			}
+			noise[j*nx+i] = sum / math.Sqrt(vx[i]*vy[j])
+		}
+	}
+	return noise
+}
+
+// gaussianKernel returns the weights of a Gaussian kernel with a
+// standard deviation of sigma cells, truncated at three standard
+// deviations.
+func gaussianKernel(sigma float64) []float64 {
+	r := int(math.Ceil(3 * sigma))
+	k := make([]float64, 2*r+1)
+	for d := range k {
+		x := float64(d - r)
+		k[d] = math.Exp(-x * x / (2 * sigma * sigma))
+	}
+	return k
+}
+
+// kernelVariance returns, for each of n cells, the sum of the squared
+// weights of kernel k that fall within the grid.
+func kernelVariance(k []float64, n int32) []float64 {
+	v := make([]float64, n)
+	for i := range v {
+		for d, w := range k {
+			if ii := i + d - len(k)/2; ii >= 0 && ii < int(n) {
+				v[i] += w * w
+			}
+		}
+	}
+	return v
+}
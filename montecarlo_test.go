@@ -0,0 +1,99 @@
+package uam_test
+
+import (
+	"math"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ctessum/uam"
+	"github.com/ctessum/uam/uamtest"
+)
+
+func TestMonteCarlo(t *testing.T) {
+	dir := t.TempDir()
+	base, err := uamtest.Emissions(uamtest.Options{Hours: 3, Pattern: uamtest.Constant(2)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := uamtest.TempFile(t, base)
+	perturbations := map[string]uam.Perturbation{
+		"NO":  {Distribution: uam.Normal, Sigma: 0.3, CorrelationLength: math.Inf(1)},
+		"NO2": {Distribution: uam.Lognormal, Sigma: 0.5},
+	}
+	files, err := uam.MonteCarlo(b, filepath.Join(dir, "a%d.uam"), 2, perturbations, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 || files[1] != filepath.Join(dir, "a1.uam") {
+		t.Fatalf("got files %v", files)
+	}
+
+	var realizations [][]*uam.Hour
+	for _, filename := range files {
+		r, err := uam.Open(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hours := readAll(t, r)
+		r.Close()
+		if len(hours) != 3 {
+			t.Fatalf("%v: got %d hours, want 3", filename, len(hours))
+		}
+		realizations = append(realizations, hours)
+		n := len(hours[0].Data["NO"]) / 2 // values per layer
+		for _, h := range hours {
+			// The factors are the same in every hour and layer.
+			for spname, vals := range h.Data {
+				if !reflect.DeepEqual(vals, hours[0].Data[spname]) {
+					t.Errorf("%v %v: factors differ between hours", filename, spname)
+				}
+				if !reflect.DeepEqual(vals[:n], vals[n:]) {
+					t.Errorf("%v %v: factors differ between layers", filename, spname)
+				}
+			}
+			for _, v := range h.Data["NO"] {
+				if v != h.Data["NO"][0] || v < 0 {
+					t.Fatalf("%v NO: got %v, want one nonnegative factor", filename, h.Data["NO"])
+				}
+			}
+			for _, v := range h.Data["NO2"] {
+				if v <= 0 {
+					t.Fatalf("%v NO2: got %v, want positive values", filename, h.Data["NO2"])
+				}
+			}
+			for _, v := range h.Data["O3"] {
+				if v != 2 {
+					t.Fatalf("%v O3: got %v, want unperturbed values", filename, h.Data["O3"])
+				}
+			}
+		}
+		if no2 := hours[0].Data["NO2"]; no2[0] == no2[1] {
+			t.Errorf("%v NO2: got the same factor for independent cells", filename)
+		}
+	}
+	if reflect.DeepEqual(realizations[0][0].Data, realizations[1][0].Data) {
+		t.Error("realizations are the same")
+	}
+
+	// The same seed gives the same realizations.
+	files, err = uam.MonteCarlo(b, filepath.Join(dir, "b%d.uam"), 1, perturbations, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := uam.Open(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	checkHours(t, readAll(t, r), realizations[0])
+
+	bad := map[string]uam.Perturbation{"CO": {Sigma: 0.1}}
+	if _, err = uam.MonteCarlo(b, filepath.Join(dir, "c%d.uam"), 1, bad, 1); err == nil {
+		t.Error("missing species: got no error")
+	}
+	bad = map[string]uam.Perturbation{"NO": {Sigma: -0.1}}
+	if _, err = uam.MonteCarlo(b, filepath.Join(dir, "c%d.uam"), 1, bad, 1); err == nil {
+		t.Error("negative sigma: got no error")
+	}
+}
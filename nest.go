@@ -0,0 +1,142 @@
+package uam
+
+import (
+	"fmt"
+	"io"
+)
+
+// NestedGrid returns the grid of a CAMx flexi-nest inside of grid g that
+// covers columns i0 through i1-1 and rows j0 through j1-1 of g (counting
+// from 0), with each of those cells divided into mesh by mesh cells. As
+// CAMx requires, the nest also has a ring of buffer cells of the same
+// size around its edge, so it has (i1-i0)*mesh+2 columns and
+// (j1-j0)*mesh+2 rows. The buffer cells must be inside of g, so the nest
+// cannot touch the edge of g.
+func (g GridDef) NestedGrid(i0, i1, j0, j1, mesh int32) (GridDef, error) {
+	if mesh < 1 {
+		return GridDef{}, fmt.Errorf("invalid meshing factor %d", mesh)
+	}
+	if i0 < 1 || j0 < 1 || i1 > g.Nx-1 || j1 > g.Ny-1 || i1 <= i0 || j1 <= j0 {
+		return GridDef{}, fmt.Errorf("nest covering columns %d to %d and rows %d to %d "+
+			"is not inside of the %dx%d grid", i0, i1-1, j0, j1-1, g.Nx, g.Ny)
+	}
+	n := g
+	n.Dx, n.Dy = g.Dx/float32(mesh), g.Dy/float32(mesh)
+	n.Xorig = g.Xorig + float32(i0)*g.Dx - n.Dx
+	n.Yorig = g.Yorig + float32(j0)*g.Dy - n.Dy
+	n.Nx, n.Ny = (i1-i0)*mesh+2, (j1-j0)*mesh+2
+	return n, nil
+}
+
+// NestDown interpolates gridded data from a parent grid onto a finer
+// grid nested inside of it, such as one returned by NestedGrid, to make
+// emissions, initial conditions, or other inputs for the nest from those
+// of the parent. Emissions are divided among the nested cells in
+// proportion to the area that they share with each parent cell, which
+// conserves their totals, as with a Regridder. Other values, such as
+// concentrations, are interpolated bilinearly between the centers of the
+// parent cells, and are the same as the parent values in each layer
+// where the parent field is uniform.
+type NestDown struct {
+	parent, nest Header
+	regrid       *Regridder // for emissions
+	sampler      *Sampler   // for other values
+}
+
+// NewNestDown returns a NestDown from grid parent to grid nest, which
+// must have the same projection and number of layers. Data are treated
+// as amounts per cell if parent is an EMISSIONS file and as intensive
+// values otherwise.
+func NewNestDown(parent, nest Header) (*NestDown, error) {
+	pg, ng := parent.GridDef(), nest.GridDef()
+	if !pg.SameProjection(ng) {
+		return nil, fmt.Errorf("parent and nested grids have different projections")
+	}
+	if pg.Nz != ng.Nz {
+		return nil, fmt.Errorf("parent grid has %d layers but nested grid has %d", pg.Nz, ng.Nz)
+	}
+	d := &NestDown{parent: parent, nest: nest}
+	var err error
+	if parent.Name == "EMISSIONS" {
+		d.regrid, err = NewRegridder(parent, nest)
+		return d, err
+	}
+	x, y := ng.CellCenters()
+	if d.sampler, err = pg.NewSampler(x, y); err != nil {
+		return nil, fmt.Errorf("nested grid is not inside of the parent grid: %w", err)
+	}
+	return d, nil
+}
+
+// Interpolate returns the data for each species in Data, which must hold
+// Nx*Ny*Nz values on the parent grid (in GLIndex order), on the nested
+// grid.
+func (d *NestDown) Interpolate(Data map[string][]float32) (map[string][]float32, error) {
+	if d.regrid != nil {
+		return d.regrid.Regrid(Data)
+	}
+	pn, nn := d.parent.Nx*d.parent.Ny, d.nest.Nx*d.nest.Ny
+	out := make(map[string][]float32, len(Data))
+	for spname, vals := range Data {
+		if len(vals) != int(pn*d.parent.Nz) {
+			return nil, fmt.Errorf("species %v has %d values; it should have %d",
+				spname, len(vals), pn*d.parent.Nz)
+		}
+		res := make([]float32, 0, nn*d.nest.Nz)
+		for k := int32(0); k < d.parent.Nz; k++ {
+			layer, err := d.sampler.Sample(vals[k*pn : (k+1)*pn])
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, layer...)
+		}
+		out[spname] = res
+	}
+	return out, nil
+}
+
+// NestDownFile interpolates every hour of the gridded file called
+// filename onto grid nest with a NestDown, and writes the result to a
+// file called outfile with the rest of its header copied from the
+// original.
+func NestDownFile(filename, outfile string, nest GridDef) error {
+	f, err := Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if f.Name == "PTSOURCE" {
+		return fmt.Errorf("%v: cannot nest down a PTSOURCE file", filename)
+	}
+	out := NewLike(f, 0)
+	h := out.Header()
+	nest.Apply(&h)
+	out.setHeader(h)
+	d, err := NewNestDown(f.Header(), h)
+	if err != nil {
+		return err
+	}
+	w, err := Create(outfile, out)
+	if err != nil {
+		return err
+	}
+	data := make(map[string][]float32)
+	for {
+		if err = f.readHour(data); err == io.EOF {
+			break
+		} else if err != nil {
+			w.Close()
+			return err
+		}
+		res, err := d.Interpolate(data)
+		if err != nil {
+			w.Close()
+			return err
+		}
+		if err = w.WriteHour(res); err != nil {
+			w.Close()
+			return err
+		}
+	}
+	return w.Close()
+}
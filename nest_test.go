@@ -0,0 +1,94 @@
+package uam_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ctessum/uam"
+	"github.com/ctessum/uam/uamtest"
+)
+
+func TestNestedGrid(t *testing.T) {
+	g := uamtest.DefaultGrid
+	n, err := g.NestedGrid(1, 3, 1, 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := g
+	want.Xorig, want.Yorig, want.Dx, want.Dy, want.Nx, want.Ny = 102000, 202000, 2000, 2000, 6, 4
+	if n != want {
+		t.Errorf("got %+v, want %+v", n, want)
+	}
+	for _, c := range [][5]int32{{0, 3, 1, 2, 2}, {1, 4, 1, 2, 2}, {1, 3, 1, 2, 0}, {2, 2, 1, 2, 2}} {
+		if _, err = g.NestedGrid(c[0], c[1], c[2], c[3], c[4]); err == nil {
+			t.Errorf("%v: got no error", c)
+		}
+	}
+}
+
+func TestNestDownFile(t *testing.T) {
+	dir := t.TempDir()
+	nest, err := uamtest.DefaultGrid.NestedGrid(1, 3, 1, 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Emissions are divided among the nested cells.
+	e, err := uamtest.Emissions(uamtest.Options{Hours: 2, Pattern: uamtest.Constant(1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	outfile := filepath.Join(dir, "emis.uam")
+	if err = uam.NestDownFile(uamtest.TempFile(t, e), outfile, nest); err != nil {
+		t.Fatal(err)
+	}
+	r, err := uam.Open(outfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if r.GridDef() != nest {
+		t.Errorf("got grid %+v, want %+v", r.GridDef(), nest)
+	}
+	hours := readAll(t, r)
+	if len(hours) != 2 {
+		t.Fatalf("got %d hours, want 2", len(hours))
+	}
+	for _, v := range hours[1].Data["NO"] {
+		if v != 0.25 {
+			t.Fatalf("emissions: got %v, want 0.25 everywhere", hours[1].Data["NO"])
+		}
+	}
+
+	// Concentrations, which increase by 1 with each parent column, are
+	// interpolated between the parent cell centers.
+	a, err := uamtest.Average(uamtest.Options{Hours: 1, Pattern: uamtest.Linear(0, 0, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	outfile = filepath.Join(dir, "avrg.uam")
+	if err = uam.NestDownFile(uamtest.TempFile(t, a), outfile, nest); err != nil {
+		t.Fatal(err)
+	}
+	r, err = uam.Open(outfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	vals := readAll(t, r)[0].Data["O3"]
+	for k := int32(0); k < nest.Nz; k++ {
+		for j := int32(0); j < nest.Ny; j++ {
+			for i := int32(0); i < nest.Nx; i++ {
+				if got, want := vals[(k*nest.Ny+j)*nest.Nx+i], 0.25+0.5*float32(i); got != want {
+					t.Errorf("(%d, %d, %d): got %g, want %g", i, j, k, got, want)
+				}
+			}
+		}
+	}
+
+	// The nest must be inside of the parent grid.
+	nest.Xorig -= 10 * nest.Dx
+	if err = uam.NestDownFile(uamtest.TempFile(t, a), outfile, nest); err == nil {
+		t.Error("nest outside of the parent grid: got no error")
+	}
+}
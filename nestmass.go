@@ -0,0 +1,133 @@
+package uam
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+)
+
+// MassDiscrepancy compares the emissions of one species in a nested
+// grid with those of the coarse grid over the same area, as returned by
+// CheckNestMass. Totals are summed over all hours and layers, in mol for
+// gas-phase species and g for aerosols (see IsAerosol).
+type MassDiscrepancy struct {
+	Species string
+	Coarse  float64 // total in the part of the coarse grid covered by the nest
+	Nested  float64 // total in the nest
+	Diff    float64 // Nested - Coarse
+	// RelDiff is Diff / Coarse; it is zero if both totals are zero and
+	// infinite if only Coarse is.
+	RelDiff float64
+}
+
+// CheckNestMass compares the emissions in the EMISSIONS file called
+// nested, for a grid nested inside of that of the EMISSIONS file called
+// coarse, with the emissions in the coarse grid over the area that the
+// nest covers, to find emissions that have been lost or double counted
+// when the nested emissions were prepared. Coarse cells that are only
+// partly covered by the nest contribute in proportion to the area
+// covered. If buffer is true, the outer ring of nested cells is treated
+// as the buffer cells of a CAMx flexi-nest (see NestedGrid) and left out
+// of the comparison. The files must cover the same time span. The result
+// holds every species in either file, sorted by name; species that are
+// only in one of the files have a total of zero in the other.
+func CheckNestMass(coarse, nested string, buffer bool) ([]MassDiscrepancy, error) {
+	cf, err := Open(coarse)
+	if err != nil {
+		return nil, err
+	}
+	defer cf.Close()
+	nf, err := Open(nested)
+	if err != nil {
+		return nil, err
+	}
+	defer nf.Close()
+	for _, f := range []*UAM{cf, nf} {
+		if f.Name != "EMISSIONS" {
+			return nil, fmt.Errorf("mass can only be compared for EMISSIONS files, not %v files", f.Name)
+		}
+	}
+	if err = sameTime(cf, nf); err != nil {
+		return nil, fmt.Errorf("%v does not match %v: %w", nested, coarse, err)
+	}
+	var b int32 // width of the buffer ring
+	if buffer {
+		b = 1
+	}
+	if nf.Nx <= 2*b || nf.Ny <= 2*b {
+		return nil, fmt.Errorf("nested grid of %dx%d cells has no interior", nf.Nx, nf.Ny)
+	}
+	src := cf.Header()
+	src.Nz = 1
+	region := nf.Header()
+	region.Utmx += float32(b) * region.Dx
+	region.Utmy += float32(b) * region.Dy
+	region.Nx, region.Ny, region.Nz = region.Nx-2*b, region.Ny-2*b, 1
+	r, err := NewRegridder(src, region)
+	if err != nil {
+		return nil, err
+	}
+	totals := make(map[string]*MassDiscrepancy)
+	for _, spname := range unionSpecies([]*UAM{cf, nf}) {
+		totals[spname] = &MassDiscrepancy{Species: spname}
+	}
+	cdata, ndata := make(map[string][]float32), make(map[string][]float32)
+	cn, nn := cf.Nx*cf.Ny, nf.Nx*nf.Ny
+	for {
+		if err = cf.readHour(cdata); err == io.EOF {
+			if err = checkEOF([]*UAM{nf}, []string{nested}, coarse); err != nil {
+				return nil, err
+			}
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if err = nf.readHour(ndata); err == io.EOF {
+			return nil, fmt.Errorf("%v has fewer hours than %v", nested, coarse)
+		} else if err != nil {
+			return nil, err
+		}
+		columns := make(map[string][]float32, len(cdata))
+		for spname, vals := range cdata {
+			col := make([]float32, cn)
+			for k := int32(0); k < cf.Nz; k++ {
+				for c, v := range vals[k*cn : (k+1)*cn] {
+					col[c] += v
+				}
+			}
+			columns[spname] = col
+		}
+		res, err := r.Regrid(columns)
+		if err != nil {
+			return nil, err
+		}
+		for spname, vals := range res {
+			for _, v := range vals {
+				totals[spname].Coarse += float64(v)
+			}
+		}
+		for spname, vals := range ndata {
+			for k := int32(0); k < nf.Nz; k++ {
+				for j := b; j < nf.Ny-b; j++ {
+					for i := b; i < nf.Nx-b; i++ {
+						totals[spname].Nested += float64(vals[k*nn+j*nf.Nx+i])
+					}
+				}
+			}
+		}
+	}
+	out := make([]MassDiscrepancy, 0, len(totals))
+	for _, t := range totals {
+		t.Diff = t.Nested - t.Coarse
+		switch {
+		case t.Coarse != 0:
+			t.RelDiff = t.Diff / t.Coarse
+		case t.Diff != 0:
+			t.RelDiff = math.Inf(1)
+		}
+		out = append(out, *t)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Species < out[j].Species })
+	return out, nil
+}
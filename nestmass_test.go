@@ -0,0 +1,72 @@
+package uam_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ctessum/uam"
+	"github.com/ctessum/uam/uamtest"
+)
+
+func TestCheckNestMass(t *testing.T) {
+	nest, err := uamtest.DefaultGrid.NestedGrid(1, 3, 1, 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := uamtest.Emissions(uamtest.Options{Hours: 2, Pattern: uamtest.Constant(1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	coarse := uamtest.TempFile(t, e)
+	nested := filepath.Join(t.TempDir(), "nest.uam")
+	if err = uam.NestDownFile(coarse, nested, nest); err != nil {
+		t.Fatal(err)
+	}
+	// With its buffer cells, the nest covers 3x2 coarse cells, and
+	// without them 2x1, in each of 2 layers and 2 hours.
+	for _, test := range []struct {
+		buffer bool
+		want   float64
+	}{{false, 24}, {true, 8}} {
+		got, err := uam.CheckNestMass(coarse, nested, test.buffer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 3 {
+			t.Fatalf("buffer %v: got %d species, want 3", test.buffer, len(got))
+		}
+		for _, d := range got {
+			if want := (uam.MassDiscrepancy{Species: d.Species, Coarse: test.want, Nested: test.want}); d != want {
+				t.Errorf("buffer %v: got %+v, want %+v", test.buffer, d, want)
+			}
+		}
+	}
+
+	// Twice the emissions in the coarse grid are found as a loss of half.
+	e2, err := uamtest.Emissions(uamtest.Options{Hours: 2, Pattern: uamtest.Constant(2)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := uam.CheckNestMass(uamtest.TempFile(t, e2), nested, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (uam.MassDiscrepancy{Species: "NO", Coarse: 16, Nested: 8, Diff: -8, RelDiff: -0.5}); got[0] != want {
+		t.Errorf("got %+v, want %+v", got[0], want)
+	}
+
+	e3, err := uamtest.Emissions(uamtest.Options{Hours: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = uam.CheckNestMass(uamtest.TempFile(t, e3), nested, true); err == nil {
+		t.Error("different hours: got no error")
+	}
+	a, err := uamtest.Average(uamtest.Options{Hours: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = uam.CheckNestMass(uamtest.TempFile(t, a), nested, true); err == nil {
+		t.Error("AVERAGE file: got no error")
+	}
+}
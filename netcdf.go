@@ -0,0 +1,269 @@
+package uam
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// NetCDF classic format tags and types.
+const (
+	ncDimension = 0x0A
+	ncVariable  = 0x0B
+	ncAttribute = 0x0C
+
+	ncChar   = 2
+	ncInt    = 4
+	ncFloat  = 5
+	ncDouble = 6
+)
+
+type ncDim struct {
+	name string
+	n    int // 0 for the record dimension
+}
+
+type ncAttr struct {
+	name  string
+	value interface{} // string, int32, float32, or []float64
+}
+
+type ncVar struct {
+	name   string
+	dims   []int // indices into the file's dimensions
+	typ    int32
+	attrs  []ncAttr
+	vsize  int64 // bytes per record, or in total for non-record variables
+	begin  int64
+	record bool
+}
+
+// ncFile is the header of a NetCDF file in the 64-bit offset format.
+type ncFile struct {
+	dims  []ncDim
+	attrs []ncAttr
+	vars  []*ncVar
+}
+
+func (nc *ncFile) addVar(name string, typ int32, dims []int, attrs ...ncAttr) *ncVar {
+	v := &ncVar{name: name, typ: typ, dims: dims, attrs: attrs, vsize: 4}
+	if typ == ncDouble {
+		v.vsize = 8
+	}
+	for _, d := range dims {
+		if nc.dims[d].n == 0 {
+			v.record = true
+		} else {
+			v.vsize *= int64(nc.dims[d].n)
+		}
+	}
+	nc.vars = append(nc.vars, v)
+	return v
+}
+
+// layout sets the offsets of the variables, assuming the header starts
+// at byte 0, and returns the size of one record.
+func (nc *ncFile) layout() (recSize int64, err error) {
+	off := int64(len(nc.encode(0)))
+	for _, v := range nc.vars {
+		if v.vsize > math.MaxUint32-4 {
+			return 0, fmt.Errorf("netcdf: variable %v is too large (%d bytes)", v.name, v.vsize)
+		}
+		if !v.record {
+			v.begin = off
+			off += v.vsize
+		}
+	}
+	for _, v := range nc.vars {
+		if v.record {
+			v.begin = off + recSize
+			recSize += v.vsize
+		}
+	}
+	return recSize, nil
+}
+
+// encode returns the file header with the given number of records.
+func (nc *ncFile) encode(numrecs int32) []byte {
+	var b []byte
+	u32 := func(v uint32) { b = binary.BigEndian.AppendUint32(b, v) }
+	name := func(s string) {
+		u32(uint32(len(s)))
+		b = append(b, s...)
+		b = append(b, make([]byte, pad4(len(s)))...)
+	}
+	attrs := func(list []ncAttr) {
+		if len(list) == 0 {
+			u32(0)
+			u32(0)
+			return
+		}
+		u32(ncAttribute)
+		u32(uint32(len(list)))
+		for _, a := range list {
+			name(a.name)
+			switch v := a.value.(type) {
+			case string:
+				u32(ncChar)
+				name(v)
+			case int32:
+				u32(ncInt)
+				u32(1)
+				u32(uint32(v))
+			case float32:
+				u32(ncFloat)
+				u32(1)
+				u32(math.Float32bits(v))
+			case []float64:
+				u32(ncDouble)
+				u32(uint32(len(v)))
+				for _, x := range v {
+					b = binary.BigEndian.AppendUint64(b, math.Float64bits(x))
+				}
+			default:
+				panic(fmt.Sprintf("netcdf: unsupported attribute type %T", v))
+			}
+		}
+	}
+
+	b = append(b, 'C', 'D', 'F', 2)
+	u32(uint32(numrecs))
+	u32(ncDimension)
+	u32(uint32(len(nc.dims)))
+	for _, d := range nc.dims {
+		name(d.name)
+		u32(uint32(d.n))
+	}
+	attrs(nc.attrs)
+	u32(ncVariable)
+	u32(uint32(len(nc.vars)))
+	for _, v := range nc.vars {
+		name(v.name)
+		u32(uint32(len(v.dims)))
+		for _, d := range v.dims {
+			u32(uint32(d))
+		}
+		attrs(v.attrs)
+		u32(uint32(v.typ))
+		u32(uint32(v.vsize))
+		b = binary.BigEndian.AppendUint64(b, uint64(v.begin))
+	}
+	return b
+}
+
+func pad4(n int) int {
+	return (4 - n%4) % 4
+}
+
+// WriteNetCDF reads the remaining hours of f and writes them to w in
+// NetCDF format (64-bit offset), with one variable per species that is
+// selected by SelectSpecies and the horizontal window set by SetWindow,
+// if any. Gridded species have dimensions (TSTEP, LAY, ROW, COL) and
+// point source species have dimensions (TSTEP, NPTS). The start date
+// (YYJJJ) and hour of each time step are stored in the variables date
+// and time, and the header information is stored as global attributes,
+// along with the projection as the PROJ string proj4 and the WKT
+// crs_wkt, if it is supported. w must be seekable so that the number of time steps can be filled in
+// once all hours have been written.
+func (f *UAM) WriteNetCDF(w io.WriteSeeker) error {
+	var species []string
+	for _, spname := range f.Spnames {
+		if f.isSelected(spname) {
+			species = append(species, spname)
+		}
+	}
+	xorig, yorig, nx, ny := f.WindowGrid()
+	nc := &ncFile{attrs: []ncAttr{
+		{"name", f.Name}, {"note", f.Note},
+		{"sdate", f.sdate}, {"begtim", f.begtim},
+		{"edate", f.edate}, {"endtim", f.endtim},
+		{"orgx", f.orgx}, {"orgy", f.orgy}, {"iutm", f.iutm},
+		{"xorig", xorig}, {"yorig", yorig}, {"dx", f.Dx}, {"dy", f.Dy},
+		{"nzlo", f.Nzlo}, {"nzup", f.Nzup},
+		{"hts", f.hts}, {"htl", f.htl}, {"htu", f.htu},
+	}}
+	if proj, err := f.Projection().ProjString(); err == nil {
+		wkt, _ := f.Projection().WKT()
+		nc.attrs = append(nc.attrs, ncAttr{"proj4", proj}, ncAttr{"crs_wkt", wkt})
+	}
+	nc.dims = append(nc.dims, ncDim{"TSTEP", 0})
+	var dataDims []int
+	if f.Name == "PTSOURCE" {
+		nc.dims = append(nc.dims, ncDim{"NPTS", int(f.Npts)})
+		dataDims = []int{0, 1}
+		for _, name := range []string{"xcoord", "ycoord", "stkht", "stkdiam", "stktemp", "stkvel"} {
+			nc.addVar(name, ncFloat, []int{1})
+		}
+	} else {
+		nc.dims = append(nc.dims, ncDim{"LAY", int(f.Nz)}, ncDim{"ROW", int(ny)},
+			ncDim{"COL", int(nx)})
+		dataDims = []int{0, 1, 2, 3}
+		nc.addVar("x", ncDouble, []int{3}, ncAttr{"long_name", "x coordinate of cell center"})
+		nc.addVar("y", ncDouble, []int{2}, ncAttr{"long_name", "y coordinate of cell center"})
+	}
+	nc.addVar("date", ncInt, []int{0}, ncAttr{"long_name", "start date (YYJJJ)"})
+	nc.addVar("time", ncFloat, []int{0}, ncAttr{"long_name", "start hour"})
+	for _, spname := range species {
+		nc.addVar(spname, ncFloat, dataDims)
+	}
+	if _, err := nc.layout(); err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	bw.Write(nc.encode(0))
+	var buf []byte
+	floats := func(vals []float32) error {
+		buf = buf[:0]
+		for _, v := range vals {
+			buf = binary.BigEndian.AppendUint32(buf, math.Float32bits(v))
+		}
+		_, err := bw.Write(buf)
+		return err
+	}
+	if f.Name == "PTSOURCE" {
+		for _, vals := range [][]float32{f.Xcoord, f.Ycoord, f.StackHeight,
+			f.StackDiam, f.StackTemp, f.StackVel} {
+			if err := floats(vals); err != nil {
+				return err
+			}
+		}
+	} else {
+		for i := int32(0); i < nx; i++ {
+			x := float64(xorig) + (float64(i)+0.5)*float64(f.Dx)
+			bw.Write(binary.BigEndian.AppendUint64(nil, math.Float64bits(x)))
+		}
+		for j := int32(0); j < ny; j++ {
+			y := float64(yorig) + (float64(j)+0.5)*float64(f.Dy)
+			bw.Write(binary.BigEndian.AppendUint64(nil, math.Float64bits(y)))
+		}
+	}
+
+	Data := make(map[string][]float32)
+	var numrecs int32
+	for {
+		err := f.readHour(Data)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		bw.Write(binary.BigEndian.AppendUint32(nil, uint32(f.date)))
+		bw.Write(binary.BigEndian.AppendUint32(nil, math.Float32bits(f.time)))
+		for _, spname := range species {
+			if err = floats(Data[spname]); err != nil {
+				return err
+			}
+		}
+		numrecs++
+	}
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	if _, err := w.Seek(4, io.SeekStart); err != nil {
+		return err
+	}
+	return binary.Write(w, binary.BigEndian, numrecs)
+}
@@ -0,0 +1,63 @@
+package uam_test
+
+import (
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ctessum/uam/uamtest"
+)
+
+func TestWriteNetCDF(t *testing.T) {
+	e, err := uamtest.Emissions(uamtest.Options{Hours: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := open(t, e)
+	if err = r.SelectSpecies([]string{"NO2", "O3"}); err != nil {
+		t.Fatal(err)
+	}
+	filename := filepath.Join(t.TempDir(), "emis.nc")
+	w, err := os.Create(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	if err = r.WriteNetCDF(w); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b[:4]) != "CDF\x02" {
+		t.Fatalf("got magic number %q", b[:4])
+	}
+	if n := binary.BigEndian.Uint32(b[4:]); n != 3 {
+		t.Errorf("got %d records, want 3", n)
+	}
+
+	// The records are at the end of the file, each with the date, the
+	// time, and the values of each selected species.
+	const n = 4 * 3 * 2
+	recSize := 4 + 4 + 2*n*4
+	for h := 0; h < 3; h++ {
+		rec := b[len(b)-(3-h)*recSize:]
+		if date := int32(binary.BigEndian.Uint32(rec)); date != e.Hours[h].Date {
+			t.Errorf("hour %d: got date %d, want %d", h, date, e.Hours[h].Date)
+		}
+		if tm := math.Float32frombits(binary.BigEndian.Uint32(rec[4:])); tm != e.Hours[h].Time {
+			t.Errorf("hour %d: got time %g, want %g", h, tm, e.Hours[h].Time)
+		}
+		for s, spname := range []string{"NO2", "O3"} {
+			for i, want := range e.Hours[h].Data[spname] {
+				v := math.Float32frombits(binary.BigEndian.Uint32(rec[8+4*(s*n+i):]))
+				if v != want {
+					t.Fatalf("hour %d %v %d: got %g, want %g", h, spname, i, v, want)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package uam
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"sync"
+)
+
+// WithWorkers sets the number of goroutines that are used to decode the
+// species and layer records of each hour of a gridded file. Records are
+// only decoded in parallel when the file supports random access, which is
+// the case for files opened with Open or OpenMmap, and has no cache (see
+// WithCache). The default is 1.
+func WithWorkers(n int) Option {
+	return func(f *UAM) {
+		f.workers = n
+	}
+}
+
+// readerAt returns random access to the input, or nil if the input
+// does not support it.
+func (s *stream) readerAt() io.ReaderAt {
+	if s.mem {
+		return bytes.NewReader(s.data)
+	}
+	if ra, ok := s.src.(io.ReaderAt); ok {
+		if s.base != 0 {
+			return io.NewSectionReader(ra, s.base, math.MaxInt64-s.base)
+		}
+		return ra
+	}
+	return nil
+}
+
+// readGriddedRecordsParallel reads the species and layer records of one
+// hour of a gridded file, starting at the current position, using
+// f.workers goroutines. Data must already be allocated.
+func (f *UAM) readGriddedRecordsParallel(ra io.ReaderAt, Data map[string][]float32) error {
+	type job struct {
+		spname string
+		k      int32
+		off    int64
+	}
+	recSize := f.gridRecordSize()
+	jobs := make(chan job)
+	errs := make(chan error, f.workers)
+	var wg sync.WaitGroup
+	for w := 0; w < f.workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			buf := make([]byte, recSize)
+			for j := range jobs {
+				if err := f.decodeGridRecord(ra, j.off, buf, j.spname, j.k, f.window, Data); err != nil {
+					errs <- f.parseError(err, f.hour, j.off)
+					// Drain remaining jobs.
+					for range jobs {
+					}
+					return
+				}
+				f.logRecord(j.spname, j.k, j.off, f.decodeMarker(buf))
+			}
+		}()
+	}
+	start := f.r.off
+	off := start
+	var ctxErr error
+loop:
+	for _, spname := range f.Spnames {
+		for k := int32(0); k < f.Nz; k++ {
+			if ctxErr = f.checkContext(); ctxErr != nil {
+				break loop
+			}
+			if f.isSelected(spname) {
+				jobs <- job{spname: spname, k: k, off: off}
+			}
+			off += recSize
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	close(errs)
+	if err := <-errs; err != nil {
+		return err
+	}
+	if ctxErr != nil {
+		return f.parseError(ctxErr, f.hour, off)
+	}
+	if err := skip(f.r, off-start); err != nil {
+		return err
+	}
+	if f.progress != nil {
+		for l := range f.Spnames {
+			f.progress(f.hour, l, start+int64(l+1)*int64(f.Nz)*recSize)
+		}
+	}
+	return nil
+}
+
+// decodeGridRecord reads the record at offset off into buf and decodes
+// it into layer k of species spname in Data, keeping only the values
+// within window w, or all of them if w is nil.
+func (f *UAM) decodeGridRecord(ra io.ReaderAt, off int64, buf []byte, spname string, k int32, w *window, Data map[string][]float32) error {
+	if _, err := ra.ReadAt(buf, off); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	ml := int(f.markerLen())
+	n := int64(len(buf) - 2*ml)
+	if err := f.markerError(f.decodeMarker(buf), n, off); err != nil {
+		return err
+	}
+	if f.strict {
+		m := f.decodeMarker(buf[len(buf)-ml:])
+		if err := f.markerError(m, n, off+n+int64(ml)); err != nil {
+			return err
+		}
+	}
+	if s := recordName(buf[ml:]); s != f.fileName(spname) {
+		return fmt.Errorf("%w: found record for species %v where %v "+
+			"was expected", ErrSpeciesMismatch, s, f.fileName(spname))
+	}
+	vals := buf[ml+44 : len(buf)-ml]
+	if w == nil {
+		w = &window{i2: f.Nx, j2: f.Ny}
+	}
+	nx, ny := w.i2-w.i1, w.j2-w.j1
+	dst := Data[spname][k*nx*ny : (k+1)*nx*ny]
+	for j := w.j1; j < w.j2; j++ {
+		row := vals[4*(j*f.Nx+w.i1):]
+		out := dst[(j-w.j1)*nx : (j-w.j1+1)*nx]
+		for i := range out {
+			out[i] = math.Float32frombits(ByteOrder.Uint32(row[4*i:]))
+		}
+	}
+	return nil
+}
+
+// recordName decodes the species name of the data record whose contents
+// (after the leading marker) start with b.
+func recordName(b []byte) string {
+	name := make([]byte, 10)
+	for i := range name {
+		name[i] = b[4+4*i]
+	}
+	return strings.Trim(string(name), " ")
+}
@@ -0,0 +1,72 @@
+package uam
+
+import "fmt"
+
+// Profile is the vertical profile of a species in one grid column.
+type Profile struct {
+	Values []float32 // value in each layer, from the ground up
+	Tops   []float32 // height (m) of the top of each layer; nil if unknown
+}
+
+// Profile returns the values in column (i, j) of vals, which holds the
+// values of one species in one hour of gridded file f in the format
+// returned by ReadHour. i and j count from the SW corner of the full
+// grid, even if a window is set with SetWindow. If layers is not nil,
+// the profile includes the layer top heights in the column.
+func (f *UAM) Profile(vals []float32, i, j int32, layers Layers) (Profile, error) {
+	if f.Name == "PTSOURCE" {
+		return Profile{}, fmt.Errorf("profiles can only be extracted from gridded files")
+	}
+	_, _, nx, ny := f.WindowGrid()
+	if len(vals) != int(nx*ny*f.Nz) {
+		return Profile{}, fmt.Errorf("field has %d values; it should have %d",
+			len(vals), nx*ny*f.Nz)
+	}
+	var i1, j1 int32
+	if f.window != nil {
+		i1, j1 = f.window.i1, f.window.j1
+	}
+	if i < i1 || i >= i1+nx || j < j1 || j >= j1+ny {
+		return Profile{}, fmt.Errorf("cell (%d, %d) is outside of the grid", i, j)
+	}
+	return profile(vals, nx, ny, f.Nz, i-i1, j-j1, layers, i, j)
+}
+
+// Profile returns the vertical profile of the named species in cell
+// (i, j) of h, which must be from a gridded file. As with UAM.Profile, i
+// and j count from the SW corner of the full grid even if h was read
+// with a window. If layers is not nil, the profile includes the layer
+// top heights in the column.
+func (h *Hour) Profile(species string, i, j int32, layers Layers) (Profile, error) {
+	vals, ok := h.Data[species]
+	if !ok {
+		return Profile{}, fmt.Errorf("species %v is not in the hour", species)
+	}
+	n := h.nx * h.ny
+	if n == 0 || len(vals)%int(n) != 0 {
+		return Profile{}, fmt.Errorf("the grid size of the hour is not known")
+	}
+	if i < h.i1 || i >= h.i1+h.nx || j < h.j1 || j >= h.j1+h.ny {
+		return Profile{}, fmt.Errorf("cell (%d, %d) is outside of the grid", i, j)
+	}
+	return profile(vals, h.nx, h.ny, int32(len(vals))/n, i-h.i1, j-h.j1, layers, i, j)
+}
+
+// profile extracts the profile at (i, j) from vals, which holds nz
+// layers of nx*ny values, with the layer tops of column (li, lj) of
+// layers.
+func profile(vals []float32, nx, ny, nz, i, j int32, layers Layers, li, lj int32) (Profile, error) {
+	p := Profile{Values: make([]float32, nz)}
+	for k := range p.Values {
+		p.Values[k] = vals[(int32(k)*ny+j)*nx+i]
+	}
+	if layers != nil {
+		p.Tops = layers.LayerTops(li, lj)
+		if len(p.Tops) < int(nz) {
+			return Profile{}, fmt.Errorf("there are %d layer tops but %d layers",
+				len(p.Tops), nz)
+		}
+		p.Tops = p.Tops[:nz]
+	}
+	return p, nil
+}
@@ -0,0 +1,134 @@
+package uam
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// ProjectionType is a map projection, identified by the iproj code that
+// CAMx stores in the Nzlo field of the header.
+type ProjectionType int32
+
+// The projection types supported by CAMx.
+const (
+	LatLon             ProjectionType = 0
+	UTM                ProjectionType = 1
+	LambertConformal   ProjectionType = 2
+	PolarStereographic ProjectionType = 3
+)
+
+func (t ProjectionType) String() string {
+	switch t {
+	case LatLon:
+		return "latitude-longitude"
+	case UTM:
+		return "UTM"
+	case LambertConformal:
+		return "Lambert conformal conic"
+	case PolarStereographic:
+		return "polar stereographic"
+	}
+	return fmt.Sprintf("unknown (type %d)", int32(t))
+}
+
+// Projection is the map projection of a grid. Projected coordinates are
+// in meters, except for latitude-longitude grids, where they are in
+// degrees. Lambert conformal conic and polar stereographic coordinates
+// are on a sphere with a radius of 6370 km and have their origin at
+// (CenterLon, CenterLat).
+type Projection struct {
+	Type ProjectionType
+	Zone int32 // UTM zone; negative zones are in the southern hemisphere
+
+	// CenterLon and CenterLat are the longitude and latitude (degrees)
+	// of the origin of Lambert conformal conic and polar stereographic
+	// projections. CenterLon is also the longitude that is parallel to
+	// the y axis.
+	CenterLon, CenterLat float64
+
+	// TrueLat1 and TrueLat2 are the latitudes (degrees) at which
+	// Lambert conformal conic projections are true to scale. Polar
+	// stereographic projections use only TrueLat1, whose sign gives the
+	// hemisphere of the pole.
+	TrueLat1, TrueLat2 float64
+}
+
+// Projection returns the projection of grid g.
+func (g GridDef) Projection() Projection {
+	return Projection{
+		Type:      ProjectionType(g.Iproj),
+		Zone:      g.Iutm,
+		CenterLon: decimal(g.Orgx),
+		CenterLat: decimal(g.Orgy),
+		TrueLat1:  decimal(g.Tlat1),
+		TrueLat2:  decimal(g.Tlat2),
+	}
+}
+
+// decimal converts v to the float64 closest to its shortest decimal
+// representation, so that a header value of 33.1 is 33.1 rather than
+// 33.099998474121094.
+func decimal(v float32) float64 {
+	d, _ := strconv.ParseFloat(strconv.FormatFloat(float64(v), 'g', -1, 32), 64)
+	return d
+}
+
+// Projection returns the projection of the grid of f.
+func (f *UAM) Projection() Projection {
+	return f.GridDef().Projection()
+}
+
+func (p Projection) String() string {
+	switch p.Type {
+	case UTM:
+		return fmt.Sprintf("UTM zone %d", p.Zone)
+	case LambertConformal:
+		return fmt.Sprintf("Lambert conformal conic, center (%g, %g), "+
+			"true latitudes %g and %g", p.CenterLon, p.CenterLat, p.TrueLat1, p.TrueLat2)
+	case PolarStereographic:
+		return fmt.Sprintf("polar stereographic, center (%g, %g), "+
+			"true latitude %g", p.CenterLon, p.CenterLat, p.TrueLat1)
+	}
+	return p.Type.String()
+}
+
+// Forward converts longitude and latitude (degrees) to projected
+// coordinates.
+func (p Projection) Forward(lon, lat float64) (x, y float64, err error) {
+	switch p.Type {
+	case LatLon:
+		return lon, lat, nil
+	case UTM:
+		x, y = lonLatToUTM(lon, lat, int(p.Zone))
+		return x, y, nil
+	case LambertConformal:
+		x, y = lonLatToLCC(lon, lat, p.CenterLon, p.CenterLat, p.TrueLat1, p.TrueLat2)
+		return x, y, nil
+	case PolarStereographic:
+		x, y = lonLatToPS(lon, lat, p.CenterLon, p.TrueLat1)
+		x0, y0 := lonLatToPS(p.CenterLon, p.CenterLat, p.CenterLon, p.TrueLat1)
+		return x - x0, y - y0, nil
+	}
+	return math.NaN(), math.NaN(), fmt.Errorf("unsupported projection %v", p.Type)
+}
+
+// Inverse converts projected coordinates to longitude and latitude
+// (degrees).
+func (p Projection) Inverse(x, y float64) (lon, lat float64, err error) {
+	switch p.Type {
+	case LatLon:
+		return x, y, nil
+	case UTM:
+		lon, lat = utmToLonLat(x, y, int(p.Zone))
+		return lon, lat, nil
+	case LambertConformal:
+		lon, lat = lccToLonLat(x, y, p.CenterLon, p.CenterLat, p.TrueLat1, p.TrueLat2)
+		return lon, lat, nil
+	case PolarStereographic:
+		x0, y0 := lonLatToPS(p.CenterLon, p.CenterLat, p.CenterLon, p.TrueLat1)
+		lon, lat = psToLonLat(x+x0, y+y0, p.CenterLon, p.TrueLat1)
+		return lon, lat, nil
+	}
+	return math.NaN(), math.NaN(), fmt.Errorf("unsupported projection %v", p.Type)
+}
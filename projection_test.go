@@ -0,0 +1,66 @@
+package uam_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/ctessum/uam"
+)
+
+func TestProjection(t *testing.T) {
+	for _, test := range []struct {
+		p        uam.Projection
+		lon, lat float64
+		x, y     float64 // expected projected coordinates, if not NaN
+	}{
+		{p: uam.Projection{Type: uam.LatLon}, lon: -97.5, lat: 40.25, x: -97.5, y: 40.25},
+		// The central meridian of UTM zone 15 is at 93°W, with a false
+		// easting of 500 km.
+		{p: uam.Projection{Type: uam.UTM, Zone: 15}, lon: -93, lat: 0, x: 500000, y: 0},
+		{p: uam.Projection{Type: uam.UTM, Zone: 15}, lon: -94.2, lat: 41.7, x: math.NaN(), y: math.NaN()},
+		// The center of Lambert conformal and polar stereographic
+		// projections is at (0, 0).
+		{p: uam.Projection{Type: uam.LambertConformal, CenterLon: -97, CenterLat: 40,
+			TrueLat1: 33, TrueLat2: 45}, lon: -97, lat: 40, x: 0, y: 0},
+		{p: uam.Projection{Type: uam.LambertConformal, CenterLon: -97, CenterLat: 40,
+			TrueLat1: 33, TrueLat2: 45}, lon: -80.1, lat: 25.8, x: math.NaN(), y: math.NaN()},
+		{p: uam.Projection{Type: uam.PolarStereographic, CenterLon: -98, CenterLat: 60,
+			TrueLat1: 60}, lon: -98, lat: 60, x: 0, y: 0},
+		{p: uam.Projection{Type: uam.PolarStereographic, CenterLon: -98, CenterLat: 60,
+			TrueLat1: 60}, lon: -150, lat: 61.2, x: math.NaN(), y: math.NaN()},
+	} {
+		x, y, err := test.p.Forward(test.lon, test.lat)
+		if err != nil {
+			t.Fatalf("%v: %v", test.p, err)
+		}
+		if !math.IsNaN(test.x) && (math.Abs(x-test.x) > 1e-6 || math.Abs(y-test.y) > 1e-6) {
+			t.Errorf("%v: (%g, %g) is at (%g, %g), want (%g, %g)", test.p,
+				test.lon, test.lat, x, y, test.x, test.y)
+		}
+		lon, lat, err := test.p.Inverse(x, y)
+		if err != nil {
+			t.Fatalf("%v: %v", test.p, err)
+		}
+		if math.Abs(lon-test.lon) > 1e-7 || math.Abs(lat-test.lat) > 1e-7 {
+			t.Errorf("%v: (%g, %g) round trips to (%g, %g)", test.p, test.lon, test.lat, lon, lat)
+		}
+	}
+
+	if _, _, err := (uam.Projection{Type: 9}).Forward(0, 0); err == nil {
+		t.Error("unknown projection: got no error")
+	}
+}
+
+func TestCellAt(t *testing.T) {
+	g := uam.GridDef{Xorig: -100, Yorig: 30, Dx: 0.5, Dy: 0.25, Nx: 10, Ny: 8, Nz: 1}
+	i, j, err := g.CellAt(-98.9, 31.1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if i != 2 || j != 4 {
+		t.Errorf("got cell (%d, %d), want (2, 4)", i, j)
+	}
+	if _, _, err = g.CellAt(-90, 31); err == nil {
+		t.Error("location outside of the grid: got no error")
+	}
+}
@@ -0,0 +1,100 @@
+package uam
+
+import (
+	"fmt"
+	"math"
+)
+
+// PointGridOptions controls how GridPoints allocates the emissions of
+// each stack to the layers of the grid.
+type PointGridOptions struct {
+	// Layers gives the layer structure used to find the layer into which
+	// each stack emits. If it is nil, all emissions go into the first
+	// layer.
+	Layers Layers
+	// Overrides holds the override records of the hour being gridded,
+	// such as Hour.Overrides. The plume heights of stacks whose records
+	// are flagged with HeightOverride are used as their injection heights.
+	Overrides []StackOverride
+	// PlumeRise, if not nil, returns the plume rise (m) of a stack, which
+	// is added to its stack height to give its injection height.
+	PlumeRise func(Stack) float32
+}
+
+// GridPoints adds one hour of emissions from PTSOURCE file f, in the
+// format returned by ReadHour, to the grid cell in which each stack is
+// located. dst holds the gridded emissions, with Nx*Ny*Nz values per
+// species in GLIndex order, such as an hour of an EMISSIONS file on the
+// same grid; arrays are allocated for species that are not already in
+// dst. Each stack emits into the layer that contains its injection
+// height, or the top layer if it is above the model top. Stacks that are
+// outside of the grid are skipped, and their indices are returned.
+func (f *UAM) GridPoints(dst, Data map[string][]float32, opts PointGridOptions) ([]int, error) {
+	if f.Name != "PTSOURCE" {
+		return nil, fmt.Errorf("%v is not a PTSOURCE file", f.Name)
+	}
+	if opts.Overrides != nil && len(opts.Overrides) != int(f.Npts) {
+		return nil, fmt.Errorf("there are %d stack overrides but %d stacks",
+			len(opts.Overrides), f.Npts)
+	}
+	if f.Nx <= 0 || f.Ny <= 0 || f.Nz <= 0 {
+		return nil, fmt.Errorf("invalid grid dimensions %dx%dx%d", f.Nx, f.Ny, f.Nz)
+	}
+	n := int(f.Nx * f.Ny * f.Nz)
+	for spname, vals := range Data {
+		if len(vals) != int(f.Npts) {
+			return nil, fmt.Errorf("species %v has %d values; it should have %d",
+				spname, len(vals), f.Npts)
+		}
+		if dst[spname] == nil {
+			dst[spname] = make([]float32, n)
+		} else if len(dst[spname]) != n {
+			return nil, fmt.Errorf("gridded species %v has %d values; it should have %d",
+				spname, len(dst[spname]), n)
+		}
+	}
+	stacks := f.Stacks()
+	var outside []int
+	for p, s := range stacks {
+		i, j, ok := f.stackCell(p)
+		if !ok {
+			outside = append(outside, p)
+			continue
+		}
+		k, err := f.injectionLayer(s, i, j, p, opts)
+		if err != nil {
+			return nil, err
+		}
+		idx := (k*f.Ny+j)*f.Nx + i
+		for spname, vals := range Data {
+			dst[spname][idx] += vals[p]
+		}
+	}
+	return outside, nil
+}
+
+// injectionLayer returns the layer in cell (i, j) into which stack s,
+// with index p, emits.
+func (f *UAM) injectionLayer(s Stack, i, j int32, p int, opts PointGridOptions) (int32, error) {
+	if opts.Layers == nil {
+		return 0, nil
+	}
+	height := s.Height
+	if opts.Overrides != nil && opts.Overrides[p].HeightOverride() {
+		height = float32(math.Abs(float64(opts.Overrides[p].PlumeHeight)))
+	} else if opts.PlumeRise != nil {
+		height += opts.PlumeRise(s)
+	}
+	tops := opts.Layers.LayerTops(i, j)
+	if len(tops) == 0 {
+		return 0, fmt.Errorf("no layers for cell (%d, %d)", i, j)
+	}
+	k := int32(len(tops) - 1)
+	for l, top := range tops {
+		if height <= top {
+			k = int32(l)
+			break
+		}
+	}
+	return min(k, f.Nz-1), nil
+}
@@ -0,0 +1,398 @@
+package uam_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/ctessum/uam"
+	"github.com/ctessum/uam/uamtest"
+)
+
+// open writes f to a temporary file and opens it with opts.
+func open(t *testing.T, f *uam.UAM, opts ...uam.Option) *uam.UAM {
+	t.Helper()
+	r, err := uam.Open(uamtest.TempFile(t, f), opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(r.Close)
+	return r
+}
+
+// readAll reads the remaining hours of f.
+func readAll(t *testing.T, f *uam.UAM) []*uam.Hour {
+	t.Helper()
+	var hours []*uam.Hour
+	for {
+		h, err := f.ReadNextHour()
+		if err == io.EOF {
+			return hours
+		} else if err != nil {
+			t.Fatalf("hour %d: %v", len(hours), err)
+		}
+		hours = append(hours, h)
+	}
+}
+
+// checkHours checks that the data and times of got match want.
+func checkHours(t *testing.T, got, want []*uam.Hour) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d hours, want %d", len(got), len(want))
+	}
+	for h := range want {
+		if got[h].Date != want[h].Date || got[h].Time != want[h].Time {
+			t.Errorf("hour %d: got time %d %g, want %d %g", h,
+				got[h].Date, got[h].Time, want[h].Date, want[h].Time)
+		}
+		if !reflect.DeepEqual(got[h].Data, want[h].Data) {
+			t.Errorf("hour %d: got %v, want %v", h, got[h].Data, want[h].Data)
+		}
+		overrides := want[h].Overrides
+		if overrides == nil && got[h].Overrides != nil {
+			// Nil overrides are written as zeros.
+			overrides = make([]uam.StackOverride, len(got[h].Overrides))
+		}
+		if !reflect.DeepEqual(got[h].Overrides, overrides) {
+			t.Errorf("hour %d: got overrides %v, want %v", h, got[h].Overrides, overrides)
+		}
+	}
+}
+
+func TestSelectSpecies(t *testing.T) {
+	f, err := uamtest.Emissions(uamtest.Options{Hours: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := open(t, f)
+	if err = r.SelectSpecies([]string{"O3", "NO"}); err != nil {
+		t.Fatal(err)
+	}
+	if err = r.SelectSpecies([]string{"CO"}); err == nil {
+		t.Error("selecting a missing species: got no error")
+	}
+	for h, hr := range readAll(t, r) {
+		want := map[string][]float32{"NO": f.Hours[h].Data["NO"], "O3": f.Hours[h].Data["O3"]}
+		if !reflect.DeepEqual(hr.Data, want) {
+			t.Errorf("hour %d: got %v, want %v", h, hr.Data, want)
+		}
+	}
+}
+
+func TestWindow(t *testing.T) {
+	f, err := uamtest.Emissions(uamtest.Options{Hours: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := open(t, f)
+	if err = r.SetWindow(1, 3, 1, 3); err != nil {
+		t.Fatal(err)
+	}
+	if err = r.SetWindow(1, 5, 0, 1); err == nil {
+		t.Error("window outside of the grid: got no error")
+	}
+	x, y, nx, ny := r.WindowGrid()
+	if x != f.Utmx+f.Dx || y != f.Utmy+f.Dy || nx != 2 || ny != 2 {
+		t.Fatalf("got window at (%g, %g) with %dx%d cells; want the SW corner of "+
+			"cell (1, 1) with 2x2 cells", x, y, nx, ny)
+	}
+	hr := readAll(t, r)[0]
+	vals := hr.Data["NO2"]
+	if len(vals) != int(nx*ny*f.Nz) {
+		t.Fatalf("got %d values, want %d", len(vals), nx*ny*f.Nz)
+	}
+	for k := int32(0); k < f.Nz; k++ {
+		for j := int32(0); j < ny; j++ {
+			for i := int32(0); i < nx; i++ {
+				if got, want := vals[(k*ny+j)*nx+i], uamtest.Index(1, 0, k, j+1, i+1); got != want {
+					t.Errorf("(%d, %d, %d): got %g, want %g", i, j, k, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	o := uamtest.Options{Hours: 26}
+	for name, newFile := range map[string]func(uamtest.Options) (*uam.UAM, error){
+		"EMISSIONS": uamtest.Emissions,
+		"AVERAGE":   uamtest.Average,
+		"PTSOURCE":  uamtest.PointSource,
+	} {
+		t.Run(name, func(t *testing.T) {
+			f, err := newFile(o)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := open(t, f)
+			if r.Header() != f.Header() {
+				t.Errorf("got header %+v, want %+v", r.Header(), f.Header())
+			}
+			if !reflect.DeepEqual(r.Spnames, f.Spnames) {
+				t.Errorf("got species %v, want %v", r.Spnames, f.Spnames)
+			}
+			if !reflect.DeepEqual(r.Stacks(), f.Stacks()) {
+				t.Errorf("got stacks %v, want %v", r.Stacks(), f.Stacks())
+			}
+			if n := r.CompleteHours(); n != o.Hours {
+				t.Errorf("got %d complete hours, want %d", n, o.Hours)
+			}
+			checkHours(t, readAll(t, r), f.Hours)
+			if last := f.Hours[o.Hours-1]; last.Date != 15002 || last.Time != 1 {
+				t.Errorf("last hour starts at %d %g, want 15002 1", last.Date, last.Time)
+			}
+		})
+	}
+}
+
+func TestWorkers(t *testing.T) {
+	f, err := uamtest.Emissions(uamtest.Options{Hours: 3,
+		Grid: uam.GridDef{Nx: 7, Ny: 5, Nz: 4, Dx: 1, Dy: 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := open(t, f, uam.WithWorkers(3))
+	checkHours(t, readAll(t, r), f.Hours)
+
+	r = open(t, f, uam.WithWorkers(3))
+	r.SetWindow(2, 5, 1, 4)
+	r.SelectSpecies([]string{"NO2"})
+	seq := open(t, f)
+	seq.SetWindow(2, 5, 1, 4)
+	seq.SelectSpecies([]string{"NO2"})
+	checkHours(t, readAll(t, r), readAll(t, seq))
+}
+
+func TestResync(t *testing.T) {
+	f, err := uamtest.Emissions(uamtest.Options{Hours: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	filename := uamtest.TempFile(t, f)
+	// Corrupt the marker of a species record in hour 1.
+	rec := f.RecordMap(1)[3]
+	fid, err := os.OpenFile(filename, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = fid.WriteAt([]byte{0xff, 0xff, 0xff, 0xff}, rec.Offset); err != nil {
+		t.Fatal(err)
+	}
+	fid.Close()
+
+	r, err := uam.Open(filename, uam.WithResync())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	checkHours(t, readAll(t, r), []*uam.Hour{f.Hours[0], f.Hours[2]})
+	skipped := r.Skipped()
+	if len(skipped) != 1 {
+		t.Fatalf("got skipped spans %v, want 1", skipped)
+	}
+}
+
+func TestAliases(t *testing.T) {
+	f, err := uamtest.Emissions(uamtest.Options{Hours: 1, Species: []string{"NO", "FORM"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := open(t, f, uam.WithAliases(map[string]string{"FORM": "HCHO"}))
+	if !reflect.DeepEqual(r.Spnames, []string{"NO", "HCHO"}) {
+		t.Fatalf("got species %v, want NO and HCHO", r.Spnames)
+	}
+	if err = r.SelectSpecies([]string{"HCHO"}); err != nil {
+		t.Fatal(err)
+	}
+	hr := readAll(t, r)[0]
+	if !reflect.DeepEqual(hr.Data, map[string][]float32{"HCHO": f.Hours[0].Data["FORM"]}) {
+		t.Errorf("got %v, want FORM as HCHO", hr.Data)
+	}
+
+	_, err = uam.Open(uamtest.TempFile(t, f), uam.WithAliases(map[string]string{"FORM": "NO"}))
+	if err == nil {
+		t.Error("two species with the same alias: got no error")
+	}
+}
+
+func TestTolerant(t *testing.T) {
+	f, err := uamtest.Emissions(uamtest.Options{Hours: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	filename := uamtest.TempFile(t, f)
+	info, err := os.Stat(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Cut the file off partway through the last hour.
+	if err = os.Truncate(filename, info.Size()-100); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := uam.Open(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	for h := 0; ; h++ {
+		_, err = r.ReadNextHour()
+		if err != nil {
+			if h != 2 || !errors.Is(err, uam.ErrTruncatedFile) {
+				t.Errorf("hour %d: got %v, want a truncated file in hour 2", h, err)
+			}
+			break
+		}
+	}
+
+	r, err = uam.Open(filename, uam.WithTolerant())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if n := r.CompleteHours(); n != 2 {
+		t.Errorf("got %d complete hours, want 2", n)
+	}
+	checkHours(t, readAll(t, r), f.Hours[:2])
+	if !errors.Is(r.Truncated(), uam.ErrTruncatedFile) {
+		t.Errorf("got truncation %v, want ErrTruncatedFile", r.Truncated())
+	}
+}
+
+func TestDerived(t *testing.T) {
+	f, err := uamtest.Emissions(uamtest.Options{Hours: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := open(t, f, uam.WithDerived(uam.NOx))
+	if err = r.SelectSpecies([]string{"O3", "NOX"}); err != nil {
+		t.Fatal(err)
+	}
+	hours := readAll(t, r)
+	for h, hr := range hours {
+		if len(hr.Data) != 4 {
+			t.Errorf("hour %d: got species %v, want NO, NO2, O3, and NOX", h, hr.Data)
+		}
+		for i, v := range hr.Data["NOX"] {
+			if want := f.Hours[h].Data["NO"][i] + f.Hours[h].Data["NO2"][i]; v != want {
+				t.Fatalf("hour %d: NOX[%d] = %g, want %g", h, i, v, want)
+			}
+		}
+		if !reflect.DeepEqual(hr.Data["O3"], f.Hours[h].Data["O3"]) {
+			t.Errorf("hour %d: O3 differs", h)
+		}
+	}
+
+	if _, err = uam.Open(uamtest.TempFile(t, f), uam.WithDerived(
+		uam.DerivedSpecies{Name: "X", Components: map[string]float32{"CO": 1}})); err == nil {
+		t.Error("derived species with a missing component: got no error")
+	}
+}
+
+func TestMassUnits(t *testing.T) {
+	o := uamtest.Options{Hours: 1, Pattern: uamtest.Constant(2)}
+	for _, newFile := range []func(uamtest.Options) (*uam.UAM, error){uamtest.Emissions, uamtest.PointSource} {
+		f, err := newFile(o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hr := readAll(t, open(t, f, uam.WithMassUnits(nil)))[0]
+		for _, spname := range f.Spnames {
+			want := float32(2 * uam.MolecularWeights[spname])
+			for _, v := range hr.Data[spname] {
+				if v != want {
+					t.Fatalf("%v %v: got %g g/hr, want %g", f.Name, spname, v, want)
+				}
+			}
+		}
+	}
+
+	f, err := uamtest.Average(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = uam.Open(uamtest.TempFile(t, f), uam.WithMassUnits(nil)); err == nil {
+		t.Error("AVERAGE file in mass units: got no error")
+	}
+}
+
+func TestSurface(t *testing.T) {
+	f, err := uamtest.Emissions(uamtest.Options{Hours: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := f.Hours[0].Surface("O3"), f.Hours[0].Data["O3"][:12]; !reflect.DeepEqual(got, want) {
+		t.Errorf("got surface %v, want %v", got, want)
+	}
+	r := open(t, f)
+	if err = r.SetWindow(2, 4, 0, 2); err != nil {
+		t.Fatal(err)
+	}
+	hr := readAll(t, r)[0]
+	want := []float32{uamtest.Index(2, 0, 0, 0, 2), uamtest.Index(2, 0, 0, 0, 3),
+		uamtest.Index(2, 0, 0, 1, 2), uamtest.Index(2, 0, 0, 1, 3)}
+	if got := hr.Surface("O3"); !reflect.DeepEqual(got, want) {
+		t.Errorf("got windowed surface %v, want %v", got, want)
+	}
+	if hr.Surface("CO") != nil {
+		t.Error("got a surface for a missing species")
+	}
+}
+
+func TestReaderOffset(t *testing.T) {
+	f, err := uamtest.Emissions(uamtest.Options{Hours: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The file follows other data in the input.
+	var b bytes.Buffer
+	b.WriteString("not part of the file")
+	if err = f.Write(&b); err != nil {
+		t.Fatal(err)
+	}
+	src := bytes.NewReader(b.Bytes())
+	if _, err = src.Seek(20, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	r, err := uam.NewReader(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = r.SeekHour(2); err != nil {
+		t.Fatal(err)
+	}
+	checkHours(t, readAll(t, r), f.Hours[2:])
+	if err = r.SeekHour(0); err != nil {
+		t.Fatal(err)
+	}
+	checkHours(t, readAll(t, r), f.Hours)
+	vals, err := r.ReadSpecies(1, "NO2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(vals, f.Hours[1].Data["NO2"]) {
+		t.Errorf("got %v, want %v", vals, f.Hours[1].Data["NO2"])
+	}
+}
+
+func TestSegments(t *testing.T) {
+	f, err := uamtest.Emissions(uamtest.Options{Hours: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	if err = f.Write(&b); err != nil {
+		t.Fatal(err)
+	}
+	// nseg follows the marker and the file type and note.
+	data := b.Bytes()
+	binary.BigEndian.PutUint32(data[4+40+240:], 2)
+	if _, err = uam.NewBytesReader(data); !errors.Is(err, uam.ErrInvalidHeader) {
+		t.Errorf("got %v, want ErrInvalidHeader", err)
+	}
+}
@@ -0,0 +1,258 @@
+package uam
+
+import (
+	"fmt"
+	"math"
+)
+
+// Regridder resamples gridded data onto another grid with the same
+// number of layers, weighting each source cell by the area that it
+// shares with each destination cell. Emissions, which are amounts per
+// cell, are divided among the destination cells so that totals are
+// conserved within the area covered by both grids. Other values, such as
+// concentrations, are averaged over the area of each destination cell
+// that is covered by the source grid, and are zero where there is no
+// overlap. If the grids have different projections, the areas are
+// calculated in the projection of the destination grid, with the edges
+// of each source cell approximated by straight lines between points
+// spaced a quarter of the cell apart.
+type Regridder struct {
+	src, dst   Header
+	extensive  bool
+	cols, rows [][]overlap // source cells overlapping each destination column and row
+
+	// If the projections differ, cells holds the source cells that
+	// overlap each destination cell and srcAreas the area of each source
+	// cell in the destination projection.
+	cells    [][]cellOverlap
+	srcAreas []float64
+}
+
+// overlap is the length shared by a source and a destination cell in
+// one direction.
+type overlap struct {
+	src    int32
+	length float64
+}
+
+// cellOverlap is the area shared by a source and a destination cell.
+type cellOverlap struct {
+	src  int32 // index of the source cell, j*Nx+i
+	area float64
+}
+
+// NewRegridder returns a Regridder from grid src to grid dst. Data are
+// treated as amounts per cell if src is an EMISSIONS file and as
+// intensive values otherwise.
+func NewRegridder(src, dst Header) (*Regridder, error) {
+	if src.Nz != dst.Nz {
+		return nil, fmt.Errorf("source grid has %d layers but destination "+
+			"grid has %d", src.Nz, dst.Nz)
+	}
+	if dst.Nx <= 0 || dst.Ny <= 0 || dst.Dx <= 0 || dst.Dy <= 0 {
+		return nil, fmt.Errorf("invalid destination grid %dx%d cells of %gx%g",
+			dst.Nx, dst.Ny, dst.Dx, dst.Dy)
+	}
+	r := &Regridder{src: src, dst: dst, extensive: src.Name == "EMISSIONS"}
+	if src.GridDef().SameProjection(dst.GridDef()) {
+		r.cols = overlaps(src.Utmx, src.Dx, src.Nx, dst.Utmx, dst.Dx, dst.Nx)
+		r.rows = overlaps(src.Utmy, src.Dy, src.Ny, dst.Utmy, dst.Dy, dst.Ny)
+		return r, nil
+	}
+	var err error
+	if r.cells, r.srcAreas, err = projectedOverlaps(src.GridDef(), dst.GridDef()); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// projectedOverlaps returns the source cells that overlap each
+// destination cell, and the area of each source cell, in the projection
+// of the destination grid. Areas are in units of destination cells.
+func projectedOverlaps(src, dst GridDef) ([][]cellOverlap, []float64, error) {
+	const n = 4 // points per cell edge
+	sp, dp := src.Projection(), dst.Projection()
+	cells := make([][]cellOverlap, dst.Nx*dst.Ny)
+	areas := make([]float64, src.Nx*src.Ny)
+	poly := make([][2]float64, 4*n)
+	for j := int32(0); j < src.Ny; j++ {
+		for i := int32(0); i < src.Nx; i++ {
+			// Walk around the cell counterclockwise from its SW corner,
+			// converting each point to destination grid cell units.
+			for p := range poly {
+				t := float64(p%n) / n
+				var u, v float64
+				switch p / n {
+				case 0:
+					u, v = t, 0
+				case 1:
+					u, v = 1, t
+				case 2:
+					u, v = 1-t, 1
+				case 3:
+					u, v = 0, 1-t
+				}
+				x := float64(src.Xorig) + (float64(i)+u)*float64(src.Dx)
+				y := float64(src.Yorig) + (float64(j)+v)*float64(src.Dy)
+				lon, lat, err := sp.Inverse(x, y)
+				if err != nil {
+					return nil, nil, err
+				}
+				if x, y, err = dp.Forward(lon, lat); err != nil {
+					return nil, nil, err
+				}
+				poly[p] = [2]float64{(x - float64(dst.Xorig)) / float64(dst.Dx),
+					(y - float64(dst.Yorig)) / float64(dst.Dy)}
+			}
+			c := j*src.Nx + i
+			areas[c] = polygonArea(poly)
+			lo, hi := poly[0], poly[0]
+			for _, p := range poly {
+				lo = [2]float64{math.Min(lo[0], p[0]), math.Min(lo[1], p[1])}
+				hi = [2]float64{math.Max(hi[0], p[0]), math.Max(hi[1], p[1])}
+			}
+			i0, i1 := max(int32(math.Floor(lo[0])), 0), min(int32(math.Ceil(hi[0])), dst.Nx)
+			j0, j1 := max(int32(math.Floor(lo[1])), 0), min(int32(math.Ceil(hi[1])), dst.Ny)
+			for dj := j0; dj < j1; dj++ {
+				for di := i0; di < i1; di++ {
+					a := polygonArea(clipRect(poly, float64(di), float64(dj)))
+					if a > 0 {
+						d := dj*dst.Nx + di
+						cells[d] = append(cells[d], cellOverlap{src: c, area: a})
+					}
+				}
+			}
+		}
+	}
+	return cells, areas, nil
+}
+
+// polygonArea returns the area of poly.
+func polygonArea(poly [][2]float64) float64 {
+	var a float64
+	for i := range poly {
+		p, q := poly[i], poly[(i+1)%len(poly)]
+		a += p[0]*q[1] - q[0]*p[1]
+	}
+	return math.Abs(a) / 2
+}
+
+// clipRect returns the part of poly that is inside of the unit square
+// with its lower left corner at (x, y), using the Sutherland-Hodgman
+// algorithm.
+func clipRect(poly [][2]float64, x, y float64) [][2]float64 {
+	for edge := 0; edge < 4 && len(poly) > 0; edge++ {
+		axis, bound, below := edge%2, x+float64(edge/2), edge >= 2
+		if axis == 1 {
+			bound = y + float64(edge/2)
+		}
+		inside := func(p [2]float64) bool {
+			if below {
+				return p[axis] <= bound
+			}
+			return p[axis] >= bound
+		}
+		var out [][2]float64
+		for i, p := range poly {
+			q := poly[(i+1)%len(poly)]
+			if inside(p) {
+				out = append(out, p)
+			}
+			if inside(p) != inside(q) {
+				t := (bound - p[axis]) / (q[axis] - p[axis])
+				out = append(out, [2]float64{p[0] + t*(q[0]-p[0]), p[1] + t*(q[1]-p[1])})
+			}
+		}
+		poly = out
+	}
+	return poly
+}
+
+// overlaps returns the source cells that overlap each destination cell
+// along one axis.
+func overlaps(srcOrig, srcD float32, srcN int32, dstOrig, dstD float32, dstN int32) [][]overlap {
+	o := make([][]overlap, dstN)
+	for d := range o {
+		lo := float64(dstOrig) + float64(d)*float64(dstD)
+		hi := lo + float64(dstD)
+		first := int32(math.Floor((lo - float64(srcOrig)) / float64(srcD)))
+		for s := max(first, 0); s < srcN; s++ {
+			slo := float64(srcOrig) + float64(s)*float64(srcD)
+			if slo >= hi {
+				break
+			}
+			if l := math.Min(hi, slo+float64(srcD)) - math.Max(lo, slo); l > 0 {
+				o[d] = append(o[d], overlap{src: s, length: l})
+			}
+		}
+	}
+	return o
+}
+
+// Regrid returns the data for each species in Data, which must hold
+// Nx*Ny*Nz values on the source grid (in GLIndex order), on the
+// destination grid.
+func (r *Regridder) Regrid(Data map[string][]float32) (map[string][]float32, error) {
+	sn, dn := r.src.Nx*r.src.Ny, r.dst.Nx*r.dst.Ny
+	srcArea := float64(r.src.Dx) * float64(r.src.Dy)
+	out := make(map[string][]float32, len(Data))
+	for spname, vals := range Data {
+		if len(vals) != int(sn*r.src.Nz) {
+			return nil, fmt.Errorf("species %v has %d values; it should have %d",
+				spname, len(vals), sn*r.src.Nz)
+		}
+		res := make([]float32, dn*r.dst.Nz)
+		if r.cells != nil {
+			r.regridProjected(vals, res)
+			out[spname] = res
+			continue
+		}
+		for k := int32(0); k < r.dst.Nz; k++ {
+			for j, rows := range r.rows {
+				for i, cols := range r.cols {
+					var sum, area float64
+					for _, row := range rows {
+						for _, col := range cols {
+							a := row.length * col.length
+							sum += a * float64(vals[k*sn+row.src*r.src.Nx+col.src])
+							area += a
+						}
+					}
+					switch {
+					case r.extensive:
+						sum /= srcArea
+					case area > 0:
+						sum /= area
+					}
+					res[k*dn+int32(j)*r.dst.Nx+int32(i)] = float32(sum)
+				}
+			}
+		}
+		out[spname] = res
+	}
+	return out, nil
+}
+
+// regridProjected regrids vals into res between grids with different
+// projections.
+func (r *Regridder) regridProjected(vals, res []float32) {
+	sn, dn := r.src.Nx*r.src.Ny, r.dst.Nx*r.dst.Ny
+	for k := int32(0); k < r.dst.Nz; k++ {
+		for d, cells := range r.cells {
+			var sum, area float64
+			for _, c := range cells {
+				v := float64(vals[k*sn+c.src])
+				if r.extensive {
+					sum += v * c.area / r.srcAreas[c.src]
+				} else {
+					sum += v * c.area
+				}
+				area += c.area
+			}
+			if !r.extensive && area > 0 {
+				sum /= area
+			}
+			res[k*dn+int32(d)] = float32(sum)
+		}
+	}
+}
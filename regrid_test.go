@@ -0,0 +1,100 @@
+package uam_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/ctessum/uam"
+	"github.com/ctessum/uam/uamtest"
+)
+
+func TestRegrid(t *testing.T) {
+	src := uam.GridDef{Xorig: 0, Yorig: 0, Dx: 2, Dy: 2, Nx: 4, Ny: 3, Nz: 2}
+	// The destination cells are 3x3, covering the source grid with a
+	// margin, so each source cell is split between up to four of them.
+	dst := uam.GridDef{Xorig: -1, Yorig: -1, Dx: 3, Dy: 3, Nx: 4, Ny: 3, Nz: 2}
+
+	for _, name := range []string{"EMISSIONS", "AVERAGE"} {
+		h := src.Header(name)
+		r, err := uam.NewRegridder(h, dst.Header(name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		vals := make([]float32, src.Nx*src.Ny*src.Nz)
+		for i := range vals {
+			vals[i] = float32(i%7 + 1)
+		}
+		out, err := r.Regrid(map[string][]float32{"NO": vals, "ONE": constant(len(vals), 1)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(out["NO"]) != int(dst.Nx*dst.Ny*dst.Nz) {
+			t.Fatalf("%v: got %d values, want %d", name, len(out["NO"]), dst.Nx*dst.Ny*dst.Nz)
+		}
+		if name == "EMISSIONS" {
+			// Emissions are conserved.
+			if got, want := sum(out["NO"]), sum(vals); math.Abs(got-want) > 1e-4*want {
+				t.Errorf("total emissions are %g, want %g", got, want)
+			}
+			continue
+		}
+		// Averages of a constant are the constant where the grids
+		// overlap; destination column 3 is outside of the source grid.
+		for n, v := range out["ONE"] {
+			want := float32(1)
+			if int32(n)%dst.Nx == 3 {
+				want = 0
+			}
+			if math.Abs(float64(v-want)) > 1e-5 {
+				t.Errorf("cell %d: average of 1 is %g, want %g", n, v, want)
+			}
+		}
+	}
+
+	if _, err := uam.NewRegridder(src.Header("AVERAGE"), uam.GridDef{Dx: 1, Dy: 1, Nx: 1, Ny: 1, Nz: 1}.Header("AVERAGE")); err == nil {
+		t.Error("different numbers of layers: got no error")
+	}
+}
+
+func TestRegridFile(t *testing.T) {
+	f, err := uamtest.Emissions(uamtest.Options{Hours: 1, Pattern: uamtest.Gaussian(10, 2, 1, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := uamtest.DefaultGrid
+	g.Dx, g.Dy, g.Nx, g.Ny = g.Dx/2, g.Dy/2, g.Nx*2, g.Ny*2
+	r, err := uam.NewRegridder(f.Header(), g.Header("EMISSIONS"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := r.Regrid(f.Hours[0].Data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Each cell is split evenly into four.
+	for spname, vals := range f.Hours[0].Data {
+		for n, v := range out[spname] {
+			i, j, k := int32(n)%g.Nx, int32(n)/g.Nx%g.Ny, int32(n)/(g.Nx*g.Ny)
+			want := vals[(k*f.Ny+j/2)*f.Nx+i/2] / 4
+			if math.Abs(float64(v-want)) > 1e-5*float64(want) {
+				t.Fatalf("%v (%d, %d, %d): got %g, want %g", spname, i, j, k, v, want)
+			}
+		}
+	}
+}
+
+func constant(n int, v float32) []float32 {
+	vals := make([]float32, n)
+	for i := range vals {
+		vals[i] = v
+	}
+	return vals
+}
+
+func sum(vals []float32) float64 {
+	var s float64
+	for _, v := range vals {
+		s += float64(v)
+	}
+	return s
+}
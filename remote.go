@@ -0,0 +1,121 @@
+package uam
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// HTTPReaderAt reads byte ranges of a file over HTTP or HTTPS on demand,
+// using range requests, so that selected hours and species of a large
+// remote file can be read without downloading the whole file. It can be
+// used with NewReaderAt, or through OpenURL.
+type HTTPReaderAt struct {
+	URL    string
+	Client *http.Client // client to use; nil means http.DefaultClient
+	Header http.Header  // extra request headers, such as Authorization
+}
+
+func (h *HTTPReaderAt) client() *http.Client {
+	if h.Client == nil {
+		return http.DefaultClient
+	}
+	return h.Client
+}
+
+func (h *HTTPReaderAt) request(method string) (*http.Request, error) {
+	req, err := http.NewRequest(method, h.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range h.Header {
+		req.Header[k] = v
+	}
+	return req, nil
+}
+
+// ReadAt implements io.ReaderAt.
+func (h *HTTPReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	req, err := h.request(http.MethodGet)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", off, off+int64(len(p))-1))
+	resp, err := h.client().Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+	case http.StatusRequestedRangeNotSatisfiable:
+		return 0, io.EOF
+	case http.StatusOK:
+		return 0, fmt.Errorf("%v: server does not support range requests", h.URL)
+	default:
+		return 0, fmt.Errorf("%v: %v", h.URL, resp.Status)
+	}
+	n, err := io.ReadFull(resp.Body, p)
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF // The range extends past the end of the file.
+	}
+	return n, err
+}
+
+// Size returns the size of the remote file.
+func (h *HTTPReaderAt) Size() (int64, error) {
+	req, err := h.request(http.MethodHead)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := h.client().Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("%v: %v", h.URL, resp.Status)
+	}
+	if resp.ContentLength < 0 {
+		return 0, fmt.Errorf("%v: server did not report the file size", h.URL)
+	}
+	return resp.ContentLength, nil
+}
+
+// OpenURL opens a remote file for reading over HTTP or HTTPS, fetching
+// only the parts of the file that are needed (see HTTPReaderAt). Public
+// objects in Amazon S3 and Google Cloud Storage can be given as
+// s3://bucket/key and gs://bucket/key; private objects can be read
+// through presigned URLs.
+func OpenURL(url string, opts ...Option) (*UAM, error) {
+	h := &HTTPReaderAt{URL: objectURL(url)}
+	size, err := h.Size()
+	if err != nil {
+		return nil, err
+	}
+	f, err := NewReaderAt(h, size, opts...)
+	if err != nil {
+		return nil, err
+	}
+	f.name = url
+	return f, nil
+}
+
+// objectURL converts cloud storage URLs to their public HTTPS
+// equivalents. Each segment of the object key is percent-encoded, so
+// keys may hold characters such as spaces, "$", or "+".
+func objectURL(url string) string {
+	if rest, ok := strings.CutPrefix(url, "s3://"); ok {
+		bucket, key, _ := strings.Cut(rest, "/")
+		return "https://" + bucket + ".s3.amazonaws.com/" + s3Escape(key, false)
+	}
+	if rest, ok := strings.CutPrefix(url, "gs://"); ok {
+		bucket, key, _ := strings.Cut(rest, "/")
+		return "https://storage.googleapis.com/" + bucket + "/" + s3Escape(key, false)
+	}
+	return url
+}
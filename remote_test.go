@@ -0,0 +1,72 @@
+package uam_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ctessum/uam"
+	"github.com/ctessum/uam/uamtest"
+)
+
+func TestOpenURL(t *testing.T) {
+	e, err := uamtest.Emissions(uamtest.Options{Hours: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	if err = e.Write(&b); err != nil {
+		t.Fatal(err)
+	}
+	var ranges int
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/emis.uam":
+			if r.Header.Get("Range") != "" {
+				ranges++
+			}
+			http.ServeContent(w, r, "emis.uam", time.Time{}, bytes.NewReader(b.Bytes()))
+		case "/norange.uam":
+			w.Write(b.Bytes())
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer s.Close()
+
+	f, err := uam.OpenURL(s.URL + "/emis.uam")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if got := f.CompleteHours(); got != 3 {
+		t.Errorf("got %d hours, want 3", got)
+	}
+	if err = f.SeekHour(2); err != nil {
+		t.Fatal(err)
+	}
+	checkHours(t, readAll(t, f), e.Hours[2:])
+	if ranges == 0 {
+		t.Error("the file was not read with range requests")
+	}
+
+	// Reading past the end of the file gives io.EOF.
+	ra := &uam.HTTPReaderAt{URL: s.URL + "/emis.uam"}
+	p := make([]byte, 8)
+	if n, err := ra.ReadAt(p, int64(b.Len()-4)); n != 4 || err != io.EOF {
+		t.Errorf("past the end: got %d bytes and error %v", n, err)
+	}
+	if n, err := ra.ReadAt(p, 4); n != 8 || err != nil || !bytes.Equal(p, b.Bytes()[4:12]) {
+		t.Errorf("got %d bytes and error %v", n, err)
+	}
+
+	if _, err = uam.OpenURL(s.URL + "/norange.uam"); err == nil {
+		t.Error("no range support: got no error")
+	}
+	if _, err = uam.OpenURL(s.URL + "/missing.uam"); err == nil {
+		t.Error("missing file: got no error")
+	}
+}
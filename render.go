@@ -0,0 +1,79 @@
+package uam
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+)
+
+// Colormap maps a value in the range [0, 1] to a color.
+type Colormap func(v float64) color.Color
+
+// Diverging is a blue-white-red colormap that is suited to difference
+// plots, where 0.5 (white) represents no change.
+func Diverging(v float64) color.Color {
+	v = math.Max(0, math.Min(1, v))
+	if v < 0.5 {
+		c := uint8(255 * v * 2)
+		return color.RGBA{R: c, G: c, B: 255, A: 255}
+	}
+	c := uint8(255 * (1 - v) * 2)
+	return color.RGBA{R: 255, G: c, B: c, A: 255}
+}
+
+// sequentialStops are evenly spaced colors of the viridis colormap.
+var sequentialStops = []color.RGBA{
+	{0x44, 0x01, 0x54, 255}, {0x3b, 0x52, 0x8b, 255}, {0x21, 0x91, 0x8c, 255},
+	{0x5e, 0xc9, 0x62, 255}, {0xfd, 0xe7, 0x25, 255},
+}
+
+// Sequential is a dark blue to yellow (viridis) colormap that is suited
+// to concentrations and emissions, which increase from zero.
+func Sequential(v float64) color.Color {
+	v = math.Max(0, math.Min(1, v)) * float64(len(sequentialStops)-1)
+	n := min(int(v), len(sequentialStops)-2)
+	a, b, frac := sequentialStops[n], sequentialStops[n+1], v-float64(n)
+	mix := func(x, y uint8) uint8 { return uint8(float64(x) + frac*(float64(y)-float64(x)) + 0.5) }
+	return color.RGBA{R: mix(a.R, b.R), G: mix(a.G, b.G), B: mix(a.B, b.B), A: 255}
+}
+
+// Render creates an image of a 2D field with nx*ny values (in the
+// same order as the layers of a gridded file), where values from vmin to
+// vmax are spread across cmap and values outside of that range are
+// clamped. Each grid cell is drawn as a square of scale×scale pixels,
+// with row j = 0 at the bottom of the image. NaN values are transparent.
+func Render(field []float32, nx, ny int, vmin, vmax float32, cmap Colormap, scale int) *image.RGBA {
+	if scale < 1 {
+		scale = 1
+	}
+	img := image.NewRGBA(image.Rect(0, 0, nx*scale, ny*scale))
+	span := float64(vmax - vmin)
+	for j := 0; j < ny; j++ {
+		for i := 0; i < nx; i++ {
+			v := float64(field[j*nx+i])
+			if math.IsNaN(v) {
+				continue
+			}
+			frac := 0.5
+			if span != 0 {
+				frac = (v - float64(vmin)) / span
+			}
+			c := cmap(frac)
+			y0 := (ny - 1 - j) * scale
+			for y := y0; y < y0+scale; y++ {
+				for x := i * scale; x < (i+1)*scale; x++ {
+					img.Set(x, y, c)
+				}
+			}
+		}
+	}
+	return img
+}
+
+// WritePNG writes an image of a 2D field to w in PNG format. The
+// arguments are the same as for Render.
+func WritePNG(w io.Writer, field []float32, nx, ny int, vmin, vmax float32, cmap Colormap, scale int) error {
+	return png.Encode(w, Render(field, nx, ny, vmin, vmax, cmap, scale))
+}
@@ -0,0 +1,97 @@
+package uam
+
+import (
+	"fmt"
+	"io"
+)
+
+// TimeInterpolation is a way of estimating values between hours.
+type TimeInterpolation int
+
+const (
+	// LinearInterpolation interpolates linearly between the values at
+	// the start of consecutive hours. The values of the last hour are
+	// held constant until the end of the file.
+	LinearInterpolation TimeInterpolation = iota
+	// StepInterpolation holds the values of each hour constant until
+	// the start of the next.
+	StepInterpolation
+)
+
+// ResampleTime writes the data in the file called filename to a file
+// called outfile with time steps of the given number of minutes, which
+// must divide an hour evenly, for driving models that run on sub-hourly
+// inputs. The values of each time step are interpolated from the hourly
+// values, which are taken to apply at the start of each hour. Values are
+// not rescaled for the shorter time steps, so emission rates, for
+// example, remain per hour. The header, including the time span, is
+// copied from the original file; PTSOURCE override records are those of
+// the hour in which each time step starts.
+func ResampleTime(filename, outfile string, minutes int, interp TimeInterpolation) error {
+	if err := checkTimeStep(minutes); err != nil {
+		return err
+	}
+	if interp != LinearInterpolation && interp != StepInterpolation {
+		return fmt.Errorf("unknown time interpolation %d", interp)
+	}
+	f, err := Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w, err := Create(outfile, NewLike(f, 0))
+	if err != nil {
+		return err
+	}
+	if err = w.SetTimeStep(minutes); err != nil {
+		w.Close()
+		return err
+	}
+	if err = resampleHours(f, w, 60/minutes, interp); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// resampleHours writes steps time steps to w for each hour of f.
+func resampleHours(f *UAM, w *Writer, steps int, interp TimeInterpolation) error {
+	cur, err := f.ReadNextHour()
+	if err != nil {
+		if err == io.EOF {
+			err = fmt.Errorf("file has no hours")
+		}
+		return err
+	}
+	step := make(map[string][]float32, len(cur.Data))
+	buf := make(map[string][]float32, len(cur.Data)) // interpolated values
+	for cur != nil {
+		next, err := f.ReadNextHour()
+		if err == io.EOF {
+			next = nil
+		} else if err != nil {
+			return err
+		}
+		for s := 0; s < steps; s++ {
+			a := float32(s) / float32(steps)
+			for spname, v0 := range cur.Data {
+				if interp == StepInterpolation || next == nil || s == 0 {
+					step[spname] = v0
+					continue
+				}
+				v1 := next.Data[spname]
+				vals := reuse(buf[spname], len(v0))
+				buf[spname] = vals
+				for i := range vals {
+					vals[i] = (1-a)*v0[i] + a*v1[i]
+				}
+				step[spname] = vals
+			}
+			if err = w.WriteHourOverrides(step, cur.Overrides); err != nil {
+				return err
+			}
+		}
+		cur = next
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package uam_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ctessum/uam"
+	"github.com/ctessum/uam/uamtest"
+)
+
+func TestResampleTime(t *testing.T) {
+	dir := t.TempDir()
+	// The values are 1 in hour 0 and 3 in hour 1.
+	e, err := uamtest.Emissions(uamtest.Options{Hours: 2, Pattern: uamtest.Linear(1, 2, 0, 0, 0)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	filename := uamtest.TempFile(t, e)
+	for _, test := range []struct {
+		interp uam.TimeInterpolation
+		want   []float32
+	}{
+		{uam.LinearInterpolation, []float32{1, 2, 3, 3}},
+		{uam.StepInterpolation, []float32{1, 1, 3, 3}},
+	} {
+		outfile := filepath.Join(dir, "resampled.uam")
+		if err = uam.ResampleTime(filename, outfile, 30, test.interp); err != nil {
+			t.Fatal(err)
+		}
+		r, err := uam.Open(outfile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		steps := readAll(t, r)
+		r.Close()
+		if len(steps) != len(test.want) {
+			t.Fatalf("interpolation %d: got %d time steps, want %d", test.interp, len(steps), len(test.want))
+		}
+		for s, h := range steps {
+			if h.Date != 15001 || h.Time != float32(s)/2 {
+				t.Errorf("interpolation %d step %d: got time %d %g", test.interp, s, h.Date, h.Time)
+			}
+			for _, v := range h.Data["NO2"] {
+				if v != test.want[s] {
+					t.Fatalf("interpolation %d step %d: got %g, want %g", test.interp, s, v, test.want[s])
+				}
+			}
+		}
+	}
+
+	outfile := filepath.Join(dir, "bad.uam")
+	if err = uam.ResampleTime(filename, outfile, 7, uam.LinearInterpolation); err == nil {
+		t.Error("uneven time step: got no error")
+	}
+	if err = uam.ResampleTime(filename, outfile, 30, 2); err == nil {
+		t.Error("unknown interpolation: got no error")
+	}
+}
@@ -0,0 +1,122 @@
+package uam
+
+import (
+	"bytes"
+	"io"
+	"math"
+)
+
+// WithResync enables a best-effort recovery mode for salvaging data from
+// corrupt files. When an hour cannot be read, the reader scans forward for
+// the next plausible time record and resumes reading from there, and the
+// section of the file that was skipped is recorded (see Skipped). If no
+// further time record is found, ReadHour returns io.EOF. Recovery
+// requires random access to the file, as provided by Open and OpenMmap.
+func WithResync() Option {
+	return func(f *UAM) {
+		f.resync = true
+	}
+}
+
+// SkippedSpan is a section of a file that was skipped over while
+// recovering from a corrupt record.
+type SkippedSpan struct {
+	From, To int64 // byte offsets of the start and end of the section
+	Err      error // the error that caused the section to be skipped
+}
+
+// Skipped returns the sections of the file that have been skipped over
+// while recovering from corrupt records. See WithResync.
+func (f *UAM) Skipped() []SkippedSpan {
+	return f.skipped
+}
+
+// resyncAfter handles an error that occurred when reading the hour
+// starting at byte offset start by finding the next plausible time
+// record and reading the hour that starts there instead.
+func (f *UAM) resyncAfter(start int64, cause error, Data map[string][]float32) error {
+	ra := f.r.readerAt()
+	if ra == nil {
+		return cause
+	}
+	next, found, err := f.findTimeRecord(ra, start+1)
+	if err != nil {
+		return cause
+	}
+	f.skipped = append(f.skipped, SkippedSpan{From: start, To: next, Err: cause})
+	if f.log != nil {
+		f.log.Warn("skipped corrupt section", "from", start, "to", next,
+			"err", cause)
+	}
+	if _, err = f.r.Seek(next, io.SeekStart); err != nil {
+		return cause
+	}
+	if !found {
+		return io.EOF
+	}
+	if hs := f.hourSize(); (next-f.dataStart)%hs == 0 {
+		f.hour = int((next - f.dataStart) / hs)
+	} else {
+		f.hour++
+	}
+	return f.readHour(Data)
+}
+
+// findTimeRecord scans ra from byte offset from for the next plausible
+// time record. If none is found, it returns the offset of the end of
+// the file and found = false.
+func (f *UAM) findTimeRecord(ra io.ReaderAt, from int64) (off int64, found bool, err error) {
+	const chunk = 1 << 20
+	// A time record plus the leading marker of the record after it.
+	n := int(f.timeRecordSize() + f.markerLen())
+	buf := make([]byte, chunk+n)
+	for {
+		m, err := ra.ReadAt(buf, from)
+		if err != nil && err != io.EOF {
+			return 0, false, err
+		}
+		for i := 0; i+n <= m; i++ {
+			if f.plausibleTimeRecord(buf[i : i+n]) {
+				return from + int64(i), true, nil
+			}
+		}
+		if m < len(buf) {
+			return from + int64(m), false, nil
+		}
+		from += chunk
+	}
+}
+
+// plausibleTimeRecord returns whether b holds a time record, with
+// times and dates consistent with the header, followed by the start
+// of the first record of an hour.
+func (f *UAM) plausibleTimeRecord(b []byte) bool {
+	ml := int(f.markerLen())
+	if f.decodeMarker(b) != 16 || f.decodeMarker(b[ml+16:]) != 16 {
+		return false
+	}
+	next := f.decodeMarker(b[2*ml+16:])
+	if f.Name == "PTSOURCE" {
+		if next != 8 {
+			return false
+		}
+	} else if next != f.gridRecordSize()-2*int64(ml) {
+		return false
+	}
+	b = b[ml-4:] // Align the time values with 4-byte markers.
+	d1, d2 := int32(ByteOrder.Uint32(b[4:])), int32(ByteOrder.Uint32(b[12:]))
+	t1 := math.Float32frombits(ByteOrder.Uint32(b[8:]))
+	t2 := math.Float32frombits(ByteOrder.Uint32(b[16:]))
+	if d1 <= 0 || d2 < d1 || !plausibleTime(t1) || !plausibleTime(t2) {
+		return false
+	}
+	if f.edate >= f.sdate && f.sdate > 0 && (d1 < f.sdate || d2 > f.edate) {
+		return false
+	}
+	// A record of zeros is not a time record.
+	return !bytes.Equal(b[4:20], make([]byte, 16))
+}
+
+func plausibleTime(t float32) bool {
+	return t >= 0 && t <= 2400
+}
@@ -0,0 +1,330 @@
+package uam
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultPartSize is the default size in bytes of the parts of an S3
+// multipart upload.
+const DefaultPartSize = 8 << 20
+
+// minPartSize is the smallest part size allowed by S3, except for the
+// last part.
+const minPartSize = 5 << 20
+
+// S3Backend writes files to an Amazon S3 (or S3-compatible) bucket using
+// multipart uploads, so files of any size can be written without
+// holding them in memory. Requests are signed with AWS Signature
+// Version 4. An object only appears in the bucket once its writer has
+// been closed without error.
+type S3Backend struct {
+	Bucket string
+	Region string
+	Prefix string // prepended to the names of created objects
+
+	// Endpoint is the base URL of an S3-compatible service, such as
+	// "http://localhost:9000". Objects are addressed in path style
+	// (Endpoint/Bucket/key). If Endpoint is empty, the virtual-hosted
+	// AWS endpoint for Bucket and Region is used.
+	Endpoint string
+
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string // for temporary credentials; may be empty
+
+	PartSize int          // defaults to DefaultPartSize; at least 5 MiB
+	Client   *http.Client // defaults to http.DefaultClient
+}
+
+// Create implements Backend. It starts a multipart upload for the object
+// Prefix+name.
+func (b *S3Backend) Create(name string) (io.WriteCloser, error) {
+	partSize := b.PartSize
+	if partSize == 0 {
+		partSize = DefaultPartSize
+	}
+	if partSize < minPartSize {
+		return nil, fmt.Errorf("s3: part size %d is smaller than the minimum of %d bytes",
+			partSize, minPartSize)
+	}
+	u := &s3Upload{b: b, key: b.Prefix + name, partSize: partSize}
+	var res struct {
+		UploadID string `xml:"UploadId"`
+	}
+	if err := u.do(http.MethodPost, url.Values{"uploads": {""}}, nil, &res); err != nil {
+		return nil, err
+	}
+	u.id = res.UploadID
+	return u, nil
+}
+
+// objectURL returns the URL of the object with the given key.
+func (b *S3Backend) objectURL(key string) (*url.URL, error) {
+	if b.Endpoint == "" {
+		return url.Parse(fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s",
+			b.Bucket, b.Region, s3Escape(key, false)))
+	}
+	return url.Parse(strings.TrimSuffix(b.Endpoint, "/") + "/" + b.Bucket + "/" +
+		s3Escape(key, false))
+}
+
+// s3Upload is a multipart upload in progress.
+type s3Upload struct {
+	b        *S3Backend
+	key      string
+	id       string // upload ID
+	partSize int
+	buf      bytes.Buffer
+	parts    []s3Part
+	err      error // first error, after which the upload is aborted
+}
+
+type s3Part struct {
+	PartNumber int
+	ETag       string
+}
+
+func (u *s3Upload) Write(p []byte) (int, error) {
+	if u.err != nil {
+		return 0, u.err
+	}
+	n, _ := u.buf.Write(p)
+	for u.buf.Len() >= u.partSize {
+		if err := u.uploadPart(u.buf.Next(u.partSize)); err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// Close uploads any remaining data and completes the upload.
+func (u *s3Upload) Close() error {
+	if u.err != nil {
+		return u.err
+	}
+	if u.buf.Len() > 0 || len(u.parts) == 0 {
+		if err := u.uploadPart(u.buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	body, err := xml.Marshal(struct {
+		XMLName xml.Name `xml:"CompleteMultipartUpload"`
+		Parts   []s3Part `xml:"Part"`
+	}{Parts: u.parts})
+	if err != nil {
+		return err
+	}
+	// S3 can report an error in the body of a successful response.
+	var res struct {
+		XMLName xml.Name
+		Code    string
+		Message string
+	}
+	if err := u.do(http.MethodPost, url.Values{"uploadId": {u.id}}, body, &res); err != nil {
+		return u.abort(err)
+	}
+	if res.XMLName.Local == "Error" {
+		return u.abort(fmt.Errorf("s3: completing upload of %v: %v: %v",
+			u.key, res.Code, res.Message))
+	}
+	u.err = errors.New("s3: upload is closed")
+	return nil
+}
+
+func (u *s3Upload) uploadPart(data []byte) error {
+	n := len(u.parts) + 1
+	q := url.Values{"partNumber": {fmt.Sprint(n)}, "uploadId": {u.id}}
+	req, err := u.request(http.MethodPut, q, data)
+	if err != nil {
+		return u.abort(err)
+	}
+	resp, err := u.b.client().Do(req)
+	if err != nil {
+		return u.abort(err)
+	}
+	defer resp.Body.Close()
+	if err := s3Error(resp); err != nil {
+		return u.abort(err)
+	}
+	u.parts = append(u.parts, s3Part{PartNumber: n, ETag: resp.Header.Get("ETag")})
+	return nil
+}
+
+// abort cancels the upload after err, so that S3 does not keep the
+// parts that have already been uploaded.
+func (u *s3Upload) abort(err error) error {
+	u.err = err
+	u.do(http.MethodDelete, url.Values{"uploadId": {u.id}}, nil, nil)
+	return err
+}
+
+// do sends a request about the upload and decodes the XML response
+// into res, if it is not nil.
+func (u *s3Upload) do(method string, q url.Values, body []byte, res interface{}) error {
+	req, err := u.request(method, q, body)
+	if err != nil {
+		return err
+	}
+	resp, err := u.b.client().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := s3Error(resp); err != nil {
+		return err
+	}
+	if res == nil {
+		return nil
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(res); err != nil {
+		return fmt.Errorf("s3: decoding response: %w", err)
+	}
+	return nil
+}
+
+func (u *s3Upload) request(method string, q url.Values, body []byte) (*http.Request, error) {
+	loc, err := u.b.objectURL(u.key)
+	if err != nil {
+		return nil, err
+	}
+	loc.RawQuery = s3Query(q)
+	req, err := http.NewRequestWithContext(context.Background(), method, loc.String(),
+		bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	u.b.sign(req, body, time.Now())
+	return req, nil
+}
+
+func (b *S3Backend) client() *http.Client {
+	if b.Client != nil {
+		return b.Client
+	}
+	return http.DefaultClient
+}
+
+// s3Error returns an error describing resp if its status is not 2xx.
+func s3Error(resp *http.Response) error {
+	if resp.StatusCode/100 == 2 {
+		return nil
+	}
+	var e struct {
+		Code    string
+		Message string
+	}
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<16))
+	if xml.Unmarshal(msg, &e) == nil && e.Code != "" {
+		return fmt.Errorf("s3: %v %v: %v: %v", resp.Request.Method,
+			resp.Request.URL.Path, e.Code, e.Message)
+	}
+	return fmt.Errorf("s3: %v %v: %v", resp.Request.Method,
+		resp.Request.URL.Path, resp.Status)
+}
+
+// sign adds AWS Signature Version 4 authentication headers to req, whose
+// body is body. All headers already set on req are signed.
+func (b *S3Backend) sign(req *http.Request, body []byte, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := amzDate[:8]
+	sum := sha256.Sum256(body)
+	payloadHash := hex.EncodeToString(sum[:])
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if b.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", b.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		headers[strings.ToLower(k)] = strings.TrimSpace(strings.Join(v, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		s3Escape(req.URL.Path, false), // each segment encoded once
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := date + "/" + b.Region + "/s3/aws4_request"
+	crSum := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" +
+		hex.EncodeToString(crSum[:])
+
+	key := hmacSHA256([]byte("AWS4"+b.SecretAccessKey), date)
+	key = hmacSHA256(key, b.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+b.AccessKeyID+"/"+
+		scope+", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// s3Query encodes q in the canonical form used for signing: sorted by
+// key, with every key followed by "=".
+func s3Query(q url.Values) string {
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		for _, v := range q[k] {
+			parts = append(parts, s3Escape(k, true)+"="+s3Escape(v, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// s3Escape percent-encodes s as required by Signature Version 4, leaving
+// "/" unescaped unless escapeSlash is set.
+func s3Escape(s string, escapeSlash bool) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			sb.WriteByte(c)
+		case c == '/' && !escapeSlash:
+			sb.WriteByte(c)
+		default:
+			fmt.Fprintf(&sb, "%%%02X", c)
+		}
+	}
+	return sb.String()
+}
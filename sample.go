@@ -0,0 +1,86 @@
+package uam
+
+import (
+	"fmt"
+	"math"
+)
+
+// Sampler interpolates 2D fields on a grid to a fixed set of points by
+// bilinear interpolation between the centers of the four nearest cells.
+// Points between the outermost cell centers and the edge of the grid are
+// interpolated along the edge. The weights are calculated once, so a
+// Sampler can be reused for every hour and species, for example to match
+// model output to monitor locations.
+type Sampler struct {
+	n   int32        // number of values in a field
+	idx [][4]int32   // indices of the four cells around each point
+	wts [][4]float64 // weight of each of those cells
+}
+
+// NewSampler returns a Sampler for the points with projected
+// coordinates (x[n], y[n]) on grid g. The error wraps ErrOutsideDomain
+// if any point is outside of the grid.
+func (g GridDef) NewSampler(x, y []float64) (*Sampler, error) {
+	if len(x) != len(y) {
+		return nil, fmt.Errorf("there are %d x coordinates but %d y coordinates",
+			len(x), len(y))
+	}
+	s := &Sampler{n: g.Nx * g.Ny, idx: make([][4]int32, len(x)), wts: make([][4]float64, len(x))}
+	axis := func(v float64, orig, d float32, n int32) (lo, hi int32, frac float64, ok bool) {
+		u := (v - float64(orig)) / float64(d)
+		if !(u >= 0 && u <= float64(n)) {
+			return 0, 0, 0, false
+		}
+		u = math.Max(0, math.Min(float64(n-1), u-0.5)) // relative to cell centers
+		lo = min(int32(u), max(n-2, 0))
+		return lo, min(lo+1, n-1), u - float64(lo), true
+	}
+	for p := range x {
+		i0, i1, fx, okx := axis(x[p], g.Xorig, g.Dx, g.Nx)
+		j0, j1, fy, oky := axis(y[p], g.Yorig, g.Dy, g.Ny)
+		if !okx || !oky {
+			return nil, fmt.Errorf("%w: (%g, %g)", ErrOutsideDomain, x[p], y[p])
+		}
+		s.idx[p] = [4]int32{j0*g.Nx + i0, j0*g.Nx + i1, j1*g.Nx + i0, j1*g.Nx + i1}
+		s.wts[p] = [4]float64{(1 - fx) * (1 - fy), fx * (1 - fy), (1 - fx) * fy, fx * fy}
+	}
+	return s, nil
+}
+
+// NewLonLatSampler returns a Sampler for the points at longitude lon[n]
+// and latitude lat[n] (degrees) on grid g. See NewSampler.
+func (g GridDef) NewLonLatSampler(lon, lat []float64) (*Sampler, error) {
+	if len(lon) != len(lat) {
+		return nil, fmt.Errorf("there are %d longitudes but %d latitudes",
+			len(lon), len(lat))
+	}
+	p := g.Projection()
+	x, y := make([]float64, len(lon)), make([]float64, len(lat))
+	for n := range lon {
+		var err error
+		if x[n], y[n], err = p.Forward(lon[n], lat[n]); err != nil {
+			return nil, err
+		}
+	}
+	return g.NewSampler(x, y)
+}
+
+// Sample returns the value of field, which holds Nx*Ny values in the
+// same order as the layers of a gridded file, at each point of s.
+func (s *Sampler) Sample(field []float32) ([]float32, error) {
+	if len(field) != int(s.n) {
+		return nil, fmt.Errorf("field has %d values; it should have %d",
+			len(field), s.n)
+	}
+	out := make([]float32, len(s.idx))
+	for p, idx := range s.idx {
+		var v float64
+		for c, w := range s.wts[p] {
+			if w != 0 {
+				v += w * float64(field[idx[c]])
+			}
+		}
+		out[p] = float32(v)
+	}
+	return out, nil
+}
@@ -0,0 +1,69 @@
+package uam_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/ctessum/uam"
+	"github.com/ctessum/uam/uamtest"
+)
+
+func TestSampler(t *testing.T) {
+	g := uamtest.DefaultGrid
+	// A linear field is interpolated exactly between cell centers.
+	field := make([]float32, g.Nx*g.Ny)
+	for n := range field {
+		field[n] = float32(10*(int32(n)/g.Nx) + int32(n)%g.Nx)
+	}
+	// Points in units of cells from the SW corner of the grid, and the
+	// values there.
+	points := [][3]float64{
+		{1, 1, 5.5},
+		{2.25, 1.5, 11.75},
+		{0.2, 2.9, 20}, // between the outermost cell centers and the edge
+		{4, 0, 3},      // on the corner of the grid
+	}
+	var x, y []float64
+	for _, p := range points {
+		x = append(x, float64(g.Xorig)+p[0]*float64(g.Dx))
+		y = append(y, float64(g.Yorig)+p[1]*float64(g.Dy))
+	}
+	s, err := g.NewSampler(x, y)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.Sample(field)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for n, p := range points {
+		if math.Abs(float64(got[n])-p[2]) > 1e-5 {
+			t.Errorf("point (%g, %g): got %g, want %g", p[0], p[1], got[n], p[2])
+		}
+	}
+	if _, err = s.Sample(field[1:]); err == nil {
+		t.Error("wrong length: got no error")
+	}
+
+	// The center of cell (2, 1).
+	lon, lat, err := g.Projection().Inverse(float64(g.Xorig)+2.5*float64(g.Dx),
+		float64(g.Yorig)+1.5*float64(g.Dy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err = g.NewLonLatSampler([]float64{lon}, []float64{lat})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err = s.Sample(field); err != nil || math.Abs(float64(got[0])-12) > 1e-4 {
+		t.Errorf("longitude and latitude: got %v, %v, want 12", got, err)
+	}
+
+	if _, err = g.NewSampler([]float64{float64(g.Xorig) - 1}, []float64{float64(g.Yorig)}); !errors.Is(err, uam.ErrOutsideDomain) {
+		t.Errorf("outside of the grid: got error %v", err)
+	}
+	if _, err = g.NewSampler([]float64{0}, nil); err == nil {
+		t.Error("mismatched coordinates: got no error")
+	}
+}
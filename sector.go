@@ -0,0 +1,162 @@
+package uam
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// TagSeparator separates the species name from the sector name in the
+// names of tagged species created by TaggedHour.Flatten.
+var TagSeparator = "_"
+
+// TaggedHour is one hour of emissions summed over several sectors (for
+// example area, mobile, and biogenic) that keeps track of the
+// contribution of each sector, so that sector contributions can be
+// reported after merging.
+type TaggedHour struct {
+	Data    map[string][]float32            // sum over all sectors, by species
+	Sectors map[string]map[string][]float32 // contribution by sector and species
+}
+
+// NewTaggedHour returns an empty TaggedHour.
+func NewTaggedHour() *TaggedHour {
+	return &TaggedHour{
+		Data:    make(map[string][]float32),
+		Sectors: make(map[string]map[string][]float32),
+	}
+}
+
+// Add adds one hour of emissions from the named sector. The arrays for
+// each species must have the same length as those added previously.
+// Adding the same sector more than once accumulates its contribution.
+func (t *TaggedHour) Add(sector string, data map[string][]float32) error {
+	for spname, vals := range data {
+		if total, ok := t.Data[spname]; ok && len(total) != len(vals) {
+			return fmt.Errorf("sector %v species %v has %d values; it should "+
+				"have %d", sector, spname, len(vals), len(total))
+		}
+	}
+	if t.Sectors[sector] == nil {
+		t.Sectors[sector] = make(map[string][]float32)
+	}
+	for spname, vals := range data {
+		accumulate(t.Data, spname, vals)
+		accumulate(t.Sectors[sector], spname, vals)
+	}
+	return nil
+}
+
+// accumulate adds vals to the array for spname in m, creating it if
+// necessary.
+func accumulate(m map[string][]float32, spname string, vals []float32) {
+	sum, ok := m[spname]
+	if !ok {
+		sum = make([]float32, len(vals))
+		m[spname] = sum
+	}
+	for i, v := range vals {
+		sum[i] += v
+	}
+}
+
+// TagName returns the name of the tagged species that holds the
+// contribution of sector to species spname.
+func TagName(spname, sector string) string {
+	return spname + TagSeparator + sector
+}
+
+// Flatten returns the summed emissions together with the contribution of
+// each sector stored as a separate group of species named with TagName,
+// so that the sector contributions can be written to a file along with the
+// totals. It returns an error if a tagged species name is longer than the
+// 10 characters allowed in UAM files.
+func (t *TaggedHour) Flatten() (map[string][]float32, error) {
+	out := make(map[string][]float32, len(t.Data))
+	for spname, vals := range t.Data {
+		out[spname] = vals
+	}
+	for sector, data := range t.Sectors {
+		for spname, vals := range data {
+			name := TagName(spname, sector)
+			if len(name) > 10 {
+				return nil, fmt.Errorf("tagged species name %v is longer "+
+					"than 10 characters", name)
+			}
+			if _, ok := out[name]; ok {
+				return nil, fmt.Errorf("tagged species name %v is already "+
+					"in use", name)
+			}
+			out[name] = vals
+		}
+	}
+	return out, nil
+}
+
+// Untag reverses Flatten: it splits data that includes tagged species
+// for the given sectors into totals and sector contributions.
+func Untag(data map[string][]float32, sectors []string) *TaggedHour {
+	t := NewTaggedHour()
+	for spname, vals := range data {
+		tagged := false
+		for _, sector := range sectors {
+			suffix := TagSeparator + sector
+			if strings.HasSuffix(spname, suffix) {
+				if t.Sectors[sector] == nil {
+					t.Sectors[sector] = make(map[string][]float32)
+				}
+				t.Sectors[sector][strings.TrimSuffix(spname, suffix)] = vals
+				tagged = true
+				break
+			}
+		}
+		if !tagged {
+			t.Data[spname] = vals
+		}
+	}
+	return t
+}
+
+// SectorContribution is the contribution of one sector to the total
+// emissions of one species.
+type SectorContribution struct {
+	Sector   string
+	Species  string
+	Total    float64 // sum over all cells and hours
+	Fraction float64 // fraction of the total over all sectors
+}
+
+// SectorReport sums the contribution of each sector to each species
+// over all cells of the given hours. The results are sorted by species
+// and then by sector.
+func SectorReport(hours []*TaggedHour) []SectorContribution {
+	totals := make(map[[2]string]float64)
+	speciesTotals := make(map[string]float64)
+	for _, h := range hours {
+		for sector, data := range h.Sectors {
+			for spname, vals := range data {
+				var sum float64
+				for _, v := range vals {
+					sum += float64(v)
+				}
+				totals[[2]string{spname, sector}] += sum
+				speciesTotals[spname] += sum
+			}
+		}
+	}
+	report := make([]SectorContribution, 0, len(totals))
+	for key, total := range totals {
+		c := SectorContribution{Species: key[0], Sector: key[1], Total: total}
+		if st := speciesTotals[key[0]]; st != 0 {
+			c.Fraction = total / st
+		}
+		report = append(report, c)
+	}
+	sort.Slice(report, func(i, j int) bool {
+		if report[i].Species != report[j].Species {
+			return report[i].Species < report[j].Species
+		}
+		return report[i].Sector < report[j].Sector
+	})
+	return report
+}
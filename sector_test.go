@@ -0,0 +1,62 @@
+package uam_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ctessum/uam"
+)
+
+func TestTaggedHour(t *testing.T) {
+	h := uam.NewTaggedHour()
+	for sector, data := range map[string]map[string][]float32{
+		"area": {"NO": {1, 2}, "CO": {4, 0}},
+		"bio":  {"NO": {3, 2}},
+	} {
+		if err := h.Add(sector, data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Adding a sector again accumulates it.
+	if err := h.Add("bio", map[string][]float32{"NO": {0, 4}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Add("bio", map[string][]float32{"NO": {1}}); err == nil {
+		t.Error("wrong length: got no error")
+	}
+	want := map[string][]float32{"NO": {4, 8}, "CO": {4, 0}}
+	if !reflect.DeepEqual(h.Data, want) {
+		t.Errorf("got totals %v, want %v", h.Data, want)
+	}
+
+	flat, err := h.Flatten()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = map[string][]float32{
+		"NO": {4, 8}, "CO": {4, 0},
+		"NO_area": {1, 2}, "CO_area": {4, 0}, "NO_bio": {3, 6},
+	}
+	if !reflect.DeepEqual(flat, want) {
+		t.Errorf("flattened: got %v, want %v", flat, want)
+	}
+	if got := uam.Untag(flat, []string{"area", "bio"}); !reflect.DeepEqual(got, h) {
+		t.Errorf("untagged: got %+v, want %+v", got, h)
+	}
+
+	report := uam.SectorReport([]*uam.TaggedHour{h, h})
+	wantReport := []uam.SectorContribution{
+		{Sector: "area", Species: "CO", Total: 8, Fraction: 1},
+		{Sector: "area", Species: "NO", Total: 6, Fraction: 0.25},
+		{Sector: "bio", Species: "NO", Total: 18, Fraction: 0.75},
+	}
+	if !reflect.DeepEqual(report, wantReport) {
+		t.Errorf("report: got %+v, want %+v", report, wantReport)
+	}
+
+	long := uam.NewTaggedHour()
+	long.Add("biogenic", map[string][]float32{"NO2": {1}})
+	if _, err = long.Flatten(); err == nil {
+		t.Error("long tagged name: got no error")
+	}
+}
@@ -0,0 +1,149 @@
+package uam
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// SpeciesSplit is one entry in a table that maps the species of one
+// chemical mechanism to those of another: To receives Factor times the
+// amount of From. A species that is split among several species of the
+// target mechanism has one entry for each, and a species that is only
+// renamed has a single entry with a Factor of 1.
+type SpeciesSplit struct {
+	From, To string
+	Factor   float32
+}
+
+// SpeciesMap is a table of SpeciesSplits, such as one that converts
+// CB05 species to CB6 or SAPRC species. Several entries may have the
+// same To species, in which case their contributions are summed.
+type SpeciesMap []SpeciesSplit
+
+// ReadSpeciesMap reads a SpeciesMap from a text file with one entry per
+// line, made up of the species to convert from, the species to convert
+// to, and optionally the split factor, separated by whitespace. The
+// factor is 1 if it is omitted. Text following a '#' is ignored.
+func ReadSpeciesMap(r io.Reader) (SpeciesMap, error) {
+	var m SpeciesMap
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if c := strings.Index(text, "#"); c >= 0 {
+			text = text[:c]
+		}
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) < 2 || len(fields) > 3 {
+			return nil, fmt.Errorf("line %d: expected 2 or 3 fields but found %d", line, len(fields))
+		}
+		s := SpeciesSplit{From: fields[0], To: fields[1], Factor: 1}
+		if len(fields) == 3 {
+			v, err := strconv.ParseFloat(fields[2], 32)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			s.Factor = float32(v)
+		}
+		m = append(m, s)
+	}
+	return m, scanner.Err()
+}
+
+// Targets returns the species of the target mechanism, in the order in
+// which they first appear in m.
+func (m SpeciesMap) Targets() []string {
+	var out []string
+	seen := make(map[string]bool)
+	for _, s := range m {
+		if !seen[s.To] {
+			seen[s.To] = true
+			out = append(out, s.To)
+		}
+	}
+	return out
+}
+
+// Apply converts the species in Data, which holds arrays of the same
+// length for each species, to the species of the target mechanism. The
+// result holds an array for every species returned by Targets. Species
+// in m that are not in Data contribute nothing, and species in Data
+// that are not in m are dropped.
+func (m SpeciesMap) Apply(Data map[string][]float32) (map[string][]float32, error) {
+	n := -1
+	for spname, vals := range Data {
+		if n < 0 {
+			n = len(vals)
+		} else if len(vals) != n {
+			return nil, fmt.Errorf("species %v has %d values; it should have %d",
+				spname, len(vals), n)
+		}
+	}
+	n = max(n, 0)
+	out := make(map[string][]float32)
+	for _, s := range m {
+		res, ok := out[s.To]
+		if !ok {
+			res = make([]float32, n)
+			out[s.To] = res
+		}
+		for i, v := range Data[s.From] {
+			res[i] += v * s.Factor
+		}
+	}
+	return out, nil
+}
+
+// ConvertMechanism converts the species of every hour of the file called
+// filename with m, and writes the result to a file called outfile that
+// has the species returned by m.Targets, with the rest of its header (and
+// for PTSOURCE files, its stacks and override records) copied from the
+// original. If mechanism is not "", the new species are first checked
+// against that mechanism as with ValidateSpecies.
+func ConvertMechanism(filename, outfile string, m SpeciesMap, mechanism string) error {
+	species := m.Targets()
+	if len(species) == 0 {
+		return fmt.Errorf("the species map is empty")
+	}
+	if mechanism != "" {
+		if err := ValidateSpecies(mechanism, species); err != nil {
+			return err
+		}
+	}
+	f, err := Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	out := NewLike(f, 0)
+	out.Nspec = int32(len(species))
+	out.Spnames = species
+	w, err := Create(outfile, out)
+	if err != nil {
+		return err
+	}
+	for {
+		h, err := f.ReadNextHour()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			w.Close()
+			return err
+		}
+		res, err := m.Apply(h.Data)
+		if err != nil {
+			w.Close()
+			return err
+		}
+		if err = w.WriteHourOverrides(res, h.Overrides); err != nil {
+			w.Close()
+			return err
+		}
+	}
+	return w.Close()
+}
@@ -0,0 +1,76 @@
+package uam_test
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ctessum/uam"
+	"github.com/ctessum/uam/uamtest"
+)
+
+func TestConvertMechanism(t *testing.T) {
+	m, err := uam.ReadSpeciesMap(strings.NewReader(`# NO and NO2 are lumped.
+NO   NOX
+NO2  NOX  1
+O3   O3A  0.5 # half of the ozone
+
+CO   COX
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := uam.SpeciesMap{{"NO", "NOX", 1}, {"NO2", "NOX", 1}, {"O3", "O3A", 0.5}, {"CO", "COX", 1}}
+	if !reflect.DeepEqual(m, want) {
+		t.Fatalf("got %v, want %v", m, want)
+	}
+	if got := m.Targets(); !reflect.DeepEqual(got, []string{"NOX", "O3A", "COX"}) {
+		t.Errorf("got targets %v", got)
+	}
+
+	e, err := uamtest.Emissions(uamtest.Options{Hours: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	outfile := filepath.Join(t.TempDir(), "emis.uam")
+	if err = uam.ConvertMechanism(uamtest.TempFile(t, e), outfile, m, ""); err != nil {
+		t.Fatal(err)
+	}
+	r, err := uam.Open(outfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if !reflect.DeepEqual(r.Spnames, []string{"NOX", "O3A", "COX"}) {
+		t.Errorf("got species %v", r.Spnames)
+	}
+	hours := readAll(t, r)
+	if len(hours) != 2 {
+		t.Fatalf("got %d hours, want 2", len(hours))
+	}
+	for h, hr := range hours {
+		in := e.Hours[h].Data
+		for i := range in["NO"] {
+			if got, want := hr.Data["NOX"][i], in["NO"][i]+in["NO2"][i]; got != want {
+				t.Fatalf("hour %d NOX %d: got %g, want %g", h, i, got, want)
+			}
+			if got, want := hr.Data["O3A"][i], in["O3"][i]*0.5; got != want {
+				t.Fatalf("hour %d O3A %d: got %g, want %g", h, i, got, want)
+			}
+			if got := hr.Data["COX"][i]; got != 0 {
+				t.Fatalf("hour %d COX %d: got %g, want 0 for a missing species", h, i, got)
+			}
+		}
+	}
+
+	if _, err = uam.ReadSpeciesMap(strings.NewReader("NO NOX 1 2\n")); err == nil {
+		t.Error("too many fields: got no error")
+	}
+	if _, err = uam.ReadSpeciesMap(strings.NewReader("NO NOX x\n")); err == nil {
+		t.Error("invalid factor: got no error")
+	}
+	if err = uam.ConvertMechanism(uamtest.TempFile(t, e), outfile, nil, ""); err == nil {
+		t.Error("empty map: got no error")
+	}
+}
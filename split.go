@@ -0,0 +1,83 @@
+package uam
+
+import (
+	"fmt"
+	"io"
+)
+
+// WriteHours writes hours first through last-1 (counting from 0) of f
+// to w as a new file, such as a single day of a file that covers a
+// multi-day episode. The start and end of the new file are set to those
+// of the hours written; the rest of the header is copied from f. All
+// species must be read, so f must not have a species selection or
+// window. See SeekHour for the files in which first may be before the
+// next hour to be read.
+func (f *UAM) WriteHours(w io.Writer, first, last int) error {
+	out, err := f.hoursHeader(first, last)
+	if err != nil {
+		return err
+	}
+	wr, err := NewWriter(w, out)
+	if err != nil {
+		return err
+	}
+	return f.copyHours(wr, first, last)
+}
+
+// SplitHours writes hours first through last-1 of the file called
+// filename to a file called outfile. See WriteHours.
+func SplitHours(filename, outfile string, first, last int) error {
+	f, err := Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	out, err := f.hoursHeader(first, last)
+	if err != nil {
+		return err
+	}
+	w, err := Create(outfile, out)
+	if err != nil {
+		return err
+	}
+	if err = f.copyHours(w, first, last); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// hoursHeader checks that hours first through last-1 of f can be
+// written to a new file and returns the header of that file.
+func (f *UAM) hoursHeader(first, last int) (*UAM, error) {
+	if first < 0 || last <= first {
+		return nil, fmt.Errorf("invalid hour range %d to %d", first, last)
+	}
+	if f.selected != nil || f.window != nil {
+		return nil, fmt.Errorf("cannot write hours of a file with a species selection or window")
+	}
+	out := NewLike(f, 0)
+	out.sdate, out.begtim = AddHours(f.sdate, f.begtim, first)
+	out.edate, out.endtim = AddHours(f.sdate, f.begtim, last)
+	return out, nil
+}
+
+// copyHours writes hours first through last-1 of f to w.
+func (f *UAM) copyHours(w *Writer, first, last int) error {
+	if err := f.SeekHour(first); err != nil {
+		return err
+	}
+	for hour := first; hour < last; hour++ {
+		h, err := f.ReadNextHour()
+		if err == io.EOF {
+			return fmt.Errorf("hour %d is past the end of the file, which has %d hours",
+				hour, hour)
+		} else if err != nil {
+			return err
+		}
+		if err = w.WriteHourOverrides(h.Data, h.Overrides); err != nil {
+			return err
+		}
+	}
+	return nil
+}
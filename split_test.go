@@ -0,0 +1,73 @@
+package uam_test
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ctessum/uam"
+	"github.com/ctessum/uam/uamtest"
+)
+
+func TestSplitHours(t *testing.T) {
+	e, err := uamtest.Emissions(uamtest.Options{Hours: 30})
+	if err != nil {
+		t.Fatal(err)
+	}
+	filename := uamtest.TempFile(t, e)
+	outfile := filepath.Join(t.TempDir(), "day2.uam")
+	if err = uam.SplitHours(filename, outfile, 24, 30); err != nil {
+		t.Fatal(err)
+	}
+	r, err := uam.Open(outfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if h := r.Header(); h.Sdate != 15002 || h.Begtim != 0 || h.Edate != 15002 || h.Endtim != 6 {
+		t.Errorf("got times %d %g to %d %g, want 15002 0 to 15002 6", h.Sdate, h.Begtim, h.Edate, h.Endtim)
+	}
+	checkHours(t, readAll(t, r), e.Hours[24:])
+
+	if err = uam.SplitHours(filename, outfile, 24, 31); err == nil {
+		t.Error("past the end: got no error")
+	}
+	if err = uam.SplitHours(filename, outfile, 3, 3); err == nil {
+		t.Error("empty range: got no error")
+	}
+}
+
+func TestWriteHours(t *testing.T) {
+	pt, err := uamtest.PointSource(uamtest.Options{Hours: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The override records are copied along with the data.
+	pt.Hours[2].Overrides = make([]uam.StackOverride, pt.Npts)
+	for n := range pt.Hours[2].Overrides {
+		pt.Hours[2].Overrides[n].PlumeHeight = float32(n + 1)
+	}
+	r := open(t, pt)
+	readAll(t, r) // WriteHours seeks back.
+	var b bytes.Buffer
+	if err = r.WriteHours(&b, 1, 3); err != nil {
+		t.Fatal(err)
+	}
+	out, err := uam.NewBytesReader(b.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkHours(t, readAll(t, out), pt.Hours[1:3])
+	if !reflect.DeepEqual(out.Stacks(), pt.Stacks()) {
+		t.Errorf("got stacks %v, want %v", out.Stacks(), pt.Stacks())
+	}
+
+	r = open(t, pt)
+	if err = r.SelectSpecies([]string{"NO"}); err != nil {
+		t.Fatal(err)
+	}
+	if err = r.WriteHours(&b, 0, 1); err == nil {
+		t.Error("species selection: got no error")
+	}
+}
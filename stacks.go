@@ -0,0 +1,175 @@
+package uam
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// The stack parameters written by WriteStacksGeoJSON, WriteStacksCSV, and
+// WriteStacksKML, in order.
+var stackFields = []string{"height", "diameter", "temperature", "velocity", "pig"}
+
+// stackEmissions checks that f is a PTSOURCE file and that each entry of
+// emissions has one value per stack, and returns the species in
+// emissions in alphabetical order.
+func (f *UAM) stackEmissions(emissions map[string][]float32) ([]string, error) {
+	if f.Name != "PTSOURCE" {
+		return nil, fmt.Errorf("%v is not a PTSOURCE file", f.Name)
+	}
+	species := make([]string, 0, len(emissions))
+	for spname, vals := range emissions {
+		if len(vals) != int(f.Npts) {
+			return nil, fmt.Errorf("species %v has %d values; it should have %d",
+				spname, len(vals), f.Npts)
+		}
+		species = append(species, spname)
+	}
+	sort.Strings(species)
+	return species, nil
+}
+
+// stackParams returns the height (m), diameter (m), temperature (K),
+// and exit velocity (m/s) of stack s, and 1 if it is a PiG stack or 0
+// otherwise, as in Stack.
+func stackParams(s Stack) []float32 {
+	pig := float32(0)
+	if s.PiG {
+		pig = 1
+	}
+	return []float32{s.Height, s.Diameter, s.Temperature, s.Velocity, pig}
+}
+
+// WriteStacksGeoJSON writes the stacks of PTSOURCE file f to w as a
+// GeoJSON FeatureCollection of points in longitude and latitude. Each
+// point has the properties stack (the index of the stack), x and y (its
+// projected coordinates), height (m), diameter (m), temperature (K),
+// velocity (m/s), and pig (true for Plume-in-Grid stacks), as in Stack,
+// plus the emissions of the stack in each entry of emissions, which may
+// be nil and otherwise holds Npts values per species, such as the data
+// for one hour.
+func (f *UAM) WriteStacksGeoJSON(w io.Writer, emissions map[string][]float32) error {
+	species, err := f.stackEmissions(emissions)
+	if err != nil {
+		return err
+	}
+	p := f.Projection()
+	stacks := f.Stacks()
+	gw := newGeoJSONWriter(w)
+	for n := range f.Xcoord {
+		lon, lat, err := p.Inverse(float64(f.Xcoord[n]), float64(f.Ycoord[n]))
+		if err != nil {
+			return err
+		}
+		props := map[string]any{"stack": n, "x": f.Xcoord[n], "y": f.Ycoord[n]}
+		for c, v := range stackParams(stacks[n]) {
+			props[stackFields[c]] = jsonValue(v)
+		}
+		props["pig"] = stacks[n].PiG
+		for _, spname := range species {
+			props[spname] = jsonValue(emissions[spname][n])
+		}
+		err = gw.write(geoJSONFeature{
+			Type:       "Feature",
+			Geometry:   geoJSONGeometry{Type: "Point", Coordinates: [2]float64{lon, lat}},
+			Properties: props,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return gw.close()
+}
+
+// WriteStacksCSV writes the stacks of PTSOURCE file f to w in CSV
+// format, with one row per stack and the same fields as
+// WriteStacksGeoJSON, except that pig is 1 or 0. The lon and lat columns
+// are empty if the projection of f is not supported.
+func (f *UAM) WriteStacksCSV(w io.Writer, emissions map[string][]float32) error {
+	species, err := f.stackEmissions(emissions)
+	if err != nil {
+		return err
+	}
+	p := f.Projection()
+	stacks := f.Stacks()
+	cw := csv.NewWriter(w)
+	row := append([]string{"stack", "x", "y", "lon", "lat"}, stackFields...)
+	cw.Write(append(row, species...))
+	for n := range f.Xcoord {
+		row = append(row[:0], strconv.Itoa(n), ftoa32(f.Xcoord[n]), ftoa32(f.Ycoord[n]))
+		if lon, lat, err := p.Inverse(float64(f.Xcoord[n]), float64(f.Ycoord[n])); err == nil {
+			row = append(row, ftoa(lon), ftoa(lat))
+		} else {
+			row = append(row, "", "")
+		}
+		for _, v := range stackParams(stacks[n]) {
+			row = append(row, ftoa32(v))
+		}
+		for _, spname := range species {
+			row = append(row, ftoa32(emissions[spname][n]))
+		}
+		cw.Write(row)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteStacksKML writes the stacks of PTSOURCE file f to w as KML
+// placemarks, for viewing in Google Earth and similar tools. Each
+// placemark is named after the index of the stack and has the same
+// fields as WriteStacksCSV as extended data.
+func (f *UAM) WriteStacksKML(w io.Writer, emissions map[string][]float32) error {
+	species, err := f.stackEmissions(emissions)
+	if err != nil {
+		return err
+	}
+	type data struct {
+		Name  string `xml:"name,attr"`
+		Value string `xml:"value"`
+	}
+	type placemark struct {
+		Name        string `xml:"name"`
+		Data        []data `xml:"ExtendedData>Data"`
+		Coordinates string `xml:"Point>coordinates"`
+	}
+	type kml struct {
+		XMLName    xml.Name    `xml:"http://www.opengis.net/kml/2.2 kml"`
+		Name       string      `xml:"Document>name"`
+		Placemarks []placemark `xml:"Document>Placemark"`
+	}
+	p := f.Projection()
+	stacks := f.Stacks()
+	doc := kml{Name: f.Note, Placemarks: make([]placemark, f.Npts)}
+	for n := range doc.Placemarks {
+		lon, lat, err := p.Inverse(float64(f.Xcoord[n]), float64(f.Ycoord[n]))
+		if err != nil {
+			return err
+		}
+		pm := placemark{Name: strconv.Itoa(n), Coordinates: ftoa(lon) + "," + ftoa(lat)}
+		for c, v := range stackParams(stacks[n]) {
+			pm.Data = append(pm.Data, data{stackFields[c], ftoa32(v)})
+		}
+		for _, spname := range species {
+			pm.Data = append(pm.Data, data{spname, ftoa32(emissions[spname][n])})
+		}
+		doc.Placemarks[n] = pm
+	}
+	if _, err = io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err = enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "\n")
+	return err
+}
+
+// ftoa32 formats v with as few digits as identify it as a float32.
+func ftoa32(v float32) string {
+	return strconv.FormatFloat(float64(v), 'g', -1, 32)
+}
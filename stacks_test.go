@@ -0,0 +1,96 @@
+package uam_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/ctessum/uam/uamtest"
+)
+
+func TestWriteStacks(t *testing.T) {
+	f, err := uamtest.PointSource(uamtest.Options{Hours: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stacks := uamtest.DefaultStacks(uamtest.DefaultGrid)
+	// The PiG stack is stored with a negative diameter and velocities
+	// are stored in m/hr.
+	if f.StackDiam[2] != -5 || f.StackVel[2] != 20*3600 {
+		t.Fatalf("stored diameter and velocity are %g and %g; want -5 and 72000",
+			f.StackDiam[2], f.StackVel[2])
+	}
+
+	var b bytes.Buffer
+	if err = f.WriteStacksCSV(&b, f.Hours[0].Data); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&b).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"stack", "x", "y", "lon", "lat", "height", "diameter",
+		"temperature", "velocity", "pig", "NO", "NO2", "O3"}
+	if got := rows[0]; len(got) != len(want) {
+		t.Fatalf("got columns %v, want %v", got, want)
+	}
+	for n, s := range stacks {
+		row := rows[n+1]
+		pig := "0"
+		if s.PiG {
+			pig = "1"
+		}
+		for c, want := range map[int]string{6: fmt.Sprint(s.Diameter), 8: fmt.Sprint(s.Velocity), 9: pig} {
+			if row[c] != want {
+				t.Errorf("stack %d %v: got %v, want %v", n, rows[0][c], row[c], want)
+			}
+		}
+	}
+
+	b.Reset()
+	if err = f.WriteStacksGeoJSON(&b, nil); err != nil {
+		t.Fatal(err)
+	}
+	var fc struct {
+		Features []struct {
+			Properties struct {
+				Diameter, Velocity float32
+				PiG                bool `json:"pig"`
+			}
+		}
+	}
+	if err = json.Unmarshal(b.Bytes(), &fc); err != nil {
+		t.Fatal(err)
+	}
+	for n, s := range stacks {
+		got := fc.Features[n].Properties
+		if got.Diameter != s.Diameter || got.Velocity != s.Velocity || got.PiG != s.PiG {
+			t.Errorf("stack %d: got %+v, want diameter %g, velocity %g, pig %v",
+				n, got, s.Diameter, s.Velocity, s.PiG)
+		}
+	}
+}
+
+func TestStacks(t *testing.T) {
+	f, err := uamtest.PointSource(uamtest.Options{Hours: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := uamtest.DefaultStacks(uamtest.DefaultGrid)
+	if got := f.Stacks(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	// PiG stacks have negative diameters, and velocities are in m/hr.
+	if f.StackDiam[2] != -5 || f.StackDiam[1] != 2 || f.StackVel[0] != 5*3600 {
+		t.Errorf("got diameters %v and velocities %v", f.StackDiam, f.StackVel)
+	}
+	// A diameter of -0 is not negative.
+	f.StackDiam[0] = float32(math.Copysign(0, -1))
+	if f.Stacks()[0].PiG {
+		t.Error("a stack with a diameter of -0 is PiG")
+	}
+}
@@ -0,0 +1,57 @@
+package uam
+
+import "math"
+
+// Summary holds summary statistics of a set of values. The statistics
+// other than NaN and Negative are of the finite values only, and are NaN
+// if there are none.
+type Summary struct {
+	Min, Max float64
+	Mean     float64
+	Sum      float64
+	StdDev   float64 // population standard deviation
+	N        int     // number of finite values
+	NaN      int     // number of NaN values
+	Negative int     // number of negative values, including -Inf
+}
+
+// Summarize calculates summary statistics of vals in a single pass.
+func Summarize(vals []float32) Summary {
+	s := Summary{Min: math.Inf(1), Max: math.Inf(-1)}
+	var m2 float64 // sum of squared differences from the mean
+	for _, v32 := range vals {
+		v := float64(v32)
+		switch {
+		case math.IsNaN(v):
+			s.NaN++
+			continue
+		case v < 0:
+			s.Negative++
+		}
+		if math.IsInf(v, 0) {
+			continue
+		}
+		s.Min, s.Max = math.Min(s.Min, v), math.Max(s.Max, v)
+		s.Sum += v
+		s.N++
+		d := v - s.Mean
+		s.Mean += d / float64(s.N)
+		m2 += d * (v - s.Mean)
+	}
+	if s.N == 0 {
+		s.Min, s.Max, s.Mean, s.StdDev = math.NaN(), math.NaN(), math.NaN(), math.NaN()
+		return s
+	}
+	s.StdDev = math.Sqrt(m2 / float64(s.N))
+	return s
+}
+
+// Stats returns summary statistics of the values of each species in h,
+// over all layers and cells (or stacks).
+func (h *Hour) Stats() map[string]Summary {
+	stats := make(map[string]Summary, len(h.Data))
+	for spname, vals := range h.Data {
+		stats[spname] = Summarize(vals)
+	}
+	return stats
+}
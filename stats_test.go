@@ -0,0 +1,42 @@
+package uam_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/ctessum/uam"
+	"github.com/ctessum/uam/uamtest"
+)
+
+func TestSummarize(t *testing.T) {
+	nan, inf := float32(math.NaN()), float32(math.Inf(1))
+	s := uam.Summarize([]float32{2, 4, nan, 4, -inf, 4, 5, 5, 7, inf, -1})
+	// The finite values are 2, 4, 4, 4, 5, 5, 7, and -1.
+	want := uam.Summary{Min: -1, Max: 7, Mean: 3.75, Sum: 30, StdDev: math.Sqrt(39.5 / 8),
+		N: 8, NaN: 1, Negative: 2}
+	if math.Abs(s.StdDev-want.StdDev) > 1e-12 {
+		t.Errorf("got standard deviation %g, want %g", s.StdDev, want.StdDev)
+	}
+	s.StdDev = want.StdDev
+	if s != want {
+		t.Errorf("got %+v, want %+v", s, want)
+	}
+
+	s = uam.Summarize([]float32{nan})
+	if s.N != 0 || s.NaN != 1 || !math.IsNaN(s.Min) || !math.IsNaN(s.Mean) || !math.IsNaN(s.StdDev) {
+		t.Errorf("no finite values: got %+v", s)
+	}
+
+	e, err := uamtest.Emissions(uamtest.Options{Hours: 1, Pattern: uamtest.Linear(1, 0, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats := e.Hours[0].Stats()
+	if len(stats) != 3 {
+		t.Fatalf("got %d species, want 3", len(stats))
+	}
+	// The values are 1, 2, 3, and 4 in each row and layer.
+	if s := stats["NO2"]; s.N != 24 || s.Min != 1 || s.Max != 4 || s.Mean != 2.5 || s.Sum != 60 {
+		t.Errorf("NO2: got %+v", s)
+	}
+}
@@ -0,0 +1,121 @@
+package uam
+
+import (
+	"fmt"
+	"io"
+	"slices"
+)
+
+// fileSpan is the part of a file that is used when it is stitched to
+// the files before and after it.
+type fileSpan struct {
+	offset int // hours from the start of the first file to the start of this file
+	hours  int // number of hours used, starting with the first
+}
+
+// planStitch checks that files, which must be in time order, have the
+// same structure and follow one another without gaps, and returns the
+// hours of each file to use and the total number of hours. Where files
+// overlap, such as daily files that end with hour 24 and start with hour
+// 0, the hours from the later file are used.
+func planStitch(files []*UAM, filenames []string) ([]fileSpan, int, error) {
+	first := files[0]
+	spans := make([]fileSpan, len(files))
+	for n, f := range files {
+		if n > 0 {
+			if err := sameStructure(first, f); err != nil {
+				return nil, 0, fmt.Errorf("%v does not match %v: %w", filenames[n], filenames[0], err)
+			}
+			if err := first.GridDef().Check(f.GridDef()); err != nil {
+				return nil, 0, fmt.Errorf("%v does not match %v: %w", filenames[n], filenames[0], err)
+			}
+			if f.Name == "PTSOURCE" && !slices.Equal(first.Stacks(), f.Stacks()) {
+				return nil, 0, fmt.Errorf("%v does not have the same stacks as %v", filenames[n], filenames[0])
+			}
+		}
+		offset := hoursBetween(first.sdate, first.begtim, f.sdate, f.begtim)
+		hours := hoursBetween(f.sdate, f.begtim, f.edate, f.endtim)
+		if hours < 0 {
+			return nil, 0, fmt.Errorf("%v: invalid time span %d %g to %d %g",
+				filenames[n], f.sdate, f.begtim, f.edate, f.endtim)
+		}
+		if n > 0 {
+			prev := &spans[n-1]
+			switch {
+			case offset < 0 || offset <= prev.offset:
+				return nil, 0, fmt.Errorf("%v does not start after %v", filenames[n], filenames[n-1])
+			case offset > prev.offset+prev.hours:
+				return nil, 0, fmt.Errorf("there is a gap of %d hours between %v and %v",
+					offset-prev.offset-prev.hours, filenames[n-1], filenames[n])
+			}
+			prev.hours = offset - prev.offset
+		}
+		spans[n] = fileSpan{offset: offset, hours: hours}
+	}
+	last := spans[len(spans)-1]
+	return spans, last.offset + last.hours, nil
+}
+
+// StitchFiles concatenates files that cover consecutive periods of time,
+// such as the daily files of a simulation, into a single continuous file
+// called outfile. The files must be in time order and have the same
+// type, grid, and species (and, for PTSOURCE files, stacks). Where
+// consecutive files overlap, such as daily files that end with hour 24
+// of the day, the hours of the later file are used, so that each hour is
+// written once. It is an error for there to be a gap between files or
+// for the hours in a file not to match the time span in its header. The
+// header of the stitched file is copied from the first file, with the
+// end of the time span set to the end of the last file.
+func StitchFiles(outfile string, filenames []string) error {
+	if len(filenames) == 0 {
+		return fmt.Errorf("no files to stitch")
+	}
+	files := make([]*UAM, len(filenames))
+	for n, filename := range filenames {
+		f, err := Open(filename)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		files[n] = f
+	}
+	spans, total, err := planStitch(files, filenames)
+	if err != nil {
+		return err
+	}
+	out := NewLike(files[0], 0)
+	out.edate, out.endtim = AddHours(out.sdate, out.begtim, total)
+	w, err := Create(outfile, out)
+	if err != nil {
+		return err
+	}
+	for n, f := range files {
+		if err = f.copySpan(w, spans[n]); err != nil {
+			w.Close()
+			return fmt.Errorf("%v: %w", filenames[n], err)
+		}
+	}
+	return w.Close()
+}
+
+// copySpan writes the hours of f in span to w, checking that they start
+// at the expected times.
+func (f *UAM) copySpan(w *Writer, span fileSpan) error {
+	for hour := 0; hour < span.hours; hour++ {
+		h, err := f.ReadNextHour()
+		if err == io.EOF {
+			return fmt.Errorf("file has %d hours but its header says it has at least %d",
+				hour, span.hours)
+		} else if err != nil {
+			return err
+		}
+		if date, time := AddHours(w.f.sdate, w.f.begtim, span.offset+hour); h.Date != date || h.Time != time {
+			return fmt.Errorf("hour %d starts at %d %g; it should start at %d %g",
+				hour, h.Date, h.Time, date, time)
+		}
+		if err = w.WriteHourOverrides(h.Data, h.Overrides); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package uam_test
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ctessum/uam"
+	"github.com/ctessum/uam/uamtest"
+)
+
+func TestStitchFiles(t *testing.T) {
+	dir := t.TempDir()
+	e, err := uamtest.Emissions(uamtest.Options{Hours: 48})
+	if err != nil {
+		t.Fatal(err)
+	}
+	filename := uamtest.TempFile(t, e)
+	split := func(first, last int) string {
+		t.Helper()
+		out := filepath.Join(dir, fmt.Sprintf("%d-%d.uam", first, last))
+		if err := uam.SplitHours(filename, out, first, last); err != nil {
+			t.Fatal(err)
+		}
+		return out
+	}
+
+	// The first file overlaps the second by two hours.
+	outfile := filepath.Join(dir, "stitched.uam")
+	if err = uam.StitchFiles(outfile, []string{split(0, 26), split(24, 40), split(40, 48)}); err != nil {
+		t.Fatal(err)
+	}
+	r, err := uam.Open(outfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if r.Header() != e.Header() {
+		t.Errorf("got header %+v, want %+v", r.Header(), e.Header())
+	}
+	checkHours(t, readAll(t, r), e.Hours)
+
+	for name, test := range map[string]struct {
+		files []string
+		err   string
+	}{
+		"gap":      {[]string{split(0, 20), split(24, 48)}, "gap of 4 hours"},
+		"order":    {[]string{split(24, 48), split(0, 26)}, "does not start after"},
+		"same day": {[]string{split(0, 24), split(0, 24)}, "does not start after"},
+	} {
+		if err = uam.StitchFiles(outfile, test.files); err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%v: got error %v, want %q", name, err, test.err)
+		}
+	}
+	pt, err := uamtest.PointSource(uamtest.Options{Date: 15002})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = uam.StitchFiles(outfile, []string{split(0, 24), uamtest.TempFile(t, pt)}); err == nil {
+		t.Error("different file types: got no error")
+	}
+}
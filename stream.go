@@ -0,0 +1,158 @@
+package uam
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"math"
+)
+
+// DefaultBufferSize is the default size in bytes of the read buffer.
+const DefaultBufferSize = 1 << 20
+
+// Option configures how a file is read.
+type Option func(*UAM)
+
+// WithBufferSize sets the size in bytes of the buffer that is used when
+// reading the file. The default is DefaultBufferSize.
+func WithBufferSize(n int) Option {
+	return func(f *UAM) {
+		f.bufSize = n
+	}
+}
+
+// stream is a buffered reader that keeps track of its position and can
+// skip ahead without reading, if the underlying reader is seekable.
+// Alternatively, it can read directly from a byte slice holding the
+// whole file, such as a memory-mapped region.
+type stream struct {
+	r    *bufio.Reader
+	src  io.Reader
+	data []byte // whole file contents when reading from memory
+	mem  bool   // whether to read from data instead of r
+	off  int64  // bytes consumed so far
+	base int64  // position of src at the start of the stream, if it is an io.Seeker
+	slab []byte // reusable buffer for bulk decoding
+}
+
+func newStream(src io.Reader, size int) *stream {
+	if size <= 0 {
+		size = DefaultBufferSize
+	}
+	s := &stream{r: bufio.NewReaderSize(src, size), src: src}
+	if seeker, ok := src.(io.Seeker); ok {
+		// Positions are counted from where the stream starts, which need
+		// not be the start of src.
+		if pos, err := seeker.Seek(0, io.SeekCurrent); err == nil {
+			s.base = pos
+		}
+	}
+	return s
+}
+
+// newMemStream returns a stream that reads from data without buffering.
+func newMemStream(data []byte) *stream {
+	return &stream{data: data, mem: true}
+}
+
+func (s *stream) Read(p []byte) (n int, err error) {
+	if s.mem {
+		if s.off >= int64(len(s.data)) {
+			return 0, io.EOF
+		}
+		n = copy(p, s.data[s.off:])
+		s.off += int64(n)
+		return n, nil
+	}
+	n, err = s.r.Read(p)
+	s.off += int64(n)
+	return
+}
+
+// readFloats reads len(dst) values into dst using a single read.
+func (s *stream) readFloats(dst []float32) error {
+	n := 4 * len(dst)
+	var buf []byte
+	if s.mem {
+		// Decode directly from memory.
+		if s.off+int64(n) > int64(len(s.data)) {
+			s.off = int64(len(s.data))
+			return io.ErrUnexpectedEOF
+		}
+		buf = s.data[s.off : s.off+int64(n)]
+		s.off += int64(n)
+	} else {
+		if cap(s.slab) < n {
+			s.slab = make([]byte, n)
+		}
+		buf = s.slab[:n]
+		if _, err := io.ReadFull(s, buf); err != nil {
+			return err
+		}
+	}
+	for i := range dst {
+		dst[i] = math.Float32frombits(ByteOrder.Uint32(buf[4*i:]))
+	}
+	return nil
+}
+
+// Seek implements io.Seeker. Seeking backward, or to the end, is only
+// supported if the underlying reader is an io.Seeker or the stream reads
+// from memory. Forward seeks within the buffer, or on readers that are
+// not io.Seekers, are done by discarding data.
+func (s *stream) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+		offset -= s.off
+	case io.SeekCurrent:
+	default:
+		return s.off, errSeek
+	}
+	if s.mem {
+		if s.off+offset < 0 {
+			return s.off, errSeek
+		}
+		if s.off+offset > int64(len(s.data)) {
+			s.off = int64(len(s.data))
+			return s.off, io.ErrUnexpectedEOF
+		}
+		s.off += offset
+		return s.off, nil
+	}
+	if offset >= 0 && offset <= int64(s.r.Buffered()) {
+		n, err := s.r.Discard(int(offset))
+		s.off += int64(n)
+		return s.off, err
+	}
+	if seeker, ok := s.src.(io.Seeker); ok {
+		if _, err := seeker.Seek(s.base+s.off+offset, io.SeekStart); err != nil {
+			return s.off, err
+		}
+		s.r.Reset(s.src)
+		s.off += offset
+		return s.off, nil
+	}
+	if offset < 0 {
+		return s.off, errSeek
+	}
+	n, err := io.CopyN(io.Discard, s.r, offset)
+	s.off += n
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return s.off, err
+}
+
+// peek returns the next n bytes without consuming them. If fewer than n
+// bytes remain, it returns those that do along with io.EOF.
+func (s *stream) peek(n int) ([]byte, error) {
+	if s.mem {
+		if rest := s.data[s.off:]; len(rest) < n {
+			return rest, io.EOF
+		}
+		return s.data[s.off : s.off+int64(n)], nil
+	}
+	return s.r.Peek(n)
+}
+
+var errSeek = errors.New("seeking backward is not supported for this input")
@@ -0,0 +1,57 @@
+package uam
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// WithStrict enables checking of every Fortran record length marker in
+// the file against the length expected from the header. By default, only
+// the leading markers of data records are checked and the others are
+// skipped over. A marker that does not match causes an error wrapping
+// ErrBadRecordMarker that gives the offset of the marker, which makes
+// byte order and layout problems easy to find.
+func WithStrict() Option {
+	return func(f *UAM) {
+		f.strict = true
+	}
+}
+
+// beginRecord reads the leading marker of a record holding n bytes.
+func (f *UAM) beginRecord(n int64) error {
+	return f.checkMarker(n)
+}
+
+// endRecord reads the trailing marker of a record holding n bytes.
+func (f *UAM) endRecord(n int64) error {
+	return f.checkMarker(n)
+}
+
+// checkMarker reads a record marker and, in strict mode, checks that it
+// is n.
+func (f *UAM) checkMarker(n int64) error {
+	off := f.r.off
+	m, err := f.readMarker()
+	if err != nil || !f.strict {
+		return err
+	}
+	return f.markerError(m, n, off)
+}
+
+// markerError returns an error if the record marker m at byte offset off
+// is not n.
+func (f *UAM) markerError(m, n, off int64) error {
+	if m == n {
+		return nil
+	}
+	err := fmt.Errorf("%w: marker at byte %d is %d; it should be %d",
+		ErrBadRecordMarker, off, m, n)
+	swapped := int64(bits.ReverseBytes32(uint32(m)))
+	if f.markerLen() == 8 {
+		swapped = int64(bits.ReverseBytes64(uint64(m)))
+	}
+	if swapped == n {
+		err = fmt.Errorf("%w (the file may have the wrong byte order)", err)
+	}
+	return err
+}
@@ -0,0 +1,98 @@
+package uam
+
+import (
+	"fmt"
+	"io"
+)
+
+// Temperature holds the contents of a CAMx temperature file, which for
+// each hour contains a record of surface temperatures followed by a
+// record per layer of air temperatures.
+type Temperature struct {
+	Nx, Ny, Nz int32
+	Hour       []float32   // hour of each time step
+	Date       []int32     // date of each time step
+	Surface    [][]float32 // Surface[hour][j*Nx+i], K
+	Air        [][]float32 // Air[hour][GLIndex(k,j,i)], K
+}
+
+// ReadTemperature reads a CAMx temperature file for a grid with the
+// given number of cells and layers. Options such as WithMarkerSize and
+// WithStrict apply as they do to Open.
+func ReadTemperature(filename string, nx, ny, nz int32, opts ...Option) (*Temperature, error) {
+	f, err := openMet(filename, nx, ny, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	t := &Temperature{Nx: nx, Ny: ny, Nz: nz}
+	for {
+		surface := make([]float32, nx*ny)
+		hour, date, err := f.readMetRecord(surface)
+		if err == io.EOF && len(t.Hour) > 0 {
+			return t, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("reading temperature file %v: %v", filename, err)
+		}
+		air := make([]float32, nx*ny*nz)
+		for k := int32(0); k < nz; k++ {
+			if _, _, err = f.readMetRecord(air[k*nx*ny : (k+1)*nx*ny]); err != nil {
+				return nil, fmt.Errorf("reading temperature file %v: %v", filename, err)
+			}
+		}
+		t.Hour = append(t.Hour, hour)
+		t.Date = append(t.Date, date)
+		t.Surface = append(t.Surface, surface)
+		t.Air = append(t.Air, air)
+	}
+}
+
+// gasConstant is the molar gas constant (J/mol/K).
+const gasConstant = 8.314462618
+
+// MixingRatioToMass converts the gas-phase species in Data, which holds
+// one hour of an AVERAGE file in the format returned by ReadHour, from
+// mixing ratios in units of "ppm" (as written by CAMx) or "ppb" to mass
+// concentrations in µg/m³, in place. temp (K) and press (mb) hold the
+// air temperature and pressure in each cell of the same grid and hour,
+// such as Temperature.Air[h] and ZP.Pressure[h]. Aerosols (see
+// IsAerosol), which CAMx already writes in µg/m³, are not changed. The
+// molecular weights (g/mol) are taken from mw, or MolecularWeights if mw
+// is nil; it returns an error, without converting anything, if a
+// gas-phase species is not in the table.
+func MixingRatioToMass(Data map[string][]float32, units string, temp, press []float32, mw map[string]float64) error {
+	var scale float64 // mixing ratio per unit
+	switch units {
+	case "ppm":
+		scale = 1e-6
+	case "ppb":
+		scale = 1e-9
+	default:
+		return fmt.Errorf("unknown mixing ratio units %q; want ppm or ppb", units)
+	}
+	if len(temp) != len(press) {
+		return fmt.Errorf("there are %d temperatures but %d pressures",
+			len(temp), len(press))
+	}
+	for spname, vals := range Data {
+		if len(vals) != len(temp) {
+			return fmt.Errorf("species %v has %d values but there are %d temperatures",
+				spname, len(vals), len(temp))
+		}
+	}
+	// Convert each species to mass per mole of air, then multiply by the
+	// moles of air per m³ (P/RT) and 1e6 µg/g.
+	if err := ConvertToMass(Data, mw); err != nil {
+		return err
+	}
+	for spname, vals := range Data {
+		if IsAerosol(spname) {
+			continue
+		}
+		for i, v := range vals {
+			molPerM3 := float64(press[i]) * 100 / (gasConstant * float64(temp[i]))
+			vals[i] = float32(float64(v) * scale * molPerM3 * 1e6)
+		}
+	}
+	return nil
+}
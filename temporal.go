@@ -0,0 +1,133 @@
+package uam
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// TemporalProfile allocates daily emissions to the hours of the day.
+type TemporalProfile struct {
+	// Diurnal is the relative weight of each hour of the day (0-23) in
+	// local time. The weights need not sum to one.
+	Diurnal [24]float32
+	// Weekly is the relative weight of each day of the week, starting
+	// with Monday. If all weights are zero, every day is the same.
+	Weekly [7]float32
+	// Offset is the number of hours from the time zone of the file to
+	// local time, such as -5 for US Eastern Standard Time in a file
+	// whose times are UTC.
+	Offset int
+}
+
+// factors returns the fraction of a daily total that is emitted in
+// each of the 24 hours starting at date and time, adjusted by the
+// weight of the day of the week relative to an average day.
+func (p TemporalProfile) factors(date int32, hour float32) ([24]float32, error) {
+	var out [24]float32
+	dsum, err := sumWeights(p.Diurnal[:])
+	if err != nil {
+		return out, fmt.Errorf("diurnal profile: %w", err)
+	} else if dsum == 0 {
+		return out, fmt.Errorf("diurnal profile: all weights are zero")
+	}
+	wsum, err := sumWeights(p.Weekly[:])
+	if err != nil {
+		return out, fmt.Errorf("weekly profile: %w", err)
+	}
+	start := dateTime(date, hour).Add(time.Duration(p.Offset) * time.Hour)
+	for n := range out {
+		t := start.Add(time.Duration(n) * time.Hour)
+		out[n] = p.Diurnal[t.Hour()] / dsum
+		if wsum > 0 {
+			out[n] *= 7 * p.Weekly[(t.Weekday()+6)%7] / wsum
+		}
+	}
+	return out, nil
+}
+
+// sumWeights returns the sum of w, or an error if any weight is
+// negative.
+func sumWeights(w []float32) (float32, error) {
+	var sum float32
+	for _, v := range w {
+		if v < 0 {
+			return 0, fmt.Errorf("negative weight %g", v)
+		}
+		sum += v
+	}
+	return sum, nil
+}
+
+// dateTime converts a date in YYJJJ (or YYYYJJJ) format and a time in
+// hours to a time in UTC. Two-digit years before 70 are taken to be in
+// the 2000s.
+func dateTime(date int32, hour float32) time.Time {
+	year := int(date / 1000)
+	if year < 100 {
+		year += 1900
+		if year < 1970 {
+			year += 100
+		}
+	}
+	t := time.Date(year, time.January, int(date%1000), 0, 0, 0, 0, time.UTC)
+	return t.Add(time.Duration(float64(hour) * float64(time.Hour)))
+}
+
+// AllocateDaily makes a 24-hour EMISSIONS file in memory from the daily
+// total emissions of each species in daily, which must hold Nx*Ny*Nz
+// values (in GLIndex order) per species, such as a single layer of area
+// source emissions. The file starts at the date and time in h, which must
+// be an EMISSIONS header. The emissions in each hour are the daily total
+// multiplied by the fraction of the day's emissions in that hour from the
+// species' profile in profiles, or the profile with the key "" for
+// species that do not have their own. The daily totals are for an average
+// day of the week, so a day with twice the average weekly weight emits
+// twice the daily total.
+func AllocateDaily(h Header, daily map[string][]float32, profiles map[string]TemporalProfile) (*UAM, error) {
+	if h.Name != "EMISSIONS" {
+		return nil, fmt.Errorf("%v is not an EMISSIONS file", h.Name)
+	}
+	species := make([]string, 0, len(daily))
+	for spname := range daily {
+		species = append(species, spname)
+	}
+	sort.Strings(species)
+	h.Edate, h.Endtim = AddHours(h.Sdate, h.Begtim, 24)
+	f, err := NewGridded(h, species)
+	if err != nil {
+		return nil, err
+	}
+	n := int(h.Nx * h.Ny * h.Nz)
+	factors := make(map[string][24]float32, len(species))
+	for _, spname := range species {
+		if len(daily[spname]) != n {
+			return nil, fmt.Errorf("species %v has %d values; it should have %d",
+				spname, len(daily[spname]), n)
+		}
+		p, ok := profiles[spname]
+		if !ok {
+			if p, ok = profiles[""]; !ok {
+				return nil, fmt.Errorf("no temporal profile for species %v", spname)
+			}
+		}
+		if factors[spname], err = p.factors(h.Sdate, h.Begtim); err != nil {
+			return nil, fmt.Errorf("species %v: %w", spname, err)
+		}
+	}
+	for hour := 0; hour < 24; hour++ {
+		data := make(map[string][]float32, len(species))
+		for _, spname := range species {
+			fac := factors[spname][hour]
+			vals := make([]float32, n)
+			for i, v := range daily[spname] {
+				vals[i] = v * fac
+			}
+			data[spname] = vals
+		}
+		if err = f.AddHour(data); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
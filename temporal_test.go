@@ -0,0 +1,80 @@
+package uam_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/ctessum/uam"
+	"github.com/ctessum/uam/uamtest"
+)
+
+func TestAllocateDaily(t *testing.T) {
+	e, err := uamtest.Emissions(uamtest.Options{Hours: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := e.Header() // starting on Thursday, January 1, 2015
+	n := int(h.Nx * h.Ny * h.Nz)
+	daily := map[string][]float32{"NO": make([]float32, n), "CO": make([]float32, n)}
+	for _, vals := range daily {
+		for i := range vals {
+			vals[i] = 100
+		}
+	}
+	// Noon in US Eastern Standard Time, at 17 UTC, has twice the weight
+	// of other hours.
+	var peak uam.TemporalProfile
+	for hr := range peak.Diurnal {
+		peak.Diurnal[hr] = 1
+	}
+	peak.Diurnal[12] = 2
+	peak.Offset = -5
+	// Thursdays have twice the weight of other days.
+	var weekly uam.TemporalProfile
+	weekly.Diurnal = peak.Diurnal
+	weekly.Diurnal[12] = 1
+	weekly.Weekly = [7]float32{1, 1, 1, 2, 1, 1, 1}
+
+	f, err := uam.AllocateDaily(h, daily, map[string]uam.TemporalProfile{"": peak, "NO": weekly})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Hours) != 24 {
+		t.Fatalf("got %d hours, want 24", len(f.Hours))
+	}
+	if got := f.Header(); got.Edate != 15002 || got.Endtim != 0 {
+		t.Errorf("got end time %d %g", got.Edate, got.Endtim)
+	}
+	var total float64
+	for hr, hour := range f.Hours {
+		want := float32(100) / 25
+		if hr == 17 {
+			want *= 2
+		}
+		if got := hour.Data["CO"][n-1]; math.Abs(float64(got-want)) > 1e-5 {
+			t.Errorf("CO hour %d: got %g, want %g", hr, got, want)
+		}
+		total += float64(hour.Data["CO"][0])
+		if got, want := hour.Data["NO"][0], float32(100)/24*1.75; math.Abs(float64(got-want)) > 1e-5 {
+			t.Errorf("NO hour %d: got %g, want %g", hr, got, want)
+		}
+	}
+	if math.Abs(total-100) > 1e-4 {
+		t.Errorf("CO: got a daily total of %g, want 100", total)
+	}
+
+	if _, err = uam.AllocateDaily(h, daily, map[string]uam.TemporalProfile{"NO": weekly}); err == nil {
+		t.Error("no profile for CO: got no error")
+	}
+	weekly.Weekly[0] = -1
+	if _, err = uam.AllocateDaily(h, daily, map[string]uam.TemporalProfile{"": weekly}); err == nil {
+		t.Error("negative weight: got no error")
+	}
+	a, err := uamtest.Average(uamtest.Options{Hours: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = uam.AllocateDaily(a.Header(), daily, map[string]uam.TemporalProfile{"": peak}); err == nil {
+		t.Error("AVERAGE header: got no error")
+	}
+}
@@ -0,0 +1,129 @@
+package uam
+
+import "io"
+
+// TidyRecord is a single value in long ("tidy") format. For PTSOURCE
+// files, I is the index of the stack and J and Layer are -1.
+type TidyRecord struct {
+	Hour    int     // index of the hour in the file
+	Date    int32   // start date of the hour (YYJJJ)
+	Time    float32 // start time of the hour
+	Species string
+	Layer   int32
+	J, I    int32
+	Value   float32
+}
+
+// TidyFilter restricts the values returned by a TidyIterator.
+type TidyFilter struct {
+	Species  []string // species to include; nil means all
+	Layers   []int32  // layers to include; nil means all
+	Hours    []int    // indices of the hours to include; nil means all
+	SkipZero bool     // whether to leave out values that are zero
+}
+
+// TidyIterator iterates over the values in a file in long format, one
+// record per value, which is convenient for loading into data frames and
+// databases. Only one hour of data is held in memory at a time.
+type TidyIterator struct {
+	f      *UAM
+	filter TidyFilter
+	layers map[int32]bool
+	hours  map[int]bool
+	data   map[string][]float32
+	rec    TidyRecord
+	l      int // index of the current species
+	idx    int // index of the next value of the current species
+	nx, ny int32
+	loaded bool
+	err    error
+}
+
+// Tidy returns an iterator over the values in the remaining hours of f
+// that match filter. If filter.Species is set, it is passed to
+// SelectSpecies so that other species are not decoded. Reading f in any
+// other way while iterating gives undefined results.
+func (f *UAM) Tidy(filter TidyFilter) *TidyIterator {
+	it := &TidyIterator{f: f, filter: filter, data: make(map[string][]float32)}
+	if filter.Species != nil {
+		it.err = f.SelectSpecies(filter.Species)
+	}
+	if filter.Hours != nil {
+		it.hours = make(map[int]bool, len(filter.Hours))
+		for _, h := range filter.Hours {
+			it.hours[h] = true
+		}
+	}
+	if filter.Layers != nil {
+		it.layers = make(map[int32]bool, len(filter.Layers))
+		for _, k := range filter.Layers {
+			it.layers[k] = true
+		}
+	}
+	return it
+}
+
+// Next advances to the next record, returning false when there are no
+// more records or an error occurs.
+func (it *TidyIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	f := it.f
+	for {
+		if !it.loaded {
+			if _, _, _, _, _, _, err := f.ReadHour(it.data); err != nil {
+				if err != io.EOF {
+					it.err = err
+				}
+				return false
+			}
+			if it.hours != nil && !it.hours[f.hour-1] {
+				continue
+			}
+			it.loaded, it.l, it.idx = true, 0, 0
+			it.rec.Hour, it.rec.Date, it.rec.Time = f.hour-1, f.date, f.time
+			_, _, it.nx, it.ny = f.WindowGrid()
+		}
+		for ; it.l < len(f.Spnames); it.l, it.idx = it.l+1, 0 {
+			spname := f.Spnames[it.l]
+			vals, ok := it.data[spname]
+			if !ok || !f.isSelected(spname) {
+				continue
+			}
+			for it.idx < len(vals) {
+				i := it.idx
+				it.idx++
+				if it.filter.SkipZero && vals[i] == 0 {
+					continue
+				}
+				it.rec.Species, it.rec.Value = spname, vals[i]
+				if f.Name == "PTSOURCE" {
+					it.rec.Layer, it.rec.J, it.rec.I = -1, -1, int32(i)
+				} else {
+					nx, ny := it.nx, it.ny
+					k, j, ii := int32(i)/(nx*ny), int32(i)/nx%ny, int32(i)%nx
+					if it.layers != nil && !it.layers[k] {
+						continue
+					}
+					if f.window != nil {
+						j, ii = j+f.window.j1, ii+f.window.i1
+					}
+					it.rec.Layer, it.rec.J, it.rec.I = k, j, ii
+				}
+				return true
+			}
+		}
+		it.loaded = false
+	}
+}
+
+// Record returns the current record.
+func (it *TidyIterator) Record() TidyRecord {
+	return it.rec
+}
+
+// Err returns the error, if any, that stopped the iteration.
+func (it *TidyIterator) Err() error {
+	return it.err
+}
@@ -0,0 +1,79 @@
+package uam_test
+
+import (
+	"testing"
+
+	"github.com/ctessum/uam"
+	"github.com/ctessum/uam/uamtest"
+)
+
+func TestTidy(t *testing.T) {
+	e, err := uamtest.Emissions(uamtest.Options{Hours: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := open(t, e)
+	if err = r.SetWindow(1, 3, 0, 2); err != nil {
+		t.Fatal(err)
+	}
+	it := r.Tidy(uam.TidyFilter{Species: []string{"NO2"}, Layers: []int32{1}, Hours: []int{1, 2}})
+	n := 0
+	for it.Next() {
+		rec := it.Record()
+		if rec.Species != "NO2" || rec.Layer != 1 || rec.I < 1 || rec.I > 2 || rec.J > 1 {
+			t.Fatalf("got %+v", rec)
+		}
+		if hr := e.Hours[rec.Hour]; rec.Hour < 1 || rec.Date != hr.Date || rec.Time != hr.Time {
+			t.Errorf("got time %+v", rec)
+		}
+		if want := uamtest.Index(1, rec.Hour, rec.Layer, rec.J, rec.I); rec.Value != want {
+			t.Errorf("got %+v, want value %g", rec, want)
+		}
+		n++
+	}
+	if err = it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if n != 2*2*2 {
+		t.Errorf("got %d records, want 8", n)
+	}
+
+	// Only the value at cell (0, 0) of each species in layer 0 is zero.
+	e, err = uamtest.Emissions(uamtest.Options{Hours: 1, Pattern: uamtest.Linear(0, 0, 1, 1, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	it = open(t, e).Tidy(uam.TidyFilter{SkipZero: true})
+	n = 0
+	for it.Next() {
+		if it.Record().Value == 0 {
+			t.Errorf("got zero record %+v", it.Record())
+		}
+		n++
+	}
+	if want := 3 * (24 - 1); n != want {
+		t.Errorf("skipping zeros: got %d records, want %d", n, want)
+	}
+
+	pt, err := uamtest.PointSource(uamtest.Options{Hours: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	it = open(t, pt).Tidy(uam.TidyFilter{Species: []string{"O3"}})
+	n = 0
+	for it.Next() {
+		rec := it.Record()
+		if rec.Layer != -1 || rec.J != -1 || rec.I != int32(n) ||
+			rec.Value != uamtest.Index(2, 0, 0, 0, rec.I) {
+			t.Errorf("PTSOURCE: got %+v", rec)
+		}
+		n++
+	}
+	if n != int(pt.Npts) {
+		t.Errorf("PTSOURCE: got %d records, want %d", n, pt.Npts)
+	}
+
+	if it = open(t, pt).Tidy(uam.TidyFilter{Species: []string{"CO"}}); it.Next() || it.Err() == nil {
+		t.Error("missing species: got no error")
+	}
+}
@@ -0,0 +1,34 @@
+package uam
+
+// WithTolerant enables reading of files that end partway through an
+// hour, such as those left behind by a model run that crashed. Instead of
+// returning an error for the incomplete hour, ReadHour returns io.EOF
+// after the last complete hour, and Truncated reports where the file
+// ends.
+func WithTolerant() Option {
+	return func(f *UAM) {
+		f.tolerant = true
+	}
+}
+
+// Truncated returns the error, wrapping ErrTruncatedFile, that was
+// ignored when the end of a truncated file was reached in tolerant mode
+// (see WithTolerant), or nil if no truncation has been found.
+func (f *UAM) Truncated() error {
+	return f.truncated
+}
+
+// CompleteHours returns the number of complete hours in the file,
+// calculated from the size of the file, or -1 if the size is not
+// known. For files that have not been truncated, this is the number
+// of hours in the file.
+func (f *UAM) CompleteHours() int {
+	size := f.fileSize()
+	if size < 0 {
+		return -1
+	}
+	if size < f.dataStart {
+		return 0
+	}
+	return int((size - f.dataStart) / f.hourSize())
+}
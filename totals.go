@@ -0,0 +1,80 @@
+package uam
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// DailyTotal holds the domain-wide emissions of each species on one
+// day.
+type DailyTotal struct {
+	Date  int32 // YYJJJ
+	Hours int   // number of hours included in the totals
+	// Totals holds the sum of each species over all hours, layers, and
+	// cells (or stacks), in the units given in Units.
+	Totals map[string]float64
+	// Units holds the units of each total: mol for gas-phase species and
+	// g for aerosols (see IsAerosol), or g for all species if the file
+	// was read with WithMassUnits.
+	Units map[string]string
+}
+
+// EmissionTotals reads the remaining hours of EMISSIONS or PTSOURCE file
+// f and returns the domain-wide total of each selected species on each
+// day, in the order of the days in the file, for comparison with the
+// summaries produced by emissions processors.
+func (f *UAM) EmissionTotals() ([]DailyTotal, error) {
+	if f.Name != "EMISSIONS" && f.Name != "PTSOURCE" {
+		return nil, fmt.Errorf("emission totals cannot be calculated for %v files", f.Name)
+	}
+	var totals []DailyTotal
+	data := make(map[string][]float32)
+	for {
+		if err := f.readHour(data); errors.Is(err, io.EOF) {
+			return totals, nil
+		} else if err != nil {
+			return nil, err
+		}
+		if len(totals) == 0 || totals[len(totals)-1].Date != f.date {
+			totals = append(totals, DailyTotal{Date: f.date,
+				Totals: make(map[string]float64), Units: make(map[string]string)})
+		}
+		day := &totals[len(totals)-1]
+		day.Hours++
+		for spname, vals := range data {
+			var sum float64
+			for _, v := range vals {
+				sum += float64(v)
+			}
+			day.Totals[spname] += sum
+			day.Units[spname] = "mol"
+			if f.massUnits != nil || IsAerosol(spname) {
+				day.Units[spname] = "g"
+			}
+		}
+	}
+}
+
+// WriteTotalsCSV writes totals to w in CSV format, with one row per
+// day and species giving the number of hours, the units, and the total.
+func WriteTotalsCSV(w io.Writer, totals []DailyTotal) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"date", "hours", "species", "units", "total"})
+	for _, day := range totals {
+		species := make([]string, 0, len(day.Totals))
+		for spname := range day.Totals {
+			species = append(species, spname)
+		}
+		sort.Strings(species)
+		for _, spname := range species {
+			cw.Write([]string{strconv.Itoa(int(day.Date)), strconv.Itoa(day.Hours),
+				spname, day.Units[spname], strconv.FormatFloat(day.Totals[spname], 'g', -1, 64)})
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,60 @@
+package uam_test
+
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ctessum/uam"
+	"github.com/ctessum/uam/uamtest"
+)
+
+func TestEmissionTotals(t *testing.T) {
+	// 30 hours span two days.
+	f, err := uamtest.Emissions(uamtest.Options{Hours: 30, Species: []string{"NO", "PSO4"},
+		Pattern: uamtest.Constant(0.5)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cells := float64(f.Nx * f.Ny * f.Nz)
+	want := []uam.DailyTotal{
+		{Date: 15001, Hours: 24, Totals: map[string]float64{"NO": 12 * cells, "PSO4": 12 * cells},
+			Units: map[string]string{"NO": "mol", "PSO4": "g"}},
+		{Date: 15002, Hours: 6, Totals: map[string]float64{"NO": 3 * cells, "PSO4": 3 * cells},
+			Units: map[string]string{"NO": "mol", "PSO4": "g"}},
+	}
+	totals, err := open(t, f).EmissionTotals()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(totals, want) {
+		t.Errorf("got %+v, want %+v", totals, want)
+	}
+
+	// In mass units, all of the totals are in g.
+	totals, err = open(t, f, uam.WithMassUnits(nil)).EmissionTotals()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := totals[1].Totals["NO"], 3*cells*uam.MolecularWeights["NO"]; math.Abs(got-want) > 1e-4*want {
+		t.Errorf("got NO total %g g, want %g", got, want)
+	}
+	var b bytes.Buffer
+	if err = uam.WriteTotalsCSV(&b, totals[1:]); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(b.String(), "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[1], "15002,6,NO,g,") || lines[2] != "15002,6,PSO4,g,72" {
+		t.Errorf("got CSV\n%s", b.String())
+	}
+
+	a, err := uamtest.Average(uamtest.Options{Hours: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = open(t, a).EmissionTotals(); err == nil {
+		t.Error("totals of an AVERAGE file: got no error")
+	}
+}
@@ -0,0 +1,88 @@
+package uam
+
+import (
+	"fmt"
+	"math"
+)
+
+// TransectCells returns the columns and rows of the cells of grid g that
+// are crossed by the line through waypoints, which are longitude and
+// latitude pairs (degrees), in order from the first waypoint and without
+// repeats. The line is straight in the projected coordinates of g
+// between waypoints. The error wraps ErrOutsideDomain if any part of the
+// line is outside of the grid.
+func (g GridDef) TransectCells(waypoints [][2]float64) ([][2]int32, error) {
+	if len(waypoints) == 0 {
+		return nil, fmt.Errorf("no waypoints")
+	}
+	p := g.Projection()
+	pts := make([][2]float64, len(waypoints))
+	for n, wp := range waypoints {
+		if _, _, err := g.CellAt(wp[0], wp[1]); err != nil {
+			return nil, err
+		}
+		x, y, _ := p.Forward(wp[0], wp[1])
+		pts[n] = [2]float64{(x - float64(g.Xorig)) / float64(g.Dx),
+			(y - float64(g.Yorig)) / float64(g.Dy)}
+	}
+	var cells [][2]int32
+	add := func(fi, fj float64) {
+		// Points on the far edge of the grid belong to the last cell.
+		c := [2]int32{min(int32(fi), g.Nx-1), min(int32(fj), g.Ny-1)}
+		if len(cells) == 0 || cells[len(cells)-1] != c {
+			cells = append(cells, c)
+		}
+	}
+	add(pts[0][0], pts[0][1])
+	for n := 1; n < len(pts); n++ {
+		a, b := pts[n-1], pts[n]
+		// Sample the segment at intervals of a quarter of a cell.
+		steps := int(math.Ceil(4 * math.Max(math.Abs(b[0]-a[0]), math.Abs(b[1]-a[1]))))
+		for s := 1; s <= steps; s++ {
+			t := float64(s) / float64(steps)
+			add(a[0]+t*(b[0]-a[0]), a[1]+t*(b[1]-a[1]))
+		}
+	}
+	return cells, nil
+}
+
+// CrossSection is a vertical cross-section of one species along a line
+// of grid cells.
+type CrossSection struct {
+	Cells [][2]int32 // column and row of each cell along the line
+	// Distance is the distance along the line from the center of the
+	// first cell to the center of each cell, in projected units.
+	Distance []float64
+	Values   [][]float32 // Values[k][n] is the value in layer k of cell n
+}
+
+// CrossSection returns the cross-section of vals, which holds the
+// values of one species in one hour of gridded file f in the format
+// returned by ReadHour, along cells, which are columns and rows counted
+// from the SW corner of the full grid, such as those returned by
+// TransectCells.
+func (f *UAM) CrossSection(vals []float32, cells [][2]int32) (CrossSection, error) {
+	cs := CrossSection{
+		Cells:    cells,
+		Distance: make([]float64, len(cells)),
+		Values:   make([][]float32, f.Nz),
+	}
+	for k := range cs.Values {
+		cs.Values[k] = make([]float32, len(cells))
+	}
+	for n, c := range cells {
+		p, err := f.Profile(vals, c[0], c[1], nil)
+		if err != nil {
+			return CrossSection{}, err
+		}
+		for k, v := range p.Values {
+			cs.Values[k][n] = v
+		}
+		if n > 0 {
+			prev := cells[n-1]
+			cs.Distance[n] = cs.Distance[n-1] + math.Hypot(
+				float64(c[0]-prev[0])*float64(f.Dx), float64(c[1]-prev[1])*float64(f.Dy))
+		}
+	}
+	return cs, nil
+}
@@ -0,0 +1,72 @@
+package uam_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/ctessum/uam"
+	"github.com/ctessum/uam/uamtest"
+)
+
+func TestTransect(t *testing.T) {
+	e, err := uamtest.Average(uamtest.Options{Hours: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := e.GridDef()
+	center := func(i, j int32) [2]float64 {
+		lon, lat, err := g.Projection().Inverse(float64(g.Xorig)+(float64(i)+0.5)*float64(g.Dx),
+			float64(g.Yorig)+(float64(j)+0.5)*float64(g.Dy))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return [2]float64{lon, lat}
+	}
+
+	cells, err := g.TransectCells([][2]float64{center(0, 1), center(3, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][2]int32{{0, 1}, {1, 1}, {2, 1}, {3, 1}}
+	if !reflect.DeepEqual(cells, want) {
+		t.Fatalf("got cells %v, want %v", cells, want)
+	}
+	cs, err := e.CrossSection(e.Hours[0].Data["O3"], cells)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for n, c := range cells {
+		if want := float64(n) * float64(g.Dx); cs.Distance[n] != want {
+			t.Errorf("cell %d: got distance %g, want %g", n, cs.Distance[n], want)
+		}
+		for k := int32(0); k < g.Nz; k++ {
+			if want := uamtest.Index(2, 0, k, c[1], c[0]); cs.Values[k][n] != want {
+				t.Errorf("cell %v layer %d: got %g, want %g", c, k, cs.Values[k][n], want)
+			}
+		}
+	}
+
+	// A diagonal line through several waypoints visits adjacent cells
+	// from the first waypoint to the last.
+	cells, err = g.TransectCells([][2]float64{center(0, 0), center(3, 2), center(3, 0)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cells[0] != [2]int32{0, 0} || cells[len(cells)-1] != [2]int32{3, 0} {
+		t.Errorf("got cells %v from (0, 0) to (3, 0)", cells)
+	}
+	for n := 1; n < len(cells); n++ {
+		di, dj := cells[n][0]-cells[n-1][0], cells[n][1]-cells[n-1][1]
+		if di < -1 || di > 1 || dj < -1 || dj > 1 {
+			t.Errorf("cells %v and %v are not adjacent", cells[n-1], cells[n])
+		}
+	}
+
+	if _, err = g.TransectCells([][2]float64{center(0, 0), {0, 0}}); !errors.Is(err, uam.ErrOutsideDomain) {
+		t.Errorf("outside of the grid: got error %v", err)
+	}
+	if _, err = g.TransectCells(nil); err == nil {
+		t.Error("no waypoints: got no error")
+	}
+}
@@ -2,10 +2,12 @@
 package uam
 
 import (
+	"context"
 	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"strings"
 )
@@ -47,9 +49,40 @@ func readFloat(fid io.Reader) (float32, error) {
 	return floatOut[0], err
 }
 
+// skip moves past length bytes without reading them.
+func skip(fid io.Seeker, length int64) (err error) {
+	_, err = fid.Seek(length, io.SeekCurrent)
+	return
+}
+
 // UAM is a holder for UAM-formatted data.
 type UAM struct {
-	fid         *os.File
+	r           *stream // buffered reader over the input
+	bufSize     int
+	workers     int // number of goroutines for decoding; see WithWorkers
+	hour        int // index of the next hour to be read
+	ctx         context.Context
+	unmap       func() error // releases mapped memory; see OpenMmap
+	dataStart   int64        // offset of the first hour
+	resync      bool         // see WithResync
+	skipped     []SkippedSpan
+	aliases     map[string]string                        // see WithAliases
+	derived     []DerivedSpecies                         // see WithDerived
+	massUnits   map[string]float64                       // molecular weights; see WithMassUnits
+	fileNames   map[string]string                        // species names in the file, by alias
+	progress    func(hour, species int, bytesRead int64) // see OnProgress
+	log         *slog.Logger                             // see WithLogger
+	strict      bool                                     // see WithStrict
+	tolerant    bool                                     // see WithTolerant
+	truncated   error                                    // see Truncated
+	markerSize  int                                      // bytes per record marker; see WithMarkerSize
+	dec         io.Closer                                // decompressor, if the input is compressed
+	date        int32                                    // start date of the last hour read
+	time        float32                                  // start time of the last hour read
+	overrides   []StackOverride                          // override records of the last hour read; see Overrides
+	closer      io.Closer                                // input file, closed by Close
+	reopen      func() (io.Reader, io.Closer, error)     // opens the input again; see Clone
+	size        func() (int64, error)                    // size of the input, if known
 	Name        string
 	Note        string
 	nseg        int32
@@ -76,14 +109,21 @@ type UAM struct {
 	htu         float32
 	Data        map[string][]float32
 	Npts        int32
-	Spnames     []string  // Species names
-	Xcoord      []float32 // stack X coordinate (meters or lon)
-	Ycoord      []float32 // stack Y coordinate (meters or lat)
-	StackHeight []float32 // stack height  (meters)
-	StackDiam   []float32 // stack diameter (meters)
-	StackTemp   []float32 // stack temperature (K)
-	StackVel    []float32 // stack velocity (m/hr)
-	Ihr         int32     //hour index
+	Spnames     []string        // Species names
+	Xcoord      []float32       // stack X coordinate (meters or lon)
+	Ycoord      []float32       // stack Y coordinate (meters or lat)
+	StackHeight []float32       // stack height  (meters)
+	StackDiam   []float32       // stack diameter (meters); negative for PiG stacks
+	StackTemp   []float32       // stack temperature (K)
+	StackVel    []float32       // stack velocity (m/hr); see Stacks for m/s
+	Ihr         int32           //hour index
+	Hours       []*Hour         // hours held in memory; see AddHour
+	selected    map[string]bool // species to decode; nil means all
+	window      *window         // horizontal subset to read; nil means all
+	limits      *Limits         // see WithLimits; DefaultLimits if nil
+	index       *Index          // see UseIndex
+	cache       *Cache          // see WithCache
+	name        string          // name the file was opened with; see WithCache
 }
 
 // GLIndex takes the indecies for a
@@ -122,410 +162,738 @@ func (d UAM) GLIndex(k int32, j int32, i int32) (index1d int32) {
 //	return
 //}
 
-// Open opens a file for reading and reads the header info.
-func Open(filename string) (f *UAM, err error) {
-	f = new(UAM)
-	f.fid, err = os.Open(filename)
+// Open opens a file for reading and reads the header info. Files
+// that are compressed with gzip or zstd are decompressed as they are
+// read. Compressed files can only be read sequentially: hours cannot be
+// read out of order, and options that require random access, such as
+// parallel decoding and resynchronization, have no effect.
+func Open(filename string, opts ...Option) (f *UAM, err error) {
+	fid, err := os.Open(filename)
 	if err != nil {
 		return nil, err
 	}
-	f.Nhrs = int32(24)
-
-	err = readDummy(f.fid, 1)
+	size := func() (int64, error) {
+		info, err := os.Stat(filename)
+		if err != nil {
+			return 0, err
+		}
+		return info.Size(), nil
+	}
+	f, err = newReader(fid, size, opts)
 	if err != nil {
+		fid.Close()
 		return nil, err
 	}
-	f.Name, err = readStr(f.fid, 40)
+	f.closer, f.name = fid, filename
+	f.reopen = func() (io.Reader, io.Closer, error) {
+		fid, err := os.Open(filename)
+		if err != nil {
+			return nil, nil, err
+		}
+		return fid, fid, nil
+	}
+	return f, nil
+}
+
+// NewReader reads the header info from r and returns a UAM that reads
+// hours of data from it. As with Open, gzip and zstd compressed data
+// are decompressed. Random access is only available if r is an
+// io.ReaderAt (and, to read hours out of order, an io.Seeker). Close does
+// not close r.
+func NewReader(r io.Reader, opts ...Option) (*UAM, error) {
+	return newReader(r, nil, opts)
+}
+
+// newReader reads the header info from r, whose size is returned by
+// size if it is not nil.
+func newReader(r io.Reader, size func() (int64, error), opts []Option) (*UAM, error) {
+	src, dec, err := decompress(r)
 	if err != nil {
 		return nil, err
 	}
-	f.Note, err = readStr(f.fid, 240)
+	f := newUAM(opts)
+	f.dec = dec
+	f.size = size
+	f.r = newStream(src, f.bufSize)
+	if err = f.readHeader(); err != nil {
+		if dec != nil {
+			dec.Close()
+		}
+		return nil, f.parseError(err, -1, f.r.off)
+	}
+	return f, nil
+}
+
+// newUAM creates a UAM with the given options applied.
+func newUAM(opts []Option) *UAM {
+	f := new(UAM)
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
+}
+
+// readHeader reads the header info from the start of the file.
+func (f *UAM) readHeader() (err error) {
+	f.Nhrs = int32(24)
+	if err = f.detectMarkerSize(304); err != nil {
+		return err
+	}
+
+	err = f.beginRecord(304)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	f.nseg, err = readInt(f.fid)
+	f.Name, err = readStr(f.r, 40)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	f.Nspec, err = readInt(f.fid)
+	f.Note, err = readStr(f.r, 240)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	f.sdate, err = readInt(f.fid)
+	f.nseg, err = readInt(f.r)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	if f.nseg != 1 {
+		// Each segment has its own grid and data records, which are
+		// not supported.
+		return fmt.Errorf("%w: file has %d segments; only files with a "+
+			"single segment are supported", ErrInvalidHeader, f.nseg)
 	}
-	f.begtim, err = readFloat(f.fid)
+	f.Nspec, err = readInt(f.r)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	f.edate, err = readInt(f.fid)
+	f.sdate, err = readInt(f.r)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	f.endtim, err = readFloat(f.fid)
+	f.begtim, err = readFloat(f.r)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	f.edate, err = readInt(f.r)
+	if err != nil {
+		return err
+	}
+	f.endtim, err = readFloat(f.r)
+	if err != nil {
+		return err
 	}
 
 	//fmt.Println(f.Name, f.Note)
 	//	fmt.Println(f.nseg, f.Nspec, f.sdate, f.begtim, f.edate, f.endtim)
-	err = readDummy(f.fid, 2)
+	if err = f.endRecord(304); err != nil {
+		return err
+	}
+	err = f.beginRecord(60)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	f.orgx, err = readFloat(f.fid) // Center
+	f.orgx, err = readFloat(f.r) // Center
 	if err != nil {
-		return nil, err
+		return err
 	}
-	f.orgy, err = readFloat(f.fid) // Center
+	f.orgy, err = readFloat(f.r) // Center
 	if err != nil {
-		return nil, err
+		return err
 	}
-	f.iutm, err = readInt(f.fid) // UTM region?
+	f.iutm, err = readInt(f.r) // UTM region?
 	if err != nil {
-		return nil, err
+		return err
 	}
-	f.Utmx, err = readFloat(f.fid) // SW corner
+	f.Utmx, err = readFloat(f.r) // SW corner
 	if err != nil {
-		return nil, err
+		return err
 	}
-	f.Utmy, err = readFloat(f.fid) // SW corner
+	f.Utmy, err = readFloat(f.r) // SW corner
 	if err != nil {
-		return nil, err
+		return err
 	}
-	f.Dx, err = readFloat(f.fid) // grid size
+	f.Dx, err = readFloat(f.r) // grid size
 	if err != nil {
-		return nil, err
+		return err
 	}
-	f.Dy, err = readFloat(f.fid) // grid size
+	f.Dy, err = readFloat(f.r) // grid size
 	if err != nil {
-		return nil, err
+		return err
 	}
-	f.Nx, err = readInt(f.fid) // number of cells
+	f.Nx, err = readInt(f.r) // number of cells
 	if err != nil {
-		return nil, err
+		return err
 	}
-	f.Ny, err = readInt(f.fid) // number of cells
+	f.Ny, err = readInt(f.r) // number of cells
 	if err != nil {
-		return nil, err
+		return err
 	}
-	f.Nz, err = readInt(f.fid) // number of layers
+	f.Nz, err = readInt(f.r) // number of layers
 	if err != nil {
-		return nil, err
+		return err
 	}
-	f.Nzlo, err = readInt(f.fid)
+	f.Nzlo, err = readInt(f.r)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	f.Nzup, err = readInt(f.fid)
+	f.Nzup, err = readInt(f.r)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	f.hts, err = readFloat(f.fid)
+	f.hts, err = readFloat(f.r)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	f.htl, err = readFloat(f.fid)
+	f.htl, err = readFloat(f.r)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	f.htu, err = readFloat(f.fid)
+	f.htu, err = readFloat(f.r)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	err = readDummy(f.fid, 2)
+	if err = f.endRecord(60); err != nil {
+		return err
+	}
+	err = f.beginRecord(16)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	_, err = readInt(f.fid) // i1
+	_, err = readInt(f.r) // i1
 	if err != nil {
-		return nil, err
+		return err
 	}
-	_, err = readInt(f.fid) // j1
+	_, err = readInt(f.r) // j1
 	if err != nil {
-		return nil, err
+		return err
 	}
-	_, err = readInt(f.fid) //Nx1
+	_, err = readInt(f.r) //Nx1
 	if err != nil {
-		return nil, err
+		return err
 	}
-	_, err = readInt(f.fid) //Ny1
+	_, err = readInt(f.r) //Ny1
 	if err != nil {
-		return nil, err
+		return err
 	}
 	//	fmt.Println(i1, j1, Nx1, Ny1)
-	err = readDummy(f.fid, 2)
+	if err = f.endRecord(16); err != nil {
+		return err
+	}
+	if err = f.checkDims(); err != nil {
+		return err
+	}
+	err = f.beginRecord(40 * int64(f.Nspec))
 	if err != nil {
-		return nil, err
+		return err
+	}
+	if err = f.checkFits("species names", 40*int64(f.Nspec)); err != nil {
+		return err
+	}
+
+	if err = f.checkContext(); err != nil {
+		return err
 	}
 
 	// Read species names
 	var spname string
-	f.Spnames = make([]string, f.Nspec)
+	f.Spnames = make([]string, 0, min(f.Nspec, 1024))
 	for l := int32(0); l < f.Nspec; l++ {
-		spname, err = readStr(f.fid, 40)
+		spname, err = readStr(f.r, 40)
 		if err != nil {
-			return nil, err
+			return err
 		}
-		f.Spnames[l] = spname
+		f.Spnames = append(f.Spnames, spname)
+	}
+	if err = f.applyAliases(); err != nil {
+		return err
+	}
+	if err = f.checkDerived(); err != nil {
+		return err
+	}
+	if f.massUnits != nil && f.Name != "EMISSIONS" && f.Name != "PTSOURCE" {
+		return fmt.Errorf("%v files cannot be converted to mass units", f.Name)
 	}
 	f.Ihr = 0
 
 	// read point information if elevated file.
 	if f.Name == "PTSOURCE" {
+		if err = f.checkContext(); err != nil {
+			return err
+		}
 
-		err = readDummy(f.fid, 3)
+		if err = f.endRecord(40 * int64(f.Nspec)); err != nil {
+			return err
+		}
+		err = f.beginRecord(8)
 		if err != nil {
-			return nil, err
+			return err
 		}
-		f.Npts, err = readInt(f.fid) // number of point sources
+		_, err = readInt(f.r) // ione
 		if err != nil {
-			return nil, err
+			return err
+		}
+		f.Npts, err = readInt(f.r) // number of point sources
+		if err != nil {
+			return err
 		}
 		//	fmt.Println(f.Npts)
-		err = readDummy(f.fid, 2)
+		if err = f.endRecord(8); err != nil {
+			return err
+		}
+		if err = f.checkDims(); err != nil {
+			return err
+		}
+		err = f.beginRecord(24 * int64(f.Npts))
 		if err != nil {
-			return nil, err
+			return err
+		}
+		if err = f.checkFits("stack parameters", 24*int64(f.Npts)); err != nil {
+			return err
 		}
 
-		f.Xcoord = make([]float32, f.Npts)
-		f.Ycoord = make([]float32, f.Npts)
-		f.StackHeight = make([]float32, f.Npts)
-		f.StackDiam = make([]float32, f.Npts)
-		f.StackTemp = make([]float32, f.Npts)
-		f.StackVel = make([]float32, f.Npts)
+		// The arrays grow as the stacks are read, so that a corrupt
+		// number of stacks cannot cause a huge allocation.
+		n := min(f.Npts, 1024)
+		f.Xcoord = make([]float32, 0, n)
+		f.Ycoord = make([]float32, 0, n)
+		f.StackHeight = make([]float32, 0, n)
+		f.StackDiam = make([]float32, 0, n)
+		f.StackTemp = make([]float32, 0, n)
+		f.StackVel = make([]float32, 0, n)
 		for ip := int32(0); ip < f.Npts; ip++ {
-			f.Xcoord[ip], err = readFloat(f.fid)
-			if err != nil {
-				return nil, err
-			}
-			f.Ycoord[ip], err = readFloat(f.fid)
-			if err != nil {
-				return nil, err
-			}
-			f.StackHeight[ip], err = readFloat(f.fid)
-			if err != nil {
-				return nil, err
+			var v [6]float32 // x, y, height, diameter, temperature, velocity
+			for c := range v {
+				if v[c], err = readFloat(f.r); err != nil {
+					return err
+				}
 			}
-			f.StackDiam[ip], err = readFloat(f.fid)
-			if err != nil {
-				return nil, err
+			f.Xcoord = append(f.Xcoord, v[0])
+			f.Ycoord = append(f.Ycoord, v[1])
+			f.StackHeight = append(f.StackHeight, v[2])
+			f.StackDiam = append(f.StackDiam, v[3])
+			f.StackTemp = append(f.StackTemp, v[4])
+			f.StackVel = append(f.StackVel, v[5])
+		}
+	}
+	last := 40 * int64(f.Nspec)
+	if f.Name == "PTSOURCE" {
+		last = 24 * int64(f.Npts)
+	}
+	if err = f.endRecord(last); err != nil {
+		return err
+	}
+	f.dataStart = f.r.off
+	if f.log != nil {
+		f.log.Debug("header", "type", f.Name, "note", f.Note,
+			"nspec", f.Nspec, "nx", f.Nx, "ny", f.Ny, "nz", f.Nz,
+			"npts", f.Npts, "sdate", f.sdate, "begtim", f.begtim,
+			"edate", f.edate, "endtim", f.endtim, "offset", f.dataStart)
+	}
+	return nil
+}
+
+// SelectSpecies restricts subsequent calls to ReadHour to the named
+// species. Records for all other species are skipped over without being
+// decoded, and are not added to the Data map. Derived species (see
+// WithDerived) may be named as well. Calling SelectSpecies with an
+// empty list selects all species again.
+func (f *UAM) SelectSpecies(names []string) error {
+	if len(names) == 0 {
+		f.selected = nil
+		return nil
+	}
+	selected := make(map[string]bool, len(names))
+	for _, name := range names {
+		found := false
+		for _, spname := range f.Spnames {
+			if spname == name {
+				found = true
+				break
 			}
-			f.StackTemp[ip], err = readFloat(f.fid)
-			if err != nil {
-				return nil, err
+		}
+		if d, ok := f.derivedSpecies(name); ok && !found {
+			for c := range d.Components {
+				selected[c] = true
 			}
-			f.StackVel[ip], err = readFloat(f.fid)
-			if err != nil {
-				return nil, err
+			found = true
+		}
+		if !found {
+			return fmt.Errorf("species %v is not in file", name)
+		}
+		selected[name] = true
+	}
+	f.selected = selected
+	return nil
+}
+
+// isSelected returns whether species spname should be decoded.
+func (f UAM) isSelected(spname string) bool {
+	return f.selected == nil || f.selected[spname]
+}
+
+// window is a horizontal subset of the grid, covering columns [i1, i2)
+// and rows [j1, j2).
+type window struct {
+	i1, i2, j1, j2 int32
+}
+
+// SetWindow restricts subsequent calls to ReadHour to the
+// sub-rectangle of the grid covering columns i1 <= i < i2 and rows
+// j1 <= j < j2. Values outside of the window are skipped over rather
+// than decoded, and the arrays returned by ReadHour have the dimensions
+// given by WindowGrid, so the value for cell (k, j, i) is at index
+// (k*ny+j-j1)*nx+i-i1. Calling SetWindow(0, f.Nx, 0, f.Ny) reads the
+// full grid again. Windows do not apply to PTSOURCE files.
+func (f *UAM) SetWindow(i1, i2, j1, j2 int32) error {
+	if i1 < 0 || j1 < 0 || i2 > f.Nx || j2 > f.Ny || i1 >= i2 || j1 >= j2 {
+		return fmt.Errorf("invalid window [%d:%d, %d:%d] for %dx%d grid",
+			i1, i2, j1, j2, f.Nx, f.Ny)
+	}
+	if i1 == 0 && j1 == 0 && i2 == f.Nx && j2 == f.Ny {
+		f.window = nil
+		return nil
+	}
+	f.window = &window{i1: i1, i2: i2, j1: j1, j2: j2}
+	return nil
+}
+
+// WindowGrid returns the SW corner and number of cells of the window
+// set by SetWindow, or of the full grid if no window is set.
+func (f UAM) WindowGrid() (Utmx, Utmy float32, Nx, Ny int32) {
+	if f.window == nil {
+		return f.Utmx, f.Utmy, f.Nx, f.Ny
+	}
+	w := f.window
+	return f.Utmx + float32(w.i1)*f.Dx, f.Utmy + float32(w.j1)*f.Dy,
+		w.i2 - w.i1, w.j2 - w.j1
+}
+
+// setGrid records the grid of gridded file f, or its window if it has
+// one, in h.
+func (f *UAM) setGrid(h *Hour) {
+	_, _, h.nx, h.ny = f.WindowGrid()
+	if f.window != nil {
+		h.i1, h.j1 = f.window.i1, f.window.j1
+	}
+}
+
+// readGrid reads a 2D field of Nx*Ny values into dst, keeping
+// only the values within the window, if any.
+func (f *UAM) readGrid(dst []float32) (err error) {
+	w := f.window
+	if w == nil {
+		return f.r.readFloats(dst)
+	}
+	nx := w.i2 - w.i1
+	// Skip the rows before the window and the columns before it
+	// in the first row.
+	pending := 4 * (int64(w.j1)*int64(f.Nx) + int64(w.i1))
+	for j := w.j1; j < w.j2; j++ {
+		if pending > 0 {
+			if err = skip(f.r, pending); err != nil {
+				return
 			}
-			//		fmt.Println(f.Xcoord[ip],f.Ycoord[ip],f.StackHeight[ip],f.StackDiam[ip],f.StackTemp[ip],f.StackVel[ip])
 		}
+		if err = f.r.readFloats(dst[(j-w.j1)*nx : (j-w.j1+1)*nx]); err != nil {
+			return
+		}
+		pending = 4 * int64(f.Nx-nx)
 	}
-	err = readDummy(f.fid, 2)
-	if err != nil {
-		return nil, err
+	// Skip the rest of the last row and the rows after the window.
+	pending = 4 * (int64(f.Nx-w.i2) + int64(f.Ny-w.j2)*int64(f.Nx))
+	if pending > 0 {
+		err = skip(f.r, pending)
 	}
 	return
 }
 
+// OnProgress registers a function that is called by ReadHour after
+// all of the records for each species have been read, with the index
+// of the hour and species being read and the number of bytes of the
+// file that have been read so far. It can be used to show progress
+// when reading large files. Passing nil removes the function.
+func (f *UAM) OnProgress(fn func(hour, species int, bytesRead int64)) {
+	f.progress = fn
+}
+
+// reportProgress calls the progress function, if any, for species l
+// of the current hour.
+func (f *UAM) reportProgress(l int) {
+	if f.progress != nil {
+		f.progress(f.hour, l, f.r.off)
+	}
+}
+
 // Close closes the file.
 func (f UAM) Close() {
-	f.fid.Close()
+	if f.dec != nil {
+		f.dec.Close()
+	}
+	if f.closer != nil {
+		f.closer.Close()
+	}
+	if f.unmap != nil {
+		f.unmap()
+	}
 }
 
 // ReadHour reads 1 hour of data from either
-// a ground level or elevated file.
-func (f UAM) ReadHour(Data map[string][]float32) (
+// a ground level or elevated file. It returns
+// io.EOF when there are no more hours to read.
+// Arrays that are already in Data and have the
+// correct length are overwritten rather than
+// reallocated, so passing the same map for every
+// hour avoids allocating new arrays.
+func (f *UAM) ReadHour(Data map[string][]float32) (
 	[]float32, []float32, []float32, []float32,
 	[]float32, []float32, error) {
+	err := f.readHour(Data)
+	return f.Xcoord, f.Ycoord, f.StackHeight, f.StackDiam,
+		f.StackTemp, f.StackVel, err
+}
+
+// readHour reads the next hour of data, wrapping any errors with
+// their position in the file.
+func (f *UAM) readHour(Data map[string][]float32) error {
 	var err error
+	start := f.r.off
 	switch f.Name {
-	case "EMISSIONS", "AVERAGE":
-		for _, spname := range f.Spnames {
-			Data[spname] = make([]float32, f.Nx*f.Ny*f.Nz)
-		}
-
-		//var isdate int32
-		//var iedate int32
-		//var ibegtim float32
-		//var iendtim float32
-		var spname string
-		_, err = readInt(f.fid) // isdate
-		if err != nil {
-			return f.Xcoord, f.Ycoord, f.StackHeight, f.StackDiam,
-				f.StackTemp, f.StackVel, err
+	case "EMISSIONS", "AVERAGE", "AIRQUALITY", "INSTANT":
+		err = f.readGriddedHour(Data)
+	case "PTSOURCE":
+		err = f.readPointHour(Data)
+	default:
+		return fmt.Errorf("%w: %v", ErrUnknownFileType, f.Name)
+	}
+	if err == io.EOF && f.r.off > start {
+		// The file ends partway through the hour.
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil && err != io.EOF {
+		err = f.parseError(err, f.hour, f.r.off)
+		if f.tolerant && errors.Is(err, ErrTruncatedFile) {
+			f.truncated = err
+			return io.EOF
 		}
-		x, err := readFloat(f.fid) //ibegtim
-		f.Ihr = int32(x)
-		if err != nil {
-			return f.Xcoord, f.Ycoord, f.StackHeight, f.StackDiam,
-				f.StackTemp, f.StackVel, err
+		if f.resync && f.checkContext() == nil {
+			return f.resyncAfter(start, err, Data)
 		}
-		_, err = readInt(f.fid) // iedate
-		if err != nil {
-			return f.Xcoord, f.Ycoord, f.StackHeight, f.StackDiam,
-				f.StackTemp, f.StackVel, err
+		return err
+	}
+	if err == nil {
+		f.hour++
+		if err = f.convertHour(Data); err != nil {
+			return err
 		}
-		_, err = readFloat(f.fid) // iendtim
-		if err != nil {
-			return f.Xcoord, f.Ycoord, f.StackHeight, f.StackDiam,
-				f.StackTemp, f.StackVel, err
+		f.computeDerived(Data)
+	}
+	return err
+}
+
+// reuse returns buf if it has length n, or a new array otherwise.
+func reuse(buf []float32, n int) []float32 {
+	if len(buf) == n {
+		return buf
+	}
+	return make([]float32, n)
+}
+
+// readTime reads the time record at the start of each hour.
+func (f *UAM) readTime() (err error) {
+	off := f.r.off
+	err = f.beginRecord(16)
+	if err != nil {
+		return err // io.EOF if there are no more hours.
+	}
+	bdate, err := readInt(f.r) // isdate
+	if err != nil {
+		return
+	}
+	x, err := readFloat(f.r) //ibegtim
+	if err != nil {
+		return
+	}
+	f.Ihr = int32(x)
+	edate, err := readInt(f.r) // iedate
+	if err != nil {
+		return
+	}
+	etime, err := readFloat(f.r) // iendtim
+	if err != nil {
+		return
+	}
+	f.date, f.time = bdate, x
+	if f.log != nil {
+		f.log.Debug("time record", "hour", f.hour, "offset", off,
+			"bdate", bdate, "btime", x, "edate", edate, "etime", etime)
+	}
+	return f.endRecord(16)
+}
+
+// readSpeciesName reads the record length marker, segment number, and
+// species name at the start of a data record of n values, checks that it
+// is the expected species, and returns the record length.
+func (f *UAM) readSpeciesName(expected string, n int64) (int64, error) {
+	off := f.r.off
+	length, err := f.readMarker()
+	if err != nil {
+		return 0, err
+	}
+	if err = f.markerError(length, f.speciesRecordSize(n)-2*f.markerLen(), off); err != nil {
+		return 0, err
+	}
+	if err = readDummy(f.r, 1); err != nil {
+		return 0, err
+	}
+	spname, err := readStr(f.r, 40)
+	if err != nil {
+		return 0, err
+	}
+	if spname != f.fileName(expected) {
+		return 0, fmt.Errorf("%w: found record for species %v where %v "+
+			"was expected", ErrSpeciesMismatch, spname, f.fileName(expected))
+	}
+	return length, nil
+}
+
+// logRecord logs a data record for layer k of species spname that
+// starts at byte offset off.
+func (f *UAM) logRecord(spname string, k int32, off, length int64) {
+	if f.log == nil {
+		return
+	}
+	f.log.Debug("record", "hour", f.hour, "species", spname, "layer", k,
+		"offset", off, "length", length, "decoded", f.isSelected(spname))
+}
+
+// readGriddedHour reads one hour of data from an EMISSIONS or
+// AVERAGE file, where there is one record for each layer of
+// each species.
+func (f *UAM) readGriddedHour(Data map[string][]float32) error {
+	if err := f.readTime(); err != nil {
+		return err
+	}
+	_, _, nx, ny := f.WindowGrid()
+	n := nx * ny
+	for _, spname := range f.Spnames {
+		if f.isSelected(spname) {
+			Data[spname] = reuse(Data[spname], int(n*f.Nz))
 		}
-		//fmt.Println(isdate, ibegtim, iedate, iendtim)
-		err = readDummy(f.fid, 1)
-		if err != nil {
-			return f.Xcoord, f.Ycoord, f.StackHeight, f.StackDiam,
-				f.StackTemp, f.StackVel, err
+	}
+	if ra := f.r.readerAt(); f.workers > 1 && ra != nil && !f.cached() {
+		return f.readGriddedRecordsParallel(ra, Data)
+	}
+	for l, spname := range f.Spnames {
+		if f.cached() && f.isSelected(spname) {
+			if err := f.checkContext(); err != nil {
+				return err
+			}
+			if err := f.readCachedRecords(l, int64(f.Nx)*int64(f.Ny), Data[spname]); err != nil {
+				return err
+			}
+			f.reportProgress(l)
+			continue
 		}
 		for k := int32(0); k < f.Nz; k++ {
-			for l := int32(0); l < f.Nspec; l++ {
-				err = readDummy(f.fid, 2)
-				if err != nil {
-					return f.Xcoord, f.Ycoord, f.StackHeight, f.StackDiam,
-						f.StackTemp, f.StackVel, err
-				}
-				spname, err = readStr(f.fid, 40)
-				if err != nil {
-					return f.Xcoord, f.Ycoord, f.StackHeight, f.StackDiam,
-						f.StackTemp, f.StackVel, err
-				}
-				//				fmt.Println(spname)
-				for j := int32(0); j < f.Ny; j++ {
-					for i := int32(0); i < f.Nx; i++ {
-						index := f.GLIndex(k, j, i)
-						Data[spname][index], err = readFloat(f.fid)
-						if err != nil {
-							return f.Xcoord, f.Ycoord, f.StackHeight, f.StackDiam,
-								f.StackTemp, f.StackVel, err
-						}
-					}
-				}
-				if (f.Ihr != f.Nhrs-1) || (k != f.Nz-1) || (l != f.Nspec-1) {
-					err = readDummy(f.fid, 1) // Don't read at end of file
-					if err != nil {
-						return f.Xcoord, f.Ycoord, f.StackHeight, f.StackDiam,
-							f.StackTemp, f.StackVel, err
-					}
-				}
+			if err := f.checkContext(); err != nil {
+				return err
 			}
-			if (f.Ihr != f.Nhrs-1) || (k != f.Nz-1) {
-				err = readDummy(f.fid, 1) // Don't read at end of file
-				if err != nil {
-					return f.Xcoord, f.Ycoord, f.StackHeight, f.StackDiam,
-						f.StackTemp, f.StackVel, err
-				}
+			off := f.r.off
+			length, err := f.readSpeciesName(spname, int64(f.Nx)*int64(f.Ny))
+			if err != nil {
+				return err
+			}
+			f.logRecord(spname, k, off, length)
+			if f.isSelected(spname) {
+				err = f.readGrid(Data[spname][k*n : (k+1)*n])
+			} else {
+				err = skip(f.r, 4*int64(f.Nx)*int64(f.Ny))
+			}
+			if err != nil {
+				return err
+			}
+			if err = f.endRecord(f.gridRecordSize() - 2*f.markerLen()); err != nil {
+				return err
 			}
 		}
-	case "PTSOURCE":
+		f.reportProgress(l)
+	}
+	return nil
+}
 
-		for l := int32(0); l < f.Nspec; l++ {
-			Data[f.Spnames[l]] = make([]float32, f.Npts)
+// readPointHour reads one hour of data from a PTSOURCE file.
+func (f *UAM) readPointHour(Data map[string][]float32) error {
+	for _, spname := range f.Spnames {
+		if f.isSelected(spname) {
+			Data[spname] = reuse(Data[spname], int(f.Npts))
 		}
-
-		//var isdate int32
-		//var iedate int32
-		//var ibegtim float32
-		//var iendtim float32
-		//for ihr := int32(0); ihr < f.Nhrs; ihr++ {
-		_, err = readInt(f.fid) //isdate
-		if err != nil {
-			return f.Xcoord, f.Ycoord, f.StackHeight, f.StackDiam,
-				f.StackTemp, f.StackVel, err
+	}
+	if err := f.readTime(); err != nil {
+		return err
+	}
+	err := f.beginRecord(8)
+	if err != nil {
+		return err
+	}
+	_, err = readInt(f.r) // ione
+	if err != nil {
+		return err
+	}
+	npts, err := readInt(f.r)
+	if err != nil {
+		return err
+	}
+	if npts != f.Npts {
+		return fmt.Errorf("hourly record has %d points but the header has %d",
+			npts, f.Npts)
+	}
+	if err = f.endRecord(8); err != nil {
+		return err
+	}
+	err = f.beginRecord(20 * int64(f.Npts))
+	if err != nil {
+		return err
+	}
+	if err = f.readOverrides(); err != nil {
+		return err
+	}
+	err = f.endRecord(20 * int64(f.Npts))
+	if err != nil {
+		return err
+	}
+	for l, spname := range f.Spnames {
+		if err = f.checkContext(); err != nil {
+			return err
 		}
-		x, err := readFloat(f.fid) //ibegtim
-		f.Ihr = int32(x)
-		if err != nil {
-			return f.Xcoord, f.Ycoord, f.StackHeight, f.StackDiam,
-				f.StackTemp, f.StackVel, err
+		if f.cached() && f.isSelected(spname) {
+			if err = f.readCachedRecords(l, int64(f.Npts), Data[spname]); err != nil {
+				return err
+			}
+			f.reportProgress(l)
+			continue
 		}
-		_, err = readInt(f.fid) //iedate
+		off := f.r.off
+		length, err := f.readSpeciesName(spname, int64(f.Npts))
 		if err != nil {
-			return f.Xcoord, f.Ycoord, f.StackHeight, f.StackDiam,
-				f.StackTemp, f.StackVel, err
+			return err
 		}
-		_, err = readFloat(f.fid) //iendtime
-		if err != nil {
-			return f.Xcoord, f.Ycoord, f.StackHeight, f.StackDiam,
-				f.StackTemp, f.StackVel, err
+		f.logRecord(spname, 0, off, length)
+		if f.isSelected(spname) {
+			err = f.r.readFloats(Data[spname])
+		} else {
+			err = skip(f.r, 4*int64(f.Npts))
 		}
-		//fmt.Println(isdate, ibegtim, iedate, iendtim)
-		err = readDummy(f.fid, 6)
 		if err != nil {
-			return f.Xcoord, f.Ycoord, f.StackHeight, f.StackDiam,
-				f.StackTemp, f.StackVel, err
+			return err
 		}
-		for ip := int32(0); ip < f.Npts; ip++ {
-			_, err = readInt(f.fid) // icell
-			if err != nil {
-				return f.Xcoord, f.Ycoord, f.StackHeight, f.StackDiam,
-					f.StackTemp, f.StackVel, err
-			}
-			_, err = readInt(f.fid) // jcell
-			if err != nil {
-				return f.Xcoord, f.Ycoord, f.StackHeight, f.StackDiam,
-					f.StackTemp, f.StackVel, err
-			}
-			_, err = readInt(f.fid) // kcell
-			if err != nil {
-				return f.Xcoord, f.Ycoord, f.StackHeight, f.StackDiam,
-					f.StackTemp, f.StackVel, err
-			}
-			_, err = readFloat(f.fid) // flow
-			if err != nil {
-				return f.Xcoord, f.Ycoord, f.StackHeight, f.StackDiam,
-					f.StackTemp, f.StackVel, err
-			}
-			_, err = readFloat(f.fid) // plumht
-			if err != nil {
-				return f.Xcoord, f.Ycoord, f.StackHeight, f.StackDiam,
-					f.StackTemp, f.StackVel, err
-			}
+		if err = f.endRecord(f.speciesRecordSize(int64(f.Npts)) - 2*f.markerLen()); err != nil {
+			return err
 		}
-		for l := int32(0); l < f.Nspec; l++ {
-			err = readDummy(f.fid, 1)
-			if err != nil {
-				return f.Xcoord, f.Ycoord, f.StackHeight, f.StackDiam,
-					f.StackTemp, f.StackVel, err
-			}
-			_, err = readStr(f.fid, 40) // _ = spname
-			if err != nil {
-				return f.Xcoord, f.Ycoord, f.StackHeight, f.StackDiam,
-					f.StackTemp, f.StackVel, err
-			}
-			//fmt.Println(spname)
-			for ip := int32(0); ip < f.Npts; ip++ {
-				//index := f.ElIndex(ihr, ip)
-				Data[f.Spnames[l]][ip], err = readFloat(f.fid)
-				if err != nil {
-					return f.Xcoord, f.Ycoord, f.StackHeight, f.StackDiam,
-						f.StackTemp, f.StackVel, err
-				}
-			}
-			if (l != f.Nspec-1) || (f.Ihr != f.Nhrs-1) {
-				err = readDummy(f.fid, 2)
-				if err != nil {
-					return f.Xcoord, f.Ycoord, f.StackHeight, f.StackDiam,
-						f.StackTemp, f.StackVel, err
-				}
-			}
-		}
-		if f.Ihr != f.Nhrs-1 {
-			err = readDummy(f.fid, 2)
-			if err != nil {
-				return f.Xcoord, f.Ycoord, f.StackHeight, f.StackDiam,
-					f.StackTemp, f.StackVel, err
-			}
-		}
-	default:
-		msg := fmt.Sprintf("Unknown file type: %v", f.Name)
-		err = errors.New(msg)
+		f.reportProgress(l)
 	}
-	return f.Xcoord, f.Ycoord, f.StackHeight, f.StackDiam,
-		f.StackTemp, f.StackVel, err
+	return nil
 }
 
 // Info provides information about the file.
@@ -0,0 +1,139 @@
+package uamserver
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/ctessum/uam/uamserver/uampb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GRPC returns an implementation of the gRPC service in package uampb
+// that answers the same queries as the HTTP endpoints of s, for
+// registering with a grpc.Server:
+//
+//	uampb.RegisterUAMServer(g, s.GRPC())
+//
+// Errors are reported with the gRPC code that corresponds to their
+// HTTP status.
+func (s *Server) GRPC() uampb.UAMServer { return grpcServer{s: s} }
+
+// grpcServer implements uampb.UAMServer by translating requests into
+// the queries of the HTTP endpoints.
+type grpcServer struct {
+	uampb.UnimplementedUAMServer
+	s *Server
+}
+
+// Header implements uampb.UAMServer.
+func (g grpcServer) Header(ctx context.Context, req *uampb.HeaderRequest) (*uampb.HeaderResponse, error) {
+	result, err := g.s.call(req.GetFile(), url.Values{}, g.s.header)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	h := result.(HeaderInfo)
+	res := &uampb.HeaderResponse{
+		Header: &uampb.Header{
+			Name: h.Name, Note: h.Note,
+			Sdate: h.Sdate, Begtim: h.Begtim, Edate: h.Edate, Endtim: h.Endtim,
+			Orgx: h.Orgx, Orgy: h.Orgy, Iutm: h.Iutm, Utmx: h.Utmx, Utmy: h.Utmy,
+			Dx: h.Dx, Dy: h.Dy, Nx: h.Nx, Ny: h.Ny, Nz: h.Nz,
+			Nzlo: h.Nzlo, Nzup: h.Nzup, Hts: h.Hts, Htl: h.Htl, Htu: h.Htu,
+		},
+		Projection: h.Projection,
+		Species:    h.Species,
+		Hours:      int32(h.Hours),
+	}
+	for _, st := range h.Stacks {
+		res.Stacks = append(res.Stacks, &uampb.Stack{
+			X: st.X, Y: st.Y, Height: st.Height, Diameter: st.Diameter,
+			Temperature: st.Temperature, Velocity: st.Velocity, Pig: st.PiG,
+		})
+	}
+	return res, nil
+}
+
+// Slice implements uampb.UAMServer.
+func (g grpcServer) Slice(ctx context.Context, req *uampb.SliceRequest) (*uampb.SliceResponse, error) {
+	q := url.Values{}
+	setString(q, "species", req.GetSpecies())
+	q.Set("hour", strconv.Itoa(int(req.GetHour())))
+	setInt(q, "layer", req.Layer)
+	setInt(q, "i1", req.I1)
+	setInt(q, "i2", req.I2)
+	setInt(q, "j1", req.J1)
+	setInt(q, "j2", req.J2)
+	result, err := g.s.call(req.GetFile(), q, g.s.slice)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	sl := result.(Slice)
+	return &uampb.SliceResponse{
+		Species: sl.Species, Hour: int32(sl.Hour), Layer: int32(sl.Layer),
+		I1: sl.I1, J1: sl.J1, Nx: sl.Nx, Ny: sl.Ny, Nz: sl.Nz,
+		Values: sl.Values,
+	}, nil
+}
+
+// Point implements uampb.UAMServer.
+func (g grpcServer) Point(ctx context.Context, req *uampb.PointRequest) (*uampb.PointResponse, error) {
+	q := url.Values{}
+	setString(q, "species", req.GetSpecies())
+	setInt(q, "i", req.I)
+	setInt(q, "j", req.J)
+	setFloat(q, "lon", req.Lon)
+	setFloat(q, "lat", req.Lat)
+	setInt(q, "layer", req.Layer)
+	setInt(q, "from", req.From)
+	setInt(q, "to", req.To)
+	result, err := g.s.call(req.GetFile(), q, g.s.point)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	ser := result.(Series)
+	res := &uampb.PointResponse{
+		Species: ser.Species, I: ser.I, J: ser.J, Layer: ser.Layer,
+		Values: ser.Values,
+	}
+	for _, t := range ser.Hours {
+		res.Hours = append(res.Hours, &uampb.Time{Date: t.Date, Time: t.Time})
+	}
+	return res, nil
+}
+
+// grpcError returns err as a gRPC status error with the code that
+// corresponds to its HTTP status.
+func grpcError(err error) error {
+	code := codes.Internal
+	switch httpStatus(err) {
+	case http.StatusBadRequest:
+		code = codes.InvalidArgument
+	case http.StatusNotFound:
+		code = codes.NotFound
+	}
+	return status.Error(code, err.Error())
+}
+
+// setString sets the query parameter name to v if v is not empty.
+func setString(q url.Values, name, v string) {
+	if v != "" {
+		q.Set(name, v)
+	}
+}
+
+// setInt sets the query parameter name to *v if v is not nil.
+func setInt(q url.Values, name string, v *int32) {
+	if v != nil {
+		q.Set(name, strconv.Itoa(int(*v)))
+	}
+}
+
+// setFloat sets the query parameter name to *v if v is not nil.
+func setFloat(q url.Values, name string, v *float64) {
+	if v != nil {
+		q.Set(name, strconv.FormatFloat(*v, 'g', -1, 64))
+	}
+}
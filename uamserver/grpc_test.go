@@ -0,0 +1,128 @@
+package uamserver_test
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/ctessum/uam"
+	"github.com/ctessum/uam/uamserver"
+	"github.com/ctessum/uam/uamserver/uampb"
+	"github.com/ctessum/uam/uamtest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+)
+
+// grpcClient returns a client of a gRPC server for the files returned
+// by files, along with the files.
+func grpcClient(t *testing.T) (uampb.UAMClient, *uam.UAM, *uam.UAM) {
+	t.Helper()
+	fsys, emis, pt := files(t)
+	lis := bufconn.Listen(1 << 20)
+	g := grpc.NewServer()
+	uampb.RegisterUAMServer(g, uamserver.New(fsys).GRPC())
+	go g.Serve(lis)
+	t.Cleanup(g.Stop)
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return uampb.NewUAMClient(conn), emis, pt
+}
+
+func TestGRPC(t *testing.T) {
+	c, emis, pt := grpcClient(t)
+	ctx := context.Background()
+
+	h, err := c.Header(ctx, &uampb.HeaderRequest{File: "emis.uam"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if eh := emis.Header(); h.Hours != 4 || h.Header.Sdate != eh.Sdate || h.Header.Dx != eh.Dx ||
+		h.Header.Nx != eh.Nx || h.Header.Ny != eh.Ny || h.Header.Nz != eh.Nz ||
+		!reflect.DeepEqual(h.Species, emis.Spnames) {
+		t.Errorf("header: got %v, want %+v", h, eh)
+	}
+	h, err = c.Header(ctx, &uampb.HeaderRequest{File: "pt.uam"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stacks := pt.Stacks(); len(h.Stacks) != len(stacks) || h.Stacks[0].Height != stacks[0].Height {
+		t.Errorf("got stacks %v, want %v", h.Stacks, stacks)
+	}
+
+	sl, err := c.Slice(ctx, &uampb.SliceRequest{File: "emis.uam", Species: "NO2", Hour: 2,
+		Layer: proto.Int32(1), I1: proto.Int32(1), J1: proto.Int32(1), J2: proto.Int32(3)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sl.I1 != 1 || sl.J1 != 1 || sl.Nx != 3 || sl.Ny != 2 || sl.Nz != 1 || len(sl.Values) != 6 {
+		t.Fatalf("slice: got %v", sl)
+	}
+	for j := int32(0); j < sl.Ny; j++ {
+		for i := int32(0); i < sl.Nx; i++ {
+			if got, want := sl.Values[j*sl.Nx+i], uamtest.Index(1, 2, 1, j+1, i+1); got != want {
+				t.Errorf("slice (%d, %d): got %g, want %g", i, j, got, want)
+			}
+		}
+	}
+
+	ser, err := c.Point(ctx, &uampb.PointRequest{File: "emis.uam.gz", Species: "NO",
+		I: proto.Int32(2), J: proto.Int32(1), Layer: proto.Int32(1), From: proto.Int32(1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ser.I != 2 || ser.J != 1 || len(ser.Values) != 3 || len(ser.Hours) != 3 {
+		t.Fatalf("point: got %v", ser)
+	}
+	for n, v := range ser.Values {
+		if want := uamtest.Index(0, n+1, 1, 1, 2); v != want {
+			t.Errorf("point hour %d: got %g, want %g", n+1, v, want)
+		}
+	}
+}
+
+func TestGRPCErrors(t *testing.T) {
+	c, _, _ := grpcClient(t)
+	ctx := context.Background()
+	for name, call := range map[string]struct {
+		err  func() error
+		want codes.Code
+	}{
+		"missing file": {func() error {
+			_, err := c.Header(ctx, &uampb.HeaderRequest{File: "missing.uam"})
+			return err
+		}, codes.NotFound},
+		"missing species": {func() error {
+			_, err := c.Slice(ctx, &uampb.SliceRequest{File: "emis.uam", Species: "CO"})
+			return err
+		}, codes.NotFound},
+		"no species": {func() error {
+			_, err := c.Slice(ctx, &uampb.SliceRequest{File: "emis.uam"})
+			return err
+		}, codes.InvalidArgument},
+		"outside domain": {func() error {
+			_, err := c.Point(ctx, &uampb.PointRequest{File: "emis.uam", Species: "NO",
+				Lon: proto.Float64(0), Lat: proto.Float64(0)})
+			return err
+		}, codes.InvalidArgument},
+		"no location": {func() error {
+			_, err := c.Point(ctx, &uampb.PointRequest{File: "emis.uam", Species: "NO"})
+			return err
+		}, codes.InvalidArgument},
+	} {
+		if got := status.Code(call.err()); got != call.want {
+			t.Errorf("%v: got code %v, want %v", name, got, call.want)
+		}
+	}
+}
@@ -0,0 +1,6 @@
+// Package uampb holds the protocol buffer messages and gRPC service
+// generated from uam.proto, for serving UAM files with
+// uamserver.Server.GRPC and for writing clients of that service.
+package uampb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative uam.proto
@@ -0,0 +1,1107 @@
+// The UAM service provides the same queries as the HTTP endpoints of
+// package uamserver. Files are named by their path within the file
+// system that the server was created with.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.35.2
+// 	protoc        (unknown)
+// source: uam.proto
+
+package uampb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type HeaderRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	File string `protobuf:"bytes,1,opt,name=file,proto3" json:"file,omitempty"`
+}
+
+func (x *HeaderRequest) Reset() {
+	*x = HeaderRequest{}
+	mi := &file_uam_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HeaderRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HeaderRequest) ProtoMessage() {}
+
+func (x *HeaderRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_uam_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HeaderRequest.ProtoReflect.Descriptor instead.
+func (*HeaderRequest) Descriptor() ([]byte, []int) {
+	return file_uam_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *HeaderRequest) GetFile() string {
+	if x != nil {
+		return x.File
+	}
+	return ""
+}
+
+// Header holds the header information of a file, as in uam.Header.
+type Header struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name   string  `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Note   string  `protobuf:"bytes,2,opt,name=note,proto3" json:"note,omitempty"`
+	Sdate  int32   `protobuf:"varint,3,opt,name=sdate,proto3" json:"sdate,omitempty"`
+	Begtim float32 `protobuf:"fixed32,4,opt,name=begtim,proto3" json:"begtim,omitempty"`
+	Edate  int32   `protobuf:"varint,5,opt,name=edate,proto3" json:"edate,omitempty"`
+	Endtim float32 `protobuf:"fixed32,6,opt,name=endtim,proto3" json:"endtim,omitempty"`
+	Orgx   float32 `protobuf:"fixed32,7,opt,name=orgx,proto3" json:"orgx,omitempty"`
+	Orgy   float32 `protobuf:"fixed32,8,opt,name=orgy,proto3" json:"orgy,omitempty"`
+	Iutm   int32   `protobuf:"varint,9,opt,name=iutm,proto3" json:"iutm,omitempty"`
+	Utmx   float32 `protobuf:"fixed32,10,opt,name=utmx,proto3" json:"utmx,omitempty"`
+	Utmy   float32 `protobuf:"fixed32,11,opt,name=utmy,proto3" json:"utmy,omitempty"`
+	Dx     float32 `protobuf:"fixed32,12,opt,name=dx,proto3" json:"dx,omitempty"`
+	Dy     float32 `protobuf:"fixed32,13,opt,name=dy,proto3" json:"dy,omitempty"`
+	Nx     int32   `protobuf:"varint,14,opt,name=nx,proto3" json:"nx,omitempty"`
+	Ny     int32   `protobuf:"varint,15,opt,name=ny,proto3" json:"ny,omitempty"`
+	Nz     int32   `protobuf:"varint,16,opt,name=nz,proto3" json:"nz,omitempty"`
+	Nzlo   int32   `protobuf:"varint,17,opt,name=nzlo,proto3" json:"nzlo,omitempty"`
+	Nzup   int32   `protobuf:"varint,18,opt,name=nzup,proto3" json:"nzup,omitempty"`
+	Hts    float32 `protobuf:"fixed32,19,opt,name=hts,proto3" json:"hts,omitempty"`
+	Htl    float32 `protobuf:"fixed32,20,opt,name=htl,proto3" json:"htl,omitempty"`
+	Htu    float32 `protobuf:"fixed32,21,opt,name=htu,proto3" json:"htu,omitempty"`
+}
+
+func (x *Header) Reset() {
+	*x = Header{}
+	mi := &file_uam_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Header) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Header) ProtoMessage() {}
+
+func (x *Header) ProtoReflect() protoreflect.Message {
+	mi := &file_uam_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Header.ProtoReflect.Descriptor instead.
+func (*Header) Descriptor() ([]byte, []int) {
+	return file_uam_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Header) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Header) GetNote() string {
+	if x != nil {
+		return x.Note
+	}
+	return ""
+}
+
+func (x *Header) GetSdate() int32 {
+	if x != nil {
+		return x.Sdate
+	}
+	return 0
+}
+
+func (x *Header) GetBegtim() float32 {
+	if x != nil {
+		return x.Begtim
+	}
+	return 0
+}
+
+func (x *Header) GetEdate() int32 {
+	if x != nil {
+		return x.Edate
+	}
+	return 0
+}
+
+func (x *Header) GetEndtim() float32 {
+	if x != nil {
+		return x.Endtim
+	}
+	return 0
+}
+
+func (x *Header) GetOrgx() float32 {
+	if x != nil {
+		return x.Orgx
+	}
+	return 0
+}
+
+func (x *Header) GetOrgy() float32 {
+	if x != nil {
+		return x.Orgy
+	}
+	return 0
+}
+
+func (x *Header) GetIutm() int32 {
+	if x != nil {
+		return x.Iutm
+	}
+	return 0
+}
+
+func (x *Header) GetUtmx() float32 {
+	if x != nil {
+		return x.Utmx
+	}
+	return 0
+}
+
+func (x *Header) GetUtmy() float32 {
+	if x != nil {
+		return x.Utmy
+	}
+	return 0
+}
+
+func (x *Header) GetDx() float32 {
+	if x != nil {
+		return x.Dx
+	}
+	return 0
+}
+
+func (x *Header) GetDy() float32 {
+	if x != nil {
+		return x.Dy
+	}
+	return 0
+}
+
+func (x *Header) GetNx() int32 {
+	if x != nil {
+		return x.Nx
+	}
+	return 0
+}
+
+func (x *Header) GetNy() int32 {
+	if x != nil {
+		return x.Ny
+	}
+	return 0
+}
+
+func (x *Header) GetNz() int32 {
+	if x != nil {
+		return x.Nz
+	}
+	return 0
+}
+
+func (x *Header) GetNzlo() int32 {
+	if x != nil {
+		return x.Nzlo
+	}
+	return 0
+}
+
+func (x *Header) GetNzup() int32 {
+	if x != nil {
+		return x.Nzup
+	}
+	return 0
+}
+
+func (x *Header) GetHts() float32 {
+	if x != nil {
+		return x.Hts
+	}
+	return 0
+}
+
+func (x *Header) GetHtl() float32 {
+	if x != nil {
+		return x.Htl
+	}
+	return 0
+}
+
+func (x *Header) GetHtu() float32 {
+	if x != nil {
+		return x.Htu
+	}
+	return 0
+}
+
+// Stack holds the parameters of one stack of a PTSOURCE file, as in
+// uam.Stack.
+type Stack struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	X           float32 `protobuf:"fixed32,1,opt,name=x,proto3" json:"x,omitempty"`
+	Y           float32 `protobuf:"fixed32,2,opt,name=y,proto3" json:"y,omitempty"`
+	Height      float32 `protobuf:"fixed32,3,opt,name=height,proto3" json:"height,omitempty"`
+	Diameter    float32 `protobuf:"fixed32,4,opt,name=diameter,proto3" json:"diameter,omitempty"`
+	Temperature float32 `protobuf:"fixed32,5,opt,name=temperature,proto3" json:"temperature,omitempty"`
+	Velocity    float32 `protobuf:"fixed32,6,opt,name=velocity,proto3" json:"velocity,omitempty"`
+	Pig         bool    `protobuf:"varint,7,opt,name=pig,proto3" json:"pig,omitempty"`
+}
+
+func (x *Stack) Reset() {
+	*x = Stack{}
+	mi := &file_uam_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Stack) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Stack) ProtoMessage() {}
+
+func (x *Stack) ProtoReflect() protoreflect.Message {
+	mi := &file_uam_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Stack.ProtoReflect.Descriptor instead.
+func (*Stack) Descriptor() ([]byte, []int) {
+	return file_uam_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Stack) GetX() float32 {
+	if x != nil {
+		return x.X
+	}
+	return 0
+}
+
+func (x *Stack) GetY() float32 {
+	if x != nil {
+		return x.Y
+	}
+	return 0
+}
+
+func (x *Stack) GetHeight() float32 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *Stack) GetDiameter() float32 {
+	if x != nil {
+		return x.Diameter
+	}
+	return 0
+}
+
+func (x *Stack) GetTemperature() float32 {
+	if x != nil {
+		return x.Temperature
+	}
+	return 0
+}
+
+func (x *Stack) GetVelocity() float32 {
+	if x != nil {
+		return x.Velocity
+	}
+	return 0
+}
+
+func (x *Stack) GetPig() bool {
+	if x != nil {
+		return x.Pig
+	}
+	return false
+}
+
+type HeaderResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Header     *Header  `protobuf:"bytes,1,opt,name=header,proto3" json:"header,omitempty"`
+	Projection string   `protobuf:"bytes,2,opt,name=projection,proto3" json:"projection,omitempty"`
+	Species    []string `protobuf:"bytes,3,rep,name=species,proto3" json:"species,omitempty"`
+	// Number of complete hours, or -1 if it is not known.
+	Hours  int32    `protobuf:"varint,4,opt,name=hours,proto3" json:"hours,omitempty"`
+	Stacks []*Stack `protobuf:"bytes,5,rep,name=stacks,proto3" json:"stacks,omitempty"`
+}
+
+func (x *HeaderResponse) Reset() {
+	*x = HeaderResponse{}
+	mi := &file_uam_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HeaderResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HeaderResponse) ProtoMessage() {}
+
+func (x *HeaderResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_uam_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HeaderResponse.ProtoReflect.Descriptor instead.
+func (*HeaderResponse) Descriptor() ([]byte, []int) {
+	return file_uam_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *HeaderResponse) GetHeader() *Header {
+	if x != nil {
+		return x.Header
+	}
+	return nil
+}
+
+func (x *HeaderResponse) GetProjection() string {
+	if x != nil {
+		return x.Projection
+	}
+	return ""
+}
+
+func (x *HeaderResponse) GetSpecies() []string {
+	if x != nil {
+		return x.Species
+	}
+	return nil
+}
+
+func (x *HeaderResponse) GetHours() int32 {
+	if x != nil {
+		return x.Hours
+	}
+	return 0
+}
+
+func (x *HeaderResponse) GetStacks() []*Stack {
+	if x != nil {
+		return x.Stacks
+	}
+	return nil
+}
+
+// SliceRequest has the parameters of a slice request, as described for
+// uamserver.Slice.
+type SliceRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	File    string `protobuf:"bytes,1,opt,name=file,proto3" json:"file,omitempty"`
+	Species string `protobuf:"bytes,2,opt,name=species,proto3" json:"species,omitempty"`
+	Hour    int32  `protobuf:"varint,3,opt,name=hour,proto3" json:"hour,omitempty"`
+	Layer   *int32 `protobuf:"varint,4,opt,name=layer,proto3,oneof" json:"layer,omitempty"`
+	I1      *int32 `protobuf:"varint,5,opt,name=i1,proto3,oneof" json:"i1,omitempty"`
+	I2      *int32 `protobuf:"varint,6,opt,name=i2,proto3,oneof" json:"i2,omitempty"`
+	J1      *int32 `protobuf:"varint,7,opt,name=j1,proto3,oneof" json:"j1,omitempty"`
+	J2      *int32 `protobuf:"varint,8,opt,name=j2,proto3,oneof" json:"j2,omitempty"`
+}
+
+func (x *SliceRequest) Reset() {
+	*x = SliceRequest{}
+	mi := &file_uam_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SliceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SliceRequest) ProtoMessage() {}
+
+func (x *SliceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_uam_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SliceRequest.ProtoReflect.Descriptor instead.
+func (*SliceRequest) Descriptor() ([]byte, []int) {
+	return file_uam_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *SliceRequest) GetFile() string {
+	if x != nil {
+		return x.File
+	}
+	return ""
+}
+
+func (x *SliceRequest) GetSpecies() string {
+	if x != nil {
+		return x.Species
+	}
+	return ""
+}
+
+func (x *SliceRequest) GetHour() int32 {
+	if x != nil {
+		return x.Hour
+	}
+	return 0
+}
+
+func (x *SliceRequest) GetLayer() int32 {
+	if x != nil && x.Layer != nil {
+		return *x.Layer
+	}
+	return 0
+}
+
+func (x *SliceRequest) GetI1() int32 {
+	if x != nil && x.I1 != nil {
+		return *x.I1
+	}
+	return 0
+}
+
+func (x *SliceRequest) GetI2() int32 {
+	if x != nil && x.I2 != nil {
+		return *x.I2
+	}
+	return 0
+}
+
+func (x *SliceRequest) GetJ1() int32 {
+	if x != nil && x.J1 != nil {
+		return *x.J1
+	}
+	return 0
+}
+
+func (x *SliceRequest) GetJ2() int32 {
+	if x != nil && x.J2 != nil {
+		return *x.J2
+	}
+	return 0
+}
+
+type SliceResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Species string    `protobuf:"bytes,1,opt,name=species,proto3" json:"species,omitempty"`
+	Hour    int32     `protobuf:"varint,2,opt,name=hour,proto3" json:"hour,omitempty"`
+	Layer   int32     `protobuf:"varint,3,opt,name=layer,proto3" json:"layer,omitempty"`
+	I1      int32     `protobuf:"varint,4,opt,name=i1,proto3" json:"i1,omitempty"`
+	J1      int32     `protobuf:"varint,5,opt,name=j1,proto3" json:"j1,omitempty"`
+	Nx      int32     `protobuf:"varint,6,opt,name=nx,proto3" json:"nx,omitempty"`
+	Ny      int32     `protobuf:"varint,7,opt,name=ny,proto3" json:"ny,omitempty"`
+	Nz      int32     `protobuf:"varint,8,opt,name=nz,proto3" json:"nz,omitempty"`
+	Values  []float32 `protobuf:"fixed32,9,rep,packed,name=values,proto3" json:"values,omitempty"`
+}
+
+func (x *SliceResponse) Reset() {
+	*x = SliceResponse{}
+	mi := &file_uam_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SliceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SliceResponse) ProtoMessage() {}
+
+func (x *SliceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_uam_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SliceResponse.ProtoReflect.Descriptor instead.
+func (*SliceResponse) Descriptor() ([]byte, []int) {
+	return file_uam_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *SliceResponse) GetSpecies() string {
+	if x != nil {
+		return x.Species
+	}
+	return ""
+}
+
+func (x *SliceResponse) GetHour() int32 {
+	if x != nil {
+		return x.Hour
+	}
+	return 0
+}
+
+func (x *SliceResponse) GetLayer() int32 {
+	if x != nil {
+		return x.Layer
+	}
+	return 0
+}
+
+func (x *SliceResponse) GetI1() int32 {
+	if x != nil {
+		return x.I1
+	}
+	return 0
+}
+
+func (x *SliceResponse) GetJ1() int32 {
+	if x != nil {
+		return x.J1
+	}
+	return 0
+}
+
+func (x *SliceResponse) GetNx() int32 {
+	if x != nil {
+		return x.Nx
+	}
+	return 0
+}
+
+func (x *SliceResponse) GetNy() int32 {
+	if x != nil {
+		return x.Ny
+	}
+	return 0
+}
+
+func (x *SliceResponse) GetNz() int32 {
+	if x != nil {
+		return x.Nz
+	}
+	return 0
+}
+
+func (x *SliceResponse) GetValues() []float32 {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+// PointRequest has the parameters of a point request, as described for
+// uamserver.Series. The location is given by either i and j or lon and
+// lat.
+type PointRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	File    string   `protobuf:"bytes,1,opt,name=file,proto3" json:"file,omitempty"`
+	Species string   `protobuf:"bytes,2,opt,name=species,proto3" json:"species,omitempty"`
+	I       *int32   `protobuf:"varint,3,opt,name=i,proto3,oneof" json:"i,omitempty"`
+	J       *int32   `protobuf:"varint,4,opt,name=j,proto3,oneof" json:"j,omitempty"`
+	Lon     *float64 `protobuf:"fixed64,5,opt,name=lon,proto3,oneof" json:"lon,omitempty"`
+	Lat     *float64 `protobuf:"fixed64,6,opt,name=lat,proto3,oneof" json:"lat,omitempty"`
+	Layer   *int32   `protobuf:"varint,7,opt,name=layer,proto3,oneof" json:"layer,omitempty"`
+	From    *int32   `protobuf:"varint,8,opt,name=from,proto3,oneof" json:"from,omitempty"`
+	To      *int32   `protobuf:"varint,9,opt,name=to,proto3,oneof" json:"to,omitempty"`
+}
+
+func (x *PointRequest) Reset() {
+	*x = PointRequest{}
+	mi := &file_uam_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PointRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PointRequest) ProtoMessage() {}
+
+func (x *PointRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_uam_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PointRequest.ProtoReflect.Descriptor instead.
+func (*PointRequest) Descriptor() ([]byte, []int) {
+	return file_uam_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *PointRequest) GetFile() string {
+	if x != nil {
+		return x.File
+	}
+	return ""
+}
+
+func (x *PointRequest) GetSpecies() string {
+	if x != nil {
+		return x.Species
+	}
+	return ""
+}
+
+func (x *PointRequest) GetI() int32 {
+	if x != nil && x.I != nil {
+		return *x.I
+	}
+	return 0
+}
+
+func (x *PointRequest) GetJ() int32 {
+	if x != nil && x.J != nil {
+		return *x.J
+	}
+	return 0
+}
+
+func (x *PointRequest) GetLon() float64 {
+	if x != nil && x.Lon != nil {
+		return *x.Lon
+	}
+	return 0
+}
+
+func (x *PointRequest) GetLat() float64 {
+	if x != nil && x.Lat != nil {
+		return *x.Lat
+	}
+	return 0
+}
+
+func (x *PointRequest) GetLayer() int32 {
+	if x != nil && x.Layer != nil {
+		return *x.Layer
+	}
+	return 0
+}
+
+func (x *PointRequest) GetFrom() int32 {
+	if x != nil && x.From != nil {
+		return *x.From
+	}
+	return 0
+}
+
+func (x *PointRequest) GetTo() int32 {
+	if x != nil && x.To != nil {
+		return *x.To
+	}
+	return 0
+}
+
+// Time is the start of an hour.
+type Time struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Date int32   `protobuf:"varint,1,opt,name=date,proto3" json:"date,omitempty"`
+	Time float32 `protobuf:"fixed32,2,opt,name=time,proto3" json:"time,omitempty"`
+}
+
+func (x *Time) Reset() {
+	*x = Time{}
+	mi := &file_uam_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Time) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Time) ProtoMessage() {}
+
+func (x *Time) ProtoReflect() protoreflect.Message {
+	mi := &file_uam_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Time.ProtoReflect.Descriptor instead.
+func (*Time) Descriptor() ([]byte, []int) {
+	return file_uam_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Time) GetDate() int32 {
+	if x != nil {
+		return x.Date
+	}
+	return 0
+}
+
+func (x *Time) GetTime() float32 {
+	if x != nil {
+		return x.Time
+	}
+	return 0
+}
+
+type PointResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Species string    `protobuf:"bytes,1,opt,name=species,proto3" json:"species,omitempty"`
+	I       int32     `protobuf:"varint,2,opt,name=i,proto3" json:"i,omitempty"`
+	J       int32     `protobuf:"varint,3,opt,name=j,proto3" json:"j,omitempty"`
+	Layer   int32     `protobuf:"varint,4,opt,name=layer,proto3" json:"layer,omitempty"`
+	Hours   []*Time   `protobuf:"bytes,5,rep,name=hours,proto3" json:"hours,omitempty"`
+	Values  []float32 `protobuf:"fixed32,6,rep,packed,name=values,proto3" json:"values,omitempty"`
+}
+
+func (x *PointResponse) Reset() {
+	*x = PointResponse{}
+	mi := &file_uam_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PointResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PointResponse) ProtoMessage() {}
+
+func (x *PointResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_uam_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PointResponse.ProtoReflect.Descriptor instead.
+func (*PointResponse) Descriptor() ([]byte, []int) {
+	return file_uam_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *PointResponse) GetSpecies() string {
+	if x != nil {
+		return x.Species
+	}
+	return ""
+}
+
+func (x *PointResponse) GetI() int32 {
+	if x != nil {
+		return x.I
+	}
+	return 0
+}
+
+func (x *PointResponse) GetJ() int32 {
+	if x != nil {
+		return x.J
+	}
+	return 0
+}
+
+func (x *PointResponse) GetLayer() int32 {
+	if x != nil {
+		return x.Layer
+	}
+	return 0
+}
+
+func (x *PointResponse) GetHours() []*Time {
+	if x != nil {
+		return x.Hours
+	}
+	return nil
+}
+
+func (x *PointResponse) GetValues() []float32 {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+var File_uam_proto protoreflect.FileDescriptor
+
+var file_uam_proto_rawDesc = []byte{
+	0x0a, 0x09, 0x75, 0x61, 0x6d, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x06, 0x75, 0x61, 0x6d,
+	0x2e, 0x76, 0x31, 0x22, 0x23, 0x0a, 0x0d, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x66, 0x69, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x66, 0x69, 0x6c, 0x65, 0x22, 0x9e, 0x03, 0x0a, 0x06, 0x48, 0x65, 0x61,
+	0x64, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x6f, 0x74, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x6f, 0x74, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x73,
+	0x64, 0x61, 0x74, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x73, 0x64, 0x61, 0x74,
+	0x65, 0x12, 0x16, 0x0a, 0x06, 0x62, 0x65, 0x67, 0x74, 0x69, 0x6d, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x02, 0x52, 0x06, 0x62, 0x65, 0x67, 0x74, 0x69, 0x6d, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x64, 0x61,
+	0x74, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x65, 0x64, 0x61, 0x74, 0x65, 0x12,
+	0x16, 0x0a, 0x06, 0x65, 0x6e, 0x64, 0x74, 0x69, 0x6d, 0x18, 0x06, 0x20, 0x01, 0x28, 0x02, 0x52,
+	0x06, 0x65, 0x6e, 0x64, 0x74, 0x69, 0x6d, 0x12, 0x12, 0x0a, 0x04, 0x6f, 0x72, 0x67, 0x78, 0x18,
+	0x07, 0x20, 0x01, 0x28, 0x02, 0x52, 0x04, 0x6f, 0x72, 0x67, 0x78, 0x12, 0x12, 0x0a, 0x04, 0x6f,
+	0x72, 0x67, 0x79, 0x18, 0x08, 0x20, 0x01, 0x28, 0x02, 0x52, 0x04, 0x6f, 0x72, 0x67, 0x79, 0x12,
+	0x12, 0x0a, 0x04, 0x69, 0x75, 0x74, 0x6d, 0x18, 0x09, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x69,
+	0x75, 0x74, 0x6d, 0x12, 0x12, 0x0a, 0x04, 0x75, 0x74, 0x6d, 0x78, 0x18, 0x0a, 0x20, 0x01, 0x28,
+	0x02, 0x52, 0x04, 0x75, 0x74, 0x6d, 0x78, 0x12, 0x12, 0x0a, 0x04, 0x75, 0x74, 0x6d, 0x79, 0x18,
+	0x0b, 0x20, 0x01, 0x28, 0x02, 0x52, 0x04, 0x75, 0x74, 0x6d, 0x79, 0x12, 0x0e, 0x0a, 0x02, 0x64,
+	0x78, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x02, 0x52, 0x02, 0x64, 0x78, 0x12, 0x0e, 0x0a, 0x02, 0x64,
+	0x79, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x02, 0x52, 0x02, 0x64, 0x79, 0x12, 0x0e, 0x0a, 0x02, 0x6e,
+	0x78, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x05, 0x52, 0x02, 0x6e, 0x78, 0x12, 0x0e, 0x0a, 0x02, 0x6e,
+	0x79, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x05, 0x52, 0x02, 0x6e, 0x79, 0x12, 0x0e, 0x0a, 0x02, 0x6e,
+	0x7a, 0x18, 0x10, 0x20, 0x01, 0x28, 0x05, 0x52, 0x02, 0x6e, 0x7a, 0x12, 0x12, 0x0a, 0x04, 0x6e,
+	0x7a, 0x6c, 0x6f, 0x18, 0x11, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x6e, 0x7a, 0x6c, 0x6f, 0x12,
+	0x12, 0x0a, 0x04, 0x6e, 0x7a, 0x75, 0x70, 0x18, 0x12, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x6e,
+	0x7a, 0x75, 0x70, 0x12, 0x10, 0x0a, 0x03, 0x68, 0x74, 0x73, 0x18, 0x13, 0x20, 0x01, 0x28, 0x02,
+	0x52, 0x03, 0x68, 0x74, 0x73, 0x12, 0x10, 0x0a, 0x03, 0x68, 0x74, 0x6c, 0x18, 0x14, 0x20, 0x01,
+	0x28, 0x02, 0x52, 0x03, 0x68, 0x74, 0x6c, 0x12, 0x10, 0x0a, 0x03, 0x68, 0x74, 0x75, 0x18, 0x15,
+	0x20, 0x01, 0x28, 0x02, 0x52, 0x03, 0x68, 0x74, 0x75, 0x22, 0xa7, 0x01, 0x0a, 0x05, 0x53, 0x74,
+	0x61, 0x63, 0x6b, 0x12, 0x0c, 0x0a, 0x01, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x02, 0x52, 0x01,
+	0x78, 0x12, 0x0c, 0x0a, 0x01, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x02, 0x52, 0x01, 0x79, 0x12,
+	0x16, 0x0a, 0x06, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x02, 0x52,
+	0x06, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x64, 0x69, 0x61, 0x6d, 0x65,
+	0x74, 0x65, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x02, 0x52, 0x08, 0x64, 0x69, 0x61, 0x6d, 0x65,
+	0x74, 0x65, 0x72, 0x12, 0x20, 0x0a, 0x0b, 0x74, 0x65, 0x6d, 0x70, 0x65, 0x72, 0x61, 0x74, 0x75,
+	0x72, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x02, 0x52, 0x0b, 0x74, 0x65, 0x6d, 0x70, 0x65, 0x72,
+	0x61, 0x74, 0x75, 0x72, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x76, 0x65, 0x6c, 0x6f, 0x63, 0x69, 0x74,
+	0x79, 0x18, 0x06, 0x20, 0x01, 0x28, 0x02, 0x52, 0x08, 0x76, 0x65, 0x6c, 0x6f, 0x63, 0x69, 0x74,
+	0x79, 0x12, 0x10, 0x0a, 0x03, 0x70, 0x69, 0x67, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x03,
+	0x70, 0x69, 0x67, 0x22, 0xaf, 0x01, 0x0a, 0x0e, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x26, 0x0a, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x75, 0x61, 0x6d, 0x2e, 0x76, 0x31, 0x2e,
+	0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x52, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x1e,
+	0x0a, 0x0a, 0x70, 0x72, 0x6f, 0x6a, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0a, 0x70, 0x72, 0x6f, 0x6a, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x18,
+	0x0a, 0x07, 0x73, 0x70, 0x65, 0x63, 0x69, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52,
+	0x07, 0x73, 0x70, 0x65, 0x63, 0x69, 0x65, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x68, 0x6f, 0x75, 0x72,
+	0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x68, 0x6f, 0x75, 0x72, 0x73, 0x12, 0x25,
+	0x0a, 0x06, 0x73, 0x74, 0x61, 0x63, 0x6b, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0d,
+	0x2e, 0x75, 0x61, 0x6d, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x74, 0x61, 0x63, 0x6b, 0x52, 0x06, 0x73,
+	0x74, 0x61, 0x63, 0x6b, 0x73, 0x22, 0xe5, 0x01, 0x0a, 0x0c, 0x53, 0x6c, 0x69, 0x63, 0x65, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x66, 0x69, 0x6c, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x66, 0x69, 0x6c, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x70,
+	0x65, 0x63, 0x69, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x73, 0x70, 0x65,
+	0x63, 0x69, 0x65, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x6f, 0x75, 0x72, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x04, 0x68, 0x6f, 0x75, 0x72, 0x12, 0x19, 0x0a, 0x05, 0x6c, 0x61, 0x79, 0x65,
+	0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x48, 0x00, 0x52, 0x05, 0x6c, 0x61, 0x79, 0x65, 0x72,
+	0x88, 0x01, 0x01, 0x12, 0x13, 0x0a, 0x02, 0x69, 0x31, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x48,
+	0x01, 0x52, 0x02, 0x69, 0x31, 0x88, 0x01, 0x01, 0x12, 0x13, 0x0a, 0x02, 0x69, 0x32, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x05, 0x48, 0x02, 0x52, 0x02, 0x69, 0x32, 0x88, 0x01, 0x01, 0x12, 0x13, 0x0a,
+	0x02, 0x6a, 0x31, 0x18, 0x07, 0x20, 0x01, 0x28, 0x05, 0x48, 0x03, 0x52, 0x02, 0x6a, 0x31, 0x88,
+	0x01, 0x01, 0x12, 0x13, 0x0a, 0x02, 0x6a, 0x32, 0x18, 0x08, 0x20, 0x01, 0x28, 0x05, 0x48, 0x04,
+	0x52, 0x02, 0x6a, 0x32, 0x88, 0x01, 0x01, 0x42, 0x08, 0x0a, 0x06, 0x5f, 0x6c, 0x61, 0x79, 0x65,
+	0x72, 0x42, 0x05, 0x0a, 0x03, 0x5f, 0x69, 0x31, 0x42, 0x05, 0x0a, 0x03, 0x5f, 0x69, 0x32, 0x42,
+	0x05, 0x0a, 0x03, 0x5f, 0x6a, 0x31, 0x42, 0x05, 0x0a, 0x03, 0x5f, 0x6a, 0x32, 0x22, 0xbb, 0x01,
+	0x0a, 0x0d, 0x53, 0x6c, 0x69, 0x63, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x18, 0x0a, 0x07, 0x73, 0x70, 0x65, 0x63, 0x69, 0x65, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x07, 0x73, 0x70, 0x65, 0x63, 0x69, 0x65, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x6f, 0x75,
+	0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x68, 0x6f, 0x75, 0x72, 0x12, 0x14, 0x0a,
+	0x05, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x6c, 0x61,
+	0x79, 0x65, 0x72, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x31, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x02, 0x69, 0x31, 0x12, 0x0e, 0x0a, 0x02, 0x6a, 0x31, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x02, 0x6a, 0x31, 0x12, 0x0e, 0x0a, 0x02, 0x6e, 0x78, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x02, 0x6e, 0x78, 0x12, 0x0e, 0x0a, 0x02, 0x6e, 0x79, 0x18, 0x07, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x02, 0x6e, 0x79, 0x12, 0x0e, 0x0a, 0x02, 0x6e, 0x7a, 0x18, 0x08, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x02, 0x6e, 0x7a, 0x12, 0x16, 0x0a, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x18, 0x09, 0x20,
+	0x03, 0x28, 0x02, 0x52, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x22, 0x8f, 0x02, 0x0a, 0x0c,
+	0x50, 0x6f, 0x69, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04,
+	0x66, 0x69, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x66, 0x69, 0x6c, 0x65,
+	0x12, 0x18, 0x0a, 0x07, 0x73, 0x70, 0x65, 0x63, 0x69, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x07, 0x73, 0x70, 0x65, 0x63, 0x69, 0x65, 0x73, 0x12, 0x11, 0x0a, 0x01, 0x69, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x05, 0x48, 0x00, 0x52, 0x01, 0x69, 0x88, 0x01, 0x01, 0x12, 0x11, 0x0a,
+	0x01, 0x6a, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x48, 0x01, 0x52, 0x01, 0x6a, 0x88, 0x01, 0x01,
+	0x12, 0x15, 0x0a, 0x03, 0x6c, 0x6f, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x48, 0x02, 0x52,
+	0x03, 0x6c, 0x6f, 0x6e, 0x88, 0x01, 0x01, 0x12, 0x15, 0x0a, 0x03, 0x6c, 0x61, 0x74, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x01, 0x48, 0x03, 0x52, 0x03, 0x6c, 0x61, 0x74, 0x88, 0x01, 0x01, 0x12, 0x19,
+	0x0a, 0x05, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x18, 0x07, 0x20, 0x01, 0x28, 0x05, 0x48, 0x04, 0x52,
+	0x05, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x88, 0x01, 0x01, 0x12, 0x17, 0x0a, 0x04, 0x66, 0x72, 0x6f,
+	0x6d, 0x18, 0x08, 0x20, 0x01, 0x28, 0x05, 0x48, 0x05, 0x52, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x88,
+	0x01, 0x01, 0x12, 0x13, 0x0a, 0x02, 0x74, 0x6f, 0x18, 0x09, 0x20, 0x01, 0x28, 0x05, 0x48, 0x06,
+	0x52, 0x02, 0x74, 0x6f, 0x88, 0x01, 0x01, 0x42, 0x04, 0x0a, 0x02, 0x5f, 0x69, 0x42, 0x04, 0x0a,
+	0x02, 0x5f, 0x6a, 0x42, 0x06, 0x0a, 0x04, 0x5f, 0x6c, 0x6f, 0x6e, 0x42, 0x06, 0x0a, 0x04, 0x5f,
+	0x6c, 0x61, 0x74, 0x42, 0x08, 0x0a, 0x06, 0x5f, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x42, 0x07, 0x0a,
+	0x05, 0x5f, 0x66, 0x72, 0x6f, 0x6d, 0x42, 0x05, 0x0a, 0x03, 0x5f, 0x74, 0x6f, 0x22, 0x2e, 0x0a,
+	0x04, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x04, 0x64, 0x61, 0x74, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x69, 0x6d,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x02, 0x52, 0x04, 0x74, 0x69, 0x6d, 0x65, 0x22, 0x97, 0x01,
+	0x0a, 0x0d, 0x50, 0x6f, 0x69, 0x6e, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x18, 0x0a, 0x07, 0x73, 0x70, 0x65, 0x63, 0x69, 0x65, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x07, 0x73, 0x70, 0x65, 0x63, 0x69, 0x65, 0x73, 0x12, 0x0c, 0x0a, 0x01, 0x69, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x01, 0x69, 0x12, 0x0c, 0x0a, 0x01, 0x6a, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x01, 0x6a, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x12, 0x22, 0x0a, 0x05, 0x68,
+	0x6f, 0x75, 0x72, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x75, 0x61, 0x6d,
+	0x2e, 0x76, 0x31, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x52, 0x05, 0x68, 0x6f, 0x75, 0x72, 0x73, 0x12,
+	0x16, 0x0a, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x02, 0x52,
+	0x06, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x32, 0xaa, 0x01, 0x0a, 0x03, 0x55, 0x41, 0x4d, 0x12,
+	0x37, 0x0a, 0x06, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x15, 0x2e, 0x75, 0x61, 0x6d, 0x2e,
+	0x76, 0x31, 0x2e, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x16, 0x2e, 0x75, 0x61, 0x6d, 0x2e, 0x76, 0x31, 0x2e, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x34, 0x0a, 0x05, 0x53, 0x6c, 0x69, 0x63,
+	0x65, 0x12, 0x14, 0x2e, 0x75, 0x61, 0x6d, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x6c, 0x69, 0x63, 0x65,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x15, 0x2e, 0x75, 0x61, 0x6d, 0x2e, 0x76, 0x31,
+	0x2e, 0x53, 0x6c, 0x69, 0x63, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x34,
+	0x0a, 0x05, 0x50, 0x6f, 0x69, 0x6e, 0x74, 0x12, 0x14, 0x2e, 0x75, 0x61, 0x6d, 0x2e, 0x76, 0x31,
+	0x2e, 0x50, 0x6f, 0x69, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x15, 0x2e,
+	0x75, 0x61, 0x6d, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x6f, 0x69, 0x6e, 0x74, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x42, 0x28, 0x5a, 0x26, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63,
+	0x6f, 0x6d, 0x2f, 0x63, 0x74, 0x65, 0x73, 0x73, 0x75, 0x6d, 0x2f, 0x75, 0x61, 0x6d, 0x2f, 0x75,
+	0x61, 0x6d, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x2f, 0x75, 0x61, 0x6d, 0x70, 0x62, 0x62, 0x06,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_uam_proto_rawDescOnce sync.Once
+	file_uam_proto_rawDescData = file_uam_proto_rawDesc
+)
+
+func file_uam_proto_rawDescGZIP() []byte {
+	file_uam_proto_rawDescOnce.Do(func() {
+		file_uam_proto_rawDescData = protoimpl.X.CompressGZIP(file_uam_proto_rawDescData)
+	})
+	return file_uam_proto_rawDescData
+}
+
+var file_uam_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_uam_proto_goTypes = []any{
+	(*HeaderRequest)(nil),  // 0: uam.v1.HeaderRequest
+	(*Header)(nil),         // 1: uam.v1.Header
+	(*Stack)(nil),          // 2: uam.v1.Stack
+	(*HeaderResponse)(nil), // 3: uam.v1.HeaderResponse
+	(*SliceRequest)(nil),   // 4: uam.v1.SliceRequest
+	(*SliceResponse)(nil),  // 5: uam.v1.SliceResponse
+	(*PointRequest)(nil),   // 6: uam.v1.PointRequest
+	(*Time)(nil),           // 7: uam.v1.Time
+	(*PointResponse)(nil),  // 8: uam.v1.PointResponse
+}
+var file_uam_proto_depIdxs = []int32{
+	1, // 0: uam.v1.HeaderResponse.header:type_name -> uam.v1.Header
+	2, // 1: uam.v1.HeaderResponse.stacks:type_name -> uam.v1.Stack
+	7, // 2: uam.v1.PointResponse.hours:type_name -> uam.v1.Time
+	0, // 3: uam.v1.UAM.Header:input_type -> uam.v1.HeaderRequest
+	4, // 4: uam.v1.UAM.Slice:input_type -> uam.v1.SliceRequest
+	6, // 5: uam.v1.UAM.Point:input_type -> uam.v1.PointRequest
+	3, // 6: uam.v1.UAM.Header:output_type -> uam.v1.HeaderResponse
+	5, // 7: uam.v1.UAM.Slice:output_type -> uam.v1.SliceResponse
+	8, // 8: uam.v1.UAM.Point:output_type -> uam.v1.PointResponse
+	6, // [6:9] is the sub-list for method output_type
+	3, // [3:6] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_uam_proto_init() }
+func file_uam_proto_init() {
+	if File_uam_proto != nil {
+		return
+	}
+	file_uam_proto_msgTypes[4].OneofWrappers = []any{}
+	file_uam_proto_msgTypes[6].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_uam_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   9,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_uam_proto_goTypes,
+		DependencyIndexes: file_uam_proto_depIdxs,
+		MessageInfos:      file_uam_proto_msgTypes,
+	}.Build()
+	File_uam_proto = out.File
+	file_uam_proto_rawDesc = nil
+	file_uam_proto_goTypes = nil
+	file_uam_proto_depIdxs = nil
+}
@@ -0,0 +1,209 @@
+// The UAM service provides the same queries as the HTTP endpoints of
+// package uamserver. Files are named by their path within the file
+// system that the server was created with.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: uam.proto
+
+package uampb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	UAM_Header_FullMethodName = "/uam.v1.UAM/Header"
+	UAM_Slice_FullMethodName  = "/uam.v1.UAM/Slice"
+	UAM_Point_FullMethodName  = "/uam.v1.UAM/Point"
+)
+
+// UAMClient is the client API for UAM service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type UAMClient interface {
+	// Header returns the header, species, number of hours, and stacks of
+	// a file.
+	Header(ctx context.Context, in *HeaderRequest, opts ...grpc.CallOption) (*HeaderResponse, error)
+	// Slice returns one hour of one species.
+	Slice(ctx context.Context, in *SliceRequest, opts ...grpc.CallOption) (*SliceResponse, error)
+	// Point returns a time series at one grid cell.
+	Point(ctx context.Context, in *PointRequest, opts ...grpc.CallOption) (*PointResponse, error)
+}
+
+type uAMClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewUAMClient(cc grpc.ClientConnInterface) UAMClient {
+	return &uAMClient{cc}
+}
+
+func (c *uAMClient) Header(ctx context.Context, in *HeaderRequest, opts ...grpc.CallOption) (*HeaderResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HeaderResponse)
+	err := c.cc.Invoke(ctx, UAM_Header_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uAMClient) Slice(ctx context.Context, in *SliceRequest, opts ...grpc.CallOption) (*SliceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SliceResponse)
+	err := c.cc.Invoke(ctx, UAM_Slice_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uAMClient) Point(ctx context.Context, in *PointRequest, opts ...grpc.CallOption) (*PointResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PointResponse)
+	err := c.cc.Invoke(ctx, UAM_Point_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UAMServer is the server API for UAM service.
+// All implementations must embed UnimplementedUAMServer
+// for forward compatibility.
+type UAMServer interface {
+	// Header returns the header, species, number of hours, and stacks of
+	// a file.
+	Header(context.Context, *HeaderRequest) (*HeaderResponse, error)
+	// Slice returns one hour of one species.
+	Slice(context.Context, *SliceRequest) (*SliceResponse, error)
+	// Point returns a time series at one grid cell.
+	Point(context.Context, *PointRequest) (*PointResponse, error)
+	mustEmbedUnimplementedUAMServer()
+}
+
+// UnimplementedUAMServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedUAMServer struct{}
+
+func (UnimplementedUAMServer) Header(context.Context, *HeaderRequest) (*HeaderResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Header not implemented")
+}
+func (UnimplementedUAMServer) Slice(context.Context, *SliceRequest) (*SliceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Slice not implemented")
+}
+func (UnimplementedUAMServer) Point(context.Context, *PointRequest) (*PointResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Point not implemented")
+}
+func (UnimplementedUAMServer) mustEmbedUnimplementedUAMServer() {}
+func (UnimplementedUAMServer) testEmbeddedByValue()             {}
+
+// UnsafeUAMServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to UAMServer will
+// result in compilation errors.
+type UnsafeUAMServer interface {
+	mustEmbedUnimplementedUAMServer()
+}
+
+func RegisterUAMServer(s grpc.ServiceRegistrar, srv UAMServer) {
+	// If the following call pancis, it indicates UnimplementedUAMServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&UAM_ServiceDesc, srv)
+}
+
+func _UAM_Header_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HeaderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UAMServer).Header(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UAM_Header_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UAMServer).Header(ctx, req.(*HeaderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UAM_Slice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SliceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UAMServer).Slice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UAM_Slice_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UAMServer).Slice(ctx, req.(*SliceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UAM_Point_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PointRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UAMServer).Point(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UAM_Point_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UAMServer).Point(ctx, req.(*PointRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// UAM_ServiceDesc is the grpc.ServiceDesc for UAM service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var UAM_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "uam.v1.UAM",
+	HandlerType: (*UAMServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Header",
+			Handler:    _UAM_Header_Handler,
+		},
+		{
+			MethodName: "Slice",
+			Handler:    _UAM_Slice_Handler,
+		},
+		{
+			MethodName: "Point",
+			Handler:    _UAM_Point_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "uam.proto",
+}
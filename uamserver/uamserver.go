@@ -0,0 +1,393 @@
+// Package uamserver serves the contents of UAM files over HTTP as JSON
+// and over gRPC, so that web dashboards and remote analyses can query
+// the header of a file, read one hour of a species, or extract a time
+// series at one location without copying whole files. Only the records
+// that are needed are read from the files, which stay where they are.
+//
+// The endpoints, where file is the path of a file within the served file
+// system, are:
+//
+//	GET /header/{file}  the header, species, number of hours, and stacks
+//	GET /slice/{file}   one hour of one species; see Slice
+//	GET /point/{file}   a time series at one grid cell; see Series
+//
+// The same queries are available over gRPC through the UAM service in
+// package uampb, which GRPC implements.
+package uamserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+
+	"github.com/ctessum/uam"
+)
+
+// Server is an http.Handler that serves the UAM files in a file system.
+type Server struct {
+	fsys fs.FS
+	opts []uam.Option
+	mux  *http.ServeMux
+}
+
+// New returns a Server for the files in fsys, which are opened with the
+// given options. Passing uam.WithCache makes repeated requests for the
+// same hours faster.
+func New(fsys fs.FS, opts ...uam.Option) *Server {
+	s := &Server{fsys: fsys, opts: opts, mux: http.NewServeMux()}
+	s.mux.HandleFunc("GET /header/{file...}", s.handle(s.header))
+	s.mux.HandleFunc("GET /slice/{file...}", s.handle(s.slice))
+	s.mux.HandleFunc("GET /point/{file...}", s.handle(s.point))
+	return s
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// HeaderInfo is the response to a header request.
+type HeaderInfo struct {
+	uam.Header
+	Projection string
+	Species    []string
+	Hours      int         // number of complete hours, or -1 if not known
+	Stacks     []uam.Stack `json:",omitempty"` // stacks of a PTSOURCE file
+}
+
+// Slice is the response to a slice request, which has the query
+// parameters hour (counting from 0) and species, and optionally layer
+// (counting from 0; all layers if it is not given) and a window of
+// columns i1 <= i < i2 and rows j1 <= j < j2, as in uam.UAM.SetWindow.
+// For PTSOURCE files, Values has one value for each stack.
+type Slice struct {
+	Species string
+	Hour    int
+	Layer   int   // -1 for all layers
+	I1, J1  int32 // SW cell of the window
+	Nx, Ny  int32 // size of the window, or Npts and 1 for PTSOURCE files
+	Nz      int32 // number of layers in Values
+	Values  Values
+}
+
+// Series is the response to a point request, which has the query
+// parameter species, the location of a grid cell as either i and j or
+// lon and lat, and optionally layer (0 by default) and the hours from
+// through to-1 (all hours by default).
+type Series struct {
+	Species string
+	I, J    int32
+	Layer   int32
+	Hours   []Time
+	Values  Values
+}
+
+// Time is the start of an hour.
+type Time struct {
+	Date int32   // YYJJJ
+	Time float32 // hour
+}
+
+// Values is a list of values that are encoded in JSON with null in
+// place of NaN and infinite values, which JSON cannot represent.
+type Values []float32
+
+// MarshalJSON implements json.Marshaler.
+func (v Values) MarshalJSON() ([]byte, error) {
+	b := make([]byte, 0, 2+10*len(v))
+	b = append(b, '[')
+	for i, x := range v {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		if math.IsNaN(float64(x)) || math.IsInf(float64(x), 0) {
+			b = append(b, "null"...)
+		} else {
+			b = strconv.AppendFloat(b, float64(x), 'g', -1, 32)
+		}
+	}
+	return append(b, ']'), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler, decoding null as NaN.
+func (v *Values) UnmarshalJSON(b []byte) error {
+	var vals []*float32
+	if err := json.Unmarshal(b, &vals); err != nil {
+		return err
+	}
+	*v = make(Values, len(vals))
+	for i, x := range vals {
+		if x == nil {
+			(*v)[i] = float32(math.NaN())
+		} else {
+			(*v)[i] = *x
+		}
+	}
+	return nil
+}
+
+// requestError is an error in the request, reported with the given
+// HTTP status.
+type requestError struct {
+	status int
+	err    error
+}
+
+func (e requestError) Error() string { return e.err.Error() }
+
+// badRequest returns a requestError with status 400.
+func badRequest(format string, a ...any) error {
+	return requestError{status: http.StatusBadRequest, err: fmt.Errorf(format, a...)}
+}
+
+// handle returns a handler that opens the file named in the request,
+// calls fn, and writes its result or error as JSON.
+func (s *Server) handle(fn func(f *uam.UAM, q url.Values) (any, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		result, err := s.call(r.PathValue("file"), r.URL.Query(), fn)
+		w.Header().Set("Content-Type", "application/json")
+		if err != nil {
+			w.WriteHeader(httpStatus(err))
+			json.NewEncoder(w).Encode(struct{ Error string }{err.Error()})
+			return
+		}
+		json.NewEncoder(w).Encode(result)
+	}
+}
+
+// call opens the named file, calls fn with the query q, and closes the
+// file.
+func (s *Server) call(file string, q url.Values, fn func(f *uam.UAM, q url.Values) (any, error)) (any, error) {
+	f, err := uam.OpenFS(s.fsys, file, s.opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return fn(f, q)
+}
+
+// httpStatus returns the HTTP status that err is reported with.
+func httpStatus(err error) int {
+	var re requestError
+	switch {
+	case errors.As(err, &re):
+		return re.status
+	case errors.Is(err, fs.ErrNotExist):
+		return http.StatusNotFound
+	case errors.Is(err, fs.ErrInvalid), errors.Is(err, uam.ErrOutsideDomain):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// header handles header requests.
+func (s *Server) header(f *uam.UAM, q url.Values) (any, error) {
+	h := HeaderInfo{
+		Header:     f.Header(),
+		Projection: fmt.Sprint(f.Projection()),
+		Species:    f.Spnames,
+		Hours:      f.CompleteHours(),
+	}
+	if f.Name == "PTSOURCE" {
+		h.Stacks = f.Stacks()
+	}
+	return h, nil
+}
+
+// slice handles slice requests.
+func (s *Server) slice(f *uam.UAM, q url.Values) (any, error) {
+	species, err := speciesParam(f, q)
+	if err != nil {
+		return nil, err
+	}
+	hour, err := intParam(q, "hour", nil)
+	if err != nil {
+		return nil, err
+	}
+	layer, err := intParam(q, "layer", ptr(-1))
+	if err != nil {
+		return nil, err
+	}
+	sl := Slice{Species: species, Hour: hour, Layer: layer, Nz: f.Nz}
+	if f.Name == "PTSOURCE" {
+		sl.Nx, sl.Ny, sl.Nz = f.Npts, 1, 1
+		if layer > 0 {
+			return nil, badRequest("PTSOURCE files only have layer 0")
+		}
+	} else {
+		var bounds [4]int
+		for n, name := range []string{"i1", "i2", "j1", "j2"} {
+			def := []int32{0, f.Nx, 0, f.Ny}[n]
+			if bounds[n], err = intParam(q, name, ptr(int(def))); err != nil {
+				return nil, err
+			}
+		}
+		err = f.SetWindow(int32(bounds[0]), int32(bounds[1]), int32(bounds[2]), int32(bounds[3]))
+		if err != nil {
+			return nil, badRequest("%v", err)
+		}
+		sl.I1, sl.J1 = int32(bounds[0]), int32(bounds[2])
+		_, _, sl.Nx, sl.Ny = f.WindowGrid()
+	}
+	if err = checkHour(f, hour); err != nil {
+		return nil, err
+	}
+	if layer < 0 {
+		sl.Values, err = f.ReadSpecies(hour, species)
+		return sl, err
+	}
+	if layer >= int(f.Nz) {
+		return nil, badRequest("layer %d is out of range", layer)
+	}
+	sl.Nz = 1
+	sl.Values = make(Values, sl.Nx*sl.Ny)
+	return sl, f.ReadLayer(hour, species, int32(layer), sl.Values)
+}
+
+// point handles point requests.
+func (s *Server) point(f *uam.UAM, q url.Values) (any, error) {
+	if f.Name == "PTSOURCE" {
+		return nil, badRequest("points can only be extracted from gridded files")
+	}
+	species, err := speciesParam(f, q)
+	if err != nil {
+		return nil, err
+	}
+	ser := Series{Species: species}
+	if q.Has("lon") || q.Has("lat") {
+		lon, err1 := strconv.ParseFloat(q.Get("lon"), 64)
+		lat, err2 := strconv.ParseFloat(q.Get("lat"), 64)
+		if err = errors.Join(err1, err2); err != nil {
+			return nil, badRequest("invalid location: %v", err)
+		}
+		if ser.I, ser.J, err = f.CellAt(lon, lat); err != nil {
+			return nil, err
+		}
+	} else {
+		i, err := intParam(q, "i", nil)
+		if err != nil {
+			return nil, err
+		}
+		j, err := intParam(q, "j", nil)
+		if err != nil {
+			return nil, err
+		}
+		ser.I, ser.J = int32(i), int32(j)
+	}
+	layer, err := intParam(q, "layer", ptr(0))
+	if err != nil {
+		return nil, err
+	}
+	if layer < 0 || layer >= int(f.Nz) {
+		return nil, badRequest("layer %d is out of range", layer)
+	}
+	ser.Layer = int32(layer)
+	from, err := intParam(q, "from", ptr(0))
+	if err != nil {
+		return nil, err
+	}
+	// The number of hours is not known for compressed files, which are
+	// read until they end unless to is given.
+	n := f.CompleteHours()
+	to, err := intParam(q, "to", ptr(n))
+	if err != nil {
+		return nil, err
+	}
+	if err = f.SetWindow(ser.I, ser.I+1, ser.J, ser.J+1); err != nil {
+		return nil, badRequest("%v", err)
+	}
+	if err = f.SelectSpecies([]string{species}); err != nil {
+		return nil, err
+	}
+	if from < 0 || q.Has("to") && to < from || n >= 0 && (from > n || to > n) {
+		return nil, badRequest("invalid range of hours [%d, %d)", from, to)
+	}
+	var hours []*uam.Hour
+	if n >= 0 {
+		hours, err = f.ReadHours(from, to)
+	} else {
+		hours, err = readHours(f, from, to)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range hours {
+		ser.Hours = append(ser.Hours, Time{Date: h.Date, Time: h.Time})
+		ser.Values = append(ser.Values, h.Data[species][layer])
+	}
+	return ser, nil
+}
+
+// readHours reads hours from through to-1 of file f in order, for files
+// without random access, or through the end of the file if to is -1.
+func readHours(f *uam.UAM, from, to int) ([]*uam.Hour, error) {
+	var hours []*uam.Hour
+	for h := 0; to < 0 || h < to; h++ {
+		hr, err := f.ReadNextHour()
+		if err == io.EOF {
+			if to >= 0 {
+				return nil, requestError{status: http.StatusNotFound,
+					err: fmt.Errorf("hour %d is not in the file", h)}
+			}
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if h >= from {
+			hours = append(hours, hr)
+		}
+	}
+	if len(hours) == 0 && to < 0 {
+		return nil, requestError{status: http.StatusNotFound,
+			err: fmt.Errorf("hour %d is not in the file", from)}
+	}
+	return hours, nil
+}
+
+// speciesParam returns the species named in the query, which must be in
+// file f.
+func speciesParam(f *uam.UAM, q url.Values) (string, error) {
+	species := q.Get("species")
+	if species == "" {
+		return "", badRequest("no species")
+	}
+	if !slices.Contains(f.Spnames, species) {
+		return "", requestError{status: http.StatusNotFound,
+			err: fmt.Errorf("species %v is not in the file", species)}
+	}
+	return species, nil
+}
+
+// intParam returns the integer value of the named query parameter, or
+// def if it is not given and def is not nil.
+func intParam(q url.Values, name string, def *int) (int, error) {
+	if !q.Has(name) {
+		if def == nil {
+			return 0, badRequest("no %v", name)
+		}
+		return *def, nil
+	}
+	v, err := strconv.Atoi(q.Get(name))
+	if err != nil {
+		return 0, badRequest("invalid %v: %v", name, q.Get(name))
+	}
+	return v, nil
+}
+
+// checkHour returns an error if the given hour is not in file f.
+func checkHour(f *uam.UAM, hour int) error {
+	if n := f.CompleteHours(); hour < 0 || n >= 0 && hour >= n {
+		return requestError{status: http.StatusNotFound,
+			err: fmt.Errorf("hour %d is not in the file", hour)}
+	}
+	return nil
+}
+
+func ptr(v int) *int { return &v }
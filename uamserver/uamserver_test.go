@@ -0,0 +1,246 @@
+package uamserver_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+	"testing/fstest"
+
+	"github.com/ctessum/uam"
+	"github.com/ctessum/uam/uamserver"
+	"github.com/ctessum/uam/uamtest"
+)
+
+// server returns a test server for the files returned by files.
+func server(t *testing.T) (*httptest.Server, *uam.UAM, *uam.UAM) {
+	t.Helper()
+	fsys, emis, pt := files(t)
+	s := httptest.NewServer(uamserver.New(fsys))
+	t.Cleanup(s.Close)
+	return s, emis, pt
+}
+
+// files returns a file system with an EMISSIONS file named emis.uam,
+// a gzipped copy of it named emis.uam.gz, and a PTSOURCE file named
+// pt.uam made by uamtest.
+func files(t *testing.T) (fstest.MapFS, *uam.UAM, *uam.UAM) {
+	t.Helper()
+	emis, err := uamtest.Emissions(uamtest.Options{Hours: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pt, err := uamtest.PointSource(uamtest.Options{Hours: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fsys := fstest.MapFS{}
+	for name, f := range map[string]*uam.UAM{"emis.uam": emis, "pt.uam": pt} {
+		var b bytes.Buffer
+		if err = f.Write(&b); err != nil {
+			t.Fatal(err)
+		}
+		fsys[name] = &fstest.MapFile{Data: b.Bytes()}
+	}
+	var gz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	w.Write(fsys["emis.uam"].Data)
+	w.Close()
+	fsys["emis.uam.gz"] = &fstest.MapFile{Data: gz.Bytes()}
+	return fsys, emis, pt
+}
+
+// get requests path from s and decodes the response into v, returning
+// the status of the response.
+func get(t *testing.T, s *httptest.Server, path string, v any) int {
+	t.Helper()
+	resp, err := http.Get(s.URL + path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		if err = json.NewDecoder(resp.Body).Decode(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func TestHeader(t *testing.T) {
+	s, emis, pt := server(t)
+	var h uamserver.HeaderInfo
+	if status := get(t, s, "/header/emis.uam", &h); status != http.StatusOK {
+		t.Fatalf("got status %d", status)
+	}
+	if h.Header != emis.Header() || h.Hours != 4 || !reflect.DeepEqual(h.Species, emis.Spnames) {
+		t.Errorf("got %+v", h)
+	}
+	if h.Stacks != nil {
+		t.Errorf("got stacks %v for a gridded file", h.Stacks)
+	}
+	if status := get(t, s, "/header/pt.uam", &h); status != http.StatusOK {
+		t.Fatalf("got status %d", status)
+	}
+	if !reflect.DeepEqual(h.Stacks, pt.Stacks()) {
+		t.Errorf("got stacks %v, want %v", h.Stacks, pt.Stacks())
+	}
+	if status := get(t, s, "/header/missing.uam", &h); status != http.StatusNotFound {
+		t.Errorf("missing file: got status %d, want 404", status)
+	}
+}
+
+func TestSlice(t *testing.T) {
+	s, emis, pt := server(t)
+	var sl uamserver.Slice
+	if status := get(t, s, "/slice/emis.uam?species=NO2&hour=2&layer=1&i1=1&j1=1&j2=3", &sl); status != http.StatusOK {
+		t.Fatalf("got status %d", status)
+	}
+	if sl.I1 != 1 || sl.J1 != 1 || sl.Nx != 3 || sl.Ny != 2 || sl.Nz != 1 || len(sl.Values) != 6 {
+		t.Fatalf("got %+v", sl)
+	}
+	for j := int32(0); j < sl.Ny; j++ {
+		for i := int32(0); i < sl.Nx; i++ {
+			if got, want := sl.Values[j*sl.Nx+i], uamtest.Index(1, 2, 1, j+1, i+1); got != want {
+				t.Errorf("(%d, %d): got %g, want %g", i, j, got, want)
+			}
+		}
+	}
+
+	if status := get(t, s, "/slice/emis.uam?species=O3&hour=0", &sl); status != http.StatusOK {
+		t.Fatalf("got status %d", status)
+	}
+	if !reflect.DeepEqual([]float32(sl.Values), emis.Hours[0].Data["O3"]) || sl.Layer != -1 {
+		t.Errorf("all layers: got %+v", sl)
+	}
+	if status := get(t, s, "/slice/pt.uam?species=NO&hour=1", &sl); status != http.StatusOK {
+		t.Fatalf("got status %d", status)
+	}
+	if !reflect.DeepEqual([]float32(sl.Values), pt.Hours[1].Data["NO"]) || sl.Nx != pt.Npts {
+		t.Errorf("PTSOURCE: got %+v", sl)
+	}
+
+	for path, want := range map[string]int{
+		"/slice/emis.uam?species=CO&hour=0":         http.StatusNotFound,
+		"/slice/emis.uam?species=NO&hour=4":         http.StatusNotFound,
+		"/slice/emis.uam?hour=0":                    http.StatusBadRequest,
+		"/slice/emis.uam?species=NO":                http.StatusBadRequest,
+		"/slice/emis.uam?species=NO&hour=x":         http.StatusBadRequest,
+		"/slice/emis.uam?species=NO&hour=0&layer=2": http.StatusBadRequest,
+		"/slice/emis.uam?species=NO&hour=0&i2=9":    http.StatusBadRequest,
+		"/slice/pt.uam?species=NO&hour=0&layer=1":   http.StatusBadRequest,
+	} {
+		if status := get(t, s, path, &sl); status != want {
+			t.Errorf("%v: got status %d, want %d", path, status, want)
+		}
+	}
+}
+
+func TestPoint(t *testing.T) {
+	s, emis, _ := server(t)
+	var ser uamserver.Series
+	if status := get(t, s, "/point/emis.uam?species=NO&i=2&j=1&layer=1&from=1", &ser); status != http.StatusOK {
+		t.Fatalf("got status %d", status)
+	}
+	if ser.I != 2 || ser.J != 1 || ser.Layer != 1 || len(ser.Values) != 3 || len(ser.Hours) != 3 {
+		t.Fatalf("got %+v", ser)
+	}
+	for n, v := range ser.Values {
+		if want := uamtest.Index(0, n+1, 1, 1, 2); v != want {
+			t.Errorf("hour %d: got %g, want %g", n+1, v, want)
+		}
+		if hr := emis.Hours[n+1]; ser.Hours[n] != (uamserver.Time{Date: hr.Date, Time: hr.Time}) {
+			t.Errorf("hour %d: got time %+v", n+1, ser.Hours[n])
+		}
+	}
+
+	// The center of cell (1, 0).
+	lon, lat, err := emis.Projection().Inverse(float64(emis.Utmx+1.5*emis.Dx),
+		float64(emis.Utmy+0.5*emis.Dy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := "/point/emis.uam?species=O3&to=2&lon=" + fmtFloat(lon) + "&lat=" + fmtFloat(lat)
+	if status := get(t, s, path, &ser); status != http.StatusOK {
+		t.Fatalf("got status %d", status)
+	}
+	if ser.I != 1 || ser.J != 0 || len(ser.Values) != 2 {
+		t.Errorf("got %+v, want 2 hours of cell (1, 0)", ser)
+	}
+
+	for path, want := range map[string]int{
+		"/point/emis.uam?species=NO&i=0":                 http.StatusBadRequest,
+		"/point/emis.uam?species=NO&i=0&j=0&to=5":        http.StatusBadRequest,
+		"/point/emis.uam?species=NO&i=0&j=0&from=3&to=2": http.StatusBadRequest,
+		"/point/emis.uam?species=NO&i=9&j=0":             http.StatusBadRequest,
+		"/point/emis.uam?species=NO&lon=0&lat=0":         http.StatusBadRequest,
+		"/point/pt.uam?species=NO&i=0&j=0":               http.StatusBadRequest,
+	} {
+		if status := get(t, s, path, &ser); status != want {
+			t.Errorf("%v: got status %d, want %d", path, status, want)
+		}
+	}
+}
+
+func TestPointCompressed(t *testing.T) {
+	// The number of hours in a compressed file is not known until it has
+	// been read.
+	s, emis, _ := server(t)
+	var h uamserver.HeaderInfo
+	if status := get(t, s, "/header/emis.uam.gz", &h); status != http.StatusOK || h.Hours != -1 {
+		t.Fatalf("got status %d and %d hours, want -1", status, h.Hours)
+	}
+	var ser uamserver.Series
+	for path, want := range map[string][]int{
+		"/point/emis.uam.gz?species=NO&i=1&j=2":             {0, 1, 2, 3},
+		"/point/emis.uam.gz?species=NO&i=1&j=2&from=2":      {2, 3},
+		"/point/emis.uam.gz?species=NO&i=1&j=2&from=1&to=3": {1, 2},
+	} {
+		if status := get(t, s, path, &ser); status != http.StatusOK {
+			t.Fatalf("%v: got status %d", path, status)
+		}
+		if len(ser.Values) != len(want) {
+			t.Fatalf("%v: got %d values, want %d", path, len(ser.Values), len(want))
+		}
+		for n, hr := range want {
+			if got := ser.Values[n]; got != emis.Hours[hr].Data["NO"][2*4+1] {
+				t.Errorf("%v: hour %d: got %g", path, hr, got)
+			}
+		}
+	}
+	for path, want := range map[string]int{
+		"/point/emis.uam.gz?species=NO&i=1&j=2&to=5":        http.StatusNotFound,
+		"/point/emis.uam.gz?species=NO&i=1&j=2&from=4":      http.StatusNotFound,
+		"/point/emis.uam.gz?species=NO&i=1&j=2&from=2&to=1": http.StatusBadRequest,
+		"/point/emis.uam.gz?species=NO&i=1&j=2&from=-1":     http.StatusBadRequest,
+	} {
+		if status := get(t, s, path, &ser); status != want {
+			t.Errorf("%v: got status %d, want %d", path, status, want)
+		}
+	}
+}
+
+func TestValues(t *testing.T) {
+	v := uamserver.Values{1.5, float32(math.NaN()), float32(math.Inf(1))}
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "[1.5,null,null]" {
+		t.Errorf("got %s", b)
+	}
+	var got uamserver.Values
+	if err = json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 || got[0] != 1.5 || !math.IsNaN(float64(got[1])) {
+		t.Errorf("got %v", got)
+	}
+}
+
+func fmtFloat(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
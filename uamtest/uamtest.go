@@ -0,0 +1,208 @@
+// Package uamtest makes small, valid UAM files with known contents for
+// use in tests, so that programs that read or write UAM files can be
+// tested without real model inputs or outputs.
+package uamtest
+
+import (
+	"math"
+	"os"
+	"testing"
+
+	"github.com/ctessum/uam"
+)
+
+// Pattern gives the value of species number l (counting from 0 in the
+// order of Options.Species) in hour h of a file, in layer k, row j,
+// and column i of a gridded file or stack i of a PTSOURCE file (for
+// which k and j are zero).
+type Pattern func(l, h int, k, j, i int32) float32
+
+// Index is a Pattern that encodes where each value is, as
+// 1000*l + 100*k + 10*j + i + h/100, so that the values are unique in
+// grids of up to 10x10 cells and 10 layers with up to 100 hours.
+func Index(l, h int, k, j, i int32) float32 {
+	return float32(1000*int32(l)+100*k+10*j+i) + float32(h)/100
+}
+
+// Constant returns a Pattern with the same value everywhere.
+func Constant(v float32) Pattern {
+	return func(l, h int, k, j, i int32) float32 { return v }
+}
+
+// Linear returns a Pattern that starts at base and increases by the given
+// amount with each hour, layer, row, and column.
+func Linear(base, perHour, perLayer, perRow, perColumn float32) Pattern {
+	return func(l, h int, k, j, i int32) float32 {
+		return base + perHour*float32(h) + perLayer*float32(k) +
+			perRow*float32(j) + perColumn*float32(i)
+	}
+}
+
+// Gaussian returns a Pattern with the value peak at the center of cell
+// (ci, cj) that falls off with distance as a Gaussian with a standard
+// deviation of sigma cells, the same in every layer and hour.
+func Gaussian(peak, ci, cj, sigma float32) Pattern {
+	return func(l, h int, k, j, i int32) float32 {
+		di, dj := float64(float32(i)-ci), float64(float32(j)-cj)
+		return peak * float32(math.Exp(-(di*di+dj*dj)/(2*float64(sigma*sigma))))
+	}
+}
+
+// Diurnal returns a Pattern that multiplies the values of p by
+// 1 + amplitude*sin(2π(h-6)/24), so that they peak at hour 12 of each
+// day and are lowest at hour 0.
+func Diurnal(p Pattern, amplitude float32) Pattern {
+	return func(l, h int, k, j, i int32) float32 {
+		phase := 2 * math.Pi * float64(h%24-6) / 24
+		return p(l, h, k, j, i) * (1 + amplitude*float32(math.Sin(phase)))
+	}
+}
+
+// DefaultGrid is the grid used when Options.Grid is not set: 4x3 cells
+// of 4 km with 2 layers, on a Lambert conformal projection centered on
+// the continental United States.
+var DefaultGrid = uam.GridDef{
+	Xorig: 100000, Yorig: 200000, Dx: 4000, Dy: 4000, Nx: 4, Ny: 3, Nz: 2,
+	Iproj: 2, Orgx: -97, Orgy: 40, Tlat1: 33, Tlat2: 45,
+}
+
+// DefaultSpecies are the species used when Options.Species is not set.
+var DefaultSpecies = []string{"NO", "NO2", "O3"}
+
+// Options describes a file to make.
+type Options struct {
+	Grid    uam.GridDef // DefaultGrid if Nx is zero
+	Species []string    // DefaultSpecies if nil
+	Date    int32       // start date (YYJJJ); 15001 if zero
+	Hours   int         // number of hours, starting at hour 0; 24 if zero
+	Pattern Pattern     // Index if nil
+	Stacks  []uam.Stack // stacks of PTSOURCE files; DefaultStacks(Grid) if nil
+}
+
+// withDefaults returns o with the defaults filled in.
+func (o Options) withDefaults() Options {
+	if o.Grid.Nx == 0 {
+		o.Grid = DefaultGrid
+	}
+	if o.Species == nil {
+		o.Species = DefaultSpecies
+	}
+	if o.Date == 0 {
+		o.Date = 15001
+	}
+	if o.Hours == 0 {
+		o.Hours = 24
+	}
+	if o.Pattern == nil {
+		o.Pattern = Index
+	}
+	if o.Stacks == nil {
+		o.Stacks = DefaultStacks(o.Grid)
+	}
+	return o
+}
+
+// header returns the header of a file of the named type described by o.
+func (o Options) header(name string) uam.Header {
+	h := o.Grid.Header(name)
+	h.Note = "uamtest"
+	h.Sdate, h.Begtim = o.Date, 0
+	h.Edate, h.Endtim = uam.AddHours(o.Date, 0, o.Hours)
+	return h
+}
+
+// DefaultStacks returns three stacks in grid g: a short stack at the
+// center of the SW cell, a taller one at the center of the grid, and a
+// tall plume-in-grid stack at the center of the NE cell.
+func DefaultStacks(g uam.GridDef) []uam.Stack {
+	center := func(i, j int32) (float32, float32) {
+		return g.Xorig + (float32(i)+0.5)*g.Dx, g.Yorig + (float32(j)+0.5)*g.Dy
+	}
+	stacks := []uam.Stack{
+		{Height: 20, Diameter: 1, Temperature: 350, Velocity: 5},
+		{Height: 50, Diameter: 2, Temperature: 400, Velocity: 10},
+		{Height: 150, Diameter: 5, Temperature: 450, Velocity: 20, PiG: true},
+	}
+	stacks[0].X, stacks[0].Y = center(0, 0)
+	stacks[1].X, stacks[1].Y = center(g.Nx/2, g.Ny/2)
+	stacks[2].X, stacks[2].Y = center(g.Nx-1, g.Ny-1)
+	return stacks
+}
+
+// Emissions returns an EMISSIONS file described by o, held in memory.
+func Emissions(o Options) (*uam.UAM, error) {
+	return gridded("EMISSIONS", o)
+}
+
+// Average returns an AVERAGE file described by o, held in memory.
+func Average(o Options) (*uam.UAM, error) {
+	return gridded("AVERAGE", o)
+}
+
+// gridded returns a gridded file of the named type described by o.
+func gridded(name string, o Options) (*uam.UAM, error) {
+	o = o.withDefaults()
+	f, err := uam.NewGridded(o.header(name), o.Species)
+	if err != nil {
+		return nil, err
+	}
+	g := o.Grid
+	for h := 0; h < o.Hours; h++ {
+		data := make(map[string][]float32, len(o.Species))
+		for l, spname := range o.Species {
+			vals := make([]float32, 0, g.Nx*g.Ny*g.Nz)
+			for k := int32(0); k < g.Nz; k++ {
+				for j := int32(0); j < g.Ny; j++ {
+					for i := int32(0); i < g.Nx; i++ {
+						vals = append(vals, o.Pattern(l, h, k, j, i))
+					}
+				}
+			}
+			data[spname] = vals
+		}
+		if err = f.AddHour(data); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// PointSource returns a PTSOURCE file described by o, held in memory.
+func PointSource(o Options) (*uam.UAM, error) {
+	o = o.withDefaults()
+	f, err := uam.NewPointSource(o.header("PTSOURCE"), o.Species, o.Stacks)
+	if err != nil {
+		return nil, err
+	}
+	for h := 0; h < o.Hours; h++ {
+		data := make(map[string][]float32, len(o.Species))
+		for l, spname := range o.Species {
+			vals := make([]float32, len(o.Stacks))
+			for p := range vals {
+				vals[p] = o.Pattern(l, h, 0, 0, int32(p))
+			}
+			data[spname] = vals
+		}
+		if err = f.AddHour(data); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// TempFile writes f to a new file in the temporary directory of tb,
+// which is removed when the test ends, and returns the name of the file.
+// It stops the test if f cannot be written.
+func TempFile(tb testing.TB, f *uam.UAM) string {
+	tb.Helper()
+	fid, err := os.CreateTemp(tb.TempDir(), "*.uam")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	filename := fid.Name()
+	fid.Close()
+	if err = f.WriteFile(filename); err != nil {
+		tb.Fatal(err)
+	}
+	return filename
+}
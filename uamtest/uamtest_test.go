@@ -0,0 +1,32 @@
+package uamtest_test
+
+import (
+	"testing"
+
+	"github.com/ctessum/uam"
+	"github.com/ctessum/uam/uamtest"
+)
+
+func TestDates(t *testing.T) {
+	// 2016 is a leap year, so its last day is 366.
+	f, err := uamtest.PointSource(uamtest.Options{Date: 16366, Hours: 26})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := f.Header()
+	if h.Sdate != 16366 || h.Begtim != 0 || h.Edate != 17001 || h.Endtim != 2 {
+		t.Errorf("got %d %g to %d %g, want 16366 0 to 17001 2", h.Sdate, h.Begtim, h.Edate, h.Endtim)
+	}
+	if last := f.Hours[25]; last.Date != 17001 || last.Time != 1 {
+		t.Errorf("last hour starts at %d %g, want 17001 1", last.Date, last.Time)
+	}
+
+	r, err := uam.Open(uamtest.TempFile(t, f))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if r.Header() != h || r.CompleteHours() != 26 {
+		t.Errorf("got header %+v with %d hours, want %+v with 26", r.Header(), r.CompleteHours(), h)
+	}
+}
@@ -0,0 +1,133 @@
+package uam
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// UncertaintySuffix is appended to the name of a species to give the
+// name of the species that holds its uncertainty when uncertainties are
+// stored in a file alongside emissions. For example, the uncertainty
+// of NO is stored as NO_UNC.
+var UncertaintySuffix = "_UNC"
+
+// Uncertain is one hour of emissions together with the uncertainty,
+// expressed as a standard deviation in the same units, of each value.
+type Uncertain struct {
+	Data        map[string][]float32 // emissions by species
+	Uncertainty map[string][]float32 // uncertainty by species; species without an entry have no uncertainty
+}
+
+// SplitUncertainty separates one hour of data, as read by ReadHour,
+// into emissions and the uncertainties stored in species named with
+// UncertaintySuffix.
+func SplitUncertainty(data map[string][]float32) *Uncertain {
+	u := &Uncertain{
+		Data:        make(map[string][]float32),
+		Uncertainty: make(map[string][]float32),
+	}
+	for spname, vals := range data {
+		if name, ok := strings.CutSuffix(spname, UncertaintySuffix); ok {
+			u.Uncertainty[name] = vals
+		} else {
+			u.Data[spname] = vals
+		}
+	}
+	return u
+}
+
+// Combine returns the emissions together with the uncertainties stored
+// as species named with UncertaintySuffix, so that they can be written
+// to a file. It returns an error if an uncertainty species name is
+// longer than the 10 characters allowed in UAM files.
+func (u *Uncertain) Combine() (map[string][]float32, error) {
+	out := make(map[string][]float32, len(u.Data)+len(u.Uncertainty))
+	for spname, vals := range u.Data {
+		out[spname] = vals
+	}
+	for spname, vals := range u.Uncertainty {
+		name := spname + UncertaintySuffix
+		if len(name) > 10 {
+			return nil, fmt.Errorf("uncertainty species name %v is longer "+
+				"than 10 characters", name)
+		}
+		out[name] = vals
+	}
+	return out, nil
+}
+
+// SetRelative sets the uncertainty of species spname to the fraction
+// rel of the magnitude of its emissions, as is common for inventory
+// uncertainty estimates.
+func (u *Uncertain) SetRelative(spname string, rel float64) error {
+	vals, ok := u.Data[spname]
+	if !ok {
+		return fmt.Errorf("species %v is not in the data", spname)
+	}
+	unc := make([]float32, len(vals))
+	for i, v := range vals {
+		unc[i] = float32(rel * math.Abs(float64(v)))
+	}
+	u.Uncertainty[spname] = unc
+	return nil
+}
+
+// Scale multiplies the emissions of species spname by factor, which has
+// a relative uncertainty of rel (0 for an exact factor). The uncertainty
+// is propagated assuming that the factor and the emissions are
+// independent.
+func (u *Uncertain) Scale(spname string, factor, rel float64) error {
+	vals, ok := u.Data[spname]
+	if !ok {
+		return fmt.Errorf("species %v is not in the data", spname)
+	}
+	unc := u.Uncertainty[spname]
+	if unc == nil && rel != 0 {
+		unc = make([]float32, len(vals))
+		u.Uncertainty[spname] = unc
+	}
+	for i, v := range vals {
+		scaled := factor * float64(v)
+		if unc != nil {
+			s := factor * float64(unc[i])
+			unc[i] = float32(math.Hypot(s, rel*scaled))
+		}
+		vals[i] = float32(scaled)
+	}
+	return nil
+}
+
+// Add adds the emissions in o to u, for example to merge emissions
+// from two sectors. If correlated is false, the uncertainties of the
+// two sets of emissions are assumed to be independent and are added in
+// quadrature; otherwise they are assumed to be fully correlated and are
+// added directly.
+func (u *Uncertain) Add(o *Uncertain, correlated bool) error {
+	for spname, vals := range o.Data {
+		if sum, ok := u.Data[spname]; ok && len(sum) != len(vals) {
+			return fmt.Errorf("species %v has %d values; it should have %d",
+				spname, len(vals), len(sum))
+		}
+	}
+	for spname, vals := range o.Data {
+		n := len(vals)
+		ua := u.Uncertainty[spname]
+		ub := o.Uncertainty[spname]
+		if ub != nil {
+			if ua == nil {
+				ua = make([]float32, n)
+				u.Uncertainty[spname] = ua
+			}
+			for i := range ua {
+				if correlated {
+					ua[i] += ub[i]
+				} else {
+					ua[i] = float32(math.Hypot(float64(ua[i]), float64(ub[i])))
+				}
+			}
+		}
+		accumulate(u.Data, spname, vals)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package uam_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ctessum/uam"
+)
+
+func TestUncertain(t *testing.T) {
+	u := uam.SplitUncertainty(map[string][]float32{
+		"NO": {10, -20}, "NO_UNC": {3, 4}, "CO": {5, 5},
+	})
+	if !reflect.DeepEqual(u.Data, map[string][]float32{"NO": {10, -20}, "CO": {5, 5}}) ||
+		!reflect.DeepEqual(u.Uncertainty, map[string][]float32{"NO": {3, 4}}) {
+		t.Fatalf("split: got %+v", u)
+	}
+
+	if err := u.SetRelative("CO", 0.5); err != nil {
+		t.Fatal(err)
+	}
+	if got := u.Uncertainty["CO"]; !reflect.DeepEqual(got, []float32{2.5, 2.5}) {
+		t.Errorf("relative: got %v", got)
+	}
+	// Scaling 10±3 by 2±37.5% gives 20±hypot(6, 7.5).
+	if err := u.Scale("NO", 2, 0.375); err != nil {
+		t.Fatal(err)
+	}
+	if got := u.Data["NO"]; !reflect.DeepEqual(got, []float32{20, -40}) {
+		t.Errorf("scaled: got %v", got)
+	}
+	if got := u.Uncertainty["NO"]; !reflect.DeepEqual(got, []float32{9.604687, 17}) {
+		t.Errorf("scaled uncertainty: got %v", got)
+	}
+	if err := u.Scale("SO2", 2, 0); err == nil {
+		t.Error("missing species: got no error")
+	}
+
+	o := &uam.Uncertain{
+		Data:        map[string][]float32{"CO": {1, 1}},
+		Uncertainty: map[string][]float32{"CO": {6, 6}},
+	}
+	if err := u.Add(o, false); err != nil {
+		t.Fatal(err)
+	}
+	if got := u.Uncertainty["CO"]; !reflect.DeepEqual(got, []float32{6.5, 6.5}) {
+		t.Errorf("independent: got %v", got)
+	}
+	if err := u.Add(o, true); err != nil {
+		t.Fatal(err)
+	}
+	if got := u.Uncertainty["CO"]; !reflect.DeepEqual(got, []float32{12.5, 12.5}) {
+		t.Errorf("correlated: got %v", got)
+	}
+	if got := u.Data["CO"]; !reflect.DeepEqual(got, []float32{7, 7}) {
+		t.Errorf("sum: got %v", got)
+	}
+	if err := u.Add(&uam.Uncertain{Data: map[string][]float32{"CO": {1}}}, false); err == nil {
+		t.Error("wrong length: got no error")
+	}
+
+	data, err := u.Combine()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := uam.SplitUncertainty(data); !reflect.DeepEqual(got, u) {
+		t.Errorf("round trip: got %+v, want %+v", got, u)
+	}
+	u.Uncertainty["ISOPRENE"] = []float32{1}
+	if _, err = u.Combine(); err == nil {
+		t.Error("long name: got no error")
+	}
+}
@@ -0,0 +1,96 @@
+package uam
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MolecularWeights holds the molecular weights (g/mol) of the explicit
+// gas-phase species of the supported mechanisms. Lumped species such as
+// PAR or ALK1 are not included because their weights depend on the
+// speciation profiles used to make the emissions; users may add them, or
+// pass their own table to ConvertToMass or WithMassUnits.
+var MolecularWeights = map[string]float64{
+	"NO": 30.006, "NO2": 46.006, "O3": 47.998, "HONO": 47.013, "NO3": 62.004,
+	"N2O5": 108.01, "HNO3": 63.012, "PNA": 79.01, "HNO4": 79.01,
+	"CO": 28.010, "SO2": 64.064, "SULF": 98.078, "NH3": 17.031,
+	"CH4": 16.043, "ETHA": 30.069, "PRPA": 44.096, "ETH": 28.053,
+	"ETHE": 28.053, "ETHY": 26.037, "ISOP": 68.117, "TERP": 136.23,
+	"BENZ": 78.112, "TOL": 92.138, "TOLU": 92.138, "XYL": 106.16,
+	"MXYL": 106.16, "OXYL": 106.16, "PXYL": 106.16, "MEOH": 32.042,
+	"ETOH": 46.068, "FORM": 30.026, "HCHO": 30.026, "ALD2": 44.053,
+	"CCHO": 44.053, "ACET": 58.079, "MEK": 72.106, "GLY": 58.036,
+	"MGLY": 72.063, "FACD": 46.025, "HCOOH": 46.025, "AACD": 60.052,
+	"CCOOH": 60.052, "H2O2": 34.014, "CL2": 70.906, "HCL": 36.461,
+	"DMS": 62.13, "I2": 253.81, "CRES": 108.14,
+}
+
+// ConvertToMass converts the emissions in Data, which are in mol/hr for
+// gas-phase species and g/hr for aerosols (see IsAerosol), to g/hr in
+// place, using the molecular weights (g/mol) in mw, or MolecularWeights
+// if mw is nil. It returns an error, without converting anything, if a
+// gas-phase species is not in the table.
+func ConvertToMass(Data map[string][]float32, mw map[string]float64) error {
+	species := make([]string, 0, len(Data))
+	for spname := range Data {
+		species = append(species, spname)
+	}
+	return toMass(Data, species, mw)
+}
+
+// toMass converts the named species in Data to g/hr. See ConvertToMass.
+func toMass(Data map[string][]float32, species []string, mw map[string]float64) error {
+	if mw == nil {
+		mw = MolecularWeights
+	}
+	var missing []string
+	for _, spname := range species {
+		if _, ok := mw[spname]; !ok && !IsAerosol(spname) {
+			missing = append(missing, spname)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("no molecular weight for %v", strings.Join(missing, ", "))
+	}
+	for _, spname := range species {
+		if IsAerosol(spname) {
+			continue
+		}
+		w := float32(mw[spname])
+		for i := range Data[spname] {
+			Data[spname][i] *= w
+		}
+	}
+	return nil
+}
+
+// WithMassUnits converts the emissions read from EMISSIONS and PTSOURCE
+// files by ReadHour to g/hr, using the molecular weights (g/mol) in mw,
+// or MolecularWeights if mw is nil. See ConvertToMass. Opening other
+// types of file fails, as does reading an hour if a selected gas-phase
+// species is not in the table.
+func WithMassUnits(mw map[string]float64) Option {
+	return func(f *UAM) {
+		if mw == nil {
+			mw = MolecularWeights
+		}
+		f.massUnits = mw
+	}
+}
+
+// convertHour converts the species that were just read into Data to
+// g/hr if WithMassUnits was given.
+func (f *UAM) convertHour(Data map[string][]float32) error {
+	if f.massUnits == nil {
+		return nil
+	}
+	var species []string
+	for _, spname := range f.Spnames {
+		if f.isSelected(spname) {
+			species = append(species, spname)
+		}
+	}
+	return toMass(Data, species, f.massUnits)
+}
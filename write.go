@@ -0,0 +1,259 @@
+package uam
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// Writer writes UAM-formatted files.
+type Writer struct {
+	w    io.Writer
+	c    io.Closer // closed by Close, if not nil
+	f    *UAM      // header information
+	nhrs int       // number of hours (or time steps) written so far
+	step float32   // length of a time step in hours; 0 means 1; see SetTimeStep
+	buf  bytes.Buffer
+}
+
+// Create creates a file called filename and writes the header information
+// in f to it. The returned Writer should be closed when all hours have
+// been written. The file is locked while it is being written; if it is
+// already locked by another Writer, Create returns an error wrapping
+// ErrLocked without modifying the file.
+func Create(filename string, f *UAM) (*Writer, error) {
+	return CreateIn(LocalBackend{}, filename, f)
+}
+
+// NewWriter writes the header information in f to w and returns a Writer
+// that can be used to write hourly data with the same structure.
+func NewWriter(w io.Writer, f *UAM) (*Writer, error) {
+	for _, spname := range f.Spnames {
+		if len(spname) > 10 {
+			return nil, fmt.Errorf("species name %v is longer than 10 "+
+				"characters", spname)
+		}
+	}
+	wr := &Writer{w: w, f: f}
+	wr.putStr(f.Name, 40)
+	wr.putStr(f.Note, 240)
+	wr.put(int32(1), f.Nspec, f.sdate, f.begtim, f.edate, f.endtim)
+	if err := wr.flush(); err != nil {
+		return nil, err
+	}
+	wr.put(f.orgx, f.orgy, f.iutm, f.Utmx, f.Utmy, f.Dx, f.Dy,
+		f.Nx, f.Ny, f.Nz, f.Nzlo, f.Nzup, f.hts, f.htl, f.htu)
+	if err := wr.flush(); err != nil {
+		return nil, err
+	}
+	wr.put(int32(0), int32(0), f.Nx, f.Ny)
+	if err := wr.flush(); err != nil {
+		return nil, err
+	}
+	for _, spname := range f.Spnames {
+		wr.putStr(spname, 40)
+	}
+	if err := wr.flush(); err != nil {
+		return nil, err
+	}
+	if f.Name == "PTSOURCE" {
+		wr.put(int32(1), f.Npts)
+		if err := wr.flush(); err != nil {
+			return nil, err
+		}
+		for ip := int32(0); ip < f.Npts; ip++ {
+			wr.put(f.Xcoord[ip], f.Ycoord[ip], f.StackHeight[ip],
+				f.StackDiam[ip], f.StackTemp[ip], f.StackVel[ip])
+		}
+		if err := wr.flush(); err != nil {
+			return nil, err
+		}
+	}
+	return wr, nil
+}
+
+// WriteHour writes the next hour of data. Data must hold an array
+// for every species in the file, with Nx*Ny*Nz values (in GLIndex
+// order) for gridded files or Npts values for PTSOURCE files. The time
+// of each hour is calculated from the start time in the header and, for
+// files with shorter time steps, the time step set by SetTimeStep.
+func (w *Writer) WriteHour(Data map[string][]float32) error {
+	return w.WriteHourOverrides(Data, nil)
+}
+
+// WriteHourOverrides is like WriteHour, but for PTSOURCE files it also
+// writes the override record of each stack, which is written as zeros if
+// overrides is nil. It is an error to give overrides for gridded files.
+func (w *Writer) WriteHourOverrides(Data map[string][]float32, overrides []StackOverride) error {
+	f := w.f
+	if overrides != nil && (f.Name != "PTSOURCE" || len(overrides) != int(f.Npts)) {
+		return fmt.Errorf("there are %d stack overrides; there should be one per stack of a PTSOURCE file",
+			len(overrides))
+	}
+	var n int
+	switch f.Name {
+	case "EMISSIONS", "AVERAGE", "AIRQUALITY", "INSTANT":
+		n = int(f.Nx * f.Ny * f.Nz)
+	case "PTSOURCE":
+		n = int(f.Npts)
+	default:
+		return fmt.Errorf("%w: %v", ErrUnknownFileType, f.Name)
+	}
+	for _, spname := range f.Spnames {
+		if len(Data[spname]) != n {
+			return fmt.Errorf("species %v has %d values; it should have %d",
+				spname, len(Data[spname]), n)
+		}
+	}
+
+	if err := w.putTime(); err != nil {
+		return err
+	}
+	if f.Name == "PTSOURCE" {
+		w.put(int32(1), f.Npts)
+		if err := w.flush(); err != nil {
+			return err
+		}
+		for ip := int32(0); ip < f.Npts; ip++ {
+			var o StackOverride
+			if overrides != nil {
+				o = overrides[ip]
+			}
+			w.put(o.I, o.J, o.K, o.Flow, o.PlumeHeight)
+		}
+		if err := w.flush(); err != nil {
+			return err
+		}
+		for _, spname := range f.Spnames {
+			w.put(int32(1))
+			w.putStr(spname, 40)
+			w.put(Data[spname])
+			if err := w.flush(); err != nil {
+				return err
+			}
+		}
+	} else {
+		nxy := f.Nx * f.Ny
+		for _, spname := range f.Spnames {
+			for k := int32(0); k < f.Nz; k++ {
+				w.put(int32(1))
+				w.putStr(spname, 40)
+				w.put(Data[spname][k*nxy : (k+1)*nxy])
+				if err := w.flush(); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	w.nhrs++
+	return nil
+}
+
+// SetTimeStep sets the length of the time steps written by WriteHour to
+// the given number of minutes, which must divide an hour evenly, for
+// files with data more often than every hour. It must be called before
+// any data are written.
+func (w *Writer) SetTimeStep(minutes int) error {
+	if err := checkTimeStep(minutes); err != nil {
+		return err
+	}
+	if w.nhrs > 0 {
+		return fmt.Errorf("cannot change the time step after data have been written")
+	}
+	w.step = float32(minutes) / 60
+	return nil
+}
+
+// checkTimeStep returns an error if a time step of the given number of
+// minutes does not divide an hour evenly.
+func checkTimeStep(minutes int) error {
+	if minutes <= 0 || 60%minutes != 0 {
+		return fmt.Errorf("time step of %d minutes does not divide an hour evenly", minutes)
+	}
+	return nil
+}
+
+// putTime writes the time record of the next time step, calculated from
+// the start time in the header.
+func (w *Writer) putTime() error {
+	step := w.step
+	if step == 0 {
+		step = 1
+	}
+	f := w.f
+	bdate, btime := addTime(f.sdate, f.begtim, float32(w.nhrs)*step)
+	edate, etime := addTime(f.sdate, f.begtim, float32(w.nhrs+1)*step)
+	w.put(bdate, btime, edate, etime)
+	return w.flush()
+}
+
+// Close closes the underlying file if the Writer was created by Create.
+func (w *Writer) Close() error {
+	if w.c != nil {
+		return w.c.Close()
+	}
+	return nil
+}
+
+// put adds the given values to the current record.
+func (w *Writer) put(values ...interface{}) {
+	for _, v := range values {
+		binary.Write(&w.buf, ByteOrder, v)
+	}
+}
+
+// putStr adds s to the current record, padded with spaces to length/4
+// characters, with each character taking up four bytes.
+func (w *Writer) putStr(s string, length int) {
+	for i := 0; i < length/4; i++ {
+		c := byte(' ')
+		if i < len(s) {
+			c = s[i]
+		}
+		w.buf.Write([]byte{c, ' ', ' ', ' '})
+	}
+}
+
+// flush writes the current record to the output, surrounded by Fortran
+// record length markers.
+func (w *Writer) flush() error {
+	defer w.buf.Reset()
+	marker := int32(w.buf.Len())
+	if err := binary.Write(w.w, ByteOrder, marker); err != nil {
+		return err
+	}
+	if _, err := w.w.Write(w.buf.Bytes()); err != nil {
+		return err
+	}
+	return binary.Write(w.w, ByteOrder, marker)
+}
+
+// AddHours adds n hours to a date in YYJJJ (or YYYYJJJ) format and a
+// time in hours, as in the headers and time records of files, and
+// returns the new date and time.
+func AddHours(date int32, time float32, n int) (int32, float32) {
+	return addTime(date, time, float32(n))
+}
+
+// addTime adds a number of hours, which need not be whole, to a date in
+// YYJJJ (or YYYYJJJ) format and a time in hours.
+func addTime(date int32, time float32, hours float32) (int32, float32) {
+	time += hours
+	for time >= 24 {
+		time -= 24
+		year, day := date/1000, date%1000
+		daysInYear := int32(365)
+		if year%4 == 0 && (year%100 != 0 || year%400 == 0) {
+			daysInYear = 366
+		}
+		if day >= daysInYear {
+			year, day = year+1, 0
+			if year == 100 {
+				year = 0
+			}
+		}
+		date = year*1000 + day + 1
+	}
+	return date, time
+}
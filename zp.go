@@ -0,0 +1,146 @@
+package uam
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Layers gives the vertical layer structure of the model.
+type Layers interface {
+	// LayerTops returns the height (m above ground) of the top of each
+	// layer in the column at grid cell (i, j).
+	LayerTops(i, j int32) []float32
+}
+
+// UniformLayers is a layer structure that is the same in every grid
+// column. Each element is the height (m) of the top of a layer.
+type UniformLayers []float32
+
+// LayerTops returns the layer top heights, which do not depend on i or j.
+func (l UniformLayers) LayerTops(i, j int32) []float32 { return l }
+
+// ReadLayerTops reads layer top heights (m) from a text file with one
+// or more whitespace-separated numbers per line, ordered from the lowest
+// layer upward. Text following a '#' is ignored.
+func ReadLayerTops(r io.Reader) (UniformLayers, error) {
+	var tops UniformLayers
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if c := strings.Index(line, "#"); c >= 0 {
+			line = line[:c]
+		}
+		for _, field := range strings.Fields(line) {
+			v, err := strconv.ParseFloat(field, 32)
+			if err != nil {
+				return nil, err
+			}
+			tops = append(tops, float32(v))
+		}
+	}
+	return tops, scanner.Err()
+}
+
+// ZP holds the contents of a CAMx height/pressure file, which for
+// each hour contains a pair of records per layer giving the height of
+// the top of the layer (m above ground) and the pressure (mb).
+type ZP struct {
+	Nx, Ny, Nz int32
+	Hour       []float32   // hour of each time step
+	Date       []int32     // date of each time step
+	Height     [][]float32 // Height[hour][GLIndex(k,j,i)], m
+	Pressure   [][]float32 // Pressure[hour][GLIndex(k,j,i)], mb
+}
+
+// ReadZP reads a CAMx height/pressure file for a grid with the given
+// number of cells and layers. Options such as WithMarkerSize and
+// WithStrict apply as they do to Open.
+func ReadZP(filename string, nx, ny, nz int32, opts ...Option) (*ZP, error) {
+	f, err := openMet(filename, nx, ny, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	z := &ZP{Nx: nx, Ny: ny, Nz: nz}
+	for {
+		height := make([]float32, nx*ny*nz)
+		press := make([]float32, nx*ny*nz)
+		for k := int32(0); k < nz; k++ {
+			for c, v := range [][]float32{height, press} {
+				hour, date, err := f.readMetRecord(v[k*nx*ny : (k+1)*nx*ny])
+				if err == io.EOF && k == 0 && c == 0 && len(z.Hour) > 0 {
+					return z, nil
+				} else if err != nil {
+					return nil, fmt.Errorf("reading ZP file %v: %v", filename, err)
+				}
+				if k == 0 && c == 0 {
+					z.Hour = append(z.Hour, hour)
+					z.Date = append(z.Date, date)
+				}
+			}
+		}
+		z.Height = append(z.Height, height)
+		z.Pressure = append(z.Pressure, press)
+	}
+}
+
+// openMet opens a CAMx meteorological input file, whose records each
+// hold an hour, a date, and a 2D field of nx*ny values. The size of the
+// record markers is detected from the first record unless it is set in
+// opts.
+func openMet(filename string, nx, ny int32, opts []Option) (*UAM, error) {
+	fid, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	f := newUAM(opts)
+	f.closer, f.name = fid, filename
+	f.r = newStream(fid, f.bufSize)
+	if err = f.detectMarkerSize(8 + 4*int64(nx)*int64(ny)); err != nil {
+		fid.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// readMetRecord reads a single (hour, date, 2D field) record, as used in
+// CAMx meteorological input files, into data.
+func (f *UAM) readMetRecord(data []float32) (hour float32, date int32, err error) {
+	n := 8 + 4*int64(len(data))
+	if err = f.beginRecord(n); err != nil {
+		return
+	}
+	if hour, err = readFloat(f.r); err != nil {
+		return
+	}
+	if date, err = readInt(f.r); err != nil {
+		return
+	}
+	if err = f.r.readFloats(data); err != nil {
+		return
+	}
+	err = f.endRecord(n)
+	return
+}
+
+// Layers returns the layer structure for time step hour.
+func (z *ZP) Layers(hour int) Layers {
+	return zpLayers{z: z, hour: hour}
+}
+
+type zpLayers struct {
+	z    *ZP
+	hour int
+}
+
+func (l zpLayers) LayerTops(i, j int32) []float32 {
+	tops := make([]float32, l.z.Nz)
+	for k := range tops {
+		tops[k] = l.z.Height[l.hour][(int32(k)*l.z.Ny+j)*l.z.Nx+i]
+	}
+	return tops
+}
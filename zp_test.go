@@ -0,0 +1,101 @@
+package uam_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ctessum/uam"
+)
+
+// writeMet writes a meteorological input file with the given record
+// marker size, where each record holds an hour, a date, and the values
+// in fields.
+func writeMet(t *testing.T, markerSize int, hours []float32, fields [][]float32) string {
+	t.Helper()
+	perHour := len(fields) / len(hours)
+	var b bytes.Buffer
+	for n, vals := range fields {
+		marker := make([]byte, markerSize)
+		if markerSize == 8 {
+			binary.BigEndian.PutUint64(marker, uint64(8+4*len(vals)))
+		} else {
+			binary.BigEndian.PutUint32(marker, uint32(8+4*len(vals)))
+		}
+		b.Write(marker)
+		binary.Write(&b, binary.BigEndian, hours[n/perHour])
+		binary.Write(&b, binary.BigEndian, int32(16001))
+		binary.Write(&b, binary.BigEndian, vals)
+		b.Write(marker)
+	}
+	filename := filepath.Join(t.TempDir(), "met.bin")
+	if err := os.WriteFile(filename, b.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+func TestReadZP(t *testing.T) {
+	const nx, ny, nz = 3, 2, 2
+	hours := []float32{0, 1}
+	var fields [][]float32
+	for h := range hours {
+		for k := 0; k < nz; k++ {
+			for _, base := range []float32{100, 1000} { // height, pressure
+				vals := make([]float32, nx*ny)
+				for i := range vals {
+					vals[i] = base*float32(k+1) - float32(10*h+i)
+				}
+				fields = append(fields, vals)
+			}
+		}
+	}
+	for _, markerSize := range []int{4, 8} {
+		filename := writeMet(t, markerSize, hours, fields)
+		for _, opts := range [][]uam.Option{nil, {uam.WithMarkerSize(markerSize), uam.WithStrict()}} {
+			z, err := uam.ReadZP(filename, nx, ny, nz, opts...)
+			if err != nil {
+				t.Fatalf("%d-byte markers: %v", markerSize, err)
+			}
+			if len(z.Hour) != 2 || z.Hour[1] != 1 || z.Date[1] != 16001 {
+				t.Fatalf("%d-byte markers: got hours %v %v", markerSize, z.Hour, z.Date)
+			}
+			for h := range hours {
+				for k := 0; k < nz; k++ {
+					for i := 0; i < nx*ny; i++ {
+						n := k*nx*ny + i
+						if got, want := z.Height[h][n], fields[2*(h*nz+k)][i]; got != want {
+							t.Errorf("%d-byte markers: height[%d][%d] = %g, want %g", markerSize, h, n, got, want)
+						}
+						if got, want := z.Pressure[h][n], fields[2*(h*nz+k)+1][i]; got != want {
+							t.Errorf("%d-byte markers: pressure[%d][%d] = %g, want %g", markerSize, h, n, got, want)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	filename := writeMet(t, 4, hours, fields)
+	if _, err := uam.ReadZP(filename, nx, ny, nz, uam.WithMarkerSize(8), uam.WithStrict()); err == nil {
+		t.Error("wrong marker size: got no error")
+	}
+}
+
+func TestReadTemperature(t *testing.T) {
+	const nx, ny, nz = 2, 2, 3
+	hours := []float32{5}
+	fields := [][]float32{{290, 291, 292, 293}}
+	for k := 0; k < nz; k++ {
+		fields = append(fields, []float32{280, 281, 282, float32(283 - k)})
+	}
+	temp, err := uam.ReadTemperature(writeMet(t, 8, hours, fields), nx, ny, nz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if temp.Hour[0] != 5 || temp.Surface[0][3] != 293 || temp.Air[0][2*nx*ny+3] != 281 {
+		t.Errorf("got hour %g, surface %v, air %v", temp.Hour[0], temp.Surface[0], temp.Air[0])
+	}
+}